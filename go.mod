@@ -3,9 +3,11 @@ module github.com/aliyun/ossutil
 go 1.13
 
 require (
+	github.com/aliyun/alibaba-cloud-sdk-go v1.63.107
 	github.com/aliyun/aliyun-oss-go-sdk v3.0.2+incompatible
 	github.com/alyu/configparser v0.0.0-20191103060215-744e9a66e7bc
 	github.com/droundy/goopt v0.0.0-20220217183150-48d6390ad4d1
+	github.com/klauspost/compress v1.11.13
 	github.com/syndtr/goleveldb v1.0.0
 	golang.org/x/crypto v0.17.0
 	golang.org/x/time v0.0.0-20220722155302-e5dcc9cfc0b9 // indirect
@@ -85,6 +85,7 @@ var allPartSizeCommand = AllPartSizeCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -103,6 +104,10 @@ var allPartSizeCommand = AllPartSizeCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
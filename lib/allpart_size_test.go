@@ -19,6 +19,7 @@ func (s *OssutilCommandSuite) TestAllPartSize(c *C) {
 
 	// create file
 	fileName := "test-ossutil-appendfile" + randLowStr(5)
+	defer os.Remove(fileName)
 	strText := randLowStr(1024 * 10)
 	s.createFile(fileName, strText, c)
 
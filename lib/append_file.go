@@ -1,7 +1,11 @@
 package lib
 
 import (
+	"bytes"
 	"fmt"
+	"hash/crc64"
+	"io"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -17,6 +21,7 @@ var specChineseAppendFile = SpecText{
 
 	syntaxText: ` 
 	ossutil appendfromfile local_file_name oss://bucket/object [options]
+    ossutil appendfromfile - oss://bucket/object [--batch-size=size] [--batch-interval=interval] [options]
 `,
 
 	detailHelpText: ` 
@@ -26,11 +31,18 @@ var specChineseAppendFile = SpecText{
 
 用法：
 
-    该命令只有一种用法：
+    该命令有两种用法：
 
     1) ossutil appendfromfile local_file_name oss://bucket/object [--meta=meta-value]
       将local_file_name内容以append方式上传到可追加的object
       如果输入--meta选项，可以设置object的meta信息
+
+    2) ossutil appendfromfile - oss://bucket/object [--batch-size=size] [--batch-interval=interval]
+      持续读取标准输入直到结束，缓存的数据达到--batch-size(默认值：1M)，或者等待了--batch-interval毫秒(默认值：1000)
+      时追加一次，可以放在日志管道的末尾
+      如果追加时返回PositionNotEqualToLength，从错误的x-oss-next-append-position获取object的长度，如果
+      object是之前超时的请求追加后的内容，认为追加成功，否则在该位置重新追加
+      每次追加后校验object整体的crc64
 `,
 
 	sampleText: ` 
@@ -42,6 +54,9 @@ var specChineseAppendFile = SpecText{
     
     3) 以访问者付费模式上传文件内容
        ossutil appendfromfile local_file_name oss://bucket/object --payer requester
+
+    4) 将命令的输出持续追加上传，每256K或者5秒追加一次
+       tail -f app.log | ossutil appendfromfile - oss://bucket/app.log --batch-size 256K --batch-interval 5000
 `,
 }
 
@@ -52,6 +67,7 @@ var specEnglishAppendFile = SpecText{
 
 	syntaxText: ` 
 	ossutil appendfromfile local_file_name oss://bucket/object [options]
+    ossutil appendfromfile - oss://bucket/object [--batch-size=size] [--batch-interval=interval] [options]
 `,
 
 	detailHelpText: ` 
@@ -63,11 +79,19 @@ var specEnglishAppendFile = SpecText{
 
 Usages：
 
-    There is only one usage for this command:：
+    There are two usages for this command:
 
     1) ossutil appendfromfile local_file_name oss://bucket/object [--meta=meta-value]
       Upload the local_file_name content to the object by append mode
       If you input the --meta option, you can set the meta value of the object
+
+    2) ossutil appendfromfile - oss://bucket/object [--batch-size=size] [--batch-interval=interval]
+      Read stdin until it ends, the buffered data is appended once it reaches --batch-size(default: 1M),
+      or it has waited for --batch-interval milliseconds(default: 1000), so it can sit at the end of a log pipeline
+      If PositionNotEqualToLength is returned, the length of the object is got from x-oss-next-append-position
+      of the error, the append succeeds if the object has the content appended by the former timed out request,
+      otherwise the data is appended again at the position
+      The crc64 of the whole object is checked after each append
 `,
 
 	sampleText: ` 
@@ -79,6 +103,9 @@ Usages：
     
     3) Uploads file content with requester payment mode
        ossutil appendfromfile local_file_name oss://bucket/object --payer requester
+
+    4) Appends the output of the command continuously, once every 256K or 5 seconds
+       tail -f app.log | ossutil appendfromfile - oss://bucket/app.log --batch-size 256K --batch-interval 5000
 `,
 }
 
@@ -137,6 +164,7 @@ var appendFileCommand = AppendFileCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -147,6 +175,8 @@ var appendFileCommand = AppendFileCommand{
 			OptionEncodingType,
 			OptionMeta,
 			OptionMaxUpSpeed,
+			OptionBatchSize,
+			OptionBatchInterval,
 			OptionLogLevel,
 			OptionRequestPayer,
 			OptionPassword,
@@ -158,6 +188,10 @@ var appendFileCommand = AppendFileCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -207,8 +241,12 @@ func (afc *AppendFileCommand) RunCommand() error {
 	afc.afOption.bucketName = srcBucketUrL.bucket
 	afc.afOption.objectName = srcBucketUrL.object
 
-	// check input file
 	fileName := afc.command.args[0]
+	if fileName == StdioURLString {
+		return afc.appendFromStdin()
+	}
+
+	// check input file
 	stat, err := os.Stat(fileName)
 	if err != nil {
 		return err
@@ -300,3 +338,246 @@ func (afc *AppendFileCommand) AppendFromFile(bucket *oss.Bucket, position int64)
 		return nil
 	}
 }
+
+// appendStream is the state of appending the stream to the appendable object
+type appendStream struct {
+	position int64
+	crc      uint64
+	crcKnown bool
+	appended int64
+	options  []oss.Option // the options only for creating the object, like the meta
+}
+
+func (afc *AppendFileCommand) appendFromStdin() error {
+	batchSize, batchInterval, err := afc.getBatchOptions()
+	if err != nil {
+		return err
+	}
+
+	bucket, err := afc.command.ossBucket(afc.afOption.bucketName)
+	if err != nil {
+		return err
+	}
+
+	stream, err := afc.openAppendStream(bucket)
+	if err != nil {
+		return err
+	}
+
+	if err = afc.appendFromReader(bucket, os.Stdin, stream, batchSize, batchInterval); err != nil {
+		return err
+	}
+	fmt.Printf("append %d bytes from stdin, the object new size is %d\n", stream.appended, stream.position)
+	return nil
+}
+
+func (afc *AppendFileCommand) getBatchOptions() (int64, time.Duration, error) {
+	size, _ := GetString(OptionBatchSize, afc.command.options)
+	if size == "" {
+		size = AppendBatchSize
+	}
+	batchSize, err := parseFilterSize(size)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid option value of %s, %s", OptionBatchSize, err.Error())
+	}
+	if batchSize <= 0 || batchSize > MaxAppendObjectSize {
+		return 0, 0, fmt.Errorf("invalid option value of %s, %s, it should be between 1 and %d", OptionBatchSize, size, MaxAppendObjectSize)
+	}
+
+	batchInterval := int64(AppendBatchInterval)
+	if interval, err := GetInt(OptionBatchInterval, afc.command.options); err == nil {
+		batchInterval = interval
+	}
+	return batchSize, time.Duration(batchInterval) * time.Millisecond, nil
+}
+
+// openAppendStream gets the position and the crc64 of the object to append to, the object must be appendable if
+// it exists
+func (afc *AppendFileCommand) openAppendStream(bucket *oss.Bucket) (*appendStream, error) {
+	// the crc64 of the empty object is 0, so that the first append is checked too
+	stream := &appendStream{crcKnown: true}
+	props, err := afc.command.ossGetObjectStatRetry(bucket, afc.afOption.objectName, afc.commonOptions...)
+	if err != nil && !isNoSuchKeyError(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		if props.Get(StatObjectType) != "Appendable" {
+			return nil, fmt.Errorf("oss://%s/%s is not an appendable object", afc.afOption.bucketName, afc.afOption.objectName)
+		}
+		if afc.afOption.ossMeta != "" {
+			return nil, fmt.Errorf("setting meta on existing append object is not supported")
+		}
+		if stream.position, err = strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64); err != nil {
+			return nil, err
+		}
+		stream.crc, err = strconv.ParseUint(props.Get(oss.HTTPHeaderOssCRC64), 10, 64)
+		stream.crcKnown = err == nil
+		return stream, nil
+	}
+
+	if afc.afOption.ossMeta != "" {
+		metas, err := afc.command.parseHeaders(afc.afOption.ossMeta, false)
+		if err != nil {
+			return nil, err
+		}
+		if stream.options, err = afc.command.getOSSOptions(headerOptionMap, metas); err != nil {
+			return nil, err
+		}
+	}
+	return stream, nil
+}
+
+// appendFromReader reads the reader until EOF, the data is appended once it reaches batchSize, or it has waited
+// for batchInterval
+func (afc *AppendFileCommand) appendFromReader(bucket *oss.Bucket, reader io.Reader, stream *appendStream, batchSize int64, batchInterval time.Duration) error {
+	chData := make(chan []byte)
+	chErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		defer close(chData)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := reader.Read(buf)
+			if n > 0 {
+				select {
+				case chData <- append([]byte(nil), buf[:n]...):
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					chErr <- err
+				}
+				return
+			}
+		}
+	}()
+
+	var batch []byte
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	flush := func() error {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		err := afc.appendBatch(bucket, stream, batch)
+		batch = nil
+		return err
+	}
+
+	for {
+		select {
+		case data, ok := <-chData:
+			if !ok {
+				if err := flush(); err != nil {
+					return err
+				}
+				select {
+				case err := <-chErr:
+					return err
+				default:
+					return nil
+				}
+			}
+			if len(batch) == 0 {
+				timer = time.NewTimer(batchInterval)
+				timeout = timer.C
+			}
+			batch = append(batch, data...)
+			if int64(len(batch)) >= batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case <-timeout:
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// appendBatch appends the data at the position of the stream, if the position isn't the length of the object, it's
+// got from x-oss-next-append-position of the error, then the data is appended again at it, unless the object is
+// the one the data was appended to by the former request which failed to get the response
+func (afc *AppendFileCommand) appendBatch(bucket *oss.Bucket, stream *appendStream, data []byte) error {
+	size := int64(len(data))
+	dataCRC := crc64.Checksum(data, crc64.MakeTable(crc64.ECMA))
+	for retry := 0; ; retry++ {
+		if stream.position+size > MaxAppendObjectSize {
+			return fmt.Errorf("the object size will be bigger than %d, it is not supported by append", MaxAppendObjectSize)
+		}
+
+		var result *oss.AppendObjectResult
+		var header http.Header
+		var mismatch error
+		err := afc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+			options := append(append([]oss.Option{}, stream.options...), afc.commonOptions...)
+			if stream.crcKnown {
+				options = append(options, oss.InitCRC(stream.crc))
+			}
+			request := &oss.AppendObjectRequest{
+				ObjectKey: afc.afOption.objectName,
+				Reader:    bytes.NewReader(data),
+				Position:  stream.position,
+			}
+			var err error
+			result, err = bucket.DoAppendObject(request, withResponseHeader(options, respHeader))
+			header = *respHeader
+			if _, ok := err.(oss.CRCCheckError); ok {
+				// the data is appended, it would be appended again by retrying
+				mismatch = err
+				return nil
+			}
+			return err
+		})
+		if mismatch != nil {
+			return ObjectError{mismatch, afc.afOption.bucketName, afc.afOption.objectName}
+		}
+		if err == nil {
+			stream.position = result.NextPosition
+			stream.crc, stream.crcKnown = result.CRC, true
+			stream.appended += size
+			stream.options = nil
+			LogInfo("append %d bytes to oss://%s/%s, the next position is %d\n", size, afc.afOption.bucketName, afc.afOption.objectName, stream.position)
+			return nil
+		}
+
+		serviceErr, ok := err.(oss.ServiceError)
+		if !ok || serviceErr.Code != "PositionNotEqualToLength" || retry >= MaxAppendPositionRetry {
+			return ObjectError{err, afc.afOption.bucketName, afc.afOption.objectName}
+		}
+		next, perr := strconv.ParseInt(header.Get(oss.HTTPHeaderOssNextAppendPosition), 10, 64)
+		if perr != nil {
+			return ObjectError{err, afc.afOption.bucketName, afc.afOption.objectName}
+		}
+		crc, crcErr := strconv.ParseUint(header.Get(oss.HTTPHeaderOssCRC64), 10, 64)
+		if crcErr != nil {
+			props, err := afc.command.ossGetObjectStatRetry(bucket, afc.afOption.objectName, afc.commonOptions...)
+			if err != nil {
+				return err
+			}
+			crc, crcErr = strconv.ParseUint(props.Get(oss.HTTPHeaderOssCRC64), 10, 64)
+		}
+
+		stream.options = nil
+		if stream.crcKnown && crcErr == nil && next == stream.position+size && crc == oss.CRC64Combine(stream.crc, dataCRC, uint64(size)) {
+			stream.position, stream.crc = next, crc
+			stream.appended += size
+			LogInfo("the data appended to oss://%s/%s before is found at position %d\n", afc.afOption.bucketName, afc.afOption.objectName, next-size)
+			return nil
+		}
+
+		LogWarn("the position of oss://%s/%s is %d instead of %d, append at it\n", afc.afOption.bucketName, afc.afOption.objectName, next, stream.position)
+		stream.position = next
+		stream.crc, stream.crcKnown = crc, crcErr == nil
+	}
+}
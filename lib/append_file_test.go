@@ -2,8 +2,13 @@ package lib
 
 import (
 	"fmt"
+	"hash/crc64"
+	"io"
 	"io/ioutil"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"time"
 
 	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
 	. "gopkg.in/check.v1"
@@ -16,6 +21,7 @@ func (s *OssutilCommandSuite) TestAppendFileSuccessWithoutMeta(c *C) {
 
 	// create file
 	fileName := "test-ossutil-appendfile" + randLowStr(5)
+	defer os.Remove(fileName)
 	strText := randLowStr(1024)
 	s.createFile(fileName, strText, c)
 
@@ -75,6 +81,7 @@ func (s *OssutilCommandSuite) TestAppendFileSuccessWithMeta(c *C) {
 
 	// create file
 	fileName := "test-ossutil-appendfile" + randLowStr(5)
+	defer os.Remove(fileName)
 	strText := randLowStr(1024)
 	s.createFile(fileName, strText, c)
 
@@ -147,6 +154,7 @@ func (s *OssutilCommandSuite) TestAppendFileError(c *C) {
 
 	// create file
 	fileName := "test-ossutil-appendfile" + randLowStr(5)
+	defer os.Remove(fileName)
 	strText := randLowStr(1024)
 	s.createFile(fileName, strText, c)
 
@@ -203,6 +211,7 @@ func (s *OssutilCommandSuite) TestAppendFileClientError(c *C) {
 
 	// create file
 	fileName := "test-ossutil-appendfile" + randLowStr(5)
+	defer os.Remove(fileName)
 	strText := randLowStr(1024)
 	s.createFile(fileName, strText, c)
 
@@ -239,6 +248,7 @@ func (s *OssutilCommandSuite) TestAppendFileObjectExistMetaError(c *C) {
 
 	// create file
 	fileName := "test-ossutil-appendfile" + randLowStr(5)
+	defer os.Remove(fileName)
 	strText := randLowStr(1024)
 	s.createFile(fileName, strText, c)
 
@@ -273,6 +283,7 @@ func (s *OssutilCommandSuite) TestAppendFileParserMetaError(c *C) {
 
 	// create file
 	fileName := "test-ossutil-appendfile" + randLowStr(5)
+	defer os.Remove(fileName)
 	strText := randLowStr(1024)
 	s.createFile(fileName, strText, c)
 
@@ -306,6 +317,7 @@ func (s *OssutilCommandSuite) TestAppendFileAppendFromFileError(c *C) {
 
 	// create file
 	fileName := "test-ossutil-appendfile" + randLowStr(5)
+	defer os.Remove(fileName)
 	strText := randLowStr(1024)
 	s.createFile(fileName, strText, c)
 
@@ -354,6 +366,7 @@ func (s *OssutilCommandSuite) TestAppendFileWithPayer(c *C) {
 
 	// create file
 	fileName := "test-ossutil-appendfile" + randLowStr(5)
+	defer os.Remove(fileName)
 	strText := randLowStr(100)
 	s.createFile(fileName, strText, c)
 
@@ -403,3 +416,71 @@ func (s *OssutilCommandSuite) TestAppendFileWithPayer(c *C) {
 	os.Remove(fileName)
 	os.Remove(downFileName)
 }
+
+func (s *OssutilCommandSuite) TestAppendFileFromReader(c *C) {
+	as := &appendObjectServer{objects: map[string]string{"app.log": "a\n"}}
+	svr := httptest.NewServer(as)
+	defer svr.Close()
+	client, err := oss.New(svr.URL, "ak", "sk")
+	c.Assert(err, IsNil)
+	bucket, err := client.Bucket("bucket")
+	c.Assert(err, IsNil)
+
+	afc := AppendFileCommand{command: Command{options: OptionMapType{}}}
+	afc.afOption.bucketName = "bucket"
+	afc.afOption.objectName = "app.log"
+	stream, err := afc.openAppendStream(bucket)
+	c.Assert(err, IsNil)
+	c.Assert(stream.position, Equals, int64(2))
+	c.Assert(stream.crcKnown, Equals, true)
+
+	// appended once the batch size is reached, and once the batch interval is passed
+	reader, writer := io.Pipe()
+	chErr := make(chan error, 1)
+	go func() {
+		chErr <- afc.appendFromReader(bucket, reader, stream, 4, 50*time.Millisecond)
+	}()
+	writer.Write([]byte("b\nc\n"))
+	writer.Write([]byte("d\n"))
+	time.Sleep(300 * time.Millisecond)
+	as.mutex.Lock()
+	c.Assert(as.objects["app.log"], Equals, "a\nb\nc\nd\n")
+	as.mutex.Unlock()
+	writer.Write([]byte("e\n"))
+	writer.Close()
+	c.Assert(<-chErr, IsNil)
+	c.Assert(as.objects["app.log"], Equals, "a\nb\nc\nd\ne\n")
+	c.Assert(stream.position, Equals, int64(10))
+	c.Assert(stream.appended, Equals, int64(8))
+
+	// appended by another writer, the data is appended again at x-oss-next-append-position
+	as.set("app.log", as.objects["app.log"]+"x\n")
+	c.Assert(afc.appendBatch(bucket, stream, []byte("f\n")), IsNil)
+	c.Assert(as.objects["app.log"], Equals, "a\nb\nc\nd\ne\nx\nf\n")
+	c.Assert(stream.position, Equals, int64(14))
+
+	// appended by the request which failed to get the response, it isn't appended again
+	as.set("app.log", as.objects["app.log"]+"g\n")
+	c.Assert(afc.appendBatch(bucket, stream, []byte("g\n")), IsNil)
+	c.Assert(as.objects["app.log"], Equals, "a\nb\nc\nd\ne\nx\nf\ng\n")
+	c.Assert(stream.position, Equals, int64(16))
+	c.Assert(stream.crc, Equals, crc64.Checksum([]byte(as.objects["app.log"]), crc64.MakeTable(crc64.ECMA)))
+
+	// the new object
+	afc.afOption.objectName = "new.log"
+	stream, err = afc.openAppendStream(bucket)
+	c.Assert(err, IsNil)
+	c.Assert(stream.position, Equals, int64(0))
+	c.Assert(afc.appendFromReader(bucket, strings.NewReader("hello"), stream, 1024, time.Second), IsNil)
+	c.Assert(as.objects["new.log"], Equals, "hello")
+
+	size := "0"
+	afc.command.options = OptionMapType{OptionBatchSize: &size}
+	_, _, err = afc.getBatchOptions()
+	c.Assert(err, NotNil)
+	size = "256K"
+	batchSize, batchInterval, err := afc.getBatchOptions()
+	c.Assert(err, IsNil)
+	c.Assert(batchSize, Equals, int64(256*1024))
+	c.Assert(batchInterval, Equals, time.Duration(AppendBatchInterval)*time.Millisecond)
+}
@@ -0,0 +1,304 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// attributeFilter selects the objects and files by their attributes besides the name, an object or a file
+// is operated only if it matches all the conditions set. The storage class, tags and content type are only
+// known for objects, the tags and content type need a request for each object, so they are checked after
+// the attributes returned by listing.
+type attributeFilter struct {
+	command        *Command
+	minSize        int64     // -1 if not set
+	maxSize        int64     // -1 if not set
+	olderThan      time.Time // the last modified time must be before it, zero if not set
+	newerThan      time.Time // the last modified time must be after it, zero if not set
+	storageClasses []string
+	tags           []oss.Tag // the tag without value matches any value of the key
+	contentTypes   []string
+}
+
+var filterStorageClassList = append(append([]string{}, storageClassList...), StorageDeepColdArchive)
+
+// attributeFilter builds the filter by the options, it's nil if none of the options is set
+func (cmd *Command) attributeFilter() (*attributeFilter, error) {
+	f := &attributeFilter{command: cmd, minSize: -1, maxSize: -1}
+	set := false
+	var err error
+
+	if str, _ := GetString(OptionMinSize, cmd.options); str != "" {
+		if f.minSize, err = parseFilterSize(str); err != nil {
+			return nil, fmt.Errorf("invalid option value of %s, %s", OptionMinSize, err.Error())
+		}
+		set = true
+	}
+	if str, _ := GetString(OptionMaxSize, cmd.options); str != "" {
+		if f.maxSize, err = parseFilterSize(str); err != nil {
+			return nil, fmt.Errorf("invalid option value of %s, %s", OptionMaxSize, err.Error())
+		}
+		set = true
+	}
+	if f.minSize >= 0 && f.maxSize >= 0 && f.minSize > f.maxSize {
+		return nil, fmt.Errorf("min size %d is larger than max size %d", f.minSize, f.maxSize)
+	}
+
+	now := time.Now()
+	if str, _ := GetString(OptionOlderThan, cmd.options); str != "" {
+		if f.olderThan, err = parseFilterTime(str, now); err != nil {
+			return nil, fmt.Errorf("invalid option value of %s, %s", OptionOlderThan, err.Error())
+		}
+		set = true
+	}
+	if str, _ := GetString(OptionNewerThan, cmd.options); str != "" {
+		if f.newerThan, err = parseFilterTime(str, now); err != nil {
+			return nil, fmt.Errorf("invalid option value of %s, %s", OptionNewerThan, err.Error())
+		}
+		set = true
+	}
+	if !f.olderThan.IsZero() && !f.newerThan.IsZero() && !f.newerThan.Before(f.olderThan) {
+		return nil, fmt.Errorf("the time of --newer-than %s is not earlier than the time of --older-than %s",
+			f.newerThan.Format(time.RFC3339), f.olderThan.Format(time.RFC3339))
+	}
+
+	if str, _ := GetString(OptionStorageClass, cmd.options); str != "" {
+		for _, class := range splitFilterValues(str) {
+			pos := FindPosCaseInsen(class, filterStorageClassList)
+			if pos == -1 {
+				return nil, fmt.Errorf("invalid option value of %s, the value: %s is not anyone of %s", OptionStorageClass, class, strings.Join(filterStorageClassList, "/"))
+			}
+			f.storageClasses = append(f.storageClasses, filterStorageClassList[pos])
+		}
+		set = true
+	}
+
+	if str, _ := GetString(OptionHasTag, cmd.options); str != "" {
+		for _, pair := range splitFilterValues(str) {
+			tag := oss.Tag{Key: pair}
+			if pos := strings.Index(pair, "="); pos >= 0 {
+				tag = oss.Tag{Key: pair[:pos], Value: pair[pos+1:]}
+			}
+			if tag.Key == "" {
+				return nil, fmt.Errorf("invalid option value of %s, the key of tag can't be empty: %s", OptionHasTag, pair)
+			}
+			f.tags = append(f.tags, tag)
+		}
+		set = true
+	}
+
+	if str, _ := GetString(OptionContentType, cmd.options); str != "" {
+		for _, contentType := range splitFilterValues(str) {
+			f.contentTypes = append(f.contentTypes, strings.ToLower(contentType))
+		}
+		set = true
+	}
+
+	if !set {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// objectOnly returns the options which can't filter local files
+func (f *attributeFilter) objectOnly() []string {
+	var names []string
+	if f == nil {
+		return names
+	}
+	if len(f.storageClasses) > 0 {
+		names = append(names, "--storage-class")
+	}
+	if len(f.tags) > 0 {
+		names = append(names, "--has-tag")
+	}
+	if len(f.contentTypes) > 0 {
+		names = append(names, "--content-type")
+	}
+	return names
+}
+
+func splitFilterValues(str string) []string {
+	var values []string
+	for _, value := range strings.Split(str, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseFilterSize parses the size like 10M, the unit is 1024 based, the size without unit is bytes
+func parseFilterSize(str string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(str))
+	value = strings.TrimSuffix(value, "B")
+
+	unit := float64(1)
+	if len(value) > 0 {
+		if pos := strings.Index("KMGT", value[len(value)-1:]); pos >= 0 {
+			for i := 0; i <= pos; i++ {
+				unit *= 1024
+			}
+			value = value[:len(value)-1]
+		}
+	}
+
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %s, it should be a number with optional unit B/K/M/G/T", str)
+	}
+	return int64(size * unit), nil
+}
+
+// parseFilterTime parses the duration before now like 30d, or the time like 2006-01-02
+func parseFilterTime(str string, now time.Time) (time.Time, error) {
+	value := strings.TrimSpace(str)
+	if len(value) > 1 {
+		units := map[byte]time.Duration{'s': time.Second, 'm': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+		if unit, ok := units[value[len(value)-1]]; ok {
+			if num, err := strconv.ParseInt(value[:len(value)-1], 10, 64); err == nil && num >= 0 {
+				return now.Add(-time.Duration(num) * unit), nil
+			}
+		}
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %s, it should be a duration like 30d, 12h, 45m or a time like 2006-01-02, 2006-01-02T15:04:05+08:00", str)
+}
+
+// matchAttributes checks the size and the last modified time
+func (f *attributeFilter) matchAttributes(size int64, modified time.Time) bool {
+	if f == nil {
+		return true
+	}
+	if f.minSize >= 0 && size < f.minSize {
+		return false
+	}
+	if f.maxSize >= 0 && size > f.maxSize {
+		return false
+	}
+	if !f.olderThan.IsZero() && !modified.Before(f.olderThan) {
+		return false
+	}
+	if !f.newerThan.IsZero() && !modified.After(f.newerThan) {
+		return false
+	}
+	return true
+}
+
+// matchFile checks the local file, the directories always match since they are not operated by the attributes
+func (f *attributeFilter) matchFile(info os.FileInfo) bool {
+	if f == nil || info.IsDir() {
+		return true
+	}
+	return f.matchAttributes(info.Size(), info.ModTime())
+}
+
+// matchFilePath checks the file walked, the file linked is checked for the symlink
+func (f *attributeFilter) matchFilePath(fpath string, info os.FileInfo) bool {
+	if f == nil {
+		return true
+	}
+	if (info.Mode() & os.ModeSymlink) != 0 {
+		if realInfo, err := os.Stat(fpath); err == nil {
+			info = realInfo
+		}
+	}
+	return f.matchFile(info)
+}
+
+// matchObject checks the listed object, options are the common options of the requests such as payer,
+// the object deleted after listing doesn't match
+func (f *attributeFilter) matchObject(bucket *oss.Bucket, object oss.ObjectProperties, options ...oss.Option) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+	if !f.matchAttributes(object.Size, object.LastModified) {
+		return false, nil
+	}
+	if len(f.storageClasses) > 0 && FindPosCaseInsen(object.StorageClass, f.storageClasses) == -1 {
+		return false, nil
+	}
+
+	if len(f.contentTypes) > 0 {
+		props, err := f.command.ossGetObjectStatRetry(bucket, object.Key, options...)
+		if err != nil {
+			if isNoSuchKeyError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if !f.matchContentType(props.Get(oss.HTTPHeaderContentType)) {
+			return false, nil
+		}
+	}
+
+	if len(f.tags) > 0 {
+		tags, err := f.command.ossGetObjectTaggingRetry(bucket, object.Key, options...)
+		if err != nil {
+			if isNoSuchKeyError(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		if !f.matchTags(tags) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchContentType ignores the parameters such as charset, and the type like image/* matches all the subtypes
+func (f *attributeFilter) matchContentType(contentType string) bool {
+	if pos := strings.Index(contentType, ";"); pos >= 0 {
+		contentType = contentType[:pos]
+	}
+	contentType = strings.ToLower(strings.TrimSpace(contentType))
+	for _, pattern := range f.contentTypes {
+		if pattern == contentType {
+			return true
+		}
+		if strings.HasSuffix(pattern, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *attributeFilter) matchTags(tags []oss.Tag) bool {
+	for _, want := range f.tags {
+		found := false
+		for _, tag := range tags {
+			if tag.Key == want.Key && (want.Value == "" || tag.Value == want.Value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func isNoSuchKeyError(err error) bool {
+	if objectErr, ok := err.(ObjectError); ok {
+		err = objectErr.err
+	}
+	switch e := err.(type) {
+	case oss.ServiceError:
+		return e.StatusCode == 404
+	case oss.UnexpectedStatusCodeError:
+		return e.Got() == 404
+	}
+	return false
+}
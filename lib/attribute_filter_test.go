@@ -0,0 +1,141 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	. "gopkg.in/check.v1"
+)
+
+func newAttributeFilter(options map[string]string) (*attributeFilter, error) {
+	optionMap := OptionMapType{}
+	for name, value := range options {
+		v := value
+		optionMap[name] = &v
+	}
+	return (&Command{options: optionMap}).attributeFilter()
+}
+
+func (s *OssutilCommandSuite) TestAttributeFilterSize(c *C) {
+	for str, size := range map[string]int64{"0": 0, "100": 100, "100B": 100, "1k": 1024, "1.5M": 1536 * 1024, "2GB": 2 << 30, "1T": 1 << 40} {
+		value, err := parseFilterSize(str)
+		c.Assert(err, IsNil)
+		c.Assert(value, Equals, size)
+	}
+	for _, str := range []string{"", "M", "-1", "10X", "abc"} {
+		_, err := parseFilterSize(str)
+		c.Assert(err, NotNil)
+	}
+}
+
+func (s *OssutilCommandSuite) TestAttributeFilterTime(c *C) {
+	now := time.Now()
+	for str, duration := range map[string]time.Duration{"30s": 30 * time.Second, "45m": 45 * time.Minute, "12h": 12 * time.Hour, "7d": 7 * 24 * time.Hour, "2w": 14 * 24 * time.Hour} {
+		t, err := parseFilterTime(str, now)
+		c.Assert(err, IsNil)
+		c.Assert(t.Equal(now.Add(-duration)), Equals, true)
+	}
+
+	t, err := parseFilterTime("2020-01-02", now)
+	c.Assert(err, IsNil)
+	c.Assert(t.Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local)), Equals, true)
+
+	t, err = parseFilterTime("2020-01-02 03:04:05", now)
+	c.Assert(err, IsNil)
+	c.Assert(t.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)), Equals, true)
+
+	t, err = parseFilterTime("2020-01-02T03:04:05Z", now)
+	c.Assert(err, IsNil)
+	c.Assert(t.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), Equals, true)
+
+	for _, str := range []string{"", "d", "-1d", "10x", "2020/01/02"} {
+		_, err := parseFilterTime(str, now)
+		c.Assert(err, NotNil)
+	}
+}
+
+func (s *OssutilCommandSuite) TestAttributeFilterOptions(c *C) {
+	// none of the options is set
+	f, err := newAttributeFilter(map[string]string{OptionMinSize: ""})
+	c.Assert(err, IsNil)
+	c.Assert(f, IsNil)
+	c.Assert(f.matchAttributes(0, time.Now()), Equals, true)
+	c.Assert(len(f.objectOnly()), Equals, 0)
+
+	f, err = newAttributeFilter(map[string]string{
+		OptionStorageClass: "standard, ia,DeepColdArchive",
+		OptionHasTag:       "owner=alice,temp",
+		OptionContentType:  "Image/*,text/plain",
+	})
+	c.Assert(err, IsNil)
+	c.Assert(f.storageClasses, DeepEquals, []string{StorageStandard, StorageIA, StorageDeepColdArchive})
+	c.Assert(f.tags, DeepEquals, []oss.Tag{{Key: "owner", Value: "alice"}, {Key: "temp"}})
+	c.Assert(f.contentTypes, DeepEquals, []string{"image/*", "text/plain"})
+	c.Assert(f.objectOnly(), DeepEquals, []string{"--storage-class", "--has-tag", "--content-type"})
+
+	for _, options := range []map[string]string{
+		{OptionMinSize: "10M", OptionMaxSize: "1M"},
+		{OptionMaxSize: "abc"},
+		{OptionOlderThan: "30d", OptionNewerThan: "10d"},
+		{OptionNewerThan: "yesterday"},
+		{OptionStorageClass: "Standard,Hot"},
+		{OptionHasTag: "=value"},
+	} {
+		_, err = newAttributeFilter(options)
+		c.Assert(err, NotNil)
+	}
+}
+
+func (s *OssutilCommandSuite) TestAttributeFilterMatch(c *C) {
+	f, err := newAttributeFilter(map[string]string{
+		OptionMinSize:   "1K",
+		OptionMaxSize:   "1M",
+		OptionOlderThan: "1d",
+		OptionNewerThan: "30d",
+	})
+	c.Assert(err, IsNil)
+
+	now := time.Now()
+	c.Assert(f.matchAttributes(2048, now.Add(-48*time.Hour)), Equals, true)
+	c.Assert(f.matchAttributes(100, now.Add(-48*time.Hour)), Equals, false)
+	c.Assert(f.matchAttributes(2<<20, now.Add(-48*time.Hour)), Equals, false)
+	c.Assert(f.matchAttributes(2048, now), Equals, false)
+	c.Assert(f.matchAttributes(2048, now.Add(-60*24*time.Hour)), Equals, false)
+
+	// the directory always matches, the small file doesn't
+	fileName := "ossutil-attribute-filter-" + randLowStr(6)
+	c.Assert(ioutil.WriteFile(fileName, []byte("small"), 0600), IsNil)
+	defer os.Remove(fileName)
+	info, err := os.Stat(fileName)
+	c.Assert(err, IsNil)
+	c.Assert(f.matchFilePath(fileName, info), Equals, false)
+	info, err = os.Stat(".")
+	c.Assert(err, IsNil)
+	c.Assert(f.matchFile(info), Equals, true)
+
+	// the storage class is checked without request
+	f, err = newAttributeFilter(map[string]string{OptionStorageClass: "IA,Archive"})
+	c.Assert(err, IsNil)
+	ok, err := f.matchObject(nil, oss.ObjectProperties{Key: "a", StorageClass: "Archive"})
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, true)
+	ok, err = f.matchObject(nil, oss.ObjectProperties{Key: "a", StorageClass: "Standard"})
+	c.Assert(err, IsNil)
+	c.Assert(ok, Equals, false)
+
+	f, err = newAttributeFilter(map[string]string{OptionContentType: "image/*,text/plain", OptionHasTag: "owner=alice,temp"})
+	c.Assert(err, IsNil)
+	c.Assert(f.matchContentType("image/png"), Equals, true)
+	c.Assert(f.matchContentType("Text/Plain; charset=utf-8"), Equals, true)
+	c.Assert(f.matchContentType("text/html"), Equals, false)
+	c.Assert(f.matchContentType("imagex/png"), Equals, false)
+
+	c.Assert(f.matchTags([]oss.Tag{{Key: "owner", Value: "alice"}, {Key: "temp", Value: "1"}}), Equals, true)
+	c.Assert(f.matchTags([]oss.Tag{{Key: "owner", Value: "bob"}, {Key: "temp", Value: "1"}}), Equals, false)
+	c.Assert(f.matchTags([]oss.Tag{{Key: "owner", Value: "alice"}}), Equals, false)
+
+	c.Assert(isNoSuchKeyError(ObjectError{err: oss.ServiceError{StatusCode: 404}}), Equals, true)
+	c.Assert(isNoSuchKeyError(oss.ServiceError{StatusCode: 403}), Equals, false)
+}
@@ -0,0 +1,235 @@
+package lib
+
+import (
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	// autoTuneInterval is the interval to observe the transfer and adjust the concurrency
+	autoTuneInterval = 5 * time.Second
+
+	autoTuneMaxJobs      = 64
+	autoTuneMaxParallel  = 32
+	autoTuneInitParallel = 2
+)
+
+// the reasons of the adjustments, they are logged at debug level
+const (
+	autoTuneThrottled   = "throttled"
+	autoTuneNoGain      = "no gain from the last ramp up"
+	autoTuneHighLatency = "latency grows without throughput gain"
+	autoTuneRampUp      = "ramp up"
+	autoTuneIdle        = "idle"
+)
+
+// AutoTuner adjusts the file level and the part level concurrency of cp by the throughput, the latency
+// and the throttling errors observed in each interval. The concurrency is doubled until the first
+// back off and increased by one since then, it is halved on throttling, and the last ramp up is undone
+// if it brings no more throughput.
+type AutoTuner struct {
+	lock        sync.Mutex
+	cond        *sync.Cond
+	jobs        int // the file level concurrency
+	parallel    int // the part level concurrency
+	maxJobs     int
+	maxParallel int
+	active      int // the files being transferred
+	slowStart   bool
+
+	transferred func() int64
+	lastBytes   int64
+	lastSpeed   int64
+	lastAction  string
+	lastJobs    int
+	lastPara    int
+
+	requests   int
+	latency    time.Duration
+	throttled  int
+	minLatency time.Duration
+
+	chStop chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewAutoTuner creates the tuner with the initial concurrency, transferred returns the bytes transferred so far
+func NewAutoTuner(jobs, parallel, maxJobs, maxParallel int, transferred func() int64) *AutoTuner {
+	t := &AutoTuner{
+		jobs:        jobs,
+		parallel:    parallel,
+		maxJobs:     maxJobs,
+		maxParallel: maxParallel,
+		slowStart:   true,
+		transferred: transferred,
+	}
+	if t.jobs < 1 {
+		t.jobs = 1
+	} else if t.jobs > maxJobs {
+		t.jobs = maxJobs
+	}
+	if t.parallel < 1 {
+		t.parallel = 1
+	} else if t.parallel > maxParallel {
+		t.parallel = maxParallel
+	}
+	t.cond = sync.NewCond(&t.lock)
+	return t
+}
+
+// Start adjusts the concurrency every interval
+func (t *AutoTuner) Start(interval time.Duration) {
+	LogInfo("auto tune concurrency, jobs %d, parallel %d\n", t.jobs, t.parallel)
+	t.lastBytes = t.transferred()
+	t.chStop = make(chan struct{})
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := time.Now()
+		for {
+			select {
+			case now := <-ticker.C:
+				t.adjust(now.Sub(last))
+				last = now
+			case <-t.chStop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops adjusting the concurrency
+func (t *AutoTuner) Stop() {
+	if t == nil || t.chStop == nil {
+		return
+	}
+	close(t.chStop)
+	t.wg.Wait()
+	t.chStop = nil
+}
+
+// acquire waits until the number of the files being transferred is less than the file level concurrency
+func (t *AutoTuner) acquire() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	for t.active >= t.jobs {
+		t.cond.Wait()
+	}
+	t.active++
+	t.lock.Unlock()
+}
+
+func (t *AutoTuner) release() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	t.active--
+	t.lock.Unlock()
+	t.cond.Signal()
+}
+
+// partRoutines returns the part level concurrency for the file of partNum parts
+func (t *AutoTuner) partRoutines(partNum int64) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if partNum < int64(t.parallel) {
+		return int(partNum)
+	}
+	return t.parallel
+}
+
+// observe records the result and the cost of a request
+func (t *AutoTuner) observe(err error, cost time.Duration) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.requests++
+	t.latency += cost
+	if isThrottlingError(err) {
+		t.throttled++
+	}
+}
+
+// isThrottlingError returns true if oss asks the client to slow down
+func isThrottlingError(err error) bool {
+	serviceError, ok := err.(oss.ServiceError)
+	if !ok {
+		return false
+	}
+	return serviceError.StatusCode == 503 || serviceError.Code == "SlowDown" || serviceError.Code == "RequestTimeTooSkewed"
+}
+
+func (t *AutoTuner) adjust(elapsed time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	bytes := t.transferred()
+	speed := int64(0)
+	if elapsed > 0 {
+		speed = int64(float64(bytes-t.lastBytes) / elapsed.Seconds())
+	}
+	var latency time.Duration
+	if t.requests > 0 {
+		latency = t.latency / time.Duration(t.requests)
+		if t.minLatency == 0 || latency < t.minLatency {
+			t.minLatency = latency
+		}
+	}
+
+	jobs, parallel := t.jobs, t.parallel
+	action := ""
+	switch {
+	case t.throttled > 0:
+		action = autoTuneThrottled
+		t.slowStart = false
+		jobs = int(max(1, int64(jobs/2)))
+		parallel = int(max(1, int64(parallel/2)))
+	case speed == 0 && t.requests == 0:
+		action = autoTuneIdle
+	case t.lastAction == autoTuneRampUp && speed*100 < t.lastSpeed*105:
+		action = autoTuneNoGain
+		t.slowStart = false
+		jobs, parallel = t.lastJobs, t.lastPara
+	case latency > 2*t.minLatency && speed*100 < t.lastSpeed*105:
+		action = autoTuneHighLatency
+		t.slowStart = false
+	default:
+		action = autoTuneRampUp
+		// more files help only if all the file level slots are in use, or else more parts help
+		if t.active >= t.jobs && t.jobs < t.maxJobs {
+			jobs = t.rampUp(jobs, t.maxJobs)
+		} else {
+			parallel = t.rampUp(parallel, t.maxParallel)
+		}
+	}
+
+	LogDebug("auto tune: speed %d(byte/s), latency %d(ms), requests %d, throttled %d, active %d, jobs %d->%d, parallel %d->%d, reason: %s\n",
+		speed, latency.Nanoseconds()/1000/1000, t.requests, t.throttled, t.active, t.jobs, jobs, t.parallel, parallel, action)
+
+	t.lastJobs, t.lastPara = t.jobs, t.parallel
+	t.jobs, t.parallel = jobs, parallel
+	t.lastBytes, t.lastSpeed, t.lastAction = bytes, speed, action
+	t.requests, t.latency, t.throttled = 0, 0, 0
+	t.cond.Broadcast()
+}
+
+func (t *AutoTuner) rampUp(n, limit int) int {
+	if t.slowStart {
+		n *= 2
+	} else {
+		n++
+	}
+	if n > limit {
+		n = limit
+	}
+	return n
+}
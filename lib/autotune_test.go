@@ -0,0 +1,119 @@
+package lib
+
+import (
+	"fmt"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) TestAutoTunerAdjust(c *C) {
+	var transferred int64
+	tuner := NewAutoTuner(3, 2, 16, 8, func() int64 { return transferred })
+
+	// idle while nothing is transferred
+	tuner.adjust(time.Second)
+	c.Assert(tuner.lastAction, Equals, autoTuneIdle)
+	c.Assert(tuner.jobs, Equals, 3)
+
+	// the concurrency of parts is ramped up if the file slots are not all in use
+	transferred += 1000
+	tuner.observe(nil, 10*time.Millisecond)
+	tuner.adjust(time.Second)
+	c.Assert(tuner.lastAction, Equals, autoTuneRampUp)
+	c.Assert(tuner.jobs, Equals, 3)
+	c.Assert(tuner.parallel, Equals, 4)
+
+	// the concurrency of files is doubled in slow start if the file slots are all in use
+	for i := 0; i < 3; i++ {
+		tuner.acquire()
+	}
+	transferred += 2000
+	tuner.observe(nil, 10*time.Millisecond)
+	tuner.adjust(time.Second)
+	c.Assert(tuner.jobs, Equals, 6)
+	c.Assert(tuner.parallel, Equals, 4)
+
+	// no gain from the last ramp up, undo it
+	transferred += 2000
+	tuner.observe(nil, 10*time.Millisecond)
+	tuner.adjust(time.Second)
+	c.Assert(tuner.lastAction, Equals, autoTuneNoGain)
+	c.Assert(tuner.jobs, Equals, 3)
+
+	// increased by one since then
+	transferred += 3000
+	tuner.observe(nil, 10*time.Millisecond)
+	tuner.adjust(time.Second)
+	c.Assert(tuner.lastAction, Equals, autoTuneRampUp)
+	c.Assert(tuner.jobs, Equals, 4)
+
+	// keep the concurrency if the latency grows without throughput gain
+	transferred += 3000
+	tuner.observe(nil, 10*time.Millisecond)
+	tuner.adjust(time.Second)
+	c.Assert(tuner.lastAction, Equals, autoTuneNoGain)
+	transferred += 3000
+	tuner.observe(nil, 50*time.Millisecond)
+	tuner.adjust(time.Second)
+	c.Assert(tuner.lastAction, Equals, autoTuneHighLatency)
+	c.Assert(tuner.jobs, Equals, 3)
+
+	// halved on throttling
+	transferred += 3000
+	tuner.observe(oss.ServiceError{StatusCode: 503, Code: "SlowDown"}, 10*time.Millisecond)
+	tuner.adjust(time.Second)
+	c.Assert(tuner.lastAction, Equals, autoTuneThrottled)
+	c.Assert(tuner.jobs, Equals, 1)
+	c.Assert(tuner.parallel, Equals, 2)
+	c.Assert(tuner.partRoutines(1), Equals, 1)
+	c.Assert(tuner.partRoutines(100), Equals, 2)
+
+	for i := 0; i < 3; i++ {
+		tuner.release()
+	}
+	c.Assert(tuner.active, Equals, 0)
+}
+
+func (s *OssutilCommandSuite) TestAutoTunerAcquire(c *C) {
+	tuner := NewAutoTuner(1, 1, 4, 4, func() int64 { return 0 })
+	tuner.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		tuner.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		c.Fatal("acquire should wait for the file slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	tuner.release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		c.Fatal("acquire should get the released file slot")
+	}
+	tuner.release()
+
+	tuner = NewAutoTuner(0, 100, 4, 4, func() int64 { return 0 })
+	c.Assert(tuner.jobs, Equals, 1)
+	c.Assert(tuner.parallel, Equals, 4)
+
+	var nilTuner *AutoTuner
+	nilTuner.acquire()
+	nilTuner.release()
+	nilTuner.observe(nil, 0)
+	nilTuner.Stop()
+}
+
+func (s *OssutilCommandSuite) TestIsThrottlingError(c *C) {
+	c.Assert(isThrottlingError(oss.ServiceError{StatusCode: 503}), Equals, true)
+	c.Assert(isThrottlingError(oss.ServiceError{StatusCode: 403, Code: "RequestTimeTooSkewed"}), Equals, true)
+	c.Assert(isThrottlingError(oss.ServiceError{StatusCode: 403, Code: "AccessDenied"}), Equals, false)
+	c.Assert(isThrottlingError(fmt.Errorf("connection reset")), Equals, false)
+	c.Assert(isThrottlingError(nil), Equals, false)
+}
@@ -111,6 +111,7 @@ var bucketAccessMonitorCommand = BucketAccessMonitorCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -129,6 +130,10 @@ var bucketAccessMonitorCommand = BucketAccessMonitorCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
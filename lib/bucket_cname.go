@@ -230,6 +230,7 @@ var bucketCnameCommand = BucketCnameCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -248,6 +249,10 @@ var bucketCnameCommand = BucketCnameCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionItem,
@@ -136,6 +136,7 @@ var corsCommand = CorsCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -154,6 +155,10 @@ var corsCommand = CorsCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -0,0 +1,313 @@
+package lib
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// bandwidthCheckInterval is the interval to check whether the limit of the schedule changes
+const bandwidthCheckInterval = 10 * time.Second
+
+// bandwidthLimit is the upload and download speed limit, the unit is KB/s, 0 is unlimited
+type bandwidthLimit struct {
+	up   int
+	down int
+}
+
+func (bl bandwidthLimit) String() string {
+	return fmt.Sprintf("upload:%s,download:%s", formatBandwidthRate(bl.up), formatBandwidthRate(bl.down))
+}
+
+// bandwidthSlot is the limit which takes effect from minute of the day
+type bandwidthSlot struct {
+	minute int
+	limit  bandwidthLimit
+}
+
+// parseBandwidthSchedule parses the schedule like "08:00,10M 18:00,off",
+// a single rate like "10M" means the limit for the whole day,
+// the rate can be "UP:DOWN" like "10M:off" to limit upload and download separately.
+func parseBandwidthSchedule(schedule string) ([]bandwidthSlot, error) {
+	entries := strings.Fields(schedule)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("bandwidth schedule is empty")
+	}
+
+	if len(entries) == 1 && !strings.Contains(entries[0], ",") {
+		limit, err := parseBandwidthLimit(entries[0])
+		if err != nil {
+			return nil, err
+		}
+		return []bandwidthSlot{{minute: 0, limit: limit}}, nil
+	}
+
+	slots := []bandwidthSlot{}
+	for _, entry := range entries {
+		pos := strings.Index(entry, ",")
+		if pos < 0 {
+			return nil, fmt.Errorf("invalid bandwidth schedule entry %s, the format is HH:MM,RATE", entry)
+		}
+		clock, err := time.Parse("15:04", entry[:pos])
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %s in bandwidth schedule, the format is HH:MM", entry[:pos])
+		}
+		limit, err := parseBandwidthLimit(entry[pos+1:])
+		if err != nil {
+			return nil, err
+		}
+		minute := clock.Hour()*60 + clock.Minute()
+		for _, slot := range slots {
+			if slot.minute == minute {
+				return nil, fmt.Errorf("duplicate time %s in bandwidth schedule", entry[:pos])
+			}
+		}
+		slots = append(slots, bandwidthSlot{minute: minute, limit: limit})
+	}
+
+	sort.Slice(slots, func(i, j int) bool { return slots[i].minute < slots[j].minute })
+	return slots, nil
+}
+
+func parseBandwidthLimit(str string) (bandwidthLimit, error) {
+	rates := strings.Split(str, ":")
+	if len(rates) > 2 {
+		return bandwidthLimit{}, fmt.Errorf("invalid bandwidth rate %s, the format is RATE or UP:DOWN", str)
+	}
+
+	up, err := parseBandwidthRate(rates[0])
+	if err != nil {
+		return bandwidthLimit{}, err
+	}
+	down := up
+	if len(rates) == 2 {
+		if down, err = parseBandwidthRate(rates[1]); err != nil {
+			return bandwidthLimit{}, err
+		}
+	}
+	return bandwidthLimit{up: up, down: down}, nil
+}
+
+// parseBandwidthRate returns the rate in KB/s, the rate without unit is KB/s too
+func parseBandwidthRate(str string) (int, error) {
+	str = strings.TrimSpace(str)
+	if strings.EqualFold(str, "off") {
+		return 0, nil
+	}
+
+	unit := 1
+	if len(str) > 0 {
+		switch strings.ToUpper(str[len(str)-1:]) {
+		case "K":
+			str = str[:len(str)-1]
+		case "M":
+			unit = 1024
+			str = str[:len(str)-1]
+		case "G":
+			unit = 1024 * 1024
+			str = str[:len(str)-1]
+		}
+	}
+
+	rate, err := strconv.ParseFloat(str, 64)
+	if err != nil || rate < 0 {
+		return 0, fmt.Errorf("invalid bandwidth rate %s, it should be off or a number with optional unit K/M/G", str)
+	}
+	kbs := int(rate * float64(unit))
+	if rate > 0 && kbs == 0 {
+		kbs = 1
+	}
+	return kbs, nil
+}
+
+func formatBandwidthRate(kbs int) string {
+	if kbs == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d(KB/s)", kbs)
+}
+
+// readBandwidthScheduleFile reads the schedule from file, the lines begin with # are comments
+func readBandwidthScheduleFile(file string) ([]bandwidthSlot, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read bandwidth schedule file error,%s", err.Error())
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines = append(lines, line)
+		}
+	}
+	return parseBandwidthSchedule(strings.Join(lines, " "))
+}
+
+// bandwidthLimitAt returns the limit of the slot which covers t, the last slot of the day
+// covers the time before the first slot.
+func bandwidthLimitAt(slots []bandwidthSlot, t time.Time) bandwidthLimit {
+	minute := t.Hour()*60 + t.Minute()
+	limit := slots[len(slots)-1].limit
+	for _, slot := range slots {
+		if slot.minute > minute {
+			break
+		}
+		limit = slot.limit
+	}
+	return limit
+}
+
+// bandwidthClient is the client with the limit set when it's added
+type bandwidthClient struct {
+	client *oss.Client
+	limit  bandwidthLimit
+}
+
+// BandwidthScheduler changes the speed limit of the oss clients according to the schedule,
+// the schedule file is read again when ossutil receives SIGUSR1.
+// The limit of the client in use can't be changed, the sdk reads it without lock on each request,
+// so the client is replaced by the new one with the current limit for the new requests instead.
+type BandwidthScheduler struct {
+	lock     sync.Mutex
+	file     string
+	slots    []bandwidthSlot
+	current  bandwidthLimit
+	clients  map[string]bandwidthClient // the latest client of each bucket
+	chReload chan os.Signal
+	chStop   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewBandwidthScheduler creates the scheduler by the schedule, or by the content of file if file is not empty
+func NewBandwidthScheduler(schedule, file string) (*BandwidthScheduler, error) {
+	var slots []bandwidthSlot
+	var err error
+	if file != "" {
+		slots, err = readBandwidthScheduleFile(file)
+	} else {
+		slots, err = parseBandwidthSchedule(schedule)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &BandwidthScheduler{
+		file:    file,
+		slots:   slots,
+		current: bandwidthLimitAt(slots, time.Now()),
+		clients: map[string]bandwidthClient{},
+	}, nil
+}
+
+// Start checks the schedule every interval, and reloads the schedule file on SIGUSR1
+func (bs *BandwidthScheduler) Start(interval time.Duration) {
+	LogInfo("bandwidth limit is %s\n", bs.current)
+
+	bs.chStop = make(chan struct{})
+	bs.chReload = make(chan os.Signal, 1)
+	if bs.file != "" {
+		notifyBandwidthReload(bs.chReload)
+	}
+
+	bs.wg.Add(1)
+	go func() {
+		defer bs.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				bs.update(time.Now())
+			case <-bs.chReload:
+				if err := bs.reload(); err != nil {
+					LogError("reload bandwidth schedule error,%s\n", err.Error())
+				}
+			case <-bs.chStop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops changing the speed limit
+func (bs *BandwidthScheduler) Stop() {
+	if bs.chStop == nil {
+		return
+	}
+	stopBandwidthReload(bs.chReload)
+	close(bs.chStop)
+	bs.wg.Wait()
+	bs.chStop = nil
+}
+
+// AddClient sets the current limit to the new client, which must not be in use yet.
+// Only the latest client of each bucket is kept, so the clients do not pile up.
+func (bs *BandwidthScheduler) AddClient(bucketName string, client *oss.Client) error {
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+
+	if err := setClientBandwidthLimit(client, bs.current); err != nil {
+		return err
+	}
+	bs.clients[bucketName] = bandwidthClient{client: client, limit: bs.current}
+	return nil
+}
+
+// Client returns the client of the bucket with the current limit, which is client itself if its limit
+// is the latest one, false is returned if there is no such client and a new one should be added.
+func (bs *BandwidthScheduler) Client(bucketName string, client *oss.Client) (*oss.Client, bool) {
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+
+	latest, ok := bs.clients[bucketName]
+	if !ok || latest.limit != bs.current {
+		return nil, false
+	}
+	if latest.client.Config == client.Config {
+		return client, true
+	}
+	return latest.client, true
+}
+
+func (bs *BandwidthScheduler) update(now time.Time) {
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+	bs.apply(bandwidthLimitAt(bs.slots, now))
+}
+
+func (bs *BandwidthScheduler) reload() error {
+	slots, err := readBandwidthScheduleFile(bs.file)
+	if err != nil {
+		return err
+	}
+
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+	bs.slots = slots
+	LogInfo("reload bandwidth schedule %s success\n", bs.file)
+	bs.apply(bandwidthLimitAt(bs.slots, time.Now()))
+	return nil
+}
+
+// apply must be called with lock held
+func (bs *BandwidthScheduler) apply(limit bandwidthLimit) {
+	if limit == bs.current {
+		return
+	}
+	LogInfo("change bandwidth limit from %s to %s, it applies to the new requests\n", bs.current, limit)
+	bs.current = limit
+}
+
+func setClientBandwidthLimit(client *oss.Client, limit bandwidthLimit) error {
+	if err := client.LimitUploadSpeed(limit.up); err != nil {
+		return err
+	}
+	return client.LimitDownloadSpeed(limit.down)
+}
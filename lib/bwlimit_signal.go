@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package lib
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func notifyBandwidthReload(ch chan os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR1)
+}
+
+func stopBandwidthReload(ch chan os.Signal) {
+	signal.Stop(ch)
+}
@@ -0,0 +1,12 @@
+package lib
+
+import (
+	"os"
+)
+
+// there is no SIGUSR1 on windows, the schedule file is not reloaded
+func notifyBandwidthReload(ch chan os.Signal) {
+}
+
+func stopBandwidthReload(ch chan os.Signal) {
+}
@@ -0,0 +1,153 @@
+package lib
+
+import (
+	"os"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) TestParseBandwidthSchedule(c *C) {
+	slots, err := parseBandwidthSchedule("10M")
+	c.Assert(err, IsNil)
+	c.Assert(len(slots), Equals, 1)
+	c.Assert(slots[0].limit, Equals, bandwidthLimit{up: 10240, down: 10240})
+
+	slots, err = parseBandwidthSchedule(" 18:00,off   08:00,10M\n12:30,512K:2M ")
+	c.Assert(err, IsNil)
+	c.Assert(len(slots), Equals, 3)
+	c.Assert(slots[0], Equals, bandwidthSlot{minute: 8 * 60, limit: bandwidthLimit{up: 10240, down: 10240}})
+	c.Assert(slots[1], Equals, bandwidthSlot{minute: 12*60 + 30, limit: bandwidthLimit{up: 512, down: 2048}})
+	c.Assert(slots[2], Equals, bandwidthSlot{minute: 18 * 60, limit: bandwidthLimit{}})
+
+	rate, err := parseBandwidthRate("100")
+	c.Assert(err, IsNil)
+	c.Assert(rate, Equals, 100)
+	rate, err = parseBandwidthRate("1.5g")
+	c.Assert(err, IsNil)
+	c.Assert(rate, Equals, 1536*1024)
+	rate, err = parseBandwidthRate("OFF")
+	c.Assert(err, IsNil)
+	c.Assert(rate, Equals, 0)
+	rate, err = parseBandwidthRate("0.1K")
+	c.Assert(err, IsNil)
+	c.Assert(rate, Equals, 1)
+
+	for _, schedule := range []string{"", "abc", "-1M", "10M 20M", "25:00,10M", "08:00,10M 08:00,off", "08:00,1M:2M:3M", "08:00,1M 09:00"} {
+		_, err = parseBandwidthSchedule(schedule)
+		c.Assert(err, NotNil, Commentf("schedule: %s", schedule))
+	}
+}
+
+func (s *OssutilCommandSuite) TestBandwidthLimitAt(c *C) {
+	slots, err := parseBandwidthSchedule("08:00,10M 18:00,off")
+	c.Assert(err, IsNil)
+
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	c.Assert(bandwidthLimitAt(slots, day), Equals, bandwidthLimit{})
+	c.Assert(bandwidthLimitAt(slots, day.Add(7*time.Hour+59*time.Minute)), Equals, bandwidthLimit{})
+	c.Assert(bandwidthLimitAt(slots, day.Add(8*time.Hour)), Equals, bandwidthLimit{up: 10240, down: 10240})
+	c.Assert(bandwidthLimitAt(slots, day.Add(17*time.Hour+59*time.Minute)), Equals, bandwidthLimit{up: 10240, down: 10240})
+	c.Assert(bandwidthLimitAt(slots, day.Add(18*time.Hour)), Equals, bandwidthLimit{})
+
+	slots, err = parseBandwidthSchedule("1M")
+	c.Assert(err, IsNil)
+	c.Assert(bandwidthLimitAt(slots, day.Add(23*time.Hour)), Equals, bandwidthLimit{up: 1024, down: 1024})
+}
+
+func (s *OssutilCommandSuite) TestBandwidthScheduler(c *C) {
+	scheduleFile := randStr(10)
+	s.createFile(scheduleFile, "# night\n00:00,1M\n# day\n12:00,2M:off\n", c)
+	defer os.Remove(scheduleFile)
+
+	bs, err := NewBandwidthScheduler("", scheduleFile)
+	c.Assert(err, IsNil)
+
+	client, err := oss.New("oss-cn-hangzhou.aliyuncs.com", "ak", "sk")
+	c.Assert(err, IsNil)
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	bs.update(day.Add(time.Hour))
+	c.Assert(bs.AddClient("bucket", client), IsNil)
+	c.Assert(client.Config.UploadLimitSpeed, Equals, 1024)
+	c.Assert(client.Config.DownloadLimitSpeed, Equals, 1024)
+	latest, ok := bs.Client("bucket", client)
+	c.Assert(ok, Equals, true)
+	c.Assert(latest, Equals, client)
+
+	// the limit of the client in use is not changed, the client is replaced by the new one
+	bs.update(day.Add(13 * time.Hour))
+	c.Assert(client.Config.UploadLimitSpeed, Equals, 1024)
+	_, ok = bs.Client("bucket", client)
+	c.Assert(ok, Equals, false)
+
+	newClient, err := oss.New("oss-cn-hangzhou.aliyuncs.com", "ak", "sk")
+	c.Assert(err, IsNil)
+	c.Assert(bs.AddClient("bucket", newClient), IsNil)
+	c.Assert(newClient.Config.UploadLimitSpeed, Equals, 2048)
+	c.Assert(newClient.Config.DownloadLimitSpeed, Equals, 0)
+	c.Assert(newClient.Config.DownloadLimiter, IsNil)
+	c.Assert(len(bs.clients), Equals, 1)
+	latest, ok = bs.Client("bucket", client)
+	c.Assert(ok, Equals, true)
+	c.Assert(latest, Equals, newClient)
+
+	// reload the file, the invalid file is ignored
+	s.createFile(scheduleFile, "off", c)
+	c.Assert(bs.reload(), IsNil)
+	c.Assert(newClient.Config.UploadLimitSpeed, Equals, 2048)
+	_, ok = bs.Client("bucket", newClient)
+	c.Assert(ok, Equals, false)
+
+	// the bucket is created again with the current limit
+	str := ""
+	ak := "ak"
+	endpoint := "oss-cn-hangzhou.aliyuncs.com"
+	cmd := Command{options: OptionMapType{OptionEndpoint: &endpoint, OptionAccessKeyID: &ak, OptionAccessKeySecret: &ak, OptionConfigFile: &str}, bandwidth: bs}
+	bucket, err := newClient.Bucket("bucket")
+	c.Assert(err, IsNil)
+	newBucket := cmd.bandwidthBucket(bucket)
+	c.Assert(newBucket.Client.Config == bucket.Client.Config, Equals, false)
+	c.Assert(newBucket.Client.Config.UploadLimitSpeed, Equals, 0)
+	c.Assert(cmd.bandwidthBucket(newBucket), Equals, newBucket)
+
+	s.createFile(scheduleFile, "invalid", c)
+	c.Assert(bs.reload(), NotNil)
+	c.Assert(len(bs.slots), Equals, 1)
+
+	bs.Start(time.Millisecond)
+	bs.Stop()
+	bs.Stop()
+
+	_, err = NewBandwidthScheduler("", scheduleFile+"-notexist")
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestCopyBandwidthOptions(c *C) {
+	schedule := "08:00,10M 18:00,off"
+	scheduleFile := ""
+	maxUpSpeed := ""
+	options := OptionMapType{
+		OptionBwLimit:      &schedule,
+		OptionBwLimitFile:  &scheduleFile,
+		OptionMaxUpSpeed:   &maxUpSpeed,
+		OptionMaxDownSpeed: &maxUpSpeed,
+	}
+	cc := CopyCommand{command: Command{options: options}}
+	c.Assert(cc.startBandwidthScheduler(), IsNil)
+	c.Assert(cc.command.bandwidth, NotNil)
+	cc.stopBandwidthScheduler()
+	c.Assert(cc.command.bandwidth, IsNil)
+
+	maxUpSpeed = "100"
+	c.Assert(cc.startBandwidthScheduler(), NotNil)
+
+	maxUpSpeed = ""
+	scheduleFile = "file"
+	c.Assert(cc.startBandwidthScheduler(), NotNil)
+
+	schedule = ""
+	scheduleFile = ""
+	c.Assert(cc.startBandwidthScheduler(), IsNil)
+	c.Assert(cc.command.bandwidth, IsNil)
+}
@@ -3,7 +3,10 @@ package lib
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
@@ -15,7 +18,7 @@ var specChineseCat = SpecText{
 	paramText: "object [options]",
 
 	syntaxText: ` 
-	ossutil cat oss://bucket/object [--payer requester] [--version-id versionId]
+	ossutil cat oss://bucket/object [--payer requester] [--version-id versionId] [--decompress] [--range range] [--head N] [--tail N] [--bytes] [--follow]
 `,
 	detailHelpText: ` 
     cat命令可以将oss的object内容输出到标准输出,object内容最好是文本格式
@@ -25,6 +28,24 @@ var specChineseCat = SpecText{
 	
     1) ossutil cat oss://bucket/object [--version-id versionId] [--payer requester]
        将object内容输出到标准输出
+
+    指定了客户端加密的主密钥时（见help cp），客户端加密的object被解密后输出。
+
+    Content-Encoding为gzip的object总是被解压后输出，指定--decompress时，Content-Encoding为zstd的object
+    也被解压后输出，ossutil压缩上传的object（见help cp）在输出结束时校验原始内容的大小和crc64，
+    校验失败时返回错误。
+
+    --range、--head、--tail选项用于只输出object的一部分，不需要下载整个object，三者只能指定一个：
+        --range：输出指定范围的内容，格式为：3-9或3-或-9，-9表示最后9个字节
+        --head N：输出开头的N行，输出完成后即停止下载
+        --tail N：输出末尾的N行，通过从末尾开始的范围下载找到最后N行，不下载整个object
+        --bytes：--head和--tail按字节计数，而不是按行
+    对于解压后输出的object，无法按范围下载，这些选项作用于解压后的内容，从头开始读取，--head输出完成后
+    即停止，--range和--tail需要读取到指定的位置或者结尾。
+
+    --follow选项用于追加类型的object（如appendfromfile上传的日志），输出已有内容（或者--tail指定的
+    末尾部分）后，持续轮询object的长度，输出新追加的内容，类似tail -f，按Ctrl+C退出。轮询间隔由
+    --interval和--max-interval指定，object被删除、截断或者覆盖时从头开始输出，详见help tail。
 `,
 	sampleText: ` 
     1) 将object内容输出到标准输出
@@ -35,6 +56,25 @@ var specChineseCat = SpecText{
     
     3) 访问者付费模式
        ossutil cat oss://bucket/object --payer requester
+
+    4) 输出客户端加密的object解密后的内容
+       ossutil cat oss://bucket/object --encryption-rsa-private-key private.pem
+
+    5) 输出压缩的object解压后的内容
+       ossutil cat oss://bucket/object --decompress
+
+    6) 输出object的第100到199个字节
+       ossutil cat oss://bucket/object --range 100-199
+
+    7) 输出日志的前20行和最后20行
+       ossutil cat oss://bucket/app.log --head 20
+       ossutil cat oss://bucket/app.log --tail 20
+
+    8) 输出日志的最后1024个字节
+       ossutil cat oss://bucket/app.log --tail 1024 --bytes
+
+    9) 输出追加类型日志的最后10行，并持续输出新追加的内容
+       ossutil cat oss://bucket/app.log --tail 10 --follow
 `,
 }
 
@@ -44,7 +84,7 @@ var specEnglishCat = SpecText{
 	paramText: "object [options]",
 
 	syntaxText: ` 
-	ossutil cat oss://bucket/object [--payer requester] [--version-id versionId]
+	ossutil cat oss://bucket/object [--payer requester] [--version-id versionId] [--decompress] [--range range] [--head N] [--tail N] [--bytes] [--follow]
 `,
 	detailHelpText: ` 
 	The cat command can output the object content of oss to standard output
@@ -55,6 +95,31 @@ Usage:
 	
     1) ossutil cat oss://bucket/object [--version-id versionId] [--payer requester]
        The command output object content to standard output
+
+    If the master key of client side encryption is specified(see help cp), the object encrypted
+    on client side is decrypted before output.
+
+    The object whose Content-Encoding is gzip is always decompressed before output, and the object
+    whose Content-Encoding is zstd is decompressed too if --decompress is specified, the size and
+    crc64 of the original content of the object compressed by ossutil (see help cp) are validated at
+    the end of output, error is returned if they are different.
+
+    --range, --head and --tail output only part of the object without downloading the whole object,
+    only one of them can be specified:
+        --range: output the range of the content, the form is like: 3-9 or 3- or -9, -9 means the last 9 bytes
+        --head N: output the first N lines, and stop downloading after that
+        --tail N: output the last N lines, which are found by the range downloads from the end, without
+                  downloading the whole object
+        --bytes: --head and --tail count bytes instead of lines
+    The decompressed object can't be downloaded by range, these options work on the decompressed content
+    which is read from the beginning, --head stops after the output, --range and --tail have to read to
+    the position specified or the end.
+
+    --follow works with the appendable object, such as the log uploaded by appendfromfile. After the
+    content (or the tail specified by --tail) is output, ossutil polls the length of the object and
+    outputs the appended content, like tail -f, press Ctrl+C to exit. The polling interval is specified
+    by --interval and --max-interval, the output starts from the beginning again if the object is
+    deleted, truncated or overwritten, see help tail for details.
 `,
 	sampleText: ` 
     1) output object content to standard output
@@ -65,6 +130,25 @@ Usage:
     
     3) output object content with requester payment
        ossutil cat oss://bucket/object --payer requester
+
+    4) output the decrypted content of the object encrypted on client side
+       ossutil cat oss://bucket/object --encryption-rsa-private-key private.pem
+
+    5) output the decompressed content of the compressed object
+       ossutil cat oss://bucket/object --decompress
+
+    6) output the bytes 100 to 199 of the object
+       ossutil cat oss://bucket/object --range 100-199
+
+    7) output the first 20 lines and the last 20 lines of the log
+       ossutil cat oss://bucket/app.log --head 20
+       ossutil cat oss://bucket/app.log --tail 20
+
+    8) output the last 1024 bytes of the log
+       ossutil cat oss://bucket/app.log --tail 1024 --bytes
+
+    9) output the last 10 lines of the appendable log, and keep outputting the appended content
+       ossutil cat oss://bucket/app.log --tail 10 --follow
 `,
 }
 
@@ -72,6 +156,11 @@ type catOptionType struct {
 	bucketName   string
 	objectName   string
 	encodingType string
+	vrange       string
+	head         int64 // -1 if not specified
+	tail         int64 // -1 if not specified
+	bytes        bool
+	follow       bool
 }
 
 type CatCommand struct {
@@ -91,6 +180,7 @@ var catCommand = CatCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -111,6 +201,22 @@ var catCommand = CatCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
+			OptionEncryptionPubKey,
+			OptionEncryptionPriKey,
+			OptionEncryptionAesKey,
+			OptionEncryptionMatDesc,
+			OptionDecompress,
+			OptionRange,
+			OptionHead,
+			OptionTail,
+			OptionBytes,
+			OptionFollow,
+			OptionInterval,
+			OptionMaxInterval,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -150,6 +256,10 @@ func (catc *CatCommand) RunCommand() error {
 	catc.catOption.bucketName = srcBucketUrL.bucket
 	catc.catOption.objectName = srcBucketUrL.object
 
+	if err := catc.parsePartOptions(); err != nil {
+		return err
+	}
+
 	// check object exist or not
 	client, err := catc.command.ossClient(catc.catOption.bucketName)
 	if err != nil {
@@ -177,14 +287,398 @@ func (catc *CatCommand) RunCommand() error {
 		options = append(options, oss.VersionId(versionId))
 	}
 
-	body, err := bucket.GetObject(catc.catOption.objectName, options...)
+	// the size, the encoding and the type of the object decide how to read it
+	props, err := catc.command.ossGetObjectStatRetry(bucket, catc.catOption.objectName, options...)
+	if err != nil {
+		return err
+	}
+
+	// decrypt the object encrypted on client side if the master key is specified
+	var envelope *ClientEnvelope
+	encryption, err := GetClientEncryption(catc.command.options)
+	if err != nil {
+		return err
+	}
+	if encryption != nil {
+		if envelope, err = encryption.OpenEnvelope(props); err != nil {
+			return err
+		}
+	}
+
+	// decompress the gzip object always, and the zstd object if --decompress is specified
+	decompress, _ := GetBool(OptionDecompress, catc.command.options)
+	encoding := strings.ToLower(strings.TrimSpace(props.Get(oss.HTTPHeaderContentEncoding)))
+	if IsCompressEncoding(encoding) && (decompress || encoding == CompressGzip) {
+		if catc.catOption.follow {
+			return fmt.Errorf("--follow can't be used with the compressed object")
+		}
+		// or the http client decompresses the gzip content transparently
+		options = append(options, oss.AcceptEncoding("identity"))
+		return catc.catCompressedObject(bucket, props, envelope, options)
+	}
+
+	size, err := strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64)
 	if err != nil {
 		return err
 	}
+	if catc.catOption.follow && props.Get(StatObjectType) != "Appendable" {
+		return fmt.Errorf("--follow only works with the appendable object, the type of %s is %s",
+			CloudURLToString(catc.catOption.bucketName, catc.catOption.objectName), props.Get(StatObjectType))
+	}
 
+	cr := &catRangeReader{bucket: bucket, object: catc.catOption.objectName, envelope: envelope, options: options}
+	opt := catc.catOption
+	switch {
+	case opt.vrange != "":
+		start, end, err := parseCatRange(opt.vrange, size)
+		if err != nil {
+			return err
+		}
+		return cr.copyRange(os.Stdout, start, end)
+	case opt.head == 0:
+		return nil
+	case opt.head > 0 && opt.bytes:
+		end := size - 1
+		if opt.head < size {
+			end = opt.head - 1
+		}
+		return cr.copyRange(os.Stdout, 0, end)
+	case opt.head > 0:
+		body, err := cr.getRange(0, -1)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		return copyHeadLines(os.Stdout, body, opt.head)
+	case opt.tail >= 0:
+		start := max(size-opt.tail, 0)
+		if !opt.bytes {
+			if start, err = tailLinesStart(cr.readRange, size, opt.tail); err != nil {
+				return err
+			}
+		}
+		if err := cr.copyRange(os.Stdout, start, size-1); err != nil {
+			return err
+		}
+		if opt.follow {
+			return catc.followObject(cr, size)
+		}
+		return nil
+	case opt.follow:
+		if err := cr.copyRange(os.Stdout, 0, size-1); err != nil {
+			return err
+		}
+		return catc.followObject(cr, size)
+	}
+
+	body, err := cr.getRange(0, -1)
+	if err != nil {
+		return err
+	}
 	defer body.Close()
-	io.Copy(os.Stdout, body)
+	_, err = io.Copy(os.Stdout, body)
+	fmt.Printf("\n")
+
+	return err
+}
+
+// parsePartOptions parses the options to output part of the object, only one of --range, --head and --tail can be specified
+func (catc *CatCommand) parsePartOptions() error {
+	opt := &catc.catOption
+	opt.vrange, _ = GetString(OptionRange, catc.command.options)
+	opt.bytes, _ = GetBool(OptionBytes, catc.command.options)
+	opt.follow, _ = GetBool(OptionFollow, catc.command.options)
+	opt.head, opt.tail = -1, -1
+	count := 0
+	if opt.vrange != "" {
+		count++
+	}
+	if head, err := GetInt(OptionHead, catc.command.options); err == nil {
+		opt.head = head
+		count++
+	}
+	if tail, err := GetInt(OptionTail, catc.command.options); err == nil {
+		opt.tail = tail
+		count++
+	}
+
+	if count > 1 {
+		return fmt.Errorf("only one of --range, --head and --tail can be specified")
+	}
+	if opt.bytes && opt.head < 0 && opt.tail < 0 {
+		return fmt.Errorf("--bytes only works with --head or --tail")
+	}
+	if opt.follow && (opt.vrange != "" || opt.head >= 0) {
+		return fmt.Errorf("--follow can't be used with --range or --head")
+	}
+	return nil
+}
+
+// catCompressedObject outputs the decompressed content, the compressed content can't be read by range,
+// so it is read from the beginning, and stops reading as soon as the part to output is done
+func (catc *CatCommand) catCompressedObject(bucket *oss.Bucket, props http.Header, envelope *ClientEnvelope, options []oss.Option) error {
+	body, err := bucket.GetObject(catc.catOption.objectName, options...)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var reader io.Reader = body
+	if envelope != nil {
+		if reader, err = envelope.Reader(body, 0); err != nil {
+			return err
+		}
+	}
+	decompressReader, err := NewDecompressReader(reader, props)
+	if err != nil {
+		return err
+	}
+	defer decompressReader.Close()
+
+	opt := catc.catOption
+	switch {
+	case opt.vrange != "":
+		return copyStreamRange(os.Stdout, decompressReader, opt.vrange)
+	case opt.head >= 0 && opt.bytes:
+		_, err = io.CopyN(os.Stdout, decompressReader, opt.head)
+		if err == io.EOF {
+			err = nil
+		}
+		return err
+	case opt.head >= 0:
+		return copyHeadLines(os.Stdout, decompressReader, opt.head)
+	case opt.tail >= 0:
+		return copyTail(os.Stdout, decompressReader, opt.tail, opt.bytes)
+	}
+
+	_, err = io.Copy(os.Stdout, decompressReader)
 	fmt.Printf("\n")
+	return err
+}
+
+// followObject outputs the content appended after offset, until the process is interrupted
+func (catc *CatCommand) followObject(cr *catRangeReader, offset int64) error {
+	follower, err := newAppendFollower(&catc.command, cr.bucket, cr.options)
+	if err != nil {
+		return err
+	}
+	return follower.followObject(&followedObject{key: cr.object, position: offset, envelope: cr.envelope})
+}
+
+// catRangeBlockSize is the size of each range download to find the start of the last lines
+const catRangeBlockSize = 64 * 1024
+
+// catRangeReader reads the range of the object, and decrypts it if the object is encrypted on client side
+type catRangeReader struct {
+	bucket   *oss.Bucket
+	object   string
+	envelope *ClientEnvelope
+	options  []oss.Option
+}
+
+// getRange returns the content from start to end, both inclusive, or the whole object if end is negative
+func (cr *catRangeReader) getRange(start, end int64) (io.ReadCloser, error) {
+	options := cr.options
+	if end >= 0 {
+		if end < start {
+			return ioutil.NopCloser(strings.NewReader("")), nil
+		}
+		options = append(append([]oss.Option{}, cr.options...), oss.Range(start, end))
+	}
+	body, err := cr.bucket.GetObject(cr.object, options...)
+	if err != nil {
+		return nil, err
+	}
+	if cr.envelope == nil {
+		return body, nil
+	}
+	reader, err := cr.envelope.Reader(body, start)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{reader, body}, nil
+}
 
+func (cr *catRangeReader) copyRange(w io.Writer, start, end int64) error {
+	body, err := cr.getRange(start, end)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = io.Copy(w, body)
+	return err
+}
+
+func (cr *catRangeReader) readRange(start, end int64) ([]byte, error) {
+	body, err := cr.getRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return ioutil.ReadAll(body)
+}
+
+// parseCatRange parses the range like 3-9, 3- or -9 which means the last 9 bytes, returns the start and the end, both inclusive
+func parseCatRange(str string, size int64) (int64, int64, error) {
+	pos := strings.Index(str, "-")
+	if pos < 0 {
+		return 0, 0, fmt.Errorf("invalid range %s, the form is like: 3-9 or 3- or -9", str)
+	}
+	startStr, endStr := strings.TrimSpace(str[:pos]), strings.TrimSpace(str[pos+1:])
+	if startStr == "" {
+		count, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || count < 0 {
+			return 0, 0, fmt.Errorf("invalid range %s, the form is like: 3-9 or 3- or -9", str)
+		}
+		return max(size-count, 0), size - 1, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid range %s, the form is like: 3-9 or 3- or -9", str)
+	}
+	end := size - 1
+	if endStr != "" {
+		if end, err = strconv.ParseInt(endStr, 10, 64); err != nil || end < start {
+			return 0, 0, fmt.Errorf("invalid range %s, the form is like: 3-9 or 3- or -9", str)
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	if start >= size && size > 0 {
+		return 0, 0, fmt.Errorf("invalid range %s, the start is beyond the size %d of the object", str, size)
+	}
+	return start, end, nil
+}
+
+// tailLinesStart reads the ranges backwards from the end by readRange, and returns the start of the last count lines,
+// the newline at the end of the content doesn't start a new line
+func tailLinesStart(readRange func(start, end int64) ([]byte, error), size, count int64) (int64, error) {
+	if count == 0 {
+		return size, nil
+	}
+	pos := size
+	found := int64(0)
+	for pos > 0 {
+		start := max(pos-catRangeBlockSize, 0)
+		data, err := readRange(start, pos-1)
+		if err != nil {
+			return 0, err
+		}
+		for i := len(data) - 1; i >= 0; i-- {
+			if data[i] != '\n' || start+int64(i) == size-1 {
+				continue
+			}
+			found++
+			if found == count {
+				return start + int64(i) + 1, nil
+			}
+		}
+		pos = start
+	}
+	return 0, nil
+}
+
+// copyHeadLines copies the first count lines, and stops reading after that
+func copyHeadLines(w io.Writer, r io.Reader, count int64) error {
+	if count == 0 {
+		return nil
+	}
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		data := buf[:n]
+		for i, b := range data {
+			if b != '\n' {
+				continue
+			}
+			count--
+			if count == 0 {
+				_, werr := w.Write(data[:i+1])
+				return werr
+			}
+		}
+		if _, werr := w.Write(data); werr != nil {
+			return werr
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// copyStreamRange copies the range of the stream whose size is unknown
+func copyStreamRange(w io.Writer, r io.Reader, vrange string) error {
+	pos := strings.Index(vrange, "-")
+	if pos == 0 {
+		count, err := strconv.ParseInt(strings.TrimSpace(vrange[1:]), 10, 64)
+		if err != nil || count < 0 {
+			return fmt.Errorf("invalid range %s, the form is like: 3-9 or 3- or -9", vrange)
+		}
+		return copyTail(w, r, count, true)
+	}
+	start, end, err := parseCatRange(vrange, MaxInt64)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(ioutil.Discard, r, start); err != nil {
+		if err == io.EOF {
+			return fmt.Errorf("invalid range %s, the start is beyond the size of the content", vrange)
+		}
+		return err
+	}
+	if end == MaxInt64-1 {
+		_, err = io.Copy(w, r)
+		return err
+	}
+	if _, err = io.CopyN(w, r, end-start+1); err == io.EOF {
+		err = nil
+	}
+	return err
+}
+
+// copyTail copies the last count lines or bytes of the stream, which has to be read to the end
+func copyTail(w io.Writer, r io.Reader, count int64, bytes bool) error {
+	if count == 0 {
+		_, err := io.Copy(ioutil.Discard, r)
+		return err
+	}
+	var tail []byte
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		tail = append(tail, buf[:n]...)
+		// keep twice the size of the tail at most to shrink less often
+		if bytes && int64(len(tail)) > 2*count {
+			tail = append(tail[:0], tail[int64(len(tail))-count:]...)
+		} else if !bytes && len(tail) > 2*catRangeBlockSize {
+			start, _ := tailLinesStart(func(start, end int64) ([]byte, error) {
+				return tail[start : end+1], nil
+			}, int64(len(tail)), count)
+			tail = append(tail[:0], tail[start:]...)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	start := max(int64(len(tail))-count, 0)
+	if !bytes {
+		start, _ = tailLinesStart(func(start, end int64) ([]byte, error) {
+			return tail[start : end+1], nil
+		}, int64(len(tail)), count)
+	}
+	_, err := w.Write(tail[start:])
 	return err
 }
@@ -1,6 +1,8 @@
 package lib
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"strings"
 
@@ -257,3 +259,117 @@ func (s *OssutilCommandSuite) TestCatObjectWithPayer(c *C) {
 	c.Assert(strings.Contains(catBody, content), Equals, true)
 	os.Remove(resultPath)
 }
+
+func (s *OssutilCommandSuite) TestCatParseRange(c *C) {
+	cases := []struct {
+		vrange string
+		start  int64
+		end    int64
+	}{
+		{"3-9", 3, 9},
+		{"3-", 3, 99},
+		{"-9", 91, 99},
+		{"-200", 0, 99},
+		{"90-200", 90, 99},
+	}
+	for _, t := range cases {
+		start, end, err := parseCatRange(t.vrange, 100)
+		c.Assert(err, IsNil)
+		c.Assert(start, Equals, t.start, Commentf("%s", t.vrange))
+		c.Assert(end, Equals, t.end, Commentf("%s", t.vrange))
+	}
+
+	for _, vrange := range []string{"3", "a-9", "9-3", "100-", "-a", "--3"} {
+		_, _, err := parseCatRange(vrange, 100)
+		c.Assert(err, NotNil, Commentf("%s", vrange))
+	}
+}
+
+func (s *OssutilCommandSuite) TestCatTailLines(c *C) {
+	var lines []string
+	for i := 0; i < 20000; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	content := []byte(strings.Join(lines, "\n") + "\n")
+	size := int64(len(content))
+
+	// the last lines span several ranges from the end
+	var ranges int
+	readRange := func(start, end int64) ([]byte, error) {
+		ranges++
+		c.Assert(end-start+1 <= catRangeBlockSize, Equals, true)
+		return content[start : end+1], nil
+	}
+	start, err := tailLinesStart(readRange, size, 10000)
+	c.Assert(err, IsNil)
+	c.Assert(string(content[start:]), Equals, strings.Join(lines[10000:], "\n")+"\n")
+	c.Assert(ranges < int(size/catRangeBlockSize)+1, Equals, true)
+
+	start, err = tailLinesStart(readRange, size, 1)
+	c.Assert(err, IsNil)
+	c.Assert(string(content[start:]), Equals, "line 19999\n")
+
+	start, err = tailLinesStart(readRange, size, 30000)
+	c.Assert(err, IsNil)
+	c.Assert(start, Equals, int64(0))
+
+	// without the newline at the end
+	start, err = tailLinesStart(readRange, size-1, 2)
+	c.Assert(err, IsNil)
+	c.Assert(string(content[start:size-1]), Equals, "line 19998\nline 19999")
+
+	// the tail of the stream is the same
+	var out bytes.Buffer
+	c.Assert(copyTail(&out, bytes.NewReader(content), 10000, false), IsNil)
+	c.Assert(out.String(), Equals, strings.Join(lines[10000:], "\n")+"\n")
+	out.Reset()
+	c.Assert(copyTail(&out, bytes.NewReader(content), 5, true), IsNil)
+	c.Assert(out.String(), Equals, "9999\n")
+	out.Reset()
+	c.Assert(copyTail(&out, bytes.NewReader(content), 0, false), IsNil)
+	c.Assert(out.Len(), Equals, 0)
+}
+
+func (s *OssutilCommandSuite) TestCatCopyStream(c *C) {
+	content := "a\nbb\nccc\ndddd"
+	var out bytes.Buffer
+	c.Assert(copyHeadLines(&out, strings.NewReader(content), 2), IsNil)
+	c.Assert(out.String(), Equals, "a\nbb\n")
+	out.Reset()
+	c.Assert(copyHeadLines(&out, strings.NewReader(content), 10), IsNil)
+	c.Assert(out.String(), Equals, content)
+	out.Reset()
+	c.Assert(copyTail(&out, strings.NewReader(content), 2, false), IsNil)
+	c.Assert(out.String(), Equals, "ccc\ndddd")
+
+	for vrange, expect := range map[string]string{"2-4": "bb\n", "9-": "dddd", "-3": "ddd", "10-100": "ddd"} {
+		out.Reset()
+		c.Assert(copyStreamRange(&out, strings.NewReader(content), vrange), IsNil)
+		c.Assert(out.String(), Equals, expect, Commentf("%s", vrange))
+	}
+	c.Assert(copyStreamRange(&out, strings.NewReader(content), "100-"), NotNil)
+}
+
+func (s *OssutilCommandSuite) TestCatPartOptions(c *C) {
+	vrange := "1-10"
+	head := int64(10)
+	tail := int64(10)
+	flag := true
+	for _, options := range []OptionMapType{
+		{OptionRange: &vrange, OptionHead: &head},
+		{OptionHead: &head, OptionTail: &tail},
+		{OptionBytes: &flag},
+		{OptionHead: &head, OptionFollow: &flag},
+		{OptionRange: &vrange, OptionFollow: &flag},
+	} {
+		catc := CatCommand{command: Command{options: options}}
+		c.Assert(catc.parsePartOptions(), NotNil)
+	}
+
+	catc := CatCommand{command: Command{options: OptionMapType{OptionTail: &tail, OptionBytes: &flag, OptionFollow: &flag}}}
+	c.Assert(catc.parsePartOptions(), IsNil)
+	c.Assert(catc.catOption.tail, Equals, int64(10))
+	c.Assert(catc.catOption.head, Equals, int64(-1))
+	c.Assert(catc.catOption.bytes, Equals, true)
+	c.Assert(catc.catOption.follow, Equals, true)
+}
@@ -0,0 +1,723 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	osscrypto "github.com/aliyun/aliyun-oss-go-sdk/oss/crypto"
+)
+
+// the meta of client side encryption, the same as the crypto bucket of oss go sdk
+const (
+	ClientEncryptionKey                      string = osscrypto.OssClientSideEncryptionKey
+	ClientEncryptionStart                           = osscrypto.OssClientSideEncryptionStart
+	ClientEncryptionCekAlg                          = osscrypto.OssClientSideEncryptionCekAlg
+	ClientEncryptionWrapAlg                         = osscrypto.OssClientSideEncryptionWrapAlg
+	ClientEncryptionMatDesc                         = osscrypto.OssClientSideEncryptionMatDesc
+	ClientEncryptionUnencryptedContentLength        = osscrypto.OssClientSideEncryptionUnencryptedContentLength
+	ClientEncryptionDataSize                        = osscrypto.OssClientSideEncryptionDataSize
+	ClientEncryptionPartSize                        = osscrypto.OssClientSideEncryptionPartSize
+)
+
+// the algorithms of client side encryption, the content is encrypted by AES-256 in CTR mode,
+// the content key is wrapped by the RSA or AES master key. The objects wrapped by RSA master key
+// can be decrypted by the crypto bucket of oss sdks. The crypto bucket does not support AES/GCM/NoPadding,
+// the objects wrapped by AES master key can only be decrypted by ossutil.
+const (
+	ClientEncryptionCtrAlg   string = osscrypto.AesCtrAlgorithm
+	ClientEncryptionRsaWrap         = osscrypto.RsaCryptoWrap
+	ClientEncryptionAesWrap         = "AES/GCM/NoPadding"
+	ClientEncryptionUaSuffix        = osscrypto.EncryptionUaSuffix
+)
+
+const (
+	clientEncryptionBlockSize = aes.BlockSize
+	clientEncryptionCpMagic   = "6F1D2B8E-3C4A-4E59-9B7D-0A2E5C8F1B34"
+	clientEncryptionCpSuffix  = ".cse.cp"
+	clientEncryptionBufSize   = 1024 * 1024
+
+	// EncryptedTempFileSuffix is the suffix of the temp file which keeps the cipher text while downloading
+	EncryptedTempFileSuffix = ".cse.tmp"
+)
+
+// ClientEncryption is the local master key provider of client side encryption, the content keys are
+// generated and unwrapped by the AES-CTR content cipher of oss go sdk with the master cipher
+type ClientEncryption struct {
+	master    osscrypto.MasterCipher
+	builder   osscrypto.ContentCipherBuilder
+	canUnwrap bool // false if only the rsa public key is specified
+}
+
+// ClientEnvelope is the content key and iv of an encrypted object, along with the wrapped ones
+type ClientEnvelope struct {
+	osscrypto.CipherData
+	cipher osscrypto.ContentCipher
+}
+
+// GetClientEncryption creates the master key provider by the options, returns nil if no master key is specified
+func GetClientEncryption(options OptionMapType) (*ClientEncryption, error) {
+	publicKeyFile, _ := GetString(OptionEncryptionPubKey, options)
+	privateKeyFile, _ := GetString(OptionEncryptionPriKey, options)
+	aesKeyFile, _ := GetString(OptionEncryptionAesKey, options)
+	matDesc, _ := GetString(OptionEncryptionMatDesc, options)
+	return NewClientEncryption(publicKeyFile, privateKeyFile, aesKeyFile, matDesc)
+}
+
+// NewClientEncryption loads the master keys from the files, the rsa keys are in PEM format,
+// the aes key is base64 encoded, returns nil if no master key is specified
+func NewClientEncryption(publicKeyFile, privateKeyFile, aesKeyFile, matDesc string) (*ClientEncryption, error) {
+	if publicKeyFile == "" && privateKeyFile == "" && aesKeyFile == "" {
+		if matDesc != "" {
+			return nil, fmt.Errorf("--encryption-matdesc must be used with the master key of client side encryption")
+		}
+		return nil, nil
+	}
+
+	if aesKeyFile != "" && (publicKeyFile != "" || privateKeyFile != "") {
+		return nil, fmt.Errorf("the rsa and aes master key of client side encryption can't be both specified")
+	}
+
+	desc := map[string]string{}
+	if matDesc != "" {
+		if err := json.Unmarshal([]byte(matDesc), &desc); err != nil {
+			return nil, fmt.Errorf("invalid encryption matdesc %s, it must be a json object of strings, %s", matDesc, err.Error())
+		}
+	}
+
+	var master osscrypto.MasterCipher
+	if aesKeyFile != "" {
+		key, err := readAesMasterKey(aesKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		master = masterAesCipher{key: key, matDesc: matDesc}
+	} else {
+		publicKey, privateKey, err := readRsaMasterKeys(publicKeyFile, privateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if master, err = osscrypto.CreateMasterRsa(desc, publicKey, privateKey); err != nil {
+			return nil, err
+		}
+	}
+	return &ClientEncryption{
+		master:    master,
+		builder:   osscrypto.CreateAesCtrCipher(master),
+		canUnwrap: aesKeyFile != "" || privateKeyFile != "",
+	}, nil
+}
+
+func readPemBlock(fileName string) (*pem.Block, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", fileName)
+	}
+	return block, nil
+}
+
+func readRsaPublicKey(fileName string) (*rsa.PublicKey, error) {
+	block, err := readPemBlock(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse rsa public key %s error, %s", fileName, err.Error())
+		}
+		if publicKey, ok := key.(*rsa.PublicKey); ok {
+			return publicKey, nil
+		}
+		return nil, fmt.Errorf("%s is not a rsa public key", fileName)
+	case "RSA PUBLIC KEY":
+		var publicKey rsa.PublicKey
+		if _, err := asn1.Unmarshal(block.Bytes, &publicKey); err != nil {
+			return nil, fmt.Errorf("parse rsa public key %s error, %s", fileName, err.Error())
+		}
+		return &publicKey, nil
+	}
+	return nil, fmt.Errorf("%s is not a rsa public key, the PEM type is %s", fileName, block.Type)
+}
+
+func readRsaPrivateKey(fileName string) (*rsa.PrivateKey, error) {
+	block, err := readPemBlock(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse rsa private key %s error, %s", fileName, err.Error())
+		}
+		if privateKey, ok := key.(*rsa.PrivateKey); ok {
+			return privateKey, nil
+		}
+		return nil, fmt.Errorf("%s is not a rsa private key", fileName)
+	case "RSA PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse rsa private key %s error, %s", fileName, err.Error())
+		}
+		return privateKey, nil
+	}
+	return nil, fmt.Errorf("%s is not a rsa private key, the PEM type is %s", fileName, block.Type)
+}
+
+// readRsaMasterKeys returns the rsa keys in PEM format for the rsa master cipher of oss go sdk,
+// the keys are checked here for the clear error, and the public key is got from the private key if
+// it's not specified
+func readRsaMasterKeys(publicKeyFile, privateKeyFile string) (string, string, error) {
+	var publicKey *rsa.PublicKey
+	var privatePem string
+	if privateKeyFile != "" {
+		privateKey, err := readRsaPrivateKey(privateKeyFile)
+		if err != nil {
+			return "", "", err
+		}
+		privatePem = string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}))
+		publicKey = &privateKey.PublicKey
+	}
+	if publicKeyFile != "" {
+		var err error
+		if publicKey, err = readRsaPublicKey(publicKeyFile); err != nil {
+			return "", "", err
+		}
+	}
+
+	publicBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes})), privatePem, nil
+}
+
+func readAesMasterKey(fileName string) ([]byte, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("the aes master key in %s must be base64 encoded, %s", fileName, err.Error())
+	}
+	if len(key) != 16 && len(key) != 24 && len(key) != 32 {
+		return nil, fmt.Errorf("the aes master key in %s must be 16, 24 or 32 bytes, now is %d", fileName, len(key))
+	}
+	return key, nil
+}
+
+// masterAesCipher is the master cipher of oss go sdk which wraps the content key by AES/GCM, the crypto bucket
+// of oss sdks does not support it, so the objects wrapped by it can only be decrypted by ossutil
+type masterAesCipher struct {
+	key     []byte
+	matDesc string
+}
+
+func (mac masterAesCipher) GetWrapAlgorithm() string {
+	return ClientEncryptionAesWrap
+}
+
+func (mac masterAesCipher) GetMatDesc() string {
+	return mac.matDesc
+}
+
+func (mac masterAesCipher) Encrypt(data []byte) ([]byte, error) {
+	gcm, err := mac.gcm()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+func (mac masterAesCipher) Decrypt(data []byte) ([]byte, error) {
+	gcm, err := mac.gcm()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid wrapped key of client side encryption")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+func (mac masterAesCipher) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(mac.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (ce *ClientEncryption) wrapAlg() string {
+	return ce.master.GetWrapAlgorithm()
+}
+
+func (ce *ClientEncryption) matDesc() string {
+	return ce.master.GetMatDesc()
+}
+
+// NewEnvelope generates a random content key and iv, and wraps them with the master key
+func (ce *ClientEncryption) NewEnvelope() (*ClientEnvelope, error) {
+	contentCipher, err := ce.builder.ContentCipher()
+	if err != nil {
+		return nil, err
+	}
+	return &ClientEnvelope{CipherData: *contentCipher.GetCipherData(), cipher: contentCipher}, nil
+}
+
+// IsClientEncrypted returns true if the object is encrypted on client side
+func IsClientEncrypted(header http.Header) bool {
+	return header.Get(oss.HTTPHeaderOssMetaPrefix+ClientEncryptionKey) != ""
+}
+
+// OpenEnvelope unwraps the content key and iv from the meta of the object, returns nil if the object is not encrypted
+func (ce *ClientEncryption) OpenEnvelope(header http.Header) (*ClientEnvelope, error) {
+	if !IsClientEncrypted(header) {
+		return nil, nil
+	}
+
+	cekAlg := header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionCekAlg)
+	if cekAlg != ClientEncryptionCtrAlg {
+		return nil, fmt.Errorf("not supported content algorithm of client side encryption: %s", cekAlg)
+	}
+
+	encryptedKey, err := base64.StdEncoding.DecodeString(header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid meta %s, %s", ClientEncryptionKey, err.Error())
+	}
+	encryptedIV, err := base64.StdEncoding.DecodeString(header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionStart))
+	if err != nil {
+		return nil, fmt.Errorf("invalid meta %s, %s", ClientEncryptionStart, err.Error())
+	}
+	wrapAlg := header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionWrapAlg)
+	matDesc := header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionMatDesc)
+	return ce.openEnvelope(encryptedKey, encryptedIV, wrapAlg, matDesc)
+}
+
+func (ce *ClientEncryption) openEnvelope(encryptedKey, encryptedIV []byte, wrapAlg, matDesc string) (*ClientEnvelope, error) {
+	switch wrapAlg {
+	case ClientEncryptionAesWrap:
+		if ce.wrapAlg() != wrapAlg {
+			return nil, fmt.Errorf("the object is encrypted by aes master key, but the aes master key is not specified")
+		}
+	case ClientEncryptionRsaWrap:
+		if ce.wrapAlg() != wrapAlg || !ce.canUnwrap {
+			return nil, fmt.Errorf("the object is encrypted by rsa master key, but the rsa private key is not specified")
+		}
+	default:
+		return nil, fmt.Errorf("not supported wrap algorithm of client side encryption: %s", wrapAlg)
+	}
+
+	// the content cipher of sdk panics on the iv of invalid length, so it's checked first
+	iv, err := ce.master.Decrypt(encryptedIV)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap the iv of client side encryption error, %s", err.Error())
+	}
+	if len(iv) != clientEncryptionBlockSize {
+		return nil, fmt.Errorf("invalid iv length of client side encryption: %d", len(iv))
+	}
+
+	contentCipher, err := ce.builder.ContentCipherEnv(osscrypto.Envelope{
+		CipherKey: string(encryptedKey),
+		IV:        string(encryptedIV),
+		MatDesc:   matDesc,
+		WrapAlg:   wrapAlg,
+		CEKAlg:    ClientEncryptionCtrAlg,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unwrap the content key of client side encryption error, %s", err.Error())
+	}
+	return &ClientEnvelope{CipherData: *contentCipher.GetCipherData(), cipher: contentCipher}, nil
+}
+
+// Options returns the meta of the envelope for put object or initiate multipart upload
+func (env *ClientEnvelope) Options() []oss.Option {
+	options := []oss.Option{
+		oss.Meta(ClientEncryptionKey, base64.StdEncoding.EncodeToString(env.EncryptedKey)),
+		oss.Meta(ClientEncryptionStart, base64.StdEncoding.EncodeToString(env.EncryptedIV)),
+		oss.Meta(ClientEncryptionWrapAlg, env.WrapAlgorithm),
+		oss.Meta(ClientEncryptionCekAlg, ClientEncryptionCtrAlg),
+	}
+	if env.MatDesc != "" {
+		options = append(options, oss.Meta(ClientEncryptionMatDesc, env.MatDesc))
+	}
+	return options
+}
+
+// Reader encrypts or decrypts the content read from src, which starts at the offset of the content.
+// The same as the crypto bucket of oss go sdk, the content cipher starts from the block of the offset,
+// and the bytes before the offset in the block are discarded.
+func (env *ClientEnvelope) Reader(src io.Reader, offset int64) (io.Reader, error) {
+	skip := offset % clientEncryptionBlockSize
+	cd := env.CipherData.Clone()
+	cd.SeekIV(uint64(offset - skip))
+	contentCipher, err := env.cipher.Clone(cd)
+	if err != nil {
+		return nil, err
+	}
+
+	if skip > 0 {
+		src = io.MultiReader(bytes.NewReader(make([]byte, skip)), src)
+	}
+	// the encryption and the decryption of CTR are the same
+	reader, err := contentCipher.EncryptContent(src)
+	if err != nil {
+		return nil, err
+	}
+	if skip > 0 {
+		if _, err = io.CopyN(ioutil.Discard, reader, skip); err != nil {
+			return nil, err
+		}
+	}
+	return reader, nil
+}
+
+// DecryptFile decrypts the file in place, the file keeps the content from the offset of the object
+func (env *ClientEnvelope) DecryptFile(fileName string, offset int64) error {
+	fd, err := os.OpenFile(fileName, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	f, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+	reader, err := env.Reader(io.NewSectionReader(fd, 0, f.Size()), offset)
+	if err != nil {
+		return err
+	}
+
+	// the content is written back behind where it's read
+	buf := make([]byte, clientEncryptionBufSize)
+	var pos int64
+	for {
+		n, err := io.ReadFull(reader, buf)
+		if n > 0 {
+			if _, werr := fd.WriteAt(buf[:n], pos); werr != nil {
+				return werr
+			}
+			pos += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// clientEncryptionCheckpoint is the checkpoint of encrypted multipart upload, the content key and iv
+// are saved wrapped by the master key, the same as the meta of the object
+type clientEncryptionCheckpoint struct {
+	Magic        string
+	FilePath     string
+	FileSize     int64
+	FileModTime  int64
+	ObjectKey    string
+	UploadID     string
+	PartSize     int64
+	EncryptedKey []byte
+	EncryptedIV  []byte
+	WrapAlg      string
+	MatDesc      string
+	Parts        []oss.UploadPart
+}
+
+func clientEncryptionCpFile(cpDir, filePath, bucketName, objectName string) string {
+	absPath, _ := filepath.Abs(filePath)
+	src := md5.Sum([]byte(absPath))
+	dest := md5.Sum([]byte(CloudURLToString(bucketName, objectName)))
+	return filepath.Join(cpDir, hex.EncodeToString(src[:])+"-"+hex.EncodeToString(dest[:])+clientEncryptionCpSuffix)
+}
+
+func (cp *clientEncryptionCheckpoint) load(cpFile string) error {
+	data, err := ioutil.ReadFile(cpFile)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, cp)
+}
+
+func (cp *clientEncryptionCheckpoint) dump(cpFile string) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cpFile, data, 0600)
+}
+
+// clientEncryptionUa appends the user agent suffix of client side encryption, as oss go sdk does
+func clientEncryptionUa(bucket *oss.Bucket, options []oss.Option) []oss.Option {
+	if bucket.Client.Config.UserSetUa {
+		return options
+	}
+	return append(options, oss.UserAgentHeader(bucket.Client.Config.UserAgent+"/"+ClientEncryptionUaSuffix))
+}
+
+// encryptedPutObjectFromFile encrypts the file with a new envelope and puts it to oss
+func (cc *CopyCommand) encryptedPutObjectFromFile(bucket *oss.Bucket, objectName, filePath string, options ...oss.Option) error {
+	fd, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	f, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+
+	env, err := cc.cpOption.encryption.NewEnvelope()
+	if err != nil {
+		return err
+	}
+	reader, err := env.Reader(fd, 0)
+	if err != nil {
+		return err
+	}
+
+	options = oss.AddContentType(options, filePath, objectName)
+	options = append(options, env.Options()...)
+	options = append(options, oss.Meta(ClientEncryptionUnencryptedContentLength, strconv.FormatInt(f.Size(), 10)))
+	options = clientEncryptionUa(bucket, options)
+	return bucket.PutObject(objectName, oss.LimitReadCloser(reader, f.Size()), options...)
+}
+
+// encryptedUploadFile encrypts the file and uploads it by multipart, the part size is aligned to the block size
+// so that each part can be encrypted independently. The uploaded parts are recorded in the checkpoint file,
+// the upload continues from the checkpoint if the file is not modified and the content key can be unwrapped.
+func (cc *CopyCommand) encryptedUploadFile(bucket *oss.Bucket, objectName, filePath string, partSize int64, options ...oss.Option) error {
+	f, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	if partSize%clientEncryptionBlockSize != 0 {
+		partSize += clientEncryptionBlockSize - partSize%clientEncryptionBlockSize
+	}
+	_, routines := cc.preparePartOption(f.Size())
+
+	cpFile := clientEncryptionCpFile(cc.cpOption.cpDir, filePath, bucket.BucketName, objectName)
+	env, cp := cc.loadEncryptionCheckpoint(bucket, cpFile, objectName, filePath, f, partSize)
+	imur := oss.InitiateMultipartUploadResult{Bucket: bucket.BucketName, Key: objectName, UploadID: cp.UploadID}
+	sizeOptions := []oss.Option{
+		oss.Meta(ClientEncryptionDataSize, strconv.FormatInt(f.Size(), 10)),
+		oss.Meta(ClientEncryptionPartSize, strconv.FormatInt(partSize, 10)),
+	}
+
+	if env == nil {
+		if env, err = cc.cpOption.encryption.NewEnvelope(); err != nil {
+			return err
+		}
+		initOptions := oss.AddContentType(cc.cpOption.options, filePath, objectName)
+		initOptions = append(initOptions, env.Options()...)
+		initOptions = append(initOptions, sizeOptions...)
+		if imur, err = bucket.InitiateMultipartUpload(objectName, clientEncryptionUa(bucket, initOptions)...); err != nil {
+			return err
+		}
+
+		cp = clientEncryptionCheckpoint{
+			Magic:        clientEncryptionCpMagic,
+			FilePath:     filePath,
+			FileSize:     f.Size(),
+			FileModTime:  f.ModTime().UnixNano(),
+			ObjectKey:    objectName,
+			UploadID:     imur.UploadID,
+			PartSize:     partSize,
+			EncryptedKey: env.EncryptedKey,
+			EncryptedIV:  env.EncryptedIV,
+			WrapAlg:      env.WrapAlgorithm,
+			MatDesc:      env.MatDesc,
+		}
+		if err = cp.dump(cpFile); err != nil {
+			return err
+		}
+	}
+
+	// the finished parts are counted at the beginning, as the resume listener expects
+	done := map[int]bool{}
+	var doneBytes int64
+	for _, part := range cp.Parts {
+		done[part.PartNumber] = true
+		doneBytes += clientEncryptionPartLen(f.Size(), partSize, part.PartNumber)
+	}
+	listener := oss.GetProgressListener(options)
+	if listener != nil {
+		listener.ProgressChanged(&oss.ProgressEvent{ConsumedBytes: doneBytes, TotalBytes: f.Size(), EventType: oss.TransferStartedEvent})
+	}
+
+	partNum := int((f.Size()-1)/partSize + 1)
+	partOptions := clientEncryptionUa(bucket, append(oss.ChoiceTransferPartOption(options), sizeOptions...))
+	chParts := make(chan int, partNum)
+	for i := 1; i <= partNum; i++ {
+		if !done[i] {
+			chParts <- i
+		}
+	}
+	close(chParts)
+
+	var lock sync.Mutex
+	var uploadErr error
+	var wg sync.WaitGroup
+	for i := 0; i < routines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for partNumber := range chParts {
+				lock.Lock()
+				failed := uploadErr != nil
+				lock.Unlock()
+				if failed {
+					return
+				}
+
+				part, err := cc.encryptedUploadPart(bucket, imur, env, filePath, f.Size(), partSize, partNumber, partOptions...)
+				lock.Lock()
+				if err != nil {
+					uploadErr = err
+				} else {
+					cp.Parts = append(cp.Parts, part)
+					if errDump := cp.dump(cpFile); errDump != nil {
+						LogError("dump checkpoint %s error,%s\n", cpFile, errDump.Error())
+					}
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if uploadErr != nil {
+		if serviceErr, ok := uploadErr.(oss.ServiceError); ok && serviceErr.Code == "NoSuchUpload" {
+			// the upload is aborted, start over next time
+			os.Remove(cpFile)
+		}
+		return uploadErr
+	}
+
+	sort.Slice(cp.Parts, func(i, j int) bool { return cp.Parts[i].PartNumber < cp.Parts[j].PartNumber })
+	if _, err = bucket.CompleteMultipartUpload(imur, cp.Parts, oss.ChoiceCompletePartOption(options)...); err != nil {
+		return err
+	}
+	os.Remove(cpFile)
+	return nil
+}
+
+// loadEncryptionCheckpoint returns the envelope and checkpoint to continue the upload, the envelope is nil
+// if the upload can't continue, and the upload in the invalid checkpoint is aborted.
+func (cc *CopyCommand) loadEncryptionCheckpoint(bucket *oss.Bucket, cpFile, objectName, filePath string, f os.FileInfo, partSize int64) (*ClientEnvelope, clientEncryptionCheckpoint) {
+	var cp clientEncryptionCheckpoint
+	if err := cp.load(cpFile); err != nil {
+		return nil, clientEncryptionCheckpoint{}
+	}
+
+	var env *ClientEnvelope
+	var err error
+	if cp.Magic == clientEncryptionCpMagic && cp.FilePath == filePath && cp.ObjectKey == objectName &&
+		cp.FileSize == f.Size() && cp.FileModTime == f.ModTime().UnixNano() && cp.PartSize == partSize {
+		env, err = cc.cpOption.encryption.openEnvelope(cp.EncryptedKey, cp.EncryptedIV, cp.WrapAlg, cp.MatDesc)
+		if err == nil && cp.WrapAlg == cc.cpOption.encryption.wrapAlg() && cp.MatDesc == cc.cpOption.encryption.matDesc() {
+			return env, cp
+		}
+	}
+
+	// the file is modified, or the content key can't be unwrapped by the current master key, start over
+	if cp.UploadID != "" {
+		imur := oss.InitiateMultipartUploadResult{Bucket: bucket.BucketName, Key: cp.ObjectKey, UploadID: cp.UploadID}
+		if err := bucket.AbortMultipartUpload(imur, oss.ChoiceAbortPartOption(cc.cpOption.options)...); err != nil {
+			LogError("abort upload %s of %s error,%s\n", cp.UploadID, cp.ObjectKey, err.Error())
+		}
+	}
+	os.Remove(cpFile)
+	return nil, clientEncryptionCheckpoint{}
+}
+
+func (cc *CopyCommand) encryptedUploadPart(bucket *oss.Bucket, imur oss.InitiateMultipartUploadResult, env *ClientEnvelope,
+	filePath string, fileSize, partSize int64, partNumber int, options ...oss.Option) (oss.UploadPart, error) {
+	fd, err := os.Open(filePath)
+	if err != nil {
+		return oss.UploadPart{}, err
+	}
+	defer fd.Close()
+
+	offset := int64(partNumber-1) * partSize
+	size := clientEncryptionPartLen(fileSize, partSize, partNumber)
+	reader, err := env.Reader(io.NewSectionReader(fd, offset, size), offset)
+	if err != nil {
+		return oss.UploadPart{}, err
+	}
+	return bucket.UploadPart(imur, oss.LimitReadCloser(reader, size), size, partNumber, options...)
+}
+
+func clientEncryptionPartLen(fileSize, partSize int64, partNumber int) int64 {
+	offset := int64(partNumber-1) * partSize
+	if offset+partSize > fileSize {
+		return fileSize - offset
+	}
+	return partSize
+}
+
+// openObjectEnvelope returns the envelope of the object, nil if the object is not encrypted on client side
+func (cc *CopyCommand) openObjectEnvelope(bucket *oss.Bucket, object string) (*ClientEnvelope, error) {
+	statOptions := cc.cpOption.payerOptions
+	if cc.cpOption.versionId != "" {
+		statOptions = append(statOptions, oss.VersionId(cc.cpOption.versionId))
+	}
+	props, err := cc.command.ossGetObjectStatRetry(bucket, object, statOptions...)
+	if err != nil {
+		return nil, err
+	}
+	env, err := cc.cpOption.encryption.OpenEnvelope(props)
+	if err != nil {
+		return nil, ObjectError{err, bucket.BucketName, object}
+	}
+	return env, nil
+}
+
+// decryptDownloadedFile decrypts the cipher text downloaded to the temp file, and renames it to the file
+func (cc *CopyCommand) decryptDownloadedFile(env *ClientEnvelope, tempFileName, fileName string, size int64) error {
+	var offset int64
+	if cc.cpOption.vrange != "" {
+		if ur, err := oss.ParseRange("bytes=" + cc.cpOption.vrange); err == nil {
+			offset, _ = oss.AdjustRange(ur, size)
+		}
+	}
+	if err := env.DecryptFile(tempFileName, offset); err != nil {
+		return err
+	}
+	return os.Rename(tempFileName, fileName)
+}
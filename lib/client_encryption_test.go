@@ -0,0 +1,229 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	osscrypto "github.com/aliyun/aliyun-oss-go-sdk/oss/crypto"
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) createRsaKeyFiles(dir string, c *C) (string, string) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	c.Assert(err, IsNil)
+	publicBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	c.Assert(err, IsNil)
+
+	publicFile := filepath.Join(dir, "public.pem")
+	privateFile := filepath.Join(dir, "private.pem")
+	c.Assert(ioutil.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicBytes}), 0600), IsNil)
+	c.Assert(ioutil.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), 0600), IsNil)
+	return publicFile, privateFile
+}
+
+// envelopeHeader makes the response header of the object from the options of put object
+func envelopeHeader(options []oss.Option) http.Header {
+	header := http.Header{}
+	for _, name := range []string{ClientEncryptionKey, ClientEncryptionStart, ClientEncryptionCekAlg, ClientEncryptionWrapAlg, ClientEncryptionMatDesc} {
+		if val, _ := oss.FindOption(options, oss.HTTPHeaderOssMetaPrefix+name, nil); val != nil {
+			header.Set(oss.HTTPHeaderOssMetaPrefix+name, val.(string))
+		}
+	}
+	return header
+}
+
+func (s *OssutilCommandSuite) TestClientEncryptionRsa(c *C) {
+	dir := "ossutil-cse-" + randLowStr(6)
+	c.Assert(os.MkdirAll(dir, 0755), IsNil)
+	defer os.RemoveAll(dir)
+	publicFile, privateFile := s.createRsaKeyFiles(dir, c)
+
+	uploader, err := NewClientEncryption(publicFile, "", "", `{"desc":"test"}`)
+	c.Assert(err, IsNil)
+	env, err := uploader.NewEnvelope()
+	c.Assert(err, IsNil)
+	c.Assert(len(env.Key), Equals, 32)
+	c.Assert(binary.BigEndian.Uint64(env.IV[8:]) <= uint64(^uint32(0)), Equals, true)
+
+	header := envelopeHeader(env.Options())
+	c.Assert(IsClientEncrypted(header), Equals, true)
+	c.Assert(header.Get(oss.HTTPHeaderOssMetaPrefix+ClientEncryptionWrapAlg), Equals, ClientEncryptionRsaWrap)
+	c.Assert(header.Get(oss.HTTPHeaderOssMetaPrefix+ClientEncryptionCekAlg), Equals, ClientEncryptionCtrAlg)
+	c.Assert(header.Get(oss.HTTPHeaderOssMetaPrefix+ClientEncryptionMatDesc), Equals, `{"desc":"test"}`)
+
+	// the public key can't decrypt
+	_, err = uploader.OpenEnvelope(header)
+	c.Assert(err, NotNil)
+
+	downloader, err := NewClientEncryption("", privateFile, "", "")
+	c.Assert(err, IsNil)
+	opened, err := downloader.OpenEnvelope(header)
+	c.Assert(err, IsNil)
+	c.Assert(opened.Key, DeepEquals, env.Key)
+	c.Assert(opened.IV, DeepEquals, env.IV)
+
+	// not encrypted object
+	opened, err = downloader.OpenEnvelope(http.Header{})
+	c.Assert(err, IsNil)
+	c.Assert(opened == nil, Equals, true)
+
+	// the content key encoded by base64 in the meta
+	encryptedKey, err := base64.StdEncoding.DecodeString(header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionKey))
+	c.Assert(err, IsNil)
+	c.Assert(encryptedKey, DeepEquals, env.EncryptedKey)
+}
+
+func (s *OssutilCommandSuite) TestClientEncryptionAes(c *C) {
+	dir := "ossutil-cse-" + randLowStr(6)
+	c.Assert(os.MkdirAll(dir, 0755), IsNil)
+	defer os.RemoveAll(dir)
+
+	keyFile := filepath.Join(dir, "aes.key")
+	key := make([]byte, 32)
+	rand.Read(key)
+	c.Assert(ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600), IsNil)
+
+	ce, err := NewClientEncryption("", "", keyFile, "")
+	c.Assert(err, IsNil)
+	env, err := ce.NewEnvelope()
+	c.Assert(err, IsNil)
+	header := envelopeHeader(env.Options())
+	c.Assert(header.Get(oss.HTTPHeaderOssMetaPrefix+ClientEncryptionWrapAlg), Equals, ClientEncryptionAesWrap)
+	opened, err := ce.OpenEnvelope(header)
+	c.Assert(err, IsNil)
+	c.Assert(opened.Key, DeepEquals, env.Key)
+
+	// another aes key can't decrypt
+	rand.Read(key)
+	c.Assert(ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)), 0600), IsNil)
+	other, err := NewClientEncryption("", "", keyFile, "")
+	c.Assert(err, IsNil)
+	_, err = other.OpenEnvelope(header)
+	c.Assert(err, NotNil)
+
+	// invalid key and options
+	c.Assert(ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key[:10])), 0600), IsNil)
+	_, err = NewClientEncryption("", "", keyFile, "")
+	c.Assert(err, NotNil)
+	_, err = NewClientEncryption(keyFile, "", keyFile, "")
+	c.Assert(err, NotNil)
+	_, err = NewClientEncryption("", "", "", `{"desc":"test"}`)
+	c.Assert(err, NotNil)
+	ce, err = NewClientEncryption("", "", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(ce == nil, Equals, true)
+}
+
+func (s *OssutilCommandSuite) TestClientEncryptionStream(c *C) {
+	dir := "ossutil-cse-" + randLowStr(6)
+	c.Assert(os.MkdirAll(dir, 0755), IsNil)
+	defer os.RemoveAll(dir)
+	publicFile, privateFile := s.createRsaKeyFiles(dir, c)
+	ce, err := NewClientEncryption(publicFile, privateFile, "", "")
+	c.Assert(err, IsNil)
+	env, err := ce.NewEnvelope()
+	c.Assert(err, IsNil)
+
+	plain := make([]byte, 1000)
+	rand.Read(plain)
+	reader, err := env.Reader(bytes.NewReader(plain), 0)
+	c.Assert(err, IsNil)
+	cipherText, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+	c.Assert(len(cipherText), Equals, len(plain))
+	c.Assert(bytes.Equal(cipherText, plain), Equals, false)
+
+	// the parts encrypted from their offsets are the same as the whole
+	for _, offset := range []int64{16, 100, 999} {
+		reader, err = env.Reader(bytes.NewReader(plain[offset:]), offset)
+		c.Assert(err, IsNil)
+		part, err := ioutil.ReadAll(reader)
+		c.Assert(err, IsNil)
+		c.Assert(part, DeepEquals, cipherText[offset:])
+	}
+
+	// decrypt the downloaded range in place
+	fileName := filepath.Join(dir, "range")
+	c.Assert(ioutil.WriteFile(fileName, cipherText[100:500], 0600), IsNil)
+	c.Assert(env.DecryptFile(fileName, 100), IsNil)
+	data, err := ioutil.ReadFile(fileName)
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, plain[100:500])
+
+	cc := CopyCommand{}
+	cc.cpOption.vrange = "100-499"
+	tempFileName := fileName + EncryptedTempFileSuffix
+	c.Assert(ioutil.WriteFile(tempFileName, cipherText[100:500], 0600), IsNil)
+	c.Assert(cc.decryptDownloadedFile(env, tempFileName, fileName, int64(len(plain))), IsNil)
+	data, err = ioutil.ReadFile(fileName)
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, plain[100:500])
+	_, err = os.Stat(tempFileName)
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	c.Assert(clientEncryptionPartLen(1000, 112, 1), Equals, int64(112))
+	c.Assert(clientEncryptionPartLen(1000, 112, 9), Equals, int64(104))
+}
+
+func (s *OssutilCommandSuite) TestClientEncryptionSdkCompatible(c *C) {
+	dir := "ossutil-cse-" + randLowStr(6)
+	c.Assert(os.MkdirAll(dir, 0755), IsNil)
+	defer os.RemoveAll(dir)
+	publicFile, privateFile := s.createRsaKeyFiles(dir, c)
+	ce, err := NewClientEncryption(publicFile, "", "", "")
+	c.Assert(err, IsNil)
+	env, err := ce.NewEnvelope()
+	c.Assert(err, IsNil)
+
+	plain := make([]byte, 1000)
+	rand.Read(plain)
+	reader, err := env.Reader(bytes.NewReader(plain), 0)
+	c.Assert(err, IsNil)
+	cipherText, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+
+	// the content cipher of oss go sdk decrypts the object by its meta
+	header := envelopeHeader(env.Options())
+	encryptedKey, err := base64.StdEncoding.DecodeString(header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionKey))
+	c.Assert(err, IsNil)
+	encryptedIV, err := base64.StdEncoding.DecodeString(header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionStart))
+	c.Assert(err, IsNil)
+	privateKey, err := ioutil.ReadFile(privateFile)
+	c.Assert(err, IsNil)
+	master, err := osscrypto.CreateMasterRsa(nil, "", string(privateKey))
+	c.Assert(err, IsNil)
+	contentCipher, err := osscrypto.CreateAesCtrCipher(master).ContentCipherEnv(osscrypto.Envelope{
+		CipherKey: string(encryptedKey),
+		IV:        string(encryptedIV),
+		WrapAlg:   header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionWrapAlg),
+		CEKAlg:    header.Get(oss.HTTPHeaderOssMetaPrefix + ClientEncryptionCekAlg),
+	})
+	c.Assert(err, IsNil)
+	decrypter, err := contentCipher.DecryptContent(bytes.NewReader(cipherText))
+	c.Assert(err, IsNil)
+	data, err := ioutil.ReadAll(decrypter)
+	c.Assert(err, IsNil)
+	c.Assert(data, DeepEquals, plain)
+}
+
+func (s *OssutilCommandSuite) TestClientEncryptionConfig(c *C) {
+	configFile := "ossutil-cse-config-" + randLowStr(6)
+	defer os.Remove(configFile)
+	data := "[Credentials]\nendpoint = oss-cn-hangzhou.aliyuncs.com\nencryption_rsa_private_key = private.pem\nencryptionMatDesc = {\"a\":\"b\"}\n"
+	c.Assert(ioutil.WriteFile(configFile, []byte(data), 0600), IsNil)
+
+	configMap, err := LoadConfig(configFile)
+	c.Assert(err, IsNil)
+	c.Assert(configMap[OptionEncryptionPriKey], Equals, "private.pem")
+	c.Assert(configMap[OptionEncryptionMatDesc], Equals, `{"a":"b"}`)
+}
@@ -8,7 +8,6 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
-	"time"
 
 	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
@@ -52,6 +51,7 @@ type Command struct {
 	options          OptionMapType
 	configOptions    OptionMapType
 	inputKeySecret   string
+	bandwidth        *BandwidthScheduler
 }
 
 // Commander is the interface of all commands
@@ -119,19 +119,36 @@ func (cmd *Command) loadConfig(configFile string, cmder interface{}) error {
 	if cmdder, ok := cmder.(RewriteLoadConfiger); ok {
 		return cmdder.rewriteLoadConfig(configFile)
 	}
-	var err error
-	if cmd.configOptions, err = LoadConfig(configFile); err != nil && cmd.needConfigFile() {
+	envOptions, err := readConfigFromEnv()
+	if err != nil {
 		return err
 	}
+
+	profile, _ := GetString(OptionProfile, cmd.options)
+	if cmd.configOptions, err = LoadProfileConfig(configFile, profile); err != nil {
+		cmd.configOptions = OptionMapType{}
+		if cmd.needConfigFile() {
+			return err
+		}
+	}
+
+	// environment variables have priority over config file
+	for name, val := range envOptions {
+		cmd.configOptions[name] = val
+	}
 	return nil
 }
 
 func (cmd *Command) needConfigFile() bool {
+	envOptions, _ := readConfigFromEnv()
 	for _, name := range []string{OptionEndpoint, OptionAccessKeyID, OptionAccessKeySecret, OptionSTSToken} {
 		val, _ := GetString(name, cmd.options)
 		if val != "" {
 			return false
 		}
+		if _, ok := envOptions[name]; ok {
+			return false
+		}
 	}
 	return true
 }
@@ -308,6 +325,12 @@ func (cmd *Command) ossClient(bucket string) (*oss.Client, error) {
 
 	stsRegion, _ := GetString(OptionSTSRegion, cmd.options)
 
+	credentialProcess, _ := GetString(OptionCredentialProcess, cmd.options)
+	oidcProviderArn, _ := GetString(OptionOIDCProviderArn, cmd.options)
+	oidcTokenFile, _ := GetString(OptionOIDCTokenFile, cmd.options)
+	credentialsCache, _ := GetString(OptionCredentialsCache, cmd.options)
+	cache := NewCredentialsCache(credentialsCache)
+
 	ecsUrl := ""
 
 	localHost, _ := GetString(OptionLocalHost, cmd.options)
@@ -373,37 +396,38 @@ func (cmd *Command) ossClient(bucket string) (*oss.Client, error) {
 		if ramRoleArn == "" {
 			return nil, fmt.Errorf("ramRoleArn is empty")
 		}
-		if roleSessionName == "" {
-			roleSessionName = "SessNameRand" + randStr(5)
-		}
-
-		stsClient := NewClient(accessKeyID, accessKeySecret, ramRoleArn, roleSessionName)
-
-		if strTokenTimeout == "" {
-			strTokenTimeout = "3600"
-		}
-		intTokenTimeout, err := strconv.Atoi(strTokenTimeout)
+		tokenTimeout, err := getTokenTimeout(strTokenTimeout)
 		if err != nil {
 			return nil, err
 		}
-		TokenTimeout := uint(intTokenTimeout)
 
-		stsEndPoint := ""
-		if stsRegion == "" {
-			stsEndPoint = ""
-		} else {
-			stsEndPoint = "https://sts." + stsRegion + ".aliyuncs.com"
+		// the role is assumed again before the credentials expire, so that long transfers survive token rotation
+		provider := newRamRoleArnProvider(accessKeyID, accessKeySecret, ramRoleArn, roleSessionName, tokenTimeout, getStsEndPoint(stsRegion), cache)
+		if _, err := provider.GetCredentialsE(); err != nil {
+			return nil, err
 		}
-
-		resp, err := stsClient.AssumeRole(TokenTimeout, stsEndPoint)
+		options = append(options, oss.SetCredentialsProvider(provider))
+		accessKeyID = ""
+		accessKeySecret = ""
+	} else if strings.EqualFold(mode, "CredentialProcess") {
+		if credentialProcess == "" {
+			return nil, fmt.Errorf("credentialProcess is empty")
+		}
+		options = append(options, oss.SetCredentialsProvider(newProcessCredentialsProvider(credentialProcess, cache)))
+		accessKeyID = ""
+		accessKeySecret = ""
+	} else if strings.EqualFold(mode, "OIDCRoleArn") {
+		if ramRoleArn == "" || oidcProviderArn == "" || oidcTokenFile == "" {
+			return nil, fmt.Errorf("ramRoleArn, oidcProviderArn and oidcTokenFile can not be empty")
+		}
+		tokenTimeout, err := getTokenTimeout(strTokenTimeout)
 		if err != nil {
 			return nil, err
 		}
-
-		accessKeyID = resp.Credentials.AccessKeyId
-		accessKeySecret = resp.Credentials.AccessKeySecret
-		stsToken = resp.Credentials.SecurityToken
-		options = append(options, oss.SecurityToken(stsToken))
+		provider := newOIDCCredentialsProvider(ramRoleArn, oidcProviderArn, oidcTokenFile, roleSessionName, tokenTimeout, getStsEndPoint(stsRegion), cache)
+		options = append(options, oss.SetCredentialsProvider(provider))
+		accessKeyID = ""
+		accessKeySecret = ""
 	} else if strings.EqualFold(mode, "EcsRamRole") {
 		if ecsRoleName != "" {
 			ecsUrl = "http://100.100.100.200/latest/meta-data/Ram/security-credentials/" + ecsRoleName
@@ -420,19 +444,38 @@ func (cmd *Command) ossClient(bucket string) (*oss.Client, error) {
 		accessKeySecret = ""
 
 	} else if mode == "" {
+		// the credentials provider chain: AK, credential process, OIDC role, user ak service
+		providers := []oss.CredentialsProviderE{}
+		if accessKeyID == "" {
+			if credentialProcess != "" {
+				LogInfo("using credential process:%s\n", credentialProcess)
+				providers = append(providers, newProcessCredentialsProvider(credentialProcess, cache))
+			}
+			if ramRoleArn != "" && oidcProviderArn != "" && oidcTokenFile != "" {
+				tokenTimeout, err := getTokenTimeout(strTokenTimeout)
+				if err != nil {
+					return nil, err
+				}
+				LogInfo("using oidc role:%s\n", ramRoleArn)
+				providers = append(providers, newOIDCCredentialsProvider(ramRoleArn, oidcProviderArn, oidcTokenFile, roleSessionName, tokenTimeout, getStsEndPoint(stsRegion), cache))
+			}
+		}
+
 		ecsUrl, _ = cmd.getEcsRamAkService()
-		if accessKeyID == "" && ecsUrl == "" {
+		if accessKeyID == "" && ecsUrl == "" && len(providers) == 0 {
 			return nil, fmt.Errorf("accessKeyID and ecsUrl are both empty")
 		}
-		if ecsUrl == "" {
+		if ecsUrl == "" && len(providers) == 0 {
 			if err := cmd.checkCredentials(endpoint, accessKeyID, accessKeySecret); err != nil {
 				return nil, err
 			}
 		}
 		if accessKeyID == "" {
-			LogInfo("using user ak service:%s\n", ecsUrl)
-			ecsRoleAKBuild := EcsRoleAKBuild{url: ecsUrl}
-			options = append(options, oss.SetCredentialsProvider(&ecsRoleAKBuild))
+			if ecsUrl != "" {
+				LogInfo("using user ak service:%s\n", ecsUrl)
+				providers = append(providers, &EcsRoleAKBuild{url: ecsUrl})
+			}
+			options = append(options, oss.SetCredentialsProvider(NewCredentialsProviderChain(providers...)))
 		}
 
 		if stsToken != "" {
@@ -529,6 +572,12 @@ func (cmd *Command) ossClient(bucket string) (*oss.Client, error) {
 		}
 	}
 
+	if cmd.bandwidth != nil {
+		if err = cmd.bandwidth.AddClient(bucket, client); err != nil {
+			return nil, err
+		}
+	}
+
 	return client, nil
 }
 
@@ -545,6 +594,24 @@ func (cmd *Command) checkCredentials(endpoint, accessKeyID, accessKeySecret stri
 	return nil
 }
 
+func getTokenTimeout(strTokenTimeout string) (uint, error) {
+	if strTokenTimeout == "" {
+		strTokenTimeout = "3600"
+	}
+	intTokenTimeout, err := strconv.Atoi(strTokenTimeout)
+	if err != nil {
+		return 0, err
+	}
+	return uint(intTokenTimeout), nil
+}
+
+func getStsEndPoint(stsRegion string) string {
+	if stsRegion == "" {
+		return ""
+	}
+	return "https://sts." + stsRegion + ".aliyuncs.com"
+}
+
 func (cmd *Command) getEcsRamAkService() (string, bool) {
 	if urlMap, ok := cmd.configOptions[AkServiceSection]; ok {
 		if strUrl, ok := urlMap.(map[string]string)[ItemEcsAk]; ok {
@@ -602,97 +669,107 @@ func (cmd *Command) ossBucket(bucketName string) (*oss.Bucket, error) {
 	return bucket, nil
 }
 
-func (cmd *Command) ossListObjectsRetry(bucket *oss.Bucket, options ...oss.Option) (oss.ListObjectsResult, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, cmd.options)
-	for i := 1; ; i++ {
-		lor, err := bucket.ListObjects(options...)
-		if err == nil {
-			return lor, err
-		}
+// bandwidthBucket returns the bucket whose client has the current limit of the bandwidth schedule,
+// the client is created again after the limit changes
+func (cmd *Command) bandwidthBucket(bucket *oss.Bucket) *oss.Bucket {
+	if cmd.bandwidth == nil {
+		return bucket
+	}
+	client, ok := cmd.bandwidth.Client(bucket.BucketName, &bucket.Client)
+	if ok && client.Config == bucket.Client.Config {
+		return bucket
+	}
 
-		// http 4XX error no need to retry
-		// only network error or internal error need to retry
-		serviceError, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-			return lor, ObjectError{err, bucket.BucketName, ""}
-		}
+	var newBucket *oss.Bucket
+	var err error
+	if ok {
+		newBucket, err = client.Bucket(bucket.BucketName)
+	} else {
+		newBucket, err = cmd.ossBucket(bucket.BucketName)
+	}
+	if err != nil {
+		LogError("create client with the new bandwidth limit of bucket %s error,%s\n", bucket.BucketName, err.Error())
+		return bucket
+	}
+	return newBucket
+}
 
-		// wait 1 second
-		time.Sleep(time.Duration(1) * time.Second)
+func (cmd *Command) ossListObjectsRetry(bucket *oss.Bucket, options ...oss.Option) (oss.ListObjectsResult, error) {
+	var lor oss.ListObjectsResult
+	err := cmd.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		lor, err = bucket.ListObjects(withResponseHeader(options, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return lor, ObjectError{err, bucket.BucketName, ""}
 	}
+	return lor, nil
 }
 
 func (cmd *Command) ossListObjectVersionsRetry(bucket *oss.Bucket, options ...oss.Option) (oss.ListObjectVersionsResult, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, cmd.options)
-	for i := 1; ; i++ {
-		lor, err := bucket.ListObjectVersions(options...)
-		if err == nil {
-			return lor, err
-		}
-		if int64(i) >= retryTimes {
-			return lor, BucketError{err, bucket.BucketName}
-		}
+	var lor oss.ListObjectVersionsResult
+	err := cmd.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		lor, err = bucket.ListObjectVersions(withResponseHeader(options, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return lor, BucketError{err, bucket.BucketName}
 	}
+	return lor, nil
 }
 
 func (cmd *Command) ossListMultipartUploadsRetry(bucket *oss.Bucket, options ...oss.Option) (oss.ListMultipartUploadResult, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, cmd.options)
-	for i := 1; ; i++ {
-		lmr, err := bucket.ListMultipartUploads(options...)
-		if err == nil {
-			return lmr, err
-		}
-
-		// http 4XX error no need to retry
-		// only network error or internal error need to retry
-		serviceError, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-			return lmr, ObjectError{err, bucket.BucketName, ""}
-		}
-
-		// wait 1 second
-		time.Sleep(time.Duration(1) * time.Second)
+	var lmr oss.ListMultipartUploadResult
+	err := cmd.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		lmr, err = bucket.ListMultipartUploads(withResponseHeader(options, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return lmr, ObjectError{err, bucket.BucketName, ""}
 	}
+	return lmr, nil
 }
 
 func (cmd *Command) ossGetObjectStatRetry(bucket *oss.Bucket, object string, options ...oss.Option) (http.Header, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, cmd.options)
-	for i := 1; ; i++ {
-		props, err := bucket.GetObjectDetailedMeta(object, options...)
-		if err == nil {
-			return props, err
-		}
-
-		// http 4XX error no need to retry
-		// only network error or internal error need to retry
-		serviceError, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-			return props, ObjectError{err, bucket.BucketName, object}
-		}
-
-		// wait 1 second
-		time.Sleep(time.Duration(1) * time.Second)
+	var props http.Header
+	err := cmd.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		props, err = bucket.GetObjectDetailedMeta(object, withResponseHeader(options, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return props, ObjectError{err, bucket.BucketName, object}
 	}
+	return props, nil
 }
 
 func (cmd *Command) ossGetObjectMetaRetry(bucket *oss.Bucket, object string, options ...oss.Option) (http.Header, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, cmd.options)
-	for i := 1; ; i++ {
-		props, err := bucket.GetObjectMeta(object, options...)
-		if err == nil {
-			return props, err
-		}
-
-		// http 4XX error no need to retry
-		// only network error or internal error need to retry
-		serviceError, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-			return props, ObjectError{err, bucket.BucketName, object}
-		}
+	var props http.Header
+	err := cmd.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		props, err = bucket.GetObjectMeta(object, withResponseHeader(options, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return props, ObjectError{err, bucket.BucketName, object}
+	}
+	return props, nil
+}
 
-		// wait 1 second
-		time.Sleep(time.Duration(1) * time.Second)
+func (cmd *Command) ossGetObjectTaggingRetry(bucket *oss.Bucket, object string, options ...oss.Option) ([]oss.Tag, error) {
+	var result oss.GetObjectTaggingResult
+	err := cmd.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		result, err = bucket.GetObjectTagging(object, withResponseHeader(options, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return nil, ObjectError{err, bucket.BucketName, object}
 	}
+	return result.Tags, nil
 }
 
 func (cmd *Command) objectStatistic(bucket *oss.Bucket, cloudURL CloudURL, monitor Monitorer, filters []filterOptionType, options ...oss.Option) {
@@ -700,6 +777,12 @@ func (cmd *Command) objectStatistic(bucket *oss.Bucket, cloudURL CloudURL, monit
 		return
 	}
 
+	attrFilter, err := cmd.attributeFilter()
+	if err != nil {
+		monitor.setScanError(err)
+		return
+	}
+
 	pre := oss.Prefix(cloudURL.object)
 	marker := oss.Marker("")
 	for {
@@ -711,7 +794,15 @@ func (cmd *Command) objectStatistic(bucket *oss.Bucket, cloudURL CloudURL, monit
 		}
 
 		for _, object := range lor.Objects {
-			if doesSingleObjectMatchPatterns(object.Key, filters) {
+			if !doesSingleObjectMatchPatterns(filterRelativeKey(cloudURL.object, object.Key), filters) {
+				continue
+			}
+			match, err := attrFilter.matchObject(bucket, object, options...)
+			if err != nil {
+				monitor.setScanError(err)
+				return
+			}
+			if match {
 				monitor.updateScanNum(1)
 			}
 		}
@@ -727,6 +818,12 @@ func (cmd *Command) objectStatistic(bucket *oss.Bucket, cloudURL CloudURL, monit
 
 func (cmd *Command) objectProducer(bucket *oss.Bucket, cloudURL CloudURL, chObjects chan<- string, chError chan<- error, filters []filterOptionType, options ...oss.Option) {
 	defer close(chObjects)
+	attrFilter, err := cmd.attributeFilter()
+	if err != nil {
+		chError <- err
+		return
+	}
+
 	pre := oss.Prefix(cloudURL.object)
 	marker := oss.Marker("")
 	for {
@@ -737,7 +834,15 @@ func (cmd *Command) objectProducer(bucket *oss.Bucket, cloudURL CloudURL, chObje
 			return
 		}
 		for _, object := range lor.Objects {
-			if doesSingleObjectMatchPatterns(object.Key, filters) {
+			if !doesSingleObjectMatchPatterns(filterRelativeKey(cloudURL.object, object.Key), filters) {
+				continue
+			}
+			match, err := attrFilter.matchObject(bucket, object, options...)
+			if err != nil {
+				chError <- err
+				return
+			}
+			if match {
 				chObjects <- object.Key
 			}
 		}
@@ -763,6 +868,24 @@ func (cmd *Command) getRawMarker(str string) (string, error) {
 	return str, nil
 }
 
+func (cmd *Command) isDryRun() bool {
+	dryRun, _ := GetBool(OptionDryRun, cmd.options)
+	return dryRun
+}
+
+// printDryRun prints the action planned in dry run mode, instead of doing it
+func printDryRun(action, reason string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	msg := "(dryrun) " + action
+	if reason != "" {
+		msg += fmt.Sprintf(", reason: %s", reason)
+	}
+	fmt.Printf(getClearStr(msg + "\n"))
+	LogInfo("%s\n", msg)
+}
+
 func (cmd *Command) updateMonitor(err error, monitor *Monitor) {
 	if monitor == nil {
 		return
@@ -873,6 +996,7 @@ func GetAllCommands() []interface{} {
 		&allPartSizeCommand,
 		&appendFileCommand,
 		&catCommand,
+		&tailCommand,
 		&bucketTagCommand,
 		&bucketEncryptionCommand,
 		&corsOptionsCommand,
@@ -896,5 +1020,7 @@ func GetAllCommands() []interface{} {
 		&lcbCommand,
 		&bucketAccessMonitorCommand,
 		&bucketResourceGroupCommand,
+		&jobsCommand,
+		&moveCommand,
 	}
 }
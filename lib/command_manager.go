@@ -122,7 +122,8 @@ func (cm *CommandManager) RunCommand(commandName string, args []string, options
 			return false, err
 		}
 		group := reflect.ValueOf(cmd).Elem().FieldByName("command").FieldByName("group").String()
-		return group == GroupTypeNormalCommand, nil
+		// keep the machine readable output clean
+		return group == GroupTypeNormalCommand && getOutputFormat(options) == "", nil
 	}
 	return false, fmt.Errorf("no such command: \"%s\", please try \"help\" for more information", commandName)
 }
@@ -137,8 +138,9 @@ func getLoglevelFromOptions(options OptionMapType) (string, error) {
 	}
 
 	configFile, _ := GetString(OptionConfigFile, options)
+	profile, _ := GetString(OptionProfile, options)
 
-	strLevel, err = readLoglevelFromFile(configFile)
+	strLevel, err = readLoglevelFromFile(configFile, profile)
 	if err != nil {
 		return "", err
 	}
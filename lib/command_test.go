@@ -2185,57 +2185,35 @@ func (s *OssutilCommandSuite) TestGetFilter(c *C) {
 	//e.g., ossutil cp oss://tempb4/ . -rf --include "*.txt" --exclude "*.jpg"
 	cmdline := []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--include", "*.txt", "--exclude", "*.jpg"}
 	expect := []filterOptionType{{"--include", "*.txt"}, {"--exclude", "*.jpg"}}
-	res, fts := getFilter(cmdline)
+	fts, err := getFilter(cmdline)
+	c.Assert(err, IsNil)
 	same := reflect.DeepEqual(fts, expect)
 	c.Assert(same, Equals, true)
 
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--include", "/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
-
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--include", "*/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
-
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--include", "bin/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
-
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--include", "/*/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
-
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--include", "/usr/bin/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
-
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--exclude", "/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
-
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--exclude", "*/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
-
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--exclude", "bin/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
+	// the patterns containing dir info match the relative path
+	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--include", "/*.txt", "--exclude=bin/**/*.txt",
+		"--include-regex", "^usr/.*\\.txt$", "--exclude-regex=tmp", "--include", "*[!0-3]?txt"}
+	expect = []filterOptionType{{"--include", "/*.txt"}, {"--exclude", "bin/**/*.txt"},
+		{"--include-regex", "^usr/.*\\.txt$"}, {"--exclude-regex", "tmp"}, {"--include", "*[^0-3]?txt"}}
+	fts, err = getFilter(cmdline)
+	c.Assert(err, IsNil)
+	c.Assert(fts, DeepEquals, expect)
 
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--exclude", "/*/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
+	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--include", "[a-"}
+	_, err = getFilter(cmdline)
+	c.Assert(err, NotNil)
 
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--exclude", "/usr/bin/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
+	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--exclude", "bin/[a-"}
+	_, err = getFilter(cmdline)
+	c.Assert(err, NotNil)
 
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--exclude", "bin/*.txt", "--include", "/usr/bin/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
+	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--include-regex", "a(b"}
+	_, err = getFilter(cmdline)
+	c.Assert(err, NotNil)
 
-	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--exclude", "/*/*.txt", "--include", "/*/*.txt"}
-	res, fts = getFilter(cmdline)
-	c.Assert(res, Equals, false)
+	cmdline = []string{"ossutil", "cp", "oss://tempb4", ".", "-rf", "--exclude-from", "ossutil-not-exist-" + randLowStr(6)}
+	_, err = getFilter(cmdline)
+	c.Assert(err, NotNil)
 }
 
 func (s *OssutilCommandSuite) TestContainsInStrsSlice(c *C) {
@@ -2620,16 +2598,16 @@ func (s *OssutilCommandSuite) TestDoesSingleObjectMatchPatterns(c *C) {
 
 func (s *OssutilCommandSuite) TestGetObjectsFromChanToArray(c *C) {
 	chObjects := make(chan objectInfoType, ChannelBuf)
-	chObjects <- objectInfoType{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}
-	chObjects <- objectInfoType{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}
-	chObjects <- objectInfoType{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)}
+	chObjects <- objectInfoType{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}
+	chObjects <- objectInfoType{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}
+	chObjects <- objectInfoType{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)}
 	close(chObjects)
 
 	files := getObjectsFromChanToArray(chObjects)
 	expect := []objectInfoType{
-		{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 	same := reflect.DeepEqual(files, expect)
 	c.Assert(same, Equals, true)
@@ -2644,12 +2622,12 @@ func (s *OssutilCommandSuite) TestGetObjectsFromChanToArray(c *C) {
 
 func (s *OssutilCommandSuite) TestFilterObjectsWithInclude(c *C) {
 	objects := []objectInfoType{
-		{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 
-	expect := []objectInfoType{{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}}
+	expect := []objectInfoType{{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}}
 	res := filterObjectsWithInclude(objects, "*.rtf")
 	same := reflect.DeepEqual(res, expect)
 	c.Assert(same, Equals, true)
@@ -2660,9 +2638,9 @@ func (s *OssutilCommandSuite) TestFilterObjectsWithInclude(c *C) {
 	c.Assert(same, Equals, true)
 
 	expect = []objectInfoType{
-		{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 	res = filterObjectsWithInclude(objects, "*")
 	same = reflect.DeepEqual(res, expect)
@@ -2676,14 +2654,14 @@ func (s *OssutilCommandSuite) TestFilterObjectsWithInclude(c *C) {
 
 func (s *OssutilCommandSuite) TestFilterObjectsWithExclude(c *C) {
 	objects := []objectInfoType{
-		{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 
 	expect := []objectInfoType{
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 	res := filterObjectsWithExclude(objects, "*.rtf")
 	same := reflect.DeepEqual(res, expect)
@@ -2695,9 +2673,9 @@ func (s *OssutilCommandSuite) TestFilterObjectsWithExclude(c *C) {
 	c.Assert(same, Equals, true)
 
 	expect = []objectInfoType{
-		{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 	res = filterObjectsWithExclude(objects, "")
 	same = reflect.DeepEqual(res, expect)
@@ -2706,17 +2684,17 @@ func (s *OssutilCommandSuite) TestFilterObjectsWithExclude(c *C) {
 
 func (s *OssutilCommandSuite) TestMatchFiltersForObjects(c *C) {
 	objects := []objectInfoType{
-		{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 
 	fts := []filterOptionType{}
 	res := matchFiltersForObjects(objects, fts)
 	expect := []objectInfoType{
-		{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 	same := reflect.DeepEqual(res, expect)
 	c.Assert(same, Equals, true)
@@ -2724,8 +2702,8 @@ func (s *OssutilCommandSuite) TestMatchFiltersForObjects(c *C) {
 	fts = []filterOptionType{{"--include", "*.txt"}}
 	res = matchFiltersForObjects(objects, fts)
 	expect = []objectInfoType{
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 	same = reflect.DeepEqual(res, expect)
 	c.Assert(same, Equals, true)
@@ -2733,7 +2711,7 @@ func (s *OssutilCommandSuite) TestMatchFiltersForObjects(c *C) {
 	fts = []filterOptionType{{"--exclude", "*.txt"}}
 	res = matchFiltersForObjects(objects, fts)
 	expect = []objectInfoType{
-		{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
+		{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
 	}
 	same = reflect.DeepEqual(res, expect)
 	c.Assert(same, Equals, true)
@@ -2741,7 +2719,7 @@ func (s *OssutilCommandSuite) TestMatchFiltersForObjects(c *C) {
 	fts = []filterOptionType{{"--include", "*.txt"}, {"--exclude", "*2*"}}
 	res = matchFiltersForObjects(objects, fts)
 	expect = []objectInfoType{
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
 	}
 	same = reflect.DeepEqual(res, expect)
 	c.Assert(same, Equals, true)
@@ -2749,9 +2727,9 @@ func (s *OssutilCommandSuite) TestMatchFiltersForObjects(c *C) {
 
 func (s *OssutilCommandSuite) TestMakeObjectChanFromArray(c *C) {
 	objects := []objectInfoType{
-		{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
-		{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
-		{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
+		{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)},
+		{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)},
 	}
 	chObjects := make(chan objectInfoType, ChannelBuf)
 	makeObjectChanFromArray(objects, chObjects)
@@ -2767,9 +2745,9 @@ func (s *OssutilCommandSuite) TestMakeObjectChanFromArray(c *C) {
 
 func (s *OssutilCommandSuite) TestFilterObjectFromChanWithPatterns(c *C) {
 	chObjects := make(chan objectInfoType, ChannelBuf)
-	chObjects <- objectInfoType{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}
-	chObjects <- objectInfoType{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}
-	chObjects <- objectInfoType{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)}
+	chObjects <- objectInfoType{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}
+	chObjects <- objectInfoType{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}
+	chObjects <- objectInfoType{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)}
 	close(chObjects)
 	fts := []filterOptionType{{"--include", "*.txt"}}
 	dstObjects := make(chan objectInfoType, ChannelBuf)
@@ -2777,9 +2755,9 @@ func (s *OssutilCommandSuite) TestFilterObjectFromChanWithPatterns(c *C) {
 	c.Assert(len(dstObjects), Equals, 2)
 
 	chObjects = make(chan objectInfoType, ChannelBuf)
-	chObjects <- objectInfoType{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}
-	chObjects <- objectInfoType{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}
-	chObjects <- objectInfoType{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)}
+	chObjects <- objectInfoType{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}
+	chObjects <- objectInfoType{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}
+	chObjects <- objectInfoType{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)}
 	close(chObjects)
 	fts = []filterOptionType{{"--exclude", "*.txt"}}
 	dstObjects2 := make(chan objectInfoType, ChannelBuf)
@@ -2787,9 +2765,9 @@ func (s *OssutilCommandSuite) TestFilterObjectFromChanWithPatterns(c *C) {
 	c.Assert(len(dstObjects2), Equals, 1)
 
 	chObjects = make(chan objectInfoType, ChannelBuf)
-	chObjects <- objectInfoType{"dir1/", "my.rtf", 10240, time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}
-	chObjects <- objectInfoType{"dir1/", "testfile103.txt", 1040, time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}
-	chObjects <- objectInfoType{"", "testfile1021.txt", 1024, time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)}
+	chObjects <- objectInfoType{prefix: "dir1/", relativeKey: "my.rtf", size: 10240, lastModified: time.Date(2017, 10, 1, 7, 0, 0, 0, time.Local)}
+	chObjects <- objectInfoType{prefix: "dir1/", relativeKey: "testfile103.txt", size: 1040, lastModified: time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)}
+	chObjects <- objectInfoType{prefix: "", relativeKey: "testfile1021.txt", size: 1024, lastModified: time.Date(2017, 1, 19, 7, 10, 35, 0, time.UTC)}
 	close(chObjects)
 	fts = []filterOptionType{{"--include", "*.txt"}, {"--exclude", "*2*"}}
 	dstObjects3 := make(chan objectInfoType, ChannelBuf)
@@ -0,0 +1,313 @@
+package lib
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	"github.com/klauspost/compress/zstd"
+)
+
+// the meta of the object compressed by ossutil, the original content is validated by them after decompression
+const (
+	CompressUncompressedSize  string = "ossutil-uncompressed-size"
+	CompressUncompressedCRC64        = "ossutil-uncompressed-crc64"
+)
+
+// the algorithms of --compress, which are also the Content-Encoding of the compressed object
+const (
+	CompressGzip string = "gzip"
+	CompressZstd        = "zstd"
+)
+
+const (
+	// CompressedTempFileSuffix is the suffix of the temp file which keeps the compressed content while downloading
+	CompressedTempFileSuffix = ".compressed.tmp"
+)
+
+// IsCompressEncoding returns true if the Content-Encoding can be decompressed by ossutil
+func IsCompressEncoding(encoding string) bool {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+	return encoding == CompressGzip || encoding == CompressZstd
+}
+
+func newCompressWriter(w io.Writer, algorithm string) (io.WriteCloser, error) {
+	switch algorithm {
+	case CompressGzip:
+		return gzip.NewWriter(w), nil
+	case CompressZstd:
+		return zstd.NewWriter(w)
+	}
+	return nil, fmt.Errorf("unsupported compress algorithm: %s", algorithm)
+}
+
+func newDecompressReader(r io.Reader, encoding string) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case CompressGzip:
+		return gzip.NewReader(r)
+	case CompressZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+}
+
+// CompressReader returns the compressed stream of src, the compression runs in another goroutine
+func CompressReader(src io.Reader, algorithm string) (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	writer, err := newCompressWriter(pw, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		_, err := io.Copy(writer, src)
+		if errClose := writer.Close(); err == nil {
+			err = errClose
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// DecompressReader restores the original content of the compressed object from its stream, the size and
+// crc64 of the original content are validated at the end if they are recorded in the meta of the object
+type DecompressReader struct {
+	reader       io.ReadCloser
+	crc          hash.Hash64
+	size         int64
+	originalSize int64
+	originalCRC  string
+}
+
+// NewDecompressReader decompresses the stream of the object by the Content-Encoding in its header
+func NewDecompressReader(src io.Reader, header http.Header) (*DecompressReader, error) {
+	reader, err := newDecompressReader(src, header.Get(oss.HTTPHeaderContentEncoding))
+	if err != nil {
+		return nil, err
+	}
+	dr := &DecompressReader{
+		reader:       reader,
+		crc:          crc64.New(crc64.MakeTable(crc64.ECMA)),
+		originalSize: -1,
+		originalCRC:  header.Get(oss.HTTPHeaderOssMetaPrefix + CompressUncompressedCRC64),
+	}
+	if size, err := strconv.ParseInt(header.Get(oss.HTTPHeaderOssMetaPrefix+CompressUncompressedSize), 10, 64); err == nil {
+		dr.originalSize = size
+	}
+	return dr, nil
+}
+
+func (dr *DecompressReader) Read(p []byte) (int, error) {
+	n, err := dr.reader.Read(p)
+	dr.crc.Write(p[:n])
+	dr.size += int64(n)
+	if err == io.EOF {
+		if errV := dr.validate(); errV != nil {
+			return n, errV
+		}
+	}
+	return n, err
+}
+
+// Close closes the decompressor, the source stream is not closed
+func (dr *DecompressReader) Close() error {
+	return dr.reader.Close()
+}
+
+func (dr *DecompressReader) validate() error {
+	if dr.originalSize >= 0 && dr.size != dr.originalSize {
+		return fmt.Errorf("the size of decompressed content is %d, not the same as %d of original content", dr.size, dr.originalSize)
+	}
+	if crc := strconv.FormatUint(dr.crc.Sum64(), 10); dr.originalCRC != "" && crc != dr.originalCRC {
+		return fmt.Errorf("the crc64 of decompressed content is %s, not the same as %s of original content", crc, dr.originalCRC)
+	}
+	return nil
+}
+
+// decompressedCompareInfo replaces the size and hash of the compressed object with the original ones,
+// so that it can be compared with the local file
+func decompressedCompareInfo(props http.Header, info compareInfoType) compareInfoType {
+	size, err := strconv.ParseInt(props.Get(oss.HTTPHeaderOssMetaPrefix+CompressUncompressedSize), 10, 64)
+	if err != nil || !IsCompressEncoding(props.Get(oss.HTTPHeaderContentEncoding)) {
+		return info
+	}
+	info.size = size
+	info.crc64 = props.Get(oss.HTTPHeaderOssMetaPrefix + CompressUncompressedCRC64)
+	info.md5 = ""
+	return info
+}
+
+// compressProgressReader reports the progress by the original content read, so that it matches the file size
+type compressProgressReader struct {
+	reader   io.Reader
+	listener oss.ProgressListener
+	consumed int64
+	total    int64
+}
+
+func (pr *compressProgressReader) Read(p []byte) (int, error) {
+	n, err := pr.reader.Read(p)
+	if n > 0 && pr.listener != nil {
+		consumed := atomic.AddInt64(&pr.consumed, int64(n))
+		pr.listener.ProgressChanged(&oss.ProgressEvent{ConsumedBytes: consumed, TotalBytes: pr.total,
+			RwBytes: int64(n), EventType: oss.TransferDataEvent})
+	}
+	return n, err
+}
+
+func (pr *compressProgressReader) failed() {
+	if pr.listener != nil {
+		pr.listener.ProgressChanged(&oss.ProgressEvent{ConsumedBytes: atomic.LoadInt64(&pr.consumed), TotalBytes: pr.total,
+			EventType: oss.TransferFailedEvent})
+	}
+}
+
+// discardProgressListener replaces the progress listener of the requests which send the compressed content
+type discardProgressListener struct{}
+
+func (l *discardProgressListener) ProgressChanged(event *oss.ProgressEvent) {}
+
+// compressAlgorithm returns the algorithm to compress the file, empty if the file should not be compressed
+func (cc *CopyCommand) compressAlgorithm(filePath string) string {
+	if cc.cpOption.compress == "" || len(cc.cpOption.compressInclude) == 0 {
+		return cc.cpOption.compress
+	}
+	name := filepath.Base(filePath)
+	for _, pattern := range cc.cpOption.compressInclude {
+		if match, _ := filepath.Match(pattern, name); match {
+			return cc.cpOption.compress
+		}
+	}
+	return ""
+}
+
+// compressedPutObjectFromFile uploads the compressed content of the file, the content is streamed without temp file.
+// It's uploaded by PutObject if the compressed content is less than a part, otherwise by multipart upload.
+func (cc *CopyCommand) compressedPutObjectFromFile(bucket *oss.Bucket, objectName, filePath, algorithm string, options ...oss.Option) error {
+	crc, err := fileCRC64(filePath)
+	if err != nil {
+		return err
+	}
+
+	fd, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	f, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+
+	progress := &compressProgressReader{reader: fd, listener: oss.GetProgressListener(options), total: f.Size()}
+	reader, err := CompressReader(progress, algorithm)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	options = oss.AddContentType(options, filePath, objectName)
+	options = append(options, oss.ContentEncoding(algorithm),
+		oss.Meta(CompressUncompressedSize, strconv.FormatInt(f.Size(), 10)),
+		oss.Meta(CompressUncompressedCRC64, crc),
+		oss.Progress(&discardProgressListener{}))
+
+	if err = cc.putCompressedContent(bucket, objectName, reader, f.Size(), options...); err != nil {
+		progress.failed()
+	}
+	return err
+}
+
+// putCompressedContent uploads the compressed content, each request is retried by itself so that the content
+// is not compressed again for the retry.
+func (cc *CopyCommand) putCompressedContent(bucket *oss.Bucket, objectName string, reader io.Reader, size int64, options ...oss.Option) error {
+	// the content which can't be compressed gets a little larger, the part size leaves some headroom for it
+	partSize, routines := cc.preparePartOption(size + size/100)
+	if partSize < oss.MinPartSize {
+		partSize = oss.MinPartSize
+	}
+	buf := make([]byte, partSize)
+	n, err := io.ReadFull(reader, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = cc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+			startT := time.Now()
+			err := cc.command.bandwidthBucket(bucket).PutObject(objectName, bytes.NewReader(buf[:n]), withResponseHeader(options, respHeader)...)
+			cc.cpOption.tuner.observe(err, time.Since(startT))
+			if err != nil {
+				LogError("try count:%d,put compressed object %s error,%s\n", attempt, objectName, err.Error())
+			}
+			return err
+		})
+		if err != nil {
+			return ObjectError{err, bucket.BucketName, objectName}
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, _, err = cc.uploadParts(bucket, objectName, reader, buf, int64(routines), options)
+	return err
+}
+
+// statCompressedObject returns the header of the object if it's compressed, nil if it's not
+func (cc *CopyCommand) statCompressedObject(bucket *oss.Bucket, object string) (http.Header, error) {
+	statOptions := cc.cpOption.payerOptions
+	if cc.cpOption.versionId != "" {
+		statOptions = append(statOptions, oss.VersionId(cc.cpOption.versionId))
+	}
+	props, err := cc.command.ossGetObjectStatRetry(bucket, object, statOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if !IsCompressEncoding(props.Get(oss.HTTPHeaderContentEncoding)) {
+		return nil, nil
+	}
+	return props, nil
+}
+
+// decompressDownloadedFile decompresses the content downloaded to the temp file into the file, and removes the temp file
+func (cc *CopyCommand) decompressDownloadedFile(props http.Header, tempFileName, fileName string) error {
+	src, err := os.Open(tempFileName)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFileName)
+	defer src.Close()
+
+	reader, err := NewDecompressReader(src, props)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	dest, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, oss.FilePermMode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dest, reader)
+	if errClose := dest.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(fileName)
+		return fmt.Errorf("decompress %s error, %s", fileName, err.Error())
+	}
+	return nil
+}
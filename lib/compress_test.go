@@ -0,0 +1,224 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	. "gopkg.in/check.v1"
+)
+
+// compressedHeader makes the response header of the object compressed by ossutil
+func compressedHeader(encoding string, data []byte) http.Header {
+	crc, _ := calcCRC64(bytes.NewReader(data))
+	header := http.Header{}
+	header.Set(oss.HTTPHeaderContentEncoding, encoding)
+	header.Set(oss.HTTPHeaderOssMetaPrefix+CompressUncompressedSize, strconv.Itoa(len(data)))
+	header.Set(oss.HTTPHeaderOssMetaPrefix+CompressUncompressedCRC64, strconv.FormatUint(crc, 10))
+	return header
+}
+
+func (s *OssutilCommandSuite) TestCompressReader(c *C) {
+	data := []byte(strings.Repeat("ossutil compress test line\n", 10000))
+	for _, algorithm := range []string{CompressGzip, CompressZstd} {
+		reader, err := CompressReader(bytes.NewReader(data), algorithm)
+		c.Assert(err, IsNil)
+		compressed, err := ioutil.ReadAll(reader)
+		c.Assert(err, IsNil)
+		c.Assert(len(compressed) < len(data), Equals, true)
+
+		header := compressedHeader(algorithm, data)
+		dr, err := NewDecompressReader(bytes.NewReader(compressed), header)
+		c.Assert(err, IsNil)
+		decompressed, err := ioutil.ReadAll(dr)
+		c.Assert(err, IsNil)
+		c.Assert(dr.Close(), IsNil)
+		c.Assert(decompressed, DeepEquals, data)
+
+		// the original crc64 is different
+		header.Set(oss.HTTPHeaderOssMetaPrefix+CompressUncompressedCRC64, "123")
+		dr, err = NewDecompressReader(bytes.NewReader(compressed), header)
+		c.Assert(err, IsNil)
+		_, err = ioutil.ReadAll(dr)
+		c.Assert(err, NotNil)
+
+		// the original size is different
+		header = compressedHeader(algorithm, data[1:])
+		dr, err = NewDecompressReader(bytes.NewReader(compressed), header)
+		c.Assert(err, IsNil)
+		_, err = ioutil.ReadAll(dr)
+		c.Assert(err, NotNil)
+
+		// not compressed by ossutil, nothing to validate
+		header = http.Header{}
+		header.Set(oss.HTTPHeaderContentEncoding, strings.ToUpper(algorithm))
+		dr, err = NewDecompressReader(bytes.NewReader(compressed), header)
+		c.Assert(err, IsNil)
+		decompressed, err = ioutil.ReadAll(dr)
+		c.Assert(err, IsNil)
+		c.Assert(decompressed, DeepEquals, data)
+	}
+
+	_, err := CompressReader(bytes.NewReader(data), "br")
+	c.Assert(err, NotNil)
+	_, err = NewDecompressReader(bytes.NewReader(data), http.Header{})
+	c.Assert(err, NotNil)
+	c.Assert(IsCompressEncoding(" GZIP"), Equals, true)
+	c.Assert(IsCompressEncoding("br"), Equals, false)
+}
+
+func (s *OssutilCommandSuite) TestCompressAlgorithm(c *C) {
+	cc := CopyCommand{}
+	c.Assert(cc.compressAlgorithm("a.log"), Equals, "")
+
+	cc.cpOption.compress = CompressZstd
+	c.Assert(cc.compressAlgorithm("a.bin"), Equals, CompressZstd)
+
+	cc.cpOption.compressInclude = []string{"*.log", "*.txt"}
+	c.Assert(cc.compressAlgorithm(filepath.Join("dir", "a.log")), Equals, CompressZstd)
+	c.Assert(cc.compressAlgorithm("a.txt"), Equals, CompressZstd)
+	c.Assert(cc.compressAlgorithm("a.log.gz"), Equals, "")
+	c.Assert(cc.compressAlgorithm("a.bin"), Equals, "")
+}
+
+func (s *OssutilCommandSuite) TestDecompressCompareInfo(c *C) {
+	data := []byte("ossutil compress compare")
+	header := compressedHeader(CompressGzip, data)
+	info := decompressedCompareInfo(header, compareInfoType{size: 10, crc64: "1", md5: "ABC", modifiedTime: 100})
+	crc, _ := calcCRC64(bytes.NewReader(data))
+	c.Assert(info.size, Equals, int64(len(data)))
+	c.Assert(info.crc64, Equals, strconv.FormatUint(crc, 10))
+	c.Assert(info.md5, Equals, "")
+	c.Assert(info.modifiedTime, Equals, int64(100))
+
+	// not compressed
+	header.Del(oss.HTTPHeaderContentEncoding)
+	info = decompressedCompareInfo(header, compareInfoType{size: 10, crc64: "1"})
+	c.Assert(info.size, Equals, int64(10))
+	c.Assert(info.crc64, Equals, "1")
+}
+
+func (s *OssutilCommandSuite) TestDecompressDownloadedFile(c *C) {
+	dir := "ossutil-compress-" + randLowStr(6)
+	c.Assert(os.MkdirAll(dir, 0755), IsNil)
+	defer os.RemoveAll(dir)
+
+	data := []byte(strings.Repeat("ossutil decompress test line\n", 1000))
+	reader, err := CompressReader(bytes.NewReader(data), CompressGzip)
+	c.Assert(err, IsNil)
+	compressed, err := ioutil.ReadAll(reader)
+	c.Assert(err, IsNil)
+
+	cc := CopyCommand{}
+	fileName := filepath.Join(dir, "file")
+	tempFileName := fileName + CompressedTempFileSuffix
+	c.Assert(ioutil.WriteFile(tempFileName, compressed, 0600), IsNil)
+	c.Assert(cc.decompressDownloadedFile(compressedHeader(CompressGzip, data), tempFileName, fileName), IsNil)
+	result, err := ioutil.ReadFile(fileName)
+	c.Assert(err, IsNil)
+	c.Assert(result, DeepEquals, data)
+	_, err = os.Stat(tempFileName)
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	// the file is removed if the validation fails
+	c.Assert(ioutil.WriteFile(tempFileName, compressed, 0600), IsNil)
+	c.Assert(cc.decompressDownloadedFile(compressedHeader(CompressGzip, data[1:]), tempFileName, fileName), NotNil)
+	_, err = os.Stat(fileName)
+	c.Assert(os.IsNotExist(err), Equals, true)
+	_, err = os.Stat(tempFileName)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
+// multipartServer is a fake oss for PutObject and multipart upload, the first upload of failPart fails
+type multipartServer struct {
+	lock      sync.Mutex
+	object    []byte
+	parts     map[int][]byte
+	partCount int
+	failPart  int
+}
+
+func (ms *multipartServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	body, _ := ioutil.ReadAll(r.Body)
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && r.URL.RawQuery == "uploads":
+		ms.parts = map[int][]byte{}
+		fmt.Fprint(w, "<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>id</UploadId></InitiateMultipartUploadResult>")
+	case r.Method == http.MethodPut && query.Get("uploadId") != "":
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		ms.partCount++
+		if number == ms.failPart {
+			ms.failPart = 0
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		ms.parts[number] = body
+		w.Header().Set(oss.HTTPHeaderEtag, fmt.Sprintf("\"etag%d\"", number))
+	case r.Method == http.MethodPost && query.Get("uploadId") != "":
+		numbers := []int{}
+		for number := range ms.parts {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+		ms.object = nil
+		for _, number := range numbers {
+			ms.object = append(ms.object, ms.parts[number]...)
+		}
+		fmt.Fprint(w, "<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>\"etag\"</ETag></CompleteMultipartUploadResult>")
+	case r.Method == http.MethodPut:
+		ms.object = body
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *OssutilCommandSuite) TestPutCompressedContent(c *C) {
+	ms := &multipartServer{}
+	svr := httptest.NewServer(ms)
+	defer svr.Close()
+	client, err := oss.New(svr.URL, "ak", "sk")
+	c.Assert(err, IsNil)
+	bucket, err := client.Bucket("bucket")
+	c.Assert(err, IsNil)
+
+	partSize := strconv.Itoa(oss.MinPartSize)
+	parallel := "3"
+	retryTimes := int64(3)
+	baseDelay := int64(1)
+	cc := CopyCommand{}
+	cc.command.options = OptionMapType{OptionPartSize: &partSize, OptionParallel: &parallel,
+		OptionRetryTimes: &retryTimes, OptionRetryBaseDelay: &baseDelay}
+
+	// the content less than a part is put by PutObject
+	data := make([]byte, 5*oss.MinPartSize+1000)
+	rand.Read(data)
+	c.Assert(cc.putCompressedContent(bucket, "object", bytes.NewReader(data[:1000]), 1000), IsNil)
+	c.Assert(bytes.Equal(ms.object, data[:1000]), Equals, true)
+
+	// the failed part is retried by itself
+	ms.failPart = 3
+	c.Assert(cc.putCompressedContent(bucket, "object", bytes.NewReader(data), int64(len(data))), IsNil)
+	c.Assert(bytes.Equal(ms.object, data), Equals, true)
+	c.Assert(ms.partCount, Equals, 7)
+
+	// the part which fails more than the retry times aborts the upload
+	retryTimes = 1
+	ms.failPart = 2
+	err = cc.putCompressedContent(bucket, "object", bytes.NewReader(data), int64(len(data)))
+	c.Assert(err, NotNil)
+	_, ok := err.(ObjectError)
+	c.Assert(ok, Equals, true)
+}
@@ -17,7 +17,7 @@ var specChineseConfig = SpecText{
 	paramText: "[options]",
 
 	syntaxText: ` 
-    ossutil config [-e endpoint] [-i id] [-k key] [-t token] [-L language] [--output-dir outdir] [-c file] 
+    ossutil config [-e endpoint] [-i id] [-k key] [-t token] [-L language] [--output-dir outdir] [--profile name] [--list-profiles] [-c file] 
 `,
 
 	detailHelpText: ` 
@@ -112,8 +112,77 @@ var specChineseConfig = SpecText{
         优先级：--endpoint > Bucket-Cname > Bucket-Endpoint > endpoint > 默认endpoint
 
     2) ossutil config options
-        如果用户使用命令时输入了除--language和--config-file之外的任何选项，则
-    该命令进入非交互式模式。所有的配置项应当使用选项指定。
+        如果用户使用命令时输入了除--language、--config-file和--profile之外的任何
+    选项，则该命令进入非交互式模式。所有的配置项应当使用选项指定。
+
+Profile:
+
+    一个配置文件中可以保存多套配置，[Credentials]节为默认profile（名为` + DefaultProfile + `），
+    [profile name]节为名为name的profile。使用其他命令时通过--profile选项或者环境变量
+    ` + EnvOssutilProfile + `选择profile，--profile选项优先。profile中未配置的项从[Credentials]节
+    继承，[Bucket-Endpoint]、[Bucket-Cname]和[Default]节为所有profile共享。
+
+    指定--profile选项时，config命令只重写配置文件中该profile对应的节，其他节保持
+    不变；未指定时，config命令重新创建配置文件，但保留已有的[profile name]节。
+
+    --list-profiles选项列出配置文件中的所有profile。
+
+环境变量:
+
+    使用其他命令时，ossutil同样从下述环境变量读取配置，此时不强求配置文件一定要
+    存在。同一配置项的优先级为：选项 > 环境变量 > profile > [Credentials]节。
+        OSS_ENDPOINT                         endpoint
+        OSS_ACCESS_KEY_ID                    accessKeyID
+        OSS_ACCESS_KEY_SECRET                accessKeySecret
+        OSS_SESSION_TOKEN（或OSS_STS_TOKEN） stsToken
+        OSS_REGION                           region
+        OSS_SIGN_VERSION                     signVersion
+        OSS_MODE                             mode
+        OSS_RAM_ROLE_ARN（或ALIBABA_CLOUD_ROLE_ARN）
+                                             ramRoleArn
+        OSS_ROLE_SESSION_NAME（或ALIBABA_CLOUD_ROLE_SESSION_NAME）
+                                             roleSessionName
+        OSS_TOKEN_TIMEOUT                    tokenTimeout
+        OSS_STS_REGION                       stsRegion
+        OSS_ECS_ROLE_NAME                    ecsRoleName
+        OSS_READ_TIMEOUT                     readTimeOut
+        OSS_CONNECT_TIMEOUT                  connectTimeOut
+        OSS_CREDENTIAL_PROCESS               credentialProcess
+        ALIBABA_CLOUD_OIDC_PROVIDER_ARN      oidcProviderArn
+        ALIBABA_CLOUD_OIDC_TOKEN_FILE        oidcTokenFile
+        OSS_CREDENTIALS_CACHE                credentialsCache
+
+凭证获取方式:
+
+    mode指定获取访问凭证的方式：
+        AK                使用accessKeyID、accessKeySecret和stsToken
+        StsToken          同AK，必须配置stsToken
+        RamRoleArn        使用AccessKey扮演ramRoleArn指定的角色获取临时凭证
+        EcsRamRole        从ECS实例元数据服务获取ecsRoleName对应角色的临时凭证
+        CredentialProcess 运行credentialProcess指定的外部命令获取凭证，命令向标准
+                          输出打印json格式的凭证，如：
+                          {"AccessKeyId":"id","AccessKeySecret":"secret",
+                           "SecurityToken":"token","Expiration":"2006-01-02T15:04:05Z"}
+                          Expiration为空表示凭证不会过期
+        OIDCRoleArn       读取oidcTokenFile中的OIDC token，扮演ramRoleArn指定的角色
+                          获取临时凭证（即ACK集群中的RRSA），token文件在每次刷新时重读
+
+    mode为空时，如果未配置accessKeyID，ossutil依次尝试credentialProcess、OIDC
+    （ramRoleArn、oidcProviderArn和oidcTokenFile均已配置时）和ecsAk，使用第一个
+    成功获取凭证的方式。
+
+    临时凭证在过期前会自动重新获取。配置了credentialsCache时，临时凭证缓存在其指定的
+    文件中（文件权限为0600），连续运行的多个ossutil进程可以共享未过期的凭证，默认不缓存。
+    credentialsCache配置为` + CredentialsCacheOff + `时关闭缓存。
+
+客户端加密:
+
+    cp、sync、mv和cat命令的客户端加密主密钥可以配置在[Credentials]节或者[profile name]节中，
+    选项优先（详见help cp）：
+        encryptionRsaPublicKey    RSA公钥文件，同--encryption-rsa-public-key
+        encryptionRsaPrivateKey   RSA私钥文件，同--encryption-rsa-private-key
+        encryptionAesKey          AES主密钥文件，同--encryption-aes-key
+        encryptionMatDesc         主密钥描述信息，同--encryption-matdesc
 
 
 配置文件格式：
@@ -125,6 +194,10 @@ var specChineseConfig = SpecText{
         accessKeySecret = your_key_secret
         stsToken = your_sts_token
         outputDir = your_output_dir
+    [profile dev]
+        endpoint = endpoint_of_dev
+        accessKeyID = key_id_of_dev
+        accessKeySecret = key_secret_of_dev
     [Bucket-Endpoint]
         bucket1 = endpoint1
         bucket2 = endpoint2
@@ -141,11 +214,16 @@ var specChineseConfig = SpecText{
         readTimeOut = read_time_out
         connectTimeOut = connect_time_out
         retryTimes = retry_times
+        retryBaseDelay = retry_base_delay
+        retryMaxDelay = retry_max_delay
+        retryMaxElapsed = retry_max_elapsed
 `,
 
 	sampleText: ` 
     ossutil config
     ossutil config -e oss-cn-hangzhou.aliyuncs.com -c ~/.myconfig
+    ossutil config --profile dev -e oss-cn-shanghai.aliyuncs.com -i id -k key
+    ossutil config --list-profiles
 `,
 }
 
@@ -156,7 +234,7 @@ var specEnglishConfig = SpecText{
 	paramText: "[options]",
 
 	syntaxText: ` 
-    ossutil config [-e endpoint] [-i id] [-k key] [-t token] [-L language] [--output-dir outdir] [-c file] 
+    ossutil config [-e endpoint] [-i id] [-k key] [-t token] [-L language] [--output-dir outdir] [--profile name] [--list-profiles] [-c file] 
 `,
 
 	detailHelpText: ` 
@@ -262,10 +340,87 @@ Usage:
         PRI: --endpoint option > Bucket-Cname > Bucket-Endpoint > endpoint > default endpoint
 
     2) ossutil config options
-        If any options except --language and --config-file is specified, the 
-    command enter the non interactive mode. All the configurations should be 
+        If any options except --language, --config-file and --profile is specified, 
+    the command enter the non interactive mode. All the configurations should be 
     specified by options.
 
+Profile:
+
+    One config file can hold several configurations, [Credentials] section is the 
+    default profile(named ` + DefaultProfile + `), [profile name] section is the profile named 
+    name. Other commands select the profile by --profile option or environment 
+    variable ` + EnvOssutilProfile + `, --profile option has priority. The items not configured 
+    in the profile are inherited from [Credentials] section, [Bucket-Endpoint], 
+    [Bucket-Cname] and [Default] sections are shared by all profiles.
+
+    If --profile option is specified, config command only rewrites the section of 
+    the profile in config file, other sections are kept. Otherwise config command 
+    recreates the config file, but keeps the existing [profile name] sections.
+
+    --list-profiles option lists all the profiles in config file.
+
+Environment Variables:
+
+    Other commands also read the configurations from the following environment 
+    variables, in which case the config file is not insisted on. The priority of 
+    the same configuration is: option > environment variable > profile > [Credentials] 
+    section.
+        OSS_ENDPOINT                         endpoint
+        OSS_ACCESS_KEY_ID                    accessKeyID
+        OSS_ACCESS_KEY_SECRET                accessKeySecret
+        OSS_SESSION_TOKEN(or OSS_STS_TOKEN)  stsToken
+        OSS_REGION                           region
+        OSS_SIGN_VERSION                     signVersion
+        OSS_MODE                             mode
+        OSS_RAM_ROLE_ARN(or ALIBABA_CLOUD_ROLE_ARN)
+                                             ramRoleArn
+        OSS_ROLE_SESSION_NAME(or ALIBABA_CLOUD_ROLE_SESSION_NAME)
+                                             roleSessionName
+        OSS_TOKEN_TIMEOUT                    tokenTimeout
+        OSS_STS_REGION                       stsRegion
+        OSS_ECS_ROLE_NAME                    ecsRoleName
+        OSS_READ_TIMEOUT                     readTimeOut
+        OSS_CONNECT_TIMEOUT                  connectTimeOut
+        OSS_CREDENTIAL_PROCESS               credentialProcess
+        ALIBABA_CLOUD_OIDC_PROVIDER_ARN      oidcProviderArn
+        ALIBABA_CLOUD_OIDC_TOKEN_FILE        oidcTokenFile
+        OSS_CREDENTIALS_CACHE                credentialsCache
+
+Credentials Providers:
+
+    mode specifies how to get the access credentials:
+        AK                use accessKeyID, accessKeySecret and stsToken
+        StsToken          same as AK, stsToken is required
+        RamRoleArn        assume the role of ramRoleArn with the AccessKey
+        EcsRamRole        get the credentials of ecsRoleName from ECS metadata 
+                          service
+        CredentialProcess run the external command of credentialProcess, the 
+                          command prints the credentials in json to stdout, eg:
+                          {"AccessKeyId":"id","AccessKeySecret":"secret",
+                           "SecurityToken":"token","Expiration":"2006-01-02T15:04:05Z"}
+                          empty Expiration means the credentials never expire
+        OIDCRoleArn       assume the role of ramRoleArn with the OIDC token in 
+                          oidcTokenFile(RRSA in ACK cluster), the token file is 
+                          read again on every refresh
+
+    If mode is empty and accessKeyID is not configured, ossutil tries 
+    credentialProcess, OIDC(if ramRoleArn, oidcProviderArn and oidcTokenFile are 
+    all configured) and ecsAk in order, the first one which succeeds is used.
+
+    Temporary credentials are refreshed before they expire. If credentialsCache 
+    is configured, they are cached in the file(file mode is 0600), so that the 
+    ossutil processes run one after another share the credentials not expired, 
+    there is no cache by default. Set credentialsCache to ` + CredentialsCacheOff + ` to turn off the cache.
+
+Client Side Encryption:
+
+    The master keys of client side encryption for cp, sync, mv and cat can be configured in 
+    [Credentials] or [profile name] section, the options have priority(see help cp):
+        encryptionRsaPublicKey    RSA public key file, same as --encryption-rsa-public-key
+        encryptionRsaPrivateKey   RSA private key file, same as --encryption-rsa-private-key
+        encryptionAesKey          AES master key file, same as --encryption-aes-key
+        encryptionMatDesc         description of the master key, same as --encryption-matdesc
+
 
 Credential File Format:
 
@@ -277,6 +432,10 @@ Credential File Format:
         stsToken = your_sts_token
         outputDir = your_output_dir
         userAgent = your-user-agent
+    [profile dev]
+        endpoint = endpoint_of_dev
+        accessKeyID = key_id_of_dev
+        accessKeySecret = key_secret_of_dev
     [Bucket-Endpoint]
         bucket1 = endpoint1
         bucket2 = endpoint2
@@ -293,11 +452,16 @@ Credential File Format:
         readTimeOut = read_time_out
         connectTimeOut = connect_time_out
         retryTimes = retry_times
+        retryBaseDelay = retry_base_delay
+        retryMaxDelay = retry_max_delay
+        retryMaxElapsed = retry_max_elapsed
 `,
 
 	sampleText: ` 
     ossutil config
     ossutil config -e oss-cn-hangzhou.aliyuncs.com -c ~/.myconfig
+    ossutil config --profile dev -e oss-cn-shanghai.aliyuncs.com -i id -k key
+    ossutil config --list-profiles
 `,
 }
 
@@ -317,12 +481,14 @@ var configCommand = ConfigCommand{
 		group:       GroupTypeAdditionalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
 			OptionSTSToken,
 			OptionOutputDir,
 			OptionLanguage,
+			OptionListProfiles,
 		},
 	},
 }
@@ -330,8 +496,9 @@ var configCommand = ConfigCommand{
 // function for RewriteLoadConfiger interface
 func (cc *ConfigCommand) rewriteLoadConfig(configFile string) error {
 	// read config file, if error exist, do not print error
+	// language is read from the default profile, the profile to config may not exist yet
 	var err error
-	if cc.command.configOptions, err = LoadConfig(configFile); err != nil {
+	if cc.command.configOptions, err = LoadProfileConfig(configFile, DefaultProfile); err != nil {
 		cc.command.configOptions = OptionMapType{}
 	}
 	return nil
@@ -374,19 +541,83 @@ func (cc *ConfigCommand) RunCommand() error {
 	delete(cc.command.options, OptionConfigFile)
 	language, _ := GetString(OptionLanguage, cc.command.options)
 	delete(cc.command.options, OptionLanguage)
+	profile, _ := GetString(OptionProfile, cc.command.options)
+	delete(cc.command.options, OptionProfile)
+	listProfiles, _ := GetBool(OptionListProfiles, cc.command.options)
+	delete(cc.command.options, OptionListProfiles)
+
+	if listProfiles {
+		return cc.listProfiles(configFile)
+	}
 
 	// filter user input options
 	cc.filterNonInputOptions()
 
 	var err error
 	if len(cc.command.options) == 0 {
-		err = cc.runCommandInteractive(configFile, language)
+		err = cc.runCommandInteractive(configFile, language, profile)
 	} else {
-		err = cc.runCommandNonInteractive(configFile, language)
+		err = cc.runCommandNonInteractive(configFile, language, profile)
 	}
 	return err
 }
 
+func (cc *ConfigCommand) listProfiles(configFile string) error {
+	profiles, err := readProfilesFromFile(configFile)
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		fmt.Println(profile)
+	}
+	return nil
+}
+
+// newConfigSection returns the config to save and the section to write the options in.
+// For the default profile, the config file is recreated with the named profiles kept,
+// for a named profile, only its section in the config file is rewritten.
+func (cc *ConfigCommand) newConfigSection(configFile, profile string) (*configparser.Configuration, *configparser.Section) {
+	oldConfig, err := configparser.Read(configFile)
+	if err != nil {
+		oldConfig = nil
+	}
+
+	if profile = normalizeProfile(profile); profile == "" {
+		config := configparser.NewConfiguration()
+		section := config.NewSection(CREDSection)
+		if oldConfig != nil {
+			oldSections, _ := oldConfig.AllSections()
+			for _, oldSection := range oldSections {
+				if strings.HasPrefix(oldSection.Name(), ProfileSectionPrefix) {
+					copySection(config.NewSection(oldSection.Name()), oldSection)
+				}
+			}
+		}
+		return config, section
+	}
+
+	config := oldConfig
+	if config == nil {
+		config = configparser.NewConfiguration()
+	}
+	section, err := config.Section(profileSectionName(profile))
+	if err != nil {
+		return config, config.NewSection(profileSectionName(profile))
+	}
+	for _, name := range append([]string{}, section.OptionNames()...) {
+		section.Delete(name)
+	}
+	return config, section
+}
+
+func copySection(dest, src *configparser.Section) {
+	for _, name := range src.OptionNames() {
+		if strings.TrimSpace(name) != "" {
+			dest.Add(name, src.ValueOf(name))
+		}
+	}
+}
+
 func (cc *ConfigCommand) filterNonInputOptions() {
 	for name := range cc.command.options {
 		if val, err := GetString(name, cc.command.options); err != nil || val == "" {
@@ -395,7 +626,7 @@ func (cc *ConfigCommand) filterNonInputOptions() {
 	}
 }
 
-func (cc *ConfigCommand) runCommandInteractive(configFile, language string) error {
+func (cc *ConfigCommand) runCommandInteractive(configFile, language, profile string) error {
 	llanguage := strings.ToLower(language)
 	if llanguage == LEnglishLanguage {
 		fmt.Println("The command creates a configuration file and stores credentials.")
@@ -426,21 +657,23 @@ func (cc *ConfigCommand) runCommandInteractive(configFile, language string) erro
 		fmt.Println("对于下述配置，回车将跳过相关配置项的设置，配置项的具体含义，请使用\"help config\"命令查看。")
 	}
 
-	if err := cc.configInteractive(configFile, language); err != nil {
+	if err := cc.configInteractive(configFile, language, profile); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (cc *ConfigCommand) configInteractive(configFile, language string) error {
+func (cc *ConfigCommand) configInteractive(configFile, language, profile string) error {
 	var val string
-	config := configparser.NewConfiguration()
-	section := config.NewSection(CREDSection)
+	config, section := cc.newConfigSection(configFile, profile)
+	isDefault := normalizeProfile(profile) == ""
 
-	// if config file not exist, config Language
+	// if config file not exist, config Language, the named profile inherits language from the default one
 	llanguage := strings.ToLower(language)
-	section.Add(OptionLanguage, language)
-	if _, err := os.Stat(configFile); err != nil {
+	if isDefault {
+		section.Add(OptionLanguage, language)
+	}
+	if _, err := os.Stat(configFile); err != nil && isDefault {
 		if llanguage == LEnglishLanguage {
 			fmt.Printf("Please enter language(%s, default is:%s, the configuration will go into effect after the command successfully executed):", OptionMap[OptionLanguage].minVal, DefaultLanguage)
 		} else {
@@ -495,11 +728,12 @@ func (cc *ConfigCommand) configInteractive(configFile, language string) error {
 	return nil
 }
 
-func (cc *ConfigCommand) runCommandNonInteractive(configFile, language string) error {
+func (cc *ConfigCommand) runCommandNonInteractive(configFile, language, profile string) error {
 	configFile = DecideConfigFile(configFile)
-	config := configparser.NewConfiguration()
-	section := config.NewSection(CREDSection)
-	section.Add(OptionLanguage, language)
+	config, section := cc.newConfigSection(configFile, profile)
+	if normalizeProfile(profile) == "" {
+		section.Add(OptionLanguage, language)
+	}
 	for name := range CredOptionMap {
 		if val, _ := GetString(name, cc.command.options); val != "" {
 			section.Add(name, val)
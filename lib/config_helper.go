@@ -20,6 +20,9 @@ const (
 	AkServiceSection string = "AkService"
 
 	DefaultSection string = "Default"
+
+	// ProfileSectionPrefix is the prefix of named profile sections, eg: [profile dev]
+	ProfileSectionPrefix string = "profile "
 )
 
 // config items in section AKSerivce
@@ -55,31 +58,65 @@ var CredOptionList = []string{
 // CredOptionMap allows alias name for options in Credentials section
 // name, allow to show in screen
 var CredOptionMap = map[string]configOption{
-	OptionLanguage:        configOption{[]string{"language", "Language"}, false, true, "", ""},
-	OptionEndpoint:        configOption{[]string{"endpoint", "host"}, true, true, "", ""},
-	OptionAccessKeyID:     configOption{[]string{"accessKeyID", "accessKeyId", "AccessKeyID", "AccessKeyId", "access_key_id", "access_id", "accessid", "access-key-id", "access-id"}, true, false, "", ""},
-	OptionAccessKeySecret: configOption{[]string{"accessKeySecret", "AccessKeySecret", "access_key_secret", "access_key", "accesskey", "access-key-secret", "access-key"}, true, false, "", ""},
-	OptionSTSToken:        configOption{[]string{"stsToken", "ststoken", "STSToken", "sts_token", "sts-token"}, true, false, "", ""},
-	OptionOutputDir:       configOption{[]string{"outputDir", "output-dir", "output_dir", "output_directory"}, false, true, "ossutil生成的文件的输出目录, ", "the directory to store files generated by ossutil, "},
-	OptionMode:            configOption{[]string{"mode", "Mode"}, false, false, "", ""},
-	OptionRamRoleArn:      configOption{[]string{"ramRoleArn", "RamRoleArn", "ramrolearn", "ram_role_arn", "ram-role-arn"}, false, false, "", ""},
-	OptionRoleSessionName: configOption{[]string{"roleSessionName", "RoleSessionName", "rolesessionname", "role-session-name", "role_session_name"}, false, false, "", ""},
-	OptionTokenTimeout:    configOption{[]string{"tokenTimeOut", "tokenTimeout", "tokentimeout", "token_timeout", "token-timeout"}, false, false, "", ""},
-	OptionSTSRegion:       configOption{[]string{"stsRegion", "stsregion", "sts-region", "sts_region"}, false, false, "", ""},
-	OptionECSRoleName:     configOption{[]string{"ecsRoleName", "EcsRoleName", "ecsrolename", "ecs-role-name", "ecs_role_name"}, false, false, "", ""},
+	OptionLanguage:          configOption{[]string{"language", "Language"}, false, true, "", ""},
+	OptionEndpoint:          configOption{[]string{"endpoint", "host"}, true, true, "", ""},
+	OptionAccessKeyID:       configOption{[]string{"accessKeyID", "accessKeyId", "AccessKeyID", "AccessKeyId", "access_key_id", "access_id", "accessid", "access-key-id", "access-id"}, true, false, "", ""},
+	OptionAccessKeySecret:   configOption{[]string{"accessKeySecret", "AccessKeySecret", "access_key_secret", "access_key", "accesskey", "access-key-secret", "access-key"}, true, false, "", ""},
+	OptionSTSToken:          configOption{[]string{"stsToken", "ststoken", "STSToken", "sts_token", "sts-token"}, true, false, "", ""},
+	OptionOutputDir:         configOption{[]string{"outputDir", "output-dir", "output_dir", "output_directory"}, false, true, "ossutil生成的文件的输出目录, ", "the directory to store files generated by ossutil, "},
+	OptionMode:              configOption{[]string{"mode", "Mode"}, false, false, "", ""},
+	OptionRamRoleArn:        configOption{[]string{"ramRoleArn", "RamRoleArn", "ramrolearn", "ram_role_arn", "ram-role-arn"}, false, false, "", ""},
+	OptionRoleSessionName:   configOption{[]string{"roleSessionName", "RoleSessionName", "rolesessionname", "role-session-name", "role_session_name"}, false, false, "", ""},
+	OptionTokenTimeout:      configOption{[]string{"tokenTimeOut", "tokenTimeout", "tokentimeout", "token_timeout", "token-timeout"}, false, false, "", ""},
+	OptionSTSRegion:         configOption{[]string{"stsRegion", "stsregion", "sts-region", "sts_region"}, false, false, "", ""},
+	OptionECSRoleName:       configOption{[]string{"ecsRoleName", "EcsRoleName", "ecsrolename", "ecs-role-name", "ecs_role_name"}, false, false, "", ""},
+	OptionCredentialProcess: configOption{[]string{"credentialProcess", "credential_process", "credential-process", "credentialprocess"}, false, false, "", ""},
+	OptionOIDCProviderArn:   configOption{[]string{"oidcProviderArn", "oidc_provider_arn", "oidc-provider-arn", "oidcproviderarn"}, false, false, "", ""},
+	OptionOIDCTokenFile:     configOption{[]string{"oidcTokenFile", "oidc_token_file", "oidc-token-file", "oidctokenfile"}, false, false, "", ""},
+	OptionCredentialsCache:  configOption{[]string{"credentialsCache", "credentials_cache", "credentials-cache", "credentialscache"}, false, false, "", ""},
+	OptionEncryptionPubKey:  configOption{[]string{"encryptionRsaPublicKey", "encryption_rsa_public_key", "encryption-rsa-public-key", "encryptionrsapublickey"}, false, false, "", ""},
+	OptionEncryptionPriKey:  configOption{[]string{"encryptionRsaPrivateKey", "encryption_rsa_private_key", "encryption-rsa-private-key", "encryptionrsaprivatekey"}, false, false, "", ""},
+	OptionEncryptionAesKey:  configOption{[]string{"encryptionAesKey", "encryption_aes_key", "encryption-aes-key", "encryptionaeskey"}, false, false, "", ""},
+	OptionEncryptionMatDesc: configOption{[]string{"encryptionMatDesc", "encryption_matdesc", "encryption-matdesc", "encryptionmatdesc"}, false, false, "", ""},
 }
 
 // DefaultOptionMap allows alias name for options in default section
 // name, allow to show in screen
 var DefaultOptionMap = map[string]configOption{
-	OptionUserAgent:      configOption{[]string{"userAgent", "useragent", "user-agent", "user_agent"}, false, false, "", ""},
-	OptionLogLevel:       configOption{[]string{"loglevel", "log-level", "log_level"}, false, false, "", ""},
-	OptionProxyHost:      configOption{[]string{"proxyHost", "proxyhost", "proxy-host", "proxy_host"}, false, false, "", ""},
-	OptionProxyUser:      configOption{[]string{"proxyUser", "proxyuser", "proxy-user", "proxy_user"}, false, false, "", ""},
-	OptionProxyPwd:       configOption{[]string{"proxyPwd", "proxypwd", "proxy-pwd", "proxy_pwd"}, false, false, "", ""},
-	OptionReadTimeout:    configOption{[]string{"readTimeOut", "readtimeout", "read-timeout", "read_timeout"}, false, false, "", ""},
-	OptionConnectTimeout: configOption{[]string{"connectTimeOut", "connectTimeout", "connecttimeout", "connect-timeout", "connect_timeout"}, false, false, "", ""},
-	OptionRetryTimes:     configOption{[]string{"retryTimes", "retrytimes", "retry-times", "retry_times"}, false, false, "", ""},
+	OptionUserAgent:       configOption{[]string{"userAgent", "useragent", "user-agent", "user_agent"}, false, false, "", ""},
+	OptionLogLevel:        configOption{[]string{"loglevel", "log-level", "log_level"}, false, false, "", ""},
+	OptionProxyHost:       configOption{[]string{"proxyHost", "proxyhost", "proxy-host", "proxy_host"}, false, false, "", ""},
+	OptionProxyUser:       configOption{[]string{"proxyUser", "proxyuser", "proxy-user", "proxy_user"}, false, false, "", ""},
+	OptionProxyPwd:        configOption{[]string{"proxyPwd", "proxypwd", "proxy-pwd", "proxy_pwd"}, false, false, "", ""},
+	OptionReadTimeout:     configOption{[]string{"readTimeOut", "readtimeout", "read-timeout", "read_timeout"}, false, false, "", ""},
+	OptionConnectTimeout:  configOption{[]string{"connectTimeOut", "connectTimeout", "connecttimeout", "connect-timeout", "connect_timeout"}, false, false, "", ""},
+	OptionRetryTimes:      configOption{[]string{"retryTimes", "retrytimes", "retry-times", "retry_times"}, false, false, "", ""},
+	OptionRetryBaseDelay:  configOption{[]string{"retryBaseDelay", "retrybasedelay", "retry-base-delay", "retry_base_delay"}, false, false, "", ""},
+	OptionRetryMaxDelay:   configOption{[]string{"retryMaxDelay", "retrymaxdelay", "retry-max-delay", "retry_max_delay"}, false, false, "", ""},
+	OptionRetryMaxElapsed: configOption{[]string{"retryMaxElapsed", "retrymaxelapsed", "retry-max-elapsed", "retry_max_elapsed"}, false, false, "", ""},
+}
+
+// EnvOptionMap is the environment variables for options, if an option has several, the former has priority.
+// PRI: option > environment variable > profile > Credentials section
+var EnvOptionMap = map[string][]string{
+	OptionEndpoint:          []string{"OSS_ENDPOINT"},
+	OptionAccessKeyID:       []string{"OSS_ACCESS_KEY_ID"},
+	OptionAccessKeySecret:   []string{"OSS_ACCESS_KEY_SECRET"},
+	OptionSTSToken:          []string{"OSS_SESSION_TOKEN", "OSS_STS_TOKEN"},
+	OptionRegion:            []string{"OSS_REGION"},
+	OptionSignVersion:       []string{"OSS_SIGN_VERSION"},
+	OptionMode:              []string{"OSS_MODE"},
+	OptionRamRoleArn:        []string{"OSS_RAM_ROLE_ARN", "ALIBABA_CLOUD_ROLE_ARN"},
+	OptionRoleSessionName:   []string{"OSS_ROLE_SESSION_NAME", "ALIBABA_CLOUD_ROLE_SESSION_NAME"},
+	OptionTokenTimeout:      []string{"OSS_TOKEN_TIMEOUT"},
+	OptionSTSRegion:         []string{"OSS_STS_REGION"},
+	OptionECSRoleName:       []string{"OSS_ECS_ROLE_NAME"},
+	OptionReadTimeout:       []string{"OSS_READ_TIMEOUT"},
+	OptionConnectTimeout:    []string{"OSS_CONNECT_TIMEOUT"},
+	OptionCredentialProcess: []string{"OSS_CREDENTIAL_PROCESS"},
+	OptionOIDCProviderArn:   []string{"ALIBABA_CLOUD_OIDC_PROVIDER_ARN"},
+	OptionOIDCTokenFile:     []string{"ALIBABA_CLOUD_OIDC_TOKEN_FILE"},
+	OptionCredentialsCache:  []string{"OSS_CREDENTIALS_CACHE"},
 }
 
 // DecideConfigFile return the config file, if user not specified, return default one
@@ -97,11 +134,37 @@ func DecideConfigFile(configFile string) string {
 	return configFile
 }
 
-// LoadConfig load the specified config file
+// DecideProfile return the profile to use, if user not specified, return the one in environment variable
+func DecideProfile(profile string) string {
+	if profile == "" {
+		profile = os.Getenv(EnvOssutilProfile)
+	}
+	return normalizeProfile(profile)
+}
+
+// normalizeProfile return empty string for the default profile
+func normalizeProfile(profile string) string {
+	profile = strings.TrimSpace(profile)
+	if profile == DefaultProfile {
+		return ""
+	}
+	return profile
+}
+
+func profileSectionName(profile string) string {
+	return ProfileSectionPrefix + profile
+}
+
+// LoadConfig load the specified config file, with the profile in environment variable
 func LoadConfig(configFile string) (OptionMapType, error) {
+	return LoadProfileConfig(configFile, "")
+}
+
+// LoadProfileConfig load the specified profile of config file, the profile inherits the options in Credentials section
+func LoadProfileConfig(configFile, profile string) (OptionMapType, error) {
 	var configMap OptionMapType
 	var err error
-	configMap, err = readConfigFromFile(configFile)
+	configMap, err = readConfigFromFile(configFile, DecideProfile(profile))
 	if err != nil {
 		return nil, fmt.Errorf("Read config file error: %s, please try \"help config\" to set configuration or use \"--config-file\" option", err)
 	}
@@ -111,7 +174,7 @@ func LoadConfig(configFile string) (OptionMapType, error) {
 	return configMap, nil
 }
 
-func readConfigFromFile(configFile string) (OptionMapType, error) {
+func readConfigFromFile(configFile, profile string) (OptionMapType, error) {
 	configFile = DecideConfigFile(configFile)
 
 	config, err := configparser.Read(configFile)
@@ -132,23 +195,23 @@ func readConfigFromFile(configFile string) (OptionMapType, error) {
 		}
 	}
 
-	// get options in cred section
+	// get options in cred section, it's the default profile
 	credSection, err := config.Section(CREDSection)
-	if err != nil {
+	if err != nil && profile == "" {
 		return nil, err
 	}
 
-	credOptions := credSection.Options()
-
-	//added
-	//configMap[CREDSection] = map[string]string{}
+	if err == nil {
+		readCredOptions(credSection, configMap)
+	}
 
-	for name, option := range credOptions {
-		if opName, ok := getOptionNameByStr(strings.TrimSpace(name)); ok {
-			configMap[strings.TrimSpace(opName)] = strings.TrimSpace(option)
-		} else {
-			configMap[strings.TrimSpace(name)] = strings.TrimSpace(option)
+	// options in named profile override the ones in cred section
+	if profile != "" {
+		profileSection, err := config.Section(profileSectionName(profile))
+		if err != nil {
+			return nil, fmt.Errorf("profile %s not found", profile)
 		}
+		readCredOptions(profileSection, configMap)
 	}
 
 	// get options in pair sections
@@ -175,14 +238,72 @@ func readConfigFromFile(configFile string) (OptionMapType, error) {
 	return configMap, nil
 }
 
+func readCredOptions(section *configparser.Section, configMap OptionMapType) {
+	for name, option := range section.Options() {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if opName, ok := getOptionNameByStr(name); ok {
+			configMap[opName] = strings.TrimSpace(option)
+		} else {
+			configMap[name] = strings.TrimSpace(option)
+		}
+	}
+}
+
+// get options from environment variables
+func readConfigFromEnv() (OptionMapType, error) {
+	configMap := OptionMapType{}
+	for name, envNames := range EnvOptionMap {
+		for _, envName := range envNames {
+			val := strings.TrimSpace(os.Getenv(envName))
+			if val == "" {
+				continue
+			}
+			if err := checkConfigValue(name, val); err != nil {
+				return nil, fmt.Errorf("error value of environment variable \"%s\", the value is: %s, %s", envName, val, err)
+			}
+			configMap[name] = val
+			break
+		}
+	}
+	return configMap, nil
+}
+
+// get profile names from config file, the default profile is the first one if Credentials section exists
+func readProfilesFromFile(configFile string) ([]string, error) {
+	configFile = DecideConfigFile(configFile)
+	config, err := configparser.Read(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := []string{}
+	if _, err := config.Section(CREDSection); err == nil {
+		profiles = append(profiles, DefaultProfile)
+	}
+
+	sections, _ := config.AllSections()
+	for _, section := range sections {
+		if strings.HasPrefix(section.Name(), ProfileSectionPrefix) {
+			profiles = append(profiles, strings.TrimSpace(strings.TrimPrefix(section.Name(), ProfileSectionPrefix)))
+		}
+	}
+	return profiles, nil
+}
+
 // get loglevel from config file
-func readLoglevelFromFile(configFile string) (string, error) {
+func readLoglevelFromFile(configFile, profile string) (string, error) {
 	configFile = DecideConfigFile(configFile)
 	config, err := configparser.Read(configFile)
 	if err != nil {
 		return "", err
 	}
 	sectionNameList := []string{CREDSection, DefaultSection}
+	if profile = DecideProfile(profile); profile != "" {
+		sectionNameList = append([]string{profileSectionName(profile)}, sectionNameList...)
+	}
 	logConfig := DefaultOptionMap[OptionLogLevel]
 	for _, sectionName := range sectionNameList {
 		section, err := config.Section(sectionName)
@@ -223,19 +344,27 @@ func getOptionNameByDefault(name string) (string, bool) {
 
 func checkConfig(configMap OptionMapType) error {
 	for name, opval := range configMap {
-		if option, ok := OptionMap[name]; ok {
-			if option.optionType == OptionTypeInt64 {
-				if _, err := strconv.ParseInt(opval.(string), 10, 64); err != nil {
-					return fmt.Errorf("error value of option \"%s\", the value is: %s in config file, which needs int64 type", name, opval)
-				}
-			}
-			if option.optionType == OptionTypeAlternative {
-				vals := strings.Split(option.minVal, "/")
-				if FindPosCaseInsen(opval.(string), vals) == -1 {
-					return fmt.Errorf("error value of option \"%s\", the value is: %s in config file, which is not anyone of %s", name, opval, option.minVal)
-				}
+		if _, ok := OptionMap[name]; ok {
+			if err := checkConfigValue(name, opval.(string)); err != nil {
+				return fmt.Errorf("error value of option \"%s\", the value is: %s in config file, %s", name, opval, err)
 			}
 		}
 	}
 	return nil
 }
+
+func checkConfigValue(name, value string) error {
+	option := OptionMap[name]
+	if option.optionType == OptionTypeInt64 {
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("which needs int64 type")
+		}
+	}
+	if option.optionType == OptionTypeAlternative {
+		vals := strings.Split(option.minVal, "/")
+		if FindPosCaseInsen(value, vals) == -1 {
+			return fmt.Errorf("which is not anyone of %s", option.minVal)
+		}
+	}
+	return nil
+}
@@ -300,12 +300,12 @@ func (s *OssutilConfigSuite) TestConfigInvalidOption(c *C) {
 }
 
 func (s *OssutilConfigSuite) TestConfigNotConfigFile(c *C) {
-	configCommand.runCommandInteractive("", LEnglishLanguage)
+	configCommand.runCommandInteractive("", LEnglishLanguage, "")
 	contents, _ := ioutil.ReadFile(logPath)
 	LogContent := string(contents)
 	c.Assert(strings.Contains(LogContent, "Please enter the config file name"), Equals, true)
 
-	configCommand.runCommandInteractive("", ChineseLanguage)
+	configCommand.runCommandInteractive("", ChineseLanguage, "")
 	contents, _ = ioutil.ReadFile(logPath)
 	LogContent = string(contents)
 	c.Assert(strings.Contains(LogContent, "请输入配置文件名"), Equals, true)
@@ -326,7 +326,7 @@ func (s *OssutilConfigSuite) TestConfigConfigInteractive(c *C) {
 	oldStdin := os.Stdin
 	os.Stdin = inputFile
 
-	err := configCommand.configInteractive(configFileName, LEnglishLanguage)
+	err := configCommand.configInteractive(configFileName, LEnglishLanguage, "")
 	c.Assert(err, IsNil)
 
 	fileData, err := ioutil.ReadFile(configFileName)
@@ -494,6 +494,232 @@ func (s *OssutilConfigSuite) TestConfigNonInteractiveWithCommonOption(c *C) {
 	os.Remove(cfile)
 }
 
+func (s *OssutilConfigSuite) TestLoadProfileConfig(c *C) {
+	cfile := randStr(10)
+	data := "[Credentials]\nlanguage=EN\nendpoint=oss-cn-hangzhou.aliyuncs.com\naccessKeyID=ak\naccessKeySecret=sk\n" +
+		"[profile dev]\nendpoint=oss-cn-shanghai.aliyuncs.com\naccess_key_id=devak\n" +
+		"[profile prod]\naccessKeySecret=prodsk\n"
+	s.createFile(cfile, data, c)
+
+	// default profile
+	opts, err := LoadProfileConfig(cfile, "")
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionEndpoint], Equals, "oss-cn-hangzhou.aliyuncs.com")
+	c.Assert(opts[OptionAccessKeyID], Equals, "ak")
+
+	opts, err = LoadProfileConfig(cfile, DefaultProfile)
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionAccessKeyID], Equals, "ak")
+
+	// named profile inherits the default one
+	opts, err = LoadProfileConfig(cfile, "dev")
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionLanguage], Equals, "EN")
+	c.Assert(opts[OptionEndpoint], Equals, "oss-cn-shanghai.aliyuncs.com")
+	c.Assert(opts[OptionAccessKeyID], Equals, "devak")
+	c.Assert(opts[OptionAccessKeySecret], Equals, "sk")
+
+	// profile in environment variable
+	os.Setenv(EnvOssutilProfile, "prod")
+	opts, err = LoadConfig(cfile)
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionAccessKeyID], Equals, "ak")
+	c.Assert(opts[OptionAccessKeySecret], Equals, "prodsk")
+
+	// option has priority over environment variable
+	opts, err = LoadProfileConfig(cfile, "dev")
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionAccessKeyID], Equals, "devak")
+	os.Unsetenv(EnvOssutilProfile)
+
+	// profile not exist
+	_, err = LoadProfileConfig(cfile, "notexist")
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "notexist"), Equals, true)
+
+	profiles, err := readProfilesFromFile(cfile)
+	c.Assert(err, IsNil)
+	c.Assert(profiles, DeepEquals, []string{DefaultProfile, "dev", "prod"})
+	os.Remove(cfile)
+}
+
+func (s *OssutilConfigSuite) TestProfileWithoutCredentials(c *C) {
+	cfile := randStr(10)
+	s.createFile(cfile, "[profile dev]\nendpoint=oss-cn-shanghai.aliyuncs.com\nloglevel=debug\n", c)
+
+	opts, err := LoadProfileConfig(cfile, "dev")
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionEndpoint], Equals, "oss-cn-shanghai.aliyuncs.com")
+
+	_, err = LoadProfileConfig(cfile, "")
+	c.Assert(err, NotNil)
+
+	level, err := readLoglevelFromFile(cfile, "dev")
+	c.Assert(err, IsNil)
+	c.Assert(level, Equals, "debug")
+
+	level, err = readLoglevelFromFile(cfile, "")
+	c.Assert(err, IsNil)
+	c.Assert(level, Equals, "")
+	os.Remove(cfile)
+}
+
+func (s *OssutilConfigSuite) TestConfigProfile(c *C) {
+	command := "config"
+	var args []string
+	cfile := randStr(10)
+	endpoint := "oss-cn-hangzhou.aliyuncs.com"
+	accessKeyID := "ak"
+	options := OptionMapType{
+		"endpoint":    &endpoint,
+		"accessKeyID": &accessKeyID,
+		"configFile":  &cfile,
+	}
+	_, err := cm.RunCommand(command, args, options)
+	c.Assert(err, IsNil)
+
+	// add profile, the default profile is kept
+	profile := "dev"
+	devEndpoint := "oss-cn-shanghai.aliyuncs.com"
+	options = OptionMapType{
+		"endpoint":   &devEndpoint,
+		"configFile": &cfile,
+		"profile":    &profile,
+	}
+	_, err = cm.RunCommand(command, args, options)
+	c.Assert(err, IsNil)
+
+	opts, err := LoadProfileConfig(cfile, profile)
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionEndpoint], Equals, devEndpoint)
+	c.Assert(opts[OptionAccessKeyID], Equals, accessKeyID)
+
+	// rewrite profile
+	devAccessKeyID := "devak"
+	options = OptionMapType{
+		"accessKeyID": &devAccessKeyID,
+		"configFile":  &cfile,
+		"profile":     &profile,
+	}
+	_, err = cm.RunCommand(command, args, options)
+	c.Assert(err, IsNil)
+
+	opts, err = LoadProfileConfig(cfile, profile)
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionEndpoint], Equals, endpoint)
+	c.Assert(opts[OptionAccessKeyID], Equals, devAccessKeyID)
+
+	// rewrite the default profile, the named profile is kept
+	newEndpoint := "oss-cn-beijing.aliyuncs.com"
+	options = OptionMapType{
+		"endpoint":   &newEndpoint,
+		"configFile": &cfile,
+	}
+	_, err = cm.RunCommand(command, args, options)
+	c.Assert(err, IsNil)
+
+	opts, err = LoadProfileConfig(cfile, profile)
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionEndpoint], Equals, newEndpoint)
+	c.Assert(opts[OptionAccessKeyID], Equals, devAccessKeyID)
+
+	profiles, err := readProfilesFromFile(cfile)
+	c.Assert(err, IsNil)
+	c.Assert(profiles, DeepEquals, []string{DefaultProfile, profile})
+
+	listProfiles := true
+	options = OptionMapType{
+		"configFile":   &cfile,
+		"listProfiles": &listProfiles,
+	}
+	_, err = cm.RunCommand(command, args, options)
+	c.Assert(err, IsNil)
+
+	os.Remove(cfile)
+	os.Remove(cfile + ".bak")
+}
+
+func (s *OssutilConfigSuite) TestConfigFromEnv(c *C) {
+	os.Setenv("OSS_ACCESS_KEY_ID", "envak")
+	os.Setenv("OSS_STS_TOKEN", "ststoken")
+	os.Setenv("OSS_SESSION_TOKEN", "sessiontoken")
+	os.Setenv("OSS_READ_TIMEOUT", "100")
+	opts, err := readConfigFromEnv()
+	c.Assert(err, IsNil)
+	c.Assert(len(opts), Equals, 3)
+	c.Assert(opts[OptionAccessKeyID], Equals, "envak")
+	c.Assert(opts[OptionSTSToken], Equals, "sessiontoken")
+	c.Assert(opts[OptionReadTimeout], Equals, "100")
+
+	// invalid value
+	os.Setenv("OSS_READ_TIMEOUT", "abc")
+	_, err = readConfigFromEnv()
+	c.Assert(err, NotNil)
+	c.Assert(strings.Contains(err.Error(), "OSS_READ_TIMEOUT"), Equals, true)
+
+	for _, name := range []string{"OSS_ACCESS_KEY_ID", "OSS_STS_TOKEN", "OSS_SESSION_TOKEN", "OSS_READ_TIMEOUT"} {
+		os.Unsetenv(name)
+	}
+	opts, err = readConfigFromEnv()
+	c.Assert(err, IsNil)
+	c.Assert(len(opts), Equals, 0)
+}
+
+func (s *OssutilConfigSuite) TestConfigPriorityWithEnv(c *C) {
+	cfile := randStr(10)
+	data := "[Credentials]\nendpoint=oss-cn-hangzhou.aliyuncs.com\naccessKeyID=ak\naccessKeySecret=sk\n"
+	s.createFile(cfile, data, c)
+
+	os.Setenv("OSS_ACCESS_KEY_ID", "envak")
+	os.Setenv("OSS_ACCESS_KEY_SECRET", "envsk")
+	defer os.Unsetenv("OSS_ACCESS_KEY_ID")
+	defer os.Unsetenv("OSS_ACCESS_KEY_SECRET")
+
+	str := ""
+	flagSecret := "flagsk"
+	options := OptionMapType{
+		"endpoint":        &str,
+		"accessKeyID":     &str,
+		"accessKeySecret": &flagSecret,
+		"configFile":      &cfile,
+	}
+	err := statCommand.Init([]string{CloudURLToString("bucket", "")}, options)
+	c.Assert(err, IsNil)
+
+	// option > environment variable > config file
+	endpoint, _ := GetString(OptionEndpoint, statCommand.command.options)
+	c.Assert(endpoint, Equals, "oss-cn-hangzhou.aliyuncs.com")
+	accessKeyID, _ := GetString(OptionAccessKeyID, statCommand.command.options)
+	c.Assert(accessKeyID, Equals, "envak")
+	accessKeySecret, _ := GetString(OptionAccessKeySecret, statCommand.command.options)
+	c.Assert(accessKeySecret, Equals, flagSecret)
+
+	// config file is not needed
+	os.Remove(cfile)
+	notExistFile := randStr(10)
+	options = OptionMapType{
+		"endpoint":        &str,
+		"accessKeyID":     &str,
+		"accessKeySecret": &str,
+		"configFile":      &notExistFile,
+	}
+	err = statCommand.Init([]string{CloudURLToString("bucket", "")}, options)
+	c.Assert(err, IsNil)
+	accessKeyID, _ = GetString(OptionAccessKeyID, statCommand.command.options)
+	c.Assert(accessKeyID, Equals, "envak")
+
+	os.Unsetenv("OSS_ACCESS_KEY_ID")
+	os.Unsetenv("OSS_ACCESS_KEY_SECRET")
+	options = OptionMapType{
+		"endpoint":        &str,
+		"accessKeyID":     &str,
+		"accessKeySecret": &str,
+		"configFile":      &notExistFile,
+	}
+	err = statCommand.Init([]string{CloudURLToString("bucket", "")}, options)
+	c.Assert(err, NotNil)
+}
+
 func (s *OssutilConfigSuite) createFile(fileName, content string, c *C) {
 	fout, err := os.Create(fileName)
 	defer fout.Close()
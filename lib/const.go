@@ -106,6 +106,54 @@ const (
 	OptionQueryParam          = "queryParam"
 	OptionForcePathStyle      = "forcePathStyle"
 	OptionRuntime             = "runtime"
+	OptionCompare             = "compare"
+	OptionDryRun              = "dryRun"
+	OptionOutputFormat        = "outputFormat"
+	OptionProfile             = "profile"
+	OptionListProfiles        = "listProfiles"
+	OptionCredentialProcess   = "credentialProcess"
+	OptionOIDCProviderArn     = "oidcProviderArn"
+	OptionOIDCTokenFile       = "oidcTokenFile"
+	OptionCredentialsCache    = "credentialsCache"
+	OptionBwLimit             = "bwlimit"
+	OptionBwLimitFile         = "bwlimitFile"
+	OptionResumeJob           = "resumeJob"
+	OptionJobsDir             = "jobsDir"
+	OptionEncryptionPubKey    = "encryptionRsaPublicKey"
+	OptionEncryptionPriKey    = "encryptionRsaPrivateKey"
+	OptionEncryptionAesKey    = "encryptionAesKey"
+	OptionEncryptionMatDesc   = "encryptionMatDesc"
+	OptionCompress            = "compress"
+	OptionCompressInclude     = "compressInclude"
+	OptionDecompress          = "decompress"
+	OptionAutoTune            = "autoTune"
+	OptionRetryBaseDelay      = "retryBaseDelay"
+	OptionRetryMaxDelay       = "retryMaxDelay"
+	OptionRetryMaxElapsed     = "retryMaxElapsed"
+	OptionManifest            = "manifest"
+	OptionManifestFormat      = "manifestFormat"
+	OptionMinSize             = "minSize"
+	OptionMaxSize             = "maxSize"
+	OptionOlderThan           = "olderThan"
+	OptionNewerThan           = "newerThan"
+	OptionHasTag              = "hasTag"
+	OptionContentType         = "contentType"
+	OptionIncludeRegex        = "includeRegex"
+	OptionExcludeRegex        = "excludeRegex"
+	OptionExcludeFrom         = "excludeFrom"
+	OptionUploadID            = "uploadID"
+	OptionPartNumber          = "partNumber"
+	OptionPostPolicy          = "postPolicy"
+	OptionContentLengthRange  = "contentLengthRange"
+	OptionHead                = "head"
+	OptionTail                = "tail"
+	OptionBytes               = "bytes"
+	OptionFollow              = "follow"
+	OptionLines               = "lines"
+	OptionInterval            = "interval"
+	OptionMaxInterval         = "maxInterval"
+	OptionBatchSize           = "batchSize"
+	OptionBatchInterval       = "batchInterval"
 )
 
 // the elements show in stat object
@@ -120,6 +168,7 @@ const (
 	StatLastModified           = "Last-Modified"
 	StatContentMD5             = "Content-Md5"
 	StatCRC64                  = "X-Oss-Hash-Crc64ecma"
+	StatObjectType             = "X-Oss-Object-Type"
 	StatStorageClass           = "StorageClass"
 	StatSSEAlgorithm           = "SSEAlgorithm"
 	StatKMSMasterKeyID         = "KMSMasterKeyID"
@@ -163,6 +212,10 @@ const (
 	EnglishLanguage                = "EN"
 	Scheme                  string = "oss"
 	DefaultConfigFile              = "~" + string(os.PathSeparator) + ".ossutilconfig"
+	DefaultProfile                 = "default"
+	EnvOssutilProfile              = "OSSUTIL_PROFILE"
+	DefaultJobsDir                 = "~" + string(os.PathSeparator) + ".ossutil_jobs"
+	CredentialsCacheOff            = "off"
 	MaxUint                 uint   = ^uint(0)
 	MaxInt                  int    = int(MaxUint >> 1)
 	MaxUint64               uint64 = ^uint64(0)
@@ -171,6 +224,7 @@ const (
 	ReportSuffix                   = ".report"
 	DefaultOutputDir               = "ossutil_output"
 	CheckpointDir                  = ".ossutil_checkpoint"
+	SyncKeyDBPrefix                = "sync_keys_"
 	CheckpointSep                  = "---"
 	SnapshotConnector              = "==>"
 	SnapshotSep                    = "#"
@@ -190,19 +244,41 @@ const (
 	RetryTimes              int    = 10
 	MaxRetryTimes           int64  = 500
 	MinRetryTimes           int64  = 1
+	RetryBaseDelay          int    = 1000
+	RetryMaxDelay           int    = 30000
+	MaxRetryDelay           int64  = 600000
+	MinRetryDelay           int64  = 0
+	RetryMaxElapsed         int    = 0
+	MaxRetryMaxElapsed      int64  = 86400
+	MinRetryMaxElapsed      int64  = 0
 	Routines                int    = 3
 	MaxRoutines             int64  = 10000
 	MinRoutines             int64  = 1
 	MaxParallel             int64  = 10000
 	MinParallel             int64  = 1
+	DefaultStdinPartSize    int64  = 16777216
+	DefaultStdinParallel    int64  = 4
 	DefaultHashType         string = "crc64"
 	MD5HashType             string = "md5"
+	CompareSize             string = "size"
+	CompareMtime            string = "mtime"
+	CompareSizeMtime        string = "size-mtime"
+	CompareCRC64            string = "crc64"
+	CompareMD5              string = "md5"
+	OutputFormatJSON        string = "json"
+	OutputFormatJSONL       string = "jsonl"
+	OutputFormatCSV         string = "csv"
+	ManifestFormatPlain     string = "plain"
+	ManifestFormatCSV       string = "csv"
+	ManifestFormatJSONL     string = "jsonl"
+	ManifestFormatInventory string = "inventory"
 	LogFilePrefix                  = "ossutil_log_"
 	URLEncodingType                = "url"
 	StorageStandard                = string(oss.StorageStandard)
 	StorageIA                      = string(oss.StorageIA)
 	StorageArchive                 = string(oss.StorageArchive)
 	StorageColdArchive             = string(oss.StorageColdArchive)
+	StorageDeepColdArchive         = string(oss.StorageDeepColdArchive)
 	DefaultStorageClass            = StorageStandard
 	DefaultMethod                  = string(oss.HTTPGet)
 	DefaultTimeout                 = 60
@@ -211,7 +287,20 @@ const (
 	DefaultNonePattern             = ""
 	IncludePrompt                  = "--include"
 	ExcludePrompt                  = "--exclude"
+	IncludeRegexPrompt             = "--include-regex"
+	ExcludeRegexPrompt             = "--exclude-regex"
+	ExcludeFromPrompt              = "--exclude-from"
 	MaxAppendObjectSize     int64  = 5368709120
+	FollowInterval          int    = 1000
+	FollowMaxInterval       int    = 30000
+	MinFollowInterval       int64  = 100
+	MaxFollowInterval       int64  = 3600000
+	DefaultTailLines        int    = 10
+	AppendBatchSize         string = "1M"
+	AppendBatchInterval     int    = 1000
+	MinAppendBatchInterval  int64  = 10
+	MaxAppendBatchInterval  int64  = 3600000
+	MaxAppendPositionRetry  int    = 3
 	MaxBatchCount           int    = 100
 )
 
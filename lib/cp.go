@@ -1,6 +1,8 @@
 package lib
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"hash/fnv"
 	"io/ioutil"
@@ -11,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
@@ -31,6 +34,23 @@ const (
 	opCopy            = "copy"
 )
 
+var copyResultColumns = []string{"type", "src", "dest", "size", "status", "error",
+	"dry_run", "total_count", "total_size", "ok_count", "file_count", "dir_count", "skip_count", "skip_dir",
+	"error_count", "transfer_size", "skip_size"}
+
+// the reasons of upload/download/copy or skip, shown in dry run mode
+const (
+	reasonBeforeStartTime   = "last modified time is before --start-time"
+	reasonAfterEndTime      = "last modified time is after --end-time"
+	reasonSnapshot          = "not modified since the snapshot"
+	reasonNotExist          = "destination does not exist"
+	reasonOverwrite         = "destination exists, overwrite is confirmed"
+	reasonNotConfirmed      = "destination exists, overwrite is not confirmed"
+	reasonNeedConfirm       = "destination exists, overwrite needs to be confirmed"
+	reasonDestIsDir         = "destination is a directory"
+	reasonDirObjectDisabled = "--disable-dir-object"
+)
+
 /*
  * Put same type variables together to make them 64bits alignment to avoid
  * atomic.AddInt64() panic
@@ -44,9 +64,11 @@ type copyOptionType struct {
 	meta              string
 	options           []oss.Option
 	filters           []filterOptionType
+	attrFilter        *attributeFilter
 	threshold         int64
 	routines          int64
 	reporter          *Reporter
+	output            *OutputWriter
 	snapshotldb       *leveldb.DB
 	recursive         bool
 	force             bool
@@ -66,6 +88,16 @@ type copyOptionType struct {
 	bSyncCommand      bool
 	startTime         int64
 	endTime           int64
+	compare           string
+	dryRun            bool
+	job               *JobJournal
+	move              *moveRecorder
+	encryption        *ClientEncryption
+	compress          string
+	compressInclude   []string
+	decompress        bool
+	tuner             *AutoTuner
+	manifest          *manifestSource
 }
 
 type filterOptionType struct {
@@ -83,6 +115,23 @@ type objectInfoType struct {
 	relativeKey  string
 	size         int64
 	lastModified time.Time
+	entry        *ManifestEntry // the entry of the object if it's read from the manifest
+}
+
+// key returns the key of the source object, the relative key is the dest name if the manifest renames it
+func (oi objectInfoType) key() string {
+	if oi.entry != nil {
+		return oi.entry.Key
+	}
+	return oi.prefix + oi.relativeKey
+}
+
+// jobKey identifies the object in the job journal, the versions of an object are different items
+func (oi objectInfoType) jobKey() string {
+	if oi.entry != nil && oi.entry.VersionId != "" {
+		return oi.entry.Key + "?versionId=" + oi.entry.VersionId
+	}
+	return oi.key()
 }
 
 var (
@@ -152,9 +201,11 @@ var specChineseCopy = SpecText{
 	paramText: "src_url dest_url [options]",
 
 	syntaxText: ` 
-    ossutil cp file_url cloud_url  [-r] [-f] [-u] [--enable-symlink-dir] [--disable-all-symlink] [--disable-ignore-error] [--only-current-dir] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--snapshot-path=sdir] [--payer requester]
-    ossutil cp cloud_url file_url  [-r] [-f] [-u] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--range=x-y] [--payer requester] [--version-id versionId]
-    ossutil cp cloud_url cloud_url [-r] [-f] [-u] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester] [--version-id versionId]
+    ossutil cp file_url cloud_url  [-r] [-f] [-u] [--enable-symlink-dir] [--disable-all-symlink] [--disable-ignore-error] [--only-current-dir] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--snapshot-path=sdir] [--payer requester] [--min-size size] [--max-size size] [--older-than time] [--newer-than time]
+    ossutil cp cloud_url file_url  [-r] [-f] [-u] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--range=x-y] [--payer requester] [--version-id versionId] [--manifest file] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types]
+    ossutil cp cloud_url cloud_url [-r] [-f] [-u] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester] [--version-id versionId] [--manifest file] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types]
+    ossutil cp - cloud_url [-f] [--part-size=size] [--parallel=n] [--payer requester]
+    ossutil cp cloud_url - [--part-size=size] [--parallel=n] [--range=x-y] [--payer requester] [--version-id versionId]
 `,
 
 	detailHelpText: ` 
@@ -212,7 +263,9 @@ var specChineseCopy = SpecText{
     ?：匹配单个字符
     [sequence]：匹配sequence的任意字符
     [!sequence]：匹配不在sequence的任意字符
-    注意：规则不支持带目录的格式，e.g.，--include "/usr/*/test/*.jpg"。
+    **：匹配任意层目录
+    含有/或**的规则匹配相对于源目录或object前缀所在目录的路径，以/开头时同样从该目录开始匹配，以/结尾时匹配
+    该目录下的所有文件，e.g.，--include "logs/**/*.gz"，--exclude "tmp/"；其它规则只匹配文件名。
 
     --include和--exclude可以出现多次。当多个规则出现时，这些规则按从左往右的顺序应用。例如：
     当前目录下包含3个文件：
@@ -232,6 +285,27 @@ var specChineseCopy = SpecText{
     上传testfile2.txt到oss://my-bucket/path/testfile2.txt
     上传testfile33.jpg到oss://my-bucket/path/testfile33.jpg
 
+--include-regex、--exclude-regex和--exclude-from选项
+
+    --include-regex和--exclude-regex以正则表达式匹配相对路径（同上），未指定^和$时匹配路径的任意部分，
+    e.g.，--exclude-regex "\.(tmp|bak)$"。
+    --exclude-from从文件中读取排除规则，文件格式同.gitignore，如.ossignore：每行一条规则，#开头为注释，
+    !开头表示重新包含之前排除的路径，以/结尾只匹配目录，含有/的规则从根目录开始匹配，否则匹配任意层的名称，
+    已被排除的目录下的文件不能再被包含。
+    这些选项和--include、--exclude可以混合出现多次，按从左往右的顺序应用，--exclude-from整体作为一个排除规则。
+
+--min-size、--max-size、--older-than、--newer-than、--storage-class、--has-tag和--content-type选项
+
+    按属性筛选要操作的object，可以和--include、--exclude同时使用，只操作满足所有条件的object。
+    --min-size、--max-size：大小范围，单位可以为B/K/M/G/T，例如：--min-size 1M --max-size 1G
+    --older-than、--newer-than：最后修改时间范围，取值为时长，单位可以为s/m/h/d/w，例如：30d，
+        或者为时间，例如：2023-01-01、2023-01-01T08:00:00+08:00
+    --storage-class：存储方式，多个以逗号分隔，例如：IA,Archive
+    --has-tag：带有的标签，格式为k1=v1,k2=v2，只指定k表示带有该标签即可
+    --content-type：Content-Type，多个以逗号分隔，支持image/*的格式
+    --has-tag和--content-type需要为每个object发送一次请求，其他条件由列举结果判断，不需要额外请求。
+    上传时只支持按大小和最后修改时间筛选文件，下载和拷贝时按源object筛选。
+
 --meta选项
 
     该选项在上传文件的同时设置object的meta信息。当指定--recursive选项时，会设置所有上传的
@@ -276,6 +350,115 @@ var specChineseCopy = SpecText{
     否指定了，在目标文件存在时，ossutil都不会提示，直接采取上述策略。
     该选项可用于当批量拷贝失败时，重传时跳过已经成功的文件。实现增量上传。
 
+--compare选项
+
+    指定增量上传、下载、拷贝时判断源和目标是否相同的方式，相同则跳过，不同则覆盖。指定该选项时
+    无需再指定--update选项，在目标存在时ossutil同样不会提示。取值如下：
+    size:       大小相同即认为相同
+    mtime:      目标的最后修改时间不早于源的最后修改时间即认为相同，与--update选项的策略一致
+    size-mtime: 同时满足size和mtime条件才认为相同
+    crc64:      大小相同且crc64相同才认为相同，本地文件的crc64由ossutil计算，object的crc64取自
+                X-Oss-Hash-Crc64ecma，没有crc64的object总是被认为不同
+    md5:        大小相同且md5相同才认为相同，object的md5取自Content-Md5或者普通object的ETag，
+                分片上传和追加上传的object的ETag不是md5，这类object总是被认为不同
+
+    crc64和md5方式可以识别最后修改时间未变但内容已变化的文件，也可以跳过内容相同但最后修改时
+    间被修改过的文件，但需要读取本地文件的全部内容来计算校验值。
+
+--dryrun选项
+
+    如果指定了该选项，ossutil只列举要操作的文件（或object），并逐个输出将要执行的上传、下载、
+    拷贝或者跳过操作及原因，不会实际传输任何数据，也不会更新--snapshot-path下的快照信息。目标
+    存在时ossutil不会提示是否覆盖。可以与--update、--compare等选项配合使用，预先检查增量操作
+    的结果。
+
+--output-format选项
+
+    以机器可读的格式输出结果，取值为json、jsonl或csv。指定该选项后，ossutil不再输出进度，而是对
+    每个文件（或object）输出一条记录，包含操作类型（upload、download或copy）、源、目标、大小、
+    状态（ok、skip或error）以及错误信息，最后输出一条type为summary的汇总记录。json格式输出一个
+    数组，jsonl格式每行输出一个json对象，csv格式首行为列名。
+
+--bwlimit选项
+
+    按一天中的时间段限制上传和下载速度，格式为"HH:MM,RATE HH:MM,RATE ..."，每个速度从指定时间起生效，
+    直到下一个时间，第一个时间之前使用最后一个速度。例如"08:00,10M 18:00,off"表示8点到18点限速
+    10MB/s，其他时间不限速。RATE的单位可以为K、M、G（每秒字节数），不带单位时为KB/s，off表示不限速；
+    RATE也可以为UP:DOWN的格式，例如"512K:off"，分别限制上传和下载速度。只指定一个RATE时全天生效。
+    运行过程中ossutil每10秒检查一次时间表，限速变化后对新开始的文件和下载分片生效，已经在传输的请求
+    保持原有限速，长时间运行的任务可以按照网络策略自动调整速度。
+
+    --bwlimit-file选项从文件读取相同格式的时间表，时间表可以分多行，#开头的行为注释。ossutil收到
+    SIGUSR1信号（例如kill -USR1 pid）时重新读取该文件，从而在运行中调整限速，文件格式错误时保持原有
+    时间表（windows不支持）。这两个选项不能同时使用，也不能与--maxupspeed、--maxdownspeed同时使用。
+
+--resume-job选项
+
+    批量上传、下载或拷贝时，使用该选项指定任务ID，任务的进度记录在任务日志中，包括列举的位置、
+    已完成的文件以及未完成的分片上传。任务中断或者有文件失败时，使用相同的命令和任务ID再次运行，
+    ossutil从上次列举的位置继续，不再重新扫描已完成的部分，已完成且没有修改的文件被跳过，未完成的
+    分片上传从checkpoint继续。任务完成后再次运行时，任务重新开始。
+    
+    任务日志缺省保存在` + DefaultJobsDir + `目录下，可以通过--jobs-dir选项指定。未指定--checkpoint-dir
+    时，任务的checkpoint文件保存在任务目录下。可以使用jobs命令查看或者取消任务，详见help jobs。
+    该选项只能与-r同时使用，不支持--dryrun。
+
+--encryption-rsa-public-key、--encryption-rsa-private-key、--encryption-aes-key选项
+
+    客户端加密：上传时，数据在本地使用随机生成的数据密钥以AES-256-CTR加密后再发送到oss，数据密钥和
+    初始向量使用主密钥加密后保存在object的meta(x-oss-meta-client-side-encryption-*)中，与oss go sdk
+    的加密客户端兼容；下载和cat时，客户端加密的object被自动解密，没有加密的object不受影响。断点续传
+    上传时，分片大小调整为16字节的整数倍，checkpoint文件中保存的是加密后的数据密钥，继续上传时需要
+    能够解密数据密钥的主密钥，否则重新上传。
+    
+    主密钥可以是RSA密钥对或者AES密钥：--encryption-rsa-public-key指定PEM格式的公钥文件，用于上传；
+    --encryption-rsa-private-key指定PEM格式的私钥文件，用于下载，未指定公钥时上传也使用该密钥。
+    --encryption-aes-key指定AES主密钥文件，文件内容为base64编码的16、24或32字节密钥，数据密钥由
+    AES/GCM/NoPadding加密，oss sdk的加密客户端不支持该算法，因此这样加密的object只能由ossutil解密；
+    RSA主密钥加密的object可以由oss sdk的加密客户端读取。--encryption-matdesc指定json格式的主密钥描述信息，保存在object
+    的meta中。这些选项也可以配置在配置文件中，详见help config。
+    
+    客户端加密不改变object的大小，但object的md5和crc64是密文的，因此--compare为md5或crc64时总是
+    认为文件不同。oss间拷贝时object的meta被保留，数据不会重新加密。
+
+--compress、--compress-include、--decompress选项
+
+    上传时指定--compress(gzip或zstd)，文件内容在本地压缩后以流的方式上传，不生成临时文件，object的
+    Content-Encoding设置为压缩算法，原始内容的大小和crc64保存在object的meta(x-oss-meta-ossutil-uncompressed-*)
+    中。压缩后的内容小于一个分片时使用PutObject上传，否则使用分片上传，压缩上传不支持断点续传，失败时
+    整个文件重新上传。--compress-include指定需要压缩的文件名模式，例如"*.log"，可以指定多次，未指定时
+    压缩所有文件，不匹配的文件(例如已经压缩的二进制文件)按原样上传。
+    
+    下载时指定--decompress，Content-Encoding为gzip或zstd的object先下载到临时文件，再解压到目标文件，
+    并校验原始内容的大小和crc64，校验失败时删除目标文件并返回错误，其他object不受影响。cat命令也支持
+    --decompress。
+    
+    --update、--compare和mv比较压缩的object时使用meta中原始内容的大小和crc64，--compare为md5时压缩的
+    object总是认为不同。这些选项不能与客户端加密同时使用，--decompress不能与--range同时使用。
+
+--auto-tune选项
+
+    自动调整并发数：ossutil以--jobs(默认3)和--parallel(默认2)指定的并发数开始，每5秒根据观测到的吞吐量、
+    请求延迟以及503、SlowDown、RequestTimeTooSkewed错误调整文件级和分片级的并发数。遇到限流错误时
+    并发数减半；吞吐量仍在增长时增加并发数，所有文件级并发都在使用时增加文件并发数，否则增加分片并发
+    数，最多分别为64和32；增加后吞吐量没有提高时恢复到增加之前的值，延迟增长而吞吐量没有提高时保持
+    不变。分片并发数对之后开始传输的文件生效。调整的过程以debug级别记录在日志中(--loglevel debug)。
+
+--manifest、--manifest-format选项
+
+    下载和拷贝时指定--manifest，ossutil从清单中读取待处理的objects，而不是列举src_url，只处理src_url前缀
+    下的objects，此时可以不指定-r选项。清单可以为本地文件或者oss://bucket/object，--manifest-format指定
+    清单的格式，不指定时根据扩展名判断：
+        plain：每行一个object，忽略空行。
+        csv(.csv)：第一行包含key列时为表头，按列名读取key、versionId、destKey和size，其他列为设置到目标
+    object的header，例如Content-Type或者x-oss-meta-owner；否则依次为key、versionId和destKey列。
+        jsonl(.jsonl)：每行一个json，例如{"key":"a.txt","versionId":"v1","destKey":"b.txt","meta":{"Content-Type":"text/plain"}}。
+        inventory(.json)：bucket清单报告(见inventory命令)的manifest.json，ossutil从清单的目标bucket读取列出的
+    csv.gz文件并校验md5，不处理删除标记，清单的源bucket必须为src_url的bucket。
+    指定了versionId时操作该版本，指定了destKey时目标文件或者object的名称为dest_url加上destKey，而不是
+    object的相对路径，header只在拷贝时生效。--encoding-type url时清单中的key和destKey需要url编码，
+    inventory清单中的key总是url编码的。--manifest不能用于上传，不能与--version-id同时使用。
+
 --snapshot-path选项
 
     该选项用于在某些场景下加速增量上传批量文件（目前，下载和拷贝不支持该选项）。此场景为：
@@ -367,8 +550,10 @@ var specChineseCopy = SpecText{
     （1）上传到oss时：ossutil会对大文件自动分片，进行multipart分片上传，如果上传失败，会
         在本地的.ossutil_checkpoint目录记录失败信息，下次重传时会读取.ossutil_checkpoint目
         录中的信息进行断点续传，当上传成功时会删除.ossutil_checkpoint目录。
-    （2）从oss下载时：ossutil会自动对大文件分片下载，组装成一个文件，如果下载失败，同样会
-        在.ossutil_checkpoint目录记录失败信息，重试成功后会删除.ossutil_checkpoint目录。
+    （2）从oss下载时：ossutil会自动对大文件分片，多个连接并发地Range下载各个分片，直接写入
+        预先分配好的临时文件的对应位置，完成后通过合并分片的crc64校验整个object。已完成的分片以
+        位图的形式记录在.ossutil_checkpoint目录，下次下载时只下载未完成的分片，下载成功后会删
+        除.ossutil_checkpoint目录。并发数可以通过--parallel选项调大。
     （3）在oss间拷贝：ossutil会自动对大文件分片，使用Upload Part Copy方式拷贝，同样会在
         .ossutil_checkpoint目录记录失败信息，重试成功后会删除.ossutil_checkpoint目录。
 
@@ -437,7 +622,7 @@ var specChineseCopy = SpecText{
 
 用法：
 
-    该命令有三种用法：
+    该命令有四种用法：
 
     1) ossutil cp file_url oss://bucket[/prefix] [-r] [-f] [-u] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=file] [--snapshot-path=sdir]
         该用法上传本地文件系统中文件或目录到oss。file_url可以为文件或目录。当file_url为文件
@@ -475,6 +660,17 @@ var specChineseCopy = SpecText{
         当src_url为多个文件时，object名为：dest_url+源object名去除src_prefix。
 
     以上三种用法中如果指定了--recursive选项，均可以使用--include或--exclude选项使用通配符的方式过滤要操作的文件。
+
+    4) ossutil cp - oss://bucket/object 或 ossutil cp oss://bucket/object -
+        -表示标准输入或标准输出，用于在管道中上传或下载单个object，不产生临时文件。
+        从标准输入上传时，数据长度未知，数据按--part-size(缺省为` + strconv.FormatInt(DefaultStdinPartSize/1024/1024, 10) + `MB)分片读取，使用--parallel(缺省为
+    ` + strconv.FormatInt(DefaultStdinParallel, 10) + `)个并发分片上传，内存中最多保留parallel+1个分片，数据小于一个分片时使用PutObject上传。
+    分片数不能超过` + strconv.Itoa(MaxPartNum) + `，上传更大的数据请增大--part-size。上传不支持断点续传，失败时分片上传被取消。
+    由于标准输入被用作数据，object已经存在时不会询问是否覆盖，而是报错，需要指定-f覆盖。
+        下载到标准输出时，使用--parallel个并发的范围请求下载object或--range指定的范围，并按顺序写入
+    标准输出，此时不输出进度和结果信息。完整下载时校验object的crc64。
+        该用法不支持-r、-u、--compare、--snapshot-path、--partition-download、--dryrun、--resume-job、
+    客户端加密和压缩选项，mv命令不支持-。
 `,
 
 	sampleText: ` 
@@ -665,6 +861,19 @@ var specChineseCopy = SpecText{
 
     ossutil cp oss://bucket/object1 oss://bucket/object2 --tagging "tagA=A&tagB=B"
     copy的同时设置两个tagging,key分别为tagA和tagB,value分别为A和B
+
+    ossutil cp oss://bucket/ oss://bucket1/ --manifest oss://bucket2/inventory/bucket/daily/2024-01-01T00-00Z/manifest.json
+    只拷贝bucket清单报告中列出的objects
+
+    ossutil cp oss://bucket/ oss://bucket1/ --manifest objects.csv
+    拷贝objects.csv中列出的objects，按行指定版本、目标object名称和header
+
+    4) 标准输入和标准输出
+    tar c dir | ossutil cp - oss://bucket/dir.tar -f --part-size 67108864
+    将tar的输出以64MB的分片上传为oss://bucket/dir.tar
+
+    ossutil cp oss://bucket/dir.tar - --parallel 8 | tar x
+    使用8个并发下载oss://bucket/dir.tar并解压
 `,
 }
 
@@ -676,8 +885,10 @@ var specEnglishCopy = SpecText{
 
 	syntaxText: ` 
     ossutil cp file_url cloud_url  [-r] [-f] [-u] [--enable-symlink-dir] [--disable-all-symlink] [--disable-ignore-error] [--only-current-dir] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--snapshot-path=sdir] [--payer requester]
-    ossutil cp cloud_url file_url  [-r] [-f] [-u] [--only-current-dir] [--output-dir=odir] [--disable-ignore-error] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--range=x-y] [--payer requester]
-    ossutil cp cloud_url cloud_url [-r] [-f] [-u] [--only-current-dir] [--output-dir=odir] [--disable-ignore-error] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
+    ossutil cp cloud_url file_url  [-r] [-f] [-u] [--only-current-dir] [--output-dir=odir] [--disable-ignore-error] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--range=x-y] [--payer requester] [--manifest file]
+    ossutil cp cloud_url cloud_url [-r] [-f] [-u] [--only-current-dir] [--output-dir=odir] [--disable-ignore-error] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester] [--manifest file]
+    ossutil cp - cloud_url [-f] [--part-size=size] [--parallel=n] [--payer requester]
+    ossutil cp cloud_url - [--part-size=size] [--parallel=n] [--range=x-y] [--payer requester] [--version-id versionId]
 `,
 
 	detailHelpText: ` 
@@ -745,7 +956,11 @@ var specEnglishCopy = SpecText{
     ?: Matches any single character
     [sequence]: Matches any character in sequence
     [!sequence]: Matches any character not in sequence
-    Note: does not support patterns containing directory info. e.g., --include "/usr/*/test/*.jpg" 
+    **: Matches any directories
+    The pattern containing / or ** matches the path relative to the source directory or the directory of
+    the object prefix, the leading / also starts from the directory, the trailing / matches all the files
+    in the directory, e.g., --include "logs/**/*.gz", --exclude "tmp/". The other patterns only match the
+    file name.
 
     Any number of these parameters can be passed to a command. You can do this by providing an --exclude
     or --include argument multiple times, e.g.,
@@ -776,6 +991,33 @@ var specEnglishCopy = SpecText{
     upload testfile2.txt to oss://my-bucket/path/testfile2.txt
     upload testfile33.jpg to oss://my-bucket/path/testfile33.jpg
 
+--include-regex, --exclude-regex and --exclude-from option
+
+    --include-regex and --exclude-regex match the relative path(the same as above) by the regular
+    expression, it matches any part of the path without ^ and $, e.g., --exclude-regex "\.(tmp|bak)$".
+    --exclude-from reads the exclude rules from the file in .gitignore syntax, like .ossignore: a rule per
+    line, the line starting with # is comment, the rule starting with ! includes the path excluded by the
+    former rules again, the rule ending with / only matches directories, the rule containing / matches
+    from the root, otherwise it matches the name at any level, the file can't be included again if its
+    parent directory is excluded.
+    These options can be mixed with --include and --exclude for multiple times, they are applied from
+    left to right, the whole --exclude-from works as an exclude filter.
+
+--min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag and --content-type option
+
+    Filter the objects to operate by their attributes, they can be used with --include and --exclude,
+    only the objects matching all the conditions are operated.
+    --min-size, --max-size: the range of size, the unit can be B/K/M/G/T, eg: --min-size 1M --max-size 1G
+    --older-than, --newer-than: the range of last modified time, the value is a duration with unit 
+        s/m/h/d/w, eg: 30d, or a time, eg: 2023-01-01, 2023-01-01T08:00:00+08:00
+    --storage-class: the storage classes separated by comma, eg: IA,Archive
+    --has-tag: the tags of the object, the format is k1=v1,k2=v2, only k means the tag of any value
+    --content-type: the content types separated by comma, image/* is supported
+    --has-tag and --content-type send a request for each object, the other conditions are decided by 
+    the listing result without extra requests.
+    Only size and last modified time can filter the files to upload, the source objects are filtered 
+    when downloading and copying.
+
 --meta option
 
     This option will set the specified objects' meta data. If --recursive option is specified, 
@@ -826,6 +1068,150 @@ Incremental Upload/Download/Copy:
     specified or not.
     The option can be used when batch copy failed, skip the succeed files in retry.
 
+--compare option
+
+    Specify how to decide whether the source and the destination are the same in incremental 
+    upload/download/copy, the same files are skipped and the different files are overwritten. 
+    If the option is specified, --update option is not needed, and ossutil will not prompt when 
+    the destination exists. The values are:
+    size:       the same if the sizes are equal
+    mtime:      the same if the destination is not older than the source, the same policy as --update
+    size-mtime: the same if both size and mtime are the same
+    crc64:      the same if both size and crc64 are equal, crc64 of local file is calculated by 
+                ossutil, crc64 of object comes from X-Oss-Hash-Crc64ecma, the object without crc64 
+                is always considered different
+    md5:        the same if both size and md5 are equal, md5 of object comes from Content-Md5 or 
+                the ETag of normal object, the ETag of multipart or appendable object is not md5, 
+                so these objects are always considered different
+
+    crc64 and md5 can detect the changed files which kept their last modified time, and skip the 
+    same files whose last modified time was touched, but the whole local file has to be read to 
+    calculate the checksum.
+
+--dryrun option
+
+    If the option is specified, ossutil only lists the files(or objects) and prints the upload, 
+    download, copy or skip action with the reason one by one, without transferring any data or 
+    updating the snapshot information under --snapshot-path. ossutil will not prompt whether to 
+    overwrite the existing destination. It can be used together with --update, --compare, etc. to 
+    check the result of an incremental operation in advance.
+
+--output-format option
+
+    Print the result in machine readable format, the value can be json, jsonl or csv. If the option 
+    is specified, ossutil prints no progress, but one record for each file(or object), including 
+    the operation type(upload, download or copy), source, destination, size, status(ok, skip or 
+    error) and error message, and a summary record whose type is summary at last. json prints an 
+    array, jsonl prints one json object per line, csv prints the column names in the first line.
+
+--bwlimit option
+
+    Limit the upload and download speed by time of day, the format is "HH:MM,RATE HH:MM,RATE ...", 
+    each rate takes effect from its time until the next time, the last rate is used before the 
+    first time. For example, "08:00,10M 18:00,off" limits the speed to 10MB/s from 8:00 to 18:00, 
+    and no limit at other times. The unit of RATE can be K, M or G(bytes per second), KB/s if no 
+    unit, off means unlimited; RATE can also be UP:DOWN, eg: "512K:off", to limit upload and 
+    download separately. Only one RATE means the limit for the whole day.
+    ossutil checks the schedule every 10 seconds while running, the change applies to the files and 
+    the download parts started after it, the requests in flight keep the old limit, so a long job 
+    can follow the network policy automatically.
+
+    --bwlimit-file option reads the schedule in the same format from the file, the schedule can be 
+    in several lines, and the lines begin with # are comments. ossutil reads the file again when it 
+    receives SIGUSR1(eg: kill -USR1 pid), so that the limit can be adjusted while running, the old 
+    schedule is kept if the file is invalid(not supported on windows). The two options can't be 
+    used together, and can't be used with --maxupspeed or --maxdownspeed.
+
+--resume-job option
+
+    Specifies the id of the job for batch upload, download or copy, the progress of the job is 
+    recorded in its journal, including the listing positions, the finished files and the multipart 
+    uploads in flight. If the job stops or some files fail, run the same command with the same job 
+    id again, ossutil continues from the last listing position without scanning the finished part 
+    again, the finished files which are not modified are skipped, and the multipart uploads continue 
+    from their checkpoints. If the job is completed, it starts over when run again.
+
+    The journals are saved in ` + DefaultJobsDir + ` by default, which can be changed by --jobs-dir option.
+    The checkpoint files of the job are saved in the job directory if --checkpoint-dir is not specified.
+    The jobs command can list, show or cancel the jobs, see help jobs for details. The option only 
+    works with -r, and does not support --dryrun.
+
+--encryption-rsa-public-key, --encryption-rsa-private-key, --encryption-aes-key option
+
+    Client side encryption: when uploading, the data is encrypted locally by AES-256-CTR with a 
+    random data key before it is sent to oss, the data key and iv are encrypted by the master key 
+    and saved in the meta(x-oss-meta-client-side-encryption-*) of the object, which is compatible 
+    with the crypto bucket of oss go sdk. When downloading or cat, the objects encrypted on client 
+    side are decrypted automatically, the ones not encrypted are not affected. In resumable upload, 
+    the part size is aligned to 16 bytes, the checkpoint file keeps the encrypted data key, the 
+    upload continues only if the master key can decrypt the data key, otherwise it starts over.
+
+    The master key is a RSA key pair or an AES key: --encryption-rsa-public-key specifies the 
+    public key file in PEM format for uploading; --encryption-rsa-private-key specifies the private 
+    key file in PEM format for downloading, which is also used for uploading if the public key is 
+    not specified. --encryption-aes-key specifies the AES master key file, the content is the base64 
+    encoded key of 16, 24 or 32 bytes. The data key is wrapped by AES/GCM/NoPadding then, which is not 
+    supported by the crypto bucket of oss sdks, so these objects can only be decrypted by ossutil; 
+    the objects wrapped by the RSA master key can be read by the crypto bucket of oss sdks.
+    --encryption-matdesc specifies the description of the master key in json format, which is saved 
+    in the meta of the object. These options can also be set in the config file, see help config.
+
+    Client side encryption does not change the size of the object, but the md5 and crc64 of the 
+    object are of the cipher text, so the files are always different if --compare is md5 or crc64.
+    When copying between oss, the meta of the object is kept and the data is not encrypted again.
+
+--compress, --compress-include, --decompress option
+
+    If --compress(gzip or zstd) is specified when uploading, the content of the file is compressed 
+    locally and uploaded as a stream without temp file, the Content-Encoding of the object is set to 
+    the algorithm, the size and crc64 of the original content are saved in the meta
+    (x-oss-meta-ossutil-uncompressed-*) of the object. The compressed content is uploaded by PutObject 
+    if it's less than a part, otherwise by multipart upload. The compressed upload is not resumable, 
+    the whole file is uploaded again if it fails. --compress-include specifies the pattern of the file 
+    names to compress, eg: "*.log", it can be specified multiple times, all files are compressed if 
+    not specified, the files not matched(eg: the binaries already compressed) are uploaded as they are.
+
+    If --decompress is specified when downloading, the object whose Content-Encoding is gzip or zstd 
+    is downloaded to a temp file, and then decompressed to the dest file, the size and crc64 of the 
+    original content are validated, the dest file is removed and error is returned if they are 
+    different, the other objects are not affected. The cat command supports --decompress too.
+
+    --update, --compare and mv compare the compressed object by the size and crc64 of the original 
+    content in the meta, the compressed object is always different if --compare is md5. These options 
+    can't be used with client side encryption, and --decompress can't be used with --range.
+
+--auto-tune option
+
+    Adjust the concurrency automatically: ossutil starts with the concurrency of --jobs(default: 3) and 
+    --parallel(default: 2), and adjusts the concurrency of files and parts every 5 seconds by the 
+    observed throughput, request latency and 503, SlowDown, RequestTimeTooSkewed errors. The concurrency 
+    is halved on throttling errors, and increased while the throughput still grows: the concurrency of 
+    files is increased if all the file slots are in use, otherwise the concurrency of parts, up to 64 
+    and 32. If an increase brings no more throughput, it is undone; if the latency grows without 
+    throughput gain, the concurrency is kept. The concurrency of parts takes effect on the files started 
+    after the change. The decisions are logged at debug level(--loglevel debug).
+
+--manifest, --manifest-format option
+
+    If --manifest is specified when downloading or copying, ossutil reads the objects to operate from 
+    the manifest instead of listing src_url, only the objects under the prefix of src_url are operated, 
+    and -r option can be omitted. The manifest is a local file or oss://bucket/object, --manifest-format 
+    specifies its format, it's decided by the extension if not specified:
+        plain: one object per line, blank lines are ignored.
+        csv(.csv): if the first row has a key column, it's the header and the columns key, versionId, 
+    destKey and size are read by names, the other columns are the headers set on the dest object, eg: 
+    Content-Type or x-oss-meta-owner; otherwise the columns are key, versionId and destKey in order.
+        jsonl(.jsonl): one json per line, eg: {"key":"a.txt","versionId":"v1","destKey":"b.txt","meta":{"Content-Type":"text/plain"}}.
+        inventory(.json): the manifest.json of the bucket inventory report(see inventory command), 
+    ossutil reads the csv.gz files listed in it from the destination bucket of the inventory and checks 
+    their md5, the delete markers are skipped, the source bucket of the inventory must be the bucket of 
+    src_url.
+    The version is operated if versionId is specified, the name of the dest file or object is dest_url 
+    plus destKey instead of the relative path of the object if destKey is specified, and the headers only 
+    take effect when copying. The key and destKey in the manifest should be url encoded if --encoding-type 
+    is url, the keys in the inventory are always url encoded. --manifest can't be used for uploading, and 
+    can't be used with --version-id.
+
 --snapshot-path option
 
     This option is used to accelerate the incremental upload of batch files in certain scenarios(
@@ -935,9 +1321,12 @@ Resume copy of big file:
         upload is failed, ossutil will record failure information in .ossutil_checkpoint directory 
         in local file system. When retry, ossutil will read the checkpoint information and resume 
         upload, if the upload is succeed, ossutil will remove the .ossutil_checkpoint directory. 
-    (2) Download object from oss: ossutil will split the big file to many parts, range get each part. 
-        If download is failed, ossutil wll record failure information in .ossutil_checkpoint directory 
-        in local file system. If success, ossutil will remove the directory.
+    (2) Download object from oss: ossutil will split the big file to many parts, range get the parts 
+        over parallel connections and write each part to its offset in a preallocated temp file, then 
+        verify the crc64 of the whole object by combining the crc64 of the parts. The finished parts are 
+        recorded as a bitmap in .ossutil_checkpoint directory, only the unfinished parts are downloaded 
+        the next time. If success, ossutil will remove the directory. Use --parallel to raise the 
+        number of connections.
     (3) Copy between oss: ossutil will split the big file to many parts, use Upload Part Copy, and 
         record failure information in .ossutil_checkpoint directory in local file system. If success, 
         ossutil will remove the directory.
@@ -1009,7 +1398,7 @@ Batch file migration:
 
 Usage:
 
-    There are three usages:
+    There are four usages:
 
     1) ossutil cp file_url oss://bucket[/prefix] [-r] [-f] [-u] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=file] [--snapshot-path=sdir]
         The usage upload file in local system to oss. file_url can be file or directory. If file_url 
@@ -1050,6 +1439,20 @@ Usage:
         If src_url is one object: if prefix of dest_object is empty or end with "/", object name is: dest_url + object name exclude parenet directory path. 
                                   else, object name is: dest_url.
         If src_url means multiple objects: object name is: dest_url+ source object name exclude src_prefix.
+
+    4) ossutil cp - oss://bucket/object or ossutil cp oss://bucket/object -
+        - means stdin or stdout, the usage uploads or downloads a single object in pipelines without temp 
+    file. When uploading from stdin, the length of data is unknown, the data is read by parts of --part-size
+    (default ` + strconv.FormatInt(DefaultStdinPartSize/1024/1024, 10) + `MB), and uploaded by --parallel(default ` + strconv.FormatInt(DefaultStdinParallel, 10) + `) concurrent parts, at most parallel+1 
+    parts are kept in memory, the data less than a part is uploaded by PutObject. The part number can't be 
+    more than ` + strconv.Itoa(MaxPartNum) + `, specify a larger --part-size to upload more data. The upload is not resumable, the 
+    multipart upload is aborted if it fails. As stdin is the data, ossutil doesn't ask whether to overwrite 
+    the existing object, but returns error, specify -f to overwrite it.
+        When downloading to stdout, the object or the range specified by --range is downloaded by --parallel 
+    concurrent ranged GETs, and written to stdout in order, the progress and result are not printed. The 
+    crc64 of the object is validated if the whole object is downloaded.
+        The usage does not support -r, -u, --compare, --snapshot-path, --partition-download, --dryrun, 
+    --resume-job, client side encryption and compression options, mv does not support -.
 `,
 
 	sampleText: ` 
@@ -1239,6 +1642,19 @@ Usage:
 
     ossutil cp oss://bucket/object1 oss://bucket/object2 --tagging "tagA=A&tagB=B"
     Set two taggings when copying, the key is tagA and tagB, and the value is A and B
+
+    ossutil cp oss://bucket/ oss://bucket1/ --manifest oss://bucket2/inventory/bucket/daily/2024-01-01T00-00Z/manifest.json
+    Copy only the objects listed in the bucket inventory report
+
+    ossutil cp oss://bucket/ oss://bucket1/ --manifest objects.csv
+    Copy the objects listed in objects.csv, with the version, dest object name and headers of each row
+
+    4) stdin and stdout
+    tar c dir | ossutil cp - oss://bucket/dir.tar -f --part-size 67108864
+    Upload the output of tar to oss://bucket/dir.tar by parts of 64MB
+
+    ossutil cp oss://bucket/dir.tar - --parallel 8 | tar x
+    Download oss://bucket/dir.tar by 8 concurrent requests and extract it
 `,
 }
 
@@ -1269,11 +1685,24 @@ var copyCommand = CopyCommand{
 			OptionCheckpointDir,
 			OptionRange,
 			OptionEncodingType,
+			OptionManifest,
+			OptionManifestFormat,
 			OptionInclude,
 			OptionExclude,
+			OptionIncludeRegex,
+			OptionExcludeRegex,
+			OptionExcludeFrom,
+			OptionMinSize,
+			OptionMaxSize,
+			OptionOlderThan,
+			OptionNewerThan,
+			OptionStorageClass,
+			OptionHasTag,
+			OptionContentType,
 			OptionMeta,
 			OptionACL,
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -1282,6 +1711,9 @@ var copyCommand = CopyCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionRoutines,
 			OptionParallel,
 			OptionSnapshotPath,
@@ -1307,8 +1739,24 @@ var copyCommand = CopyCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionMaxDownSpeed,
+			OptionBwLimit,
+			OptionBwLimitFile,
+			OptionResumeJob,
+			OptionJobsDir,
+			OptionEncryptionPubKey,
+			OptionEncryptionPriKey,
+			OptionEncryptionAesKey,
+			OptionEncryptionMatDesc,
+			OptionCompress,
+			OptionCompressInclude,
+			OptionDecompress,
+			OptionAutoTune,
 			OptionUserAgent,
 			OptionSignVersion,
 			OptionRegion,
@@ -1316,6 +1764,9 @@ var copyCommand = CopyCommand{
 			OptionForcePathStyle,
 			OptionStartTime,
 			OptionEndTime,
+			OptionCompare,
+			OptionDryRun,
+			OptionOutputFormat,
 		},
 	},
 }
@@ -1342,6 +1793,11 @@ func (cc *CopyCommand) RunCommand() error {
 	cc.cpOption.threshold, _ = GetInt(OptionBigFileThreshold, cc.command.options)
 	cc.cpOption.cpDir, _ = GetString(OptionCheckpointDir, cc.command.options)
 	cc.cpOption.routines, _ = GetInt(OptionRoutines, cc.command.options)
+	// the objects of the manifest are operated in batch as the recursive ones
+	manifest, _ := GetString(OptionManifest, cc.command.options)
+	if manifest != "" {
+		cc.cpOption.recursive = true
+	}
 	cc.cpOption.ctnu = false
 	if cc.cpOption.recursive {
 		disableIgnoreError, _ := GetBool(OptionDisableIgnoreError, cc.command.options)
@@ -1361,16 +1817,35 @@ func (cc *CopyCommand) RunCommand() error {
 	cc.cpOption.onlyCurrentDir, _ = GetBool(OptionOnlyCurrentDir, cc.command.options)
 	cc.cpOption.disableDirObject, _ = GetBool(OptionDisableDirObject, cc.command.options)
 	cc.cpOption.disableAllSymlink, _ = GetBool(OptionDisableAllSymlink, cc.command.options)
+	compare, _ := GetString(OptionCompare, cc.command.options)
+	cc.cpOption.compare = strings.ToLower(compare)
+	cc.cpOption.dryRun = cc.command.isDryRun()
+
+	var err error
+	if cc.cpOption.encryption, err = GetClientEncryption(cc.command.options); err != nil {
+		return err
+	}
+
+	compress, _ := GetString(OptionCompress, cc.command.options)
+	cc.cpOption.compress = strings.ToLower(compress)
+	cc.cpOption.compressInclude, _ = GetStrings(OptionCompressInclude, cc.command.options)
+	cc.cpOption.decompress, _ = GetBool(OptionDecompress, cc.command.options)
+	if cc.cpOption.compress == "" && len(cc.cpOption.compressInclude) > 0 {
+		return fmt.Errorf("--compress-include only work with --compress")
+	}
+	if cc.cpOption.encryption != nil && (cc.cpOption.compress != "" || cc.cpOption.decompress) {
+		return fmt.Errorf("--compress or --decompress can't be used with client side encryption")
+	}
 
 	if cc.cpOption.enableSymlinkDir && cc.cpOption.disableAllSymlink {
 		return fmt.Errorf("--enable-symlink-dir and --disable-all-symlink can't be both exist")
 	}
 
-	var res bool
-	res, cc.cpOption.filters = getFilter(os.Args)
-	if !res {
-		return fmt.Errorf("--include or --exclude does not support format containing dir info")
+	filters, err := getFilter(os.Args)
+	if err != nil {
+		return err
 	}
+	cc.cpOption.filters = filters
 
 	if !cc.cpOption.recursive && len(cc.cpOption.filters) > 0 {
 		return fmt.Errorf("--include or --exclude only work with --recursive")
@@ -1386,13 +1861,20 @@ func (cc *CopyCommand) RunCommand() error {
 		return fmt.Errorf("start time %d is larger than end time %d", cc.cpOption.startTime, cc.cpOption.endTime)
 	}
 
+	if cc.cpOption.attrFilter, err = cc.command.attributeFilter(); err != nil {
+		return err
+	}
+	if !cc.cpOption.recursive && cc.cpOption.attrFilter != nil {
+		return fmt.Errorf("--min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag and --content-type only work with --recursive")
+	}
+
 	//get file list
 	srcURLList, err := cc.getStorageURLs(cc.command.args[0 : len(cc.command.args)-1])
 	if err != nil {
 		return err
 	}
 
-	destURL, err := StorageURLFromString(cc.command.args[len(cc.command.args)-1], cc.cpOption.encodingType)
+	destURL, err := cc.storageURLFromString(cc.command.args[len(cc.command.args)-1])
 	if err != nil {
 		return err
 	}
@@ -1404,6 +1886,11 @@ func (cc *CopyCommand) RunCommand() error {
 	if err := cc.checkCopyOptions(opType); err != nil {
 		return err
 	}
+	if opType != operationTypePut {
+		if cc.cpOption.manifest, err = cc.command.openManifest(srcURLList[0].(CloudURL)); err != nil {
+			return err
+		}
+	}
 
 	cc.cpOption.options = []oss.Option{}
 	if cc.cpOption.meta != "" {
@@ -1455,11 +1942,25 @@ func (cc *CopyCommand) RunCommand() error {
 		cc.cpOption.payerOptions = append(cc.cpOption.payerOptions, oss.RequestPayer(oss.PayerType(payer)))
 	}
 
+	// stdin and stdout are streamed without the batch process and progress bar, stdout is kept for the data only
+	if IsStdioURL(srcURLList[0]) {
+		return cc.uploadFromStdin(destURL.(CloudURL))
+	}
+	if IsStdioURL(destURL) {
+		return cc.downloadToStdout(srcURLList[0].(CloudURL))
+	}
+
 	// init reporter
 	if cc.cpOption.reporter, err = GetReporter(cc.cpOption.recursive, outputDir, commandLine); err != nil {
 		return err
 	}
 
+	// record the progress in the job journal, so that the job can continue where it stopped
+	if err := cc.openJob(destURL, opType); err != nil {
+		return err
+	}
+	defer cc.closeJob()
+
 	// create checkpoint dir
 	if err := os.MkdirAll(cc.cpOption.cpDir, 0755); err != nil {
 
@@ -1501,7 +2002,21 @@ func (cc *CopyCommand) RunCommand() error {
 		cc.cpOption.partitionCount = 0
 	}
 
+	// change the speed limit by the bandwidth schedule while transferring
+	if err := cc.startBandwidthScheduler(); err != nil {
+		return err
+	}
+	defer cc.stopBandwidthScheduler()
+
 	cc.monitor.init(opType)
+	cc.monitor.dryRun = cc.cpOption.dryRun
+
+	// adjust the concurrency by the observed transfer while transferring
+	cc.startAutoTuner()
+	defer cc.stopAutoTuner()
+	cc.cpOption.output = NewOutputWriter(getOutputFormat(cc.command.options), copyResultColumns)
+	cc.monitor.output = cc.cpOption.output
+	defer cc.cpOption.output.Close()
 	cc.cpOption.opType = opType
 
 	chProgressSignal = make(chan chProgressSignalType, 10)
@@ -1519,10 +2034,13 @@ func (cc *CopyCommand) RunCommand() error {
 		LogInfo("begin copyFiles\n")
 		err = cc.copyFiles(srcURLList[0].(CloudURL), destURL.(CloudURL))
 	}
+	cc.endJob(err)
 	endT := time.Now().UnixNano() / 1000 / 1000
 	if endT-startT > 0 {
 		averSpeed := (cc.monitor.transferSize / (endT - startT)) * 1000
-		fmt.Printf("\naverage speed %d(byte/s)\n", averSpeed)
+		if cc.cpOption.output == nil {
+			fmt.Printf("\naverage speed %d(byte/s)\n", averSpeed)
+		}
 		LogInfo("average speed %d(byte/s)\n", averSpeed)
 	}
 
@@ -1538,7 +2056,7 @@ func (cc *CopyCommand) RunCommand() error {
 func (cc *CopyCommand) getStorageURLs(urls []string) ([]StorageURLer, error) {
 	urlList := []StorageURLer{}
 	for _, url := range urls {
-		storageURL, err := StorageURLFromString(url, cc.cpOption.encodingType)
+		storageURL, err := cc.storageURLFromString(url)
 		if err != nil {
 			return nil, err
 		}
@@ -1552,7 +2070,7 @@ func (cc *CopyCommand) getStorageURLs(urls []string) ([]StorageURLer, error) {
 
 func (cc *CopyCommand) getCommandType(srcURLList []StorageURLer, destURL StorageURLer) operationType {
 	if srcURLList[0].IsCloudURL() {
-		if destURL.IsFileURL() {
+		if destURL.IsFileURL() || IsStdioURL(destURL) {
 			return operationTypeGet
 		}
 		return operationTypeCopy
@@ -1569,6 +2087,9 @@ func (cc *CopyCommand) checkCopyArgs(srcURLList []StorageURLer, destURL StorageU
 	if destURL.IsCloudURL() && destURL.(CloudURL).bucket == "" {
 		return fmt.Errorf("invalid cloud url: %s, miss bucket", destURL.ToString())
 	}
+	if err := cc.checkStdioArgs(srcURLList, destURL); err != nil {
+		return err
+	}
 
 	switch opType {
 	case operationTypePut:
@@ -1604,6 +2125,36 @@ func (cc *CopyCommand) checkCopyOptions(opType operationType) error {
 		msg := fmt.Sprintf("only download support option --range")
 		return CommandError{cc.command.name, msg}
 	}
+	if operationTypePut != opType && cc.cpOption.compress != "" {
+		msg := fmt.Sprintf("only upload support option --compress")
+		return CommandError{cc.command.name, msg}
+	}
+	if operationTypeGet != opType && cc.cpOption.decompress {
+		msg := fmt.Sprintf("only download support option --decompress")
+		return CommandError{cc.command.name, msg}
+	}
+	if cc.cpOption.decompress && cc.cpOption.vrange != "" {
+		msg := fmt.Sprintf("option --decompress can't be used with option --range")
+		return CommandError{cc.command.name, msg}
+	}
+	if manifest, _ := GetString(OptionManifest, cc.command.options); manifest != "" {
+		if operationTypePut == opType {
+			msg := fmt.Sprintf("upload doesn't support option --manifest")
+			return CommandError{cc.command.name, msg}
+		}
+		if cc.cpOption.versionId != "" {
+			msg := fmt.Sprintf("option --manifest can't be used with option --version-id, please specify the version of each object in the manifest")
+			return CommandError{cc.command.name, msg}
+		}
+		if cc.cpOption.attrFilter != nil {
+			msg := fmt.Sprintf("option --manifest can't be used with the options filtering by size, time, storage class, tag or content type")
+			return CommandError{cc.command.name, msg}
+		}
+	}
+	if names := cc.cpOption.attrFilter.objectOnly(); operationTypePut == opType && len(names) > 0 {
+		msg := fmt.Sprintf("upload doesn't support option %s, which only filters objects", strings.Join(names, ", "))
+		return CommandError{cc.command.name, msg}
+	}
 	if cc.cpOption.versionId != "" {
 		if operationTypePut == opType {
 			msg := fmt.Sprintf("upload doesn't support option --version-id")
@@ -1617,6 +2168,133 @@ func (cc *CopyCommand) checkCopyOptions(opType operationType) error {
 	return nil
 }
 
+func (cc *CopyCommand) startBandwidthScheduler() error {
+	cc.command.bandwidth = nil
+	schedule, _ := GetString(OptionBwLimit, cc.command.options)
+	scheduleFile, _ := GetString(OptionBwLimitFile, cc.command.options)
+	if schedule == "" && scheduleFile == "" {
+		return nil
+	}
+
+	if schedule != "" && scheduleFile != "" {
+		return fmt.Errorf("--bwlimit and --bwlimit-file can't be both exist")
+	}
+	_, errUp := GetInt(OptionMaxUpSpeed, cc.command.options)
+	_, errDown := GetInt(OptionMaxDownSpeed, cc.command.options)
+	if errUp == nil || errDown == nil {
+		return fmt.Errorf("--bwlimit or --bwlimit-file can't be used with --maxupspeed or --maxdownspeed")
+	}
+
+	bandwidth, err := NewBandwidthScheduler(schedule, scheduleFile)
+	if err != nil {
+		return err
+	}
+	bandwidth.Start(bandwidthCheckInterval)
+	cc.command.bandwidth = bandwidth
+	return nil
+}
+
+// startAutoTuner starts the maximum file level routines, and the tuner decides how many of them transfer at the same time
+func (cc *CopyCommand) startAutoTuner() {
+	cc.cpOption.tuner = nil
+	if autoTune, _ := GetBool(OptionAutoTune, cc.command.options); !autoTune || cc.cpOption.dryRun {
+		return
+	}
+
+	parallel, err := GetInt(OptionParallel, cc.command.options)
+	if err != nil {
+		parallel = autoTuneInitParallel
+	}
+	tuner := NewAutoTuner(int(cc.cpOption.routines), int(parallel), autoTuneMaxJobs, autoTuneMaxParallel, func() int64 {
+		return atomic.LoadInt64(&cc.monitor.transferSize)
+	})
+	if cc.cpOption.routines < autoTuneMaxJobs {
+		cc.cpOption.routines = autoTuneMaxJobs
+	}
+	tuner.Start(autoTuneInterval)
+	cc.cpOption.tuner = tuner
+}
+
+func (cc *CopyCommand) stopAutoTuner() {
+	cc.cpOption.tuner.Stop()
+}
+
+func (cc *CopyCommand) stopBandwidthScheduler() {
+	if cc.command.bandwidth != nil {
+		cc.command.bandwidth.Stop()
+		cc.command.bandwidth = nil
+	}
+}
+
+func (cc *CopyCommand) openJob(destURL StorageURLer, opType operationType) error {
+	jobID, _ := GetString(OptionResumeJob, cc.command.options)
+	if jobID == "" {
+		return nil
+	}
+	if !cc.cpOption.recursive {
+		return fmt.Errorf("--resume-job only works with --recursive")
+	}
+	if cc.cpOption.dryRun {
+		return fmt.Errorf("--resume-job and --dryrun can't be both exist")
+	}
+
+	jobsDir, _ := GetString(OptionJobsDir, cc.command.options)
+	job, err := OpenJob(jobsDir, jobID)
+	if err != nil {
+		return err
+	}
+
+	command := "cp"
+	if cc.cpOption.bSyncCommand {
+		command = "sync"
+	} else if cc.cpOption.move != nil {
+		command = "mv"
+	}
+	cpDir := cc.cpOption.cpDir
+	if cpDir == CheckpointDir {
+		// the checkpoint files of the job are kept in the job directory by default
+		cpDir = ""
+	}
+	destBucket := ""
+	if opType != operationTypeGet {
+		destBucket = destURL.(CloudURL).bucket
+	}
+	if err = job.Start(command, cc.command.args, cpDir, destBucket); err != nil {
+		job.Close()
+		return err
+	}
+
+	cc.cpOption.job = job
+	cc.cpOption.cpDir = job.CheckpointDir()
+	meta := job.Meta()
+	LogInfo("job %s run %d times, checkpoint dir %s\n", meta.ID, meta.RunCount, meta.CheckpointDir)
+	if getOutputFormat(cc.command.options) == "" {
+		if job.Resumed() {
+			fmt.Printf("resume job %s, %d items were done before\n", meta.ID, meta.DoneCount)
+		} else {
+			fmt.Printf("start job %s\n", meta.ID)
+		}
+	}
+	return nil
+}
+
+func (cc *CopyCommand) endJob(err error) {
+	if cc.cpOption.job == nil {
+		return
+	}
+	cc.cpOption.job.End(err)
+	meta := cc.cpOption.job.Meta()
+	LogInfo("job %s %s, done:%d, error:%d\n", meta.ID, meta.Status, meta.DoneCount, meta.ErrorCount)
+	if meta.Status != JobStatusCompleted && cc.cpOption.output == nil {
+		fmt.Printf("\njob %s is not completed, run the same command with --resume-job %s to continue\n", meta.ID, meta.ID)
+	}
+}
+
+func (cc *CopyCommand) closeJob() {
+	cc.cpOption.job.Close()
+	cc.cpOption.job = nil
+}
+
 func (cc *CopyCommand) progressBar() {
 	// fetch all reveal
 	for signal := range chProgressSignal {
@@ -1735,7 +2413,8 @@ func (cc *CopyCommand) fileStatistic(srcURLList []StorageURLer) {
 				return
 			}
 		} else {
-			if cc.filterPath(name, cc.cpOption.cpDir) {
+			dir, fname := filepath.Split(name)
+			if cc.filterPath(name, cc.cpOption.cpDir) && !cc.isJobFileDone(name, fileInfoType{fname, dir}, fname, f) {
 				cc.monitor.updateScanSizeNum(f.Size(), 1)
 			}
 		}
@@ -1763,7 +2442,8 @@ func (cc *CopyCommand) getCurrentDirFilesStatistic(dpath string) error {
 				continue
 			}
 
-			if doesSingleFileMatchPatterns(fileInfo.Name(), cc.cpOption.filters) {
+			if doesSingleFileMatchPatterns(fileInfo.Name(), cc.cpOption.filters) && cc.cpOption.attrFilter.matchFilePath(dpath+fileInfo.Name(), fileInfo) &&
+				!cc.isJobFileDone(dpath, fileInfoType{fileInfo.Name(), dpath}, fileInfo.Name(), fileInfo) {
 				cc.monitor.updateScanSizeNum(fileInfo.Size(), 1)
 			}
 		}
@@ -1777,6 +2457,7 @@ func (cc *CopyCommand) getFileListStatistic(dpath string) error {
 	}
 
 	name := dpath
+	walkRoot := dpath
 	symlinkDiretorys := []string{dpath}
 	walkFunc := func(fpath string, f os.FileInfo, err error) error {
 		if f == nil {
@@ -1797,6 +2478,9 @@ func (cc *CopyCommand) getFileListStatistic(dpath string) error {
 
 		if f.IsDir() {
 			if fpath != dpath {
+				if done, errSkip := cc.jobWalkDone(walkRoot, fileName, true); done {
+					return errSkip
+				}
 				cc.monitor.updateScanNum(1)
 			}
 			return nil
@@ -1831,7 +2515,10 @@ func (cc *CopyCommand) getFileListStatistic(dpath string) error {
 				return nil
 			}
 		}
-		if doesSingleFileMatchPatterns(f.Name(), cc.cpOption.filters) {
+		if doesSingleFileMatchPatterns(fileName, cc.cpOption.filters) && cc.cpOption.attrFilter.matchFilePath(fpath, f) {
+			if cc.isJobFileDone(walkRoot, fileInfoType{fileName, name}, fileName, f) {
+				return nil
+			}
 			cc.monitor.updateScanSizeNum(realFileSize, 1)
 		}
 		return nil
@@ -1842,10 +2529,12 @@ func (cc *CopyCommand) getFileListStatistic(dpath string) error {
 		symlinks := symlinkDiretorys
 		symlinkDiretorys = []string{}
 		for _, v := range symlinks {
+			walkRoot = v
 			err = filepath.Walk(v, walkFunc)
 			if err != nil {
 				return err
 			}
+			cc.cpOption.job.EndStream(v)
 		}
 		if len(symlinkDiretorys) == 0 {
 			break
@@ -1876,7 +2565,8 @@ func (cc *CopyCommand) fileProducer(srcURLList []StorageURLer, chFiles chan<- fi
 			}
 		} else {
 			dir, fname := filepath.Split(name)
-			chFiles <- fileInfoType{fname, dir}
+			cc.sendJobFile(chFiles, name, fileInfoType{fname, dir}, fname, f)
+			cc.cpOption.job.EndStream(name)
 		}
 	}
 	chListError <- nil
@@ -1900,11 +2590,14 @@ func (cc *CopyCommand) getCurrentDirFileList(dpath string, chFiles chan<- fileIn
 				continue
 			}
 
-			if doesSingleFileMatchPatterns(fileInfo.Name(), cc.cpOption.filters) {
-				chFiles <- fileInfoType{fileInfo.Name(), dpath}
+			if doesSingleFileMatchPatterns(fileInfo.Name(), cc.cpOption.filters) && cc.cpOption.attrFilter.matchFilePath(dpath+fileInfo.Name(), fileInfo) {
+				if done, _ := cc.jobWalkDone(dpath, fileInfo.Name(), false); !done {
+					cc.sendJobFile(chFiles, dpath, fileInfoType{fileInfo.Name(), dpath}, fileInfo.Name(), fileInfo)
+				}
 			}
 		}
 	}
+	cc.cpOption.job.EndStream(dpath)
 	return nil
 }
 
@@ -1914,6 +2607,7 @@ func (cc *CopyCommand) getFileList(dpath string, chFiles chan<- fileInfoType) er
 	}
 
 	name := dpath
+	walkRoot := dpath
 	symlinkDiretorys := []string{dpath}
 	walkFunc := func(fpath string, f os.FileInfo, err error) error {
 		if f == nil {
@@ -1930,10 +2624,13 @@ func (cc *CopyCommand) getFileList(dpath string, chFiles chan<- fileInfoType) er
 
 		if f.IsDir() {
 			if fpath != dpath {
+				if done, errSkip := cc.jobWalkDone(walkRoot, fileName, true); done {
+					return errSkip
+				}
 				if strings.HasSuffix(fileName, "\\") || strings.HasSuffix(fileName, "/") {
-					chFiles <- fileInfoType{fileName, name}
+					cc.sendJobFile(chFiles, walkRoot, fileInfoType{fileName, name}, fileName, f)
 				} else {
-					chFiles <- fileInfoType{fileName + string(os.PathSeparator), name}
+					cc.sendJobFile(chFiles, walkRoot, fileInfoType{fileName + string(os.PathSeparator), name}, fileName, f)
 				}
 			}
 			return nil
@@ -1962,8 +2659,10 @@ func (cc *CopyCommand) getFileList(dpath string, chFiles chan<- fileInfoType) er
 			}
 		}
 
-		if doesSingleFileMatchPatterns(fileName, cc.cpOption.filters) {
-			chFiles <- fileInfoType{fileName, name}
+		if doesSingleFileMatchPatterns(fileName, cc.cpOption.filters) && cc.cpOption.attrFilter.matchFilePath(fpath, f) {
+			if done, _ := cc.jobWalkDone(walkRoot, fileName, false); !done {
+				cc.sendJobFile(chFiles, walkRoot, fileInfoType{fileName, name}, fileName, f)
+			}
 		}
 		return nil
 	}
@@ -1973,10 +2672,12 @@ func (cc *CopyCommand) getFileList(dpath string, chFiles chan<- fileInfoType) er
 		symlinks := symlinkDiretorys
 		symlinkDiretorys = []string{}
 		for _, v := range symlinks {
+			walkRoot = v
 			err = filepath.Walk(v, walkFunc)
 			if err != nil {
 				return err
 			}
+			cc.cpOption.job.EndStream(v)
 		}
 		if len(symlinkDiretorys) == 0 {
 			break
@@ -1985,10 +2686,65 @@ func (cc *CopyCommand) getFileList(dpath string, chFiles chan<- fileInfoType) er
 	return err
 }
 
+// jobWalkDone returns true if the path is not after the marker of the job, the directory which is
+// not an ancestor of the marker is skipped as a whole unless the symlink directories are followed.
+func (cc *CopyCommand) jobWalkDone(stream, relPath string, isDir bool) (bool, error) {
+	marker := cc.cpOption.job.Marker(stream)
+	if marker == "" || comparePathInWalkOrder(relPath, marker) > 0 {
+		return false, nil
+	}
+	if isDir && !cc.cpOption.enableSymlinkDir && !isAncestorPath(relPath, marker) {
+		return true, filepath.SkipDir
+	}
+	return true, nil
+}
+
+// isJobFileDone returns true if the file is uploaded in the job before
+func (cc *CopyCommand) isJobFileDone(stream string, file fileInfoType, position string, f os.FileInfo) bool {
+	if done, _ := cc.jobWalkDone(stream, position, false); done {
+		return true
+	}
+	return cc.cpOption.job.IsDone(fileJobKey(file), fileJobSignature(f))
+}
+
+func (cc *CopyCommand) sendJobFile(chFiles chan<- fileInfoType, stream string, file fileInfoType, position string, f os.FileInfo) {
+	if cc.cpOption.job.Add(stream, fileJobKey(file), position, fileJobSignature(f)) {
+		chFiles <- file
+	}
+}
+
+func fileJobKey(file fileInfoType) string {
+	return file.dir + "\x00" + file.filePath
+}
+
+func fileJobSignature(f os.FileInfo) string {
+	return fmt.Sprintf("%d,%d", f.Size(), f.ModTime().UnixNano())
+}
+
+// comparePathInWalkOrder compares the relative paths in the order of filepath.Walk,
+// which visits the entries of a directory in lexical order and the directory before its entries.
+func comparePathInWalkOrder(a, b string) int {
+	partsA := strings.Split(filepath.ToSlash(a), "/")
+	partsB := strings.Split(filepath.ToSlash(b), "/")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		if partsA[i] != partsB[i] {
+			return strings.Compare(partsA[i], partsB[i])
+		}
+	}
+	return len(partsA) - len(partsB)
+}
+
+func isAncestorPath(dir, path string) bool {
+	return dir == path || strings.HasPrefix(filepath.ToSlash(path), filepath.ToSlash(dir)+"/")
+}
+
 func (cc *CopyCommand) uploadConsumer(bucket *oss.Bucket, destURL CloudURL, chFiles <-chan fileInfoType, chError chan<- error) {
 	for file := range chFiles {
 		if cc.filterFile(file, cc.cpOption.cpDir) {
+			cc.cpOption.tuner.acquire()
 			err := cc.uploadFileWithReport(bucket, destURL, file)
+			cc.cpOption.tuner.release()
+			cc.cpOption.job.Done(fileJobKey(file), err)
 			if err != nil {
 				chError <- err
 				if !cc.cpOption.ctnu {
@@ -1996,6 +2752,8 @@ func (cc *CopyCommand) uploadConsumer(bucket *oss.Bucket, destURL CloudURL, chFi
 				}
 				continue
 			}
+		} else {
+			cc.cpOption.job.Done(fileJobKey(file), nil)
 		}
 	}
 
@@ -2025,7 +2783,7 @@ func (cc *CopyCommand) filterPath(filePath string, cpDir string) bool {
 
 func (cc *CopyCommand) uploadFileWithReport(bucket *oss.Bucket, destURL CloudURL, file fileInfoType) error {
 	startT := time.Now()
-	skip, err, isDir, size, msg := cc.uploadFile(bucket, destURL, file)
+	skip, err, isDir, size, msg, reason := cc.uploadFile(bucket, destURL, file)
 	cost := time.Now().UnixNano()/1000/1000 - startT.UnixNano()/1000/1000
 
 	if err != nil {
@@ -2048,12 +2806,26 @@ func (cc *CopyCommand) uploadFileWithReport(bucket *oss.Bucket, destURL CloudURL
 		}
 	}
 
+	if cc.cpOption.move != nil {
+		cc.moveAfterUpload(bucket, cc.makeObjectName(destURL, file), file, skip, isDir, reason, err)
+	}
+
 	cc.updateMonitor(skip, err, isDir, size)
 	cc.report(msg, err)
+	if cc.cpOption.output != nil {
+		srcPath := filepath.Join(file.dir, file.filePath)
+		var srcSize int64 = -1
+		if f, errF := os.Stat(srcPath); errF == nil && !f.IsDir() {
+			srcSize = f.Size()
+		}
+		cc.writeResult(opUpload, srcPath, CloudURLToString(bucket.BucketName, cc.makeObjectName(destURL, file)), srcSize, skip, err)
+	}
 	return err
 }
 
-func (cc *CopyCommand) uploadFile(bucket *oss.Bucket, destURL CloudURL, file fileInfoType) (skip bool, rerr error, isDir bool, size int64, msg string) {
+func (cc *CopyCommand) uploadFile(bucket *oss.Bucket, destURL CloudURL, file fileInfoType) (skip bool, rerr error, isDir bool, size int64, msg, reason string) {
+	bucket = cc.command.bandwidthBucket(bucket)
+
 	//first make object name
 	objectName := cc.makeObjectName(destURL, file)
 
@@ -2080,7 +2852,8 @@ func (cc *CopyCommand) uploadFile(bucket *oss.Bucket, destURL CloudURL, file fil
 	srct := f.ModTime().Unix()
 	absPath, _ := filepath.Abs(filePath)
 	spath := cc.formatSnapshotKey(absPath, destURL.bucket, objectName)
-	if skip, rerr = cc.skipUpload(spath, bucket, objectName, destURL, srct); rerr != nil || skip {
+	if skip, reason, rerr = cc.skipUpload(spath, bucket, objectName, destURL, filePath, f); rerr != nil || skip {
+		cc.dryRunSkip(skip, msg, reason)
 		return
 	}
 
@@ -2088,7 +2861,12 @@ func (cc *CopyCommand) uploadFile(bucket *oss.Bucket, destURL CloudURL, file fil
 	if f.IsDir() {
 		isDir = true
 		if cc.cpOption.disableDirObject {
-			skip = true
+			skip, reason = true, reasonDirObjectDisabled
+			cc.dryRunSkip(skip, msg, reason)
+			return
+		}
+		if cc.cpOption.dryRun {
+			cc.printDryRun(msg, reason)
 			return
 		}
 		rerr = cc.ossPutObjectRetry(bucket, objectName, "")
@@ -2098,9 +2876,14 @@ func (cc *CopyCommand) uploadFile(bucket *oss.Bucket, destURL CloudURL, file fil
 		return
 	}
 
+	if cc.cpOption.dryRun {
+		cc.printDryRun(msg, reason)
+		return
+	}
+
 	size = 0
-	//decide whether to use resume upload
-	if f.Size() < cc.cpOption.threshold {
+	//decide whether to use resume upload, the compressed content is streamed by ossUploadFileRetry
+	if f.Size() < cc.cpOption.threshold || cc.compressAlgorithm(filePath) != "" {
 		var listener *OssProgressListener = &OssProgressListener{&cc.monitor, 0, 0, false}
 		options := cc.cpOption.options
 		options = append(options, oss.Progress(listener))
@@ -2139,43 +2922,46 @@ func (cc *CopyCommand) makeObjectName(destURL CloudURL, file fileInfoType) strin
 	return destURL.object
 }
 
-func (cc *CopyCommand) skipUpload(spath string, bucket *oss.Bucket, objectName string, destURL CloudURL, srcModifiedTime int64) (bool, error) {
+func (cc *CopyCommand) skipUpload(spath string, bucket *oss.Bucket, objectName string, destURL CloudURL, filePath string, f os.FileInfo) (bool, string, error) {
+	srcModifiedTime := f.ModTime().Unix()
 	if cc.cpOption.startTime > 0 && srcModifiedTime < cc.cpOption.startTime {
-		return true, nil
+		return true, reasonBeforeStartTime, nil
 	}
 
 	if cc.cpOption.endTime > 0 && srcModifiedTime > cc.cpOption.endTime {
-		return true, nil
+		return true, reasonAfterEndTime, nil
 	}
 
-	if cc.cpOption.snapshotPath != "" || cc.cpOption.update {
+	if cc.cpOption.snapshotPath != "" || cc.isIncremental() {
 		if cc.cpOption.snapshotPath != "" {
 			tstr, err := cc.cpOption.snapshotldb.Get([]byte(spath), nil)
 			if err == nil {
 				t, _ := strconv.ParseInt(string(tstr), 10, 64)
 				if t == srcModifiedTime {
-					return true, nil
+					return true, reasonSnapshot, nil
 				}
 			}
 		}
-		if cc.cpOption.update {
+		if cc.isIncremental() {
 			if props, err := cc.command.ossGetObjectStatRetry(bucket, objectName, cc.cpOption.payerOptions...); err == nil {
-				destt, err := time.Parse(http.TimeFormat, props.Get(oss.HTTPHeaderLastModified))
-				if err == nil && destt.Unix() >= srcModifiedTime {
-					return true, nil
+				if f.IsDir() && cc.cpOption.compare != "" {
+					// dir object has no content, it's the same as long as it exists
+					return true, cc.compareReason(true), nil
 				}
+				// the compressed object is compared by its original content
+				same, err := cc.isSameFile(cc.localCompareInfo(filePath, f), decompressedCompareInfo(props, remoteCompareInfo(props)))
+				return same, cc.compareReason(same), err
 			}
+			return false, reasonNotExist, nil
 		}
 	} else if !cc.cpOption.force {
 		if _, err := cc.command.ossGetObjectMetaRetry(bucket, objectName, cc.cpOption.payerOptions...); err == nil {
-			if !cc.confirm(CloudURLToString(destURL.bucket, objectName)) {
-				return true, nil
-			}
+			return cc.confirmOverwrite(CloudURLToString(destURL.bucket, objectName))
 		}
+		return false, reasonNotExist, nil
 	}
-	return false, nil
+	return false, "", nil
 }
-
 func (cc *CopyCommand) formatSnapshotKey(absPath, bucket, object string) string {
 	return absPath + SnapshotConnector + CloudURLToString(bucket, object)
 }
@@ -2192,58 +2978,67 @@ func (cc *CopyCommand) confirm(str string) bool {
 	return true
 }
 
+// confirmOverwrite asks user whether to overwrite the existing dest, never asks in dry run mode
+func (cc *CopyCommand) confirmOverwrite(str string) (bool, string, error) {
+	if cc.cpOption.dryRun {
+		return false, reasonNeedConfirm, nil
+	}
+	if !cc.confirm(str) {
+		return true, reasonNotConfirmed, nil
+	}
+	return false, reasonOverwrite, nil
+}
 func (cc *CopyCommand) ossPutObjectRetry(bucket *oss.Bucket, objectName string, content string) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, cc.command.options)
-	for i := 1; ; i++ {
-		if i > 1 {
-			time.Sleep(time.Duration(3) * time.Second)
-			if int64(i) >= retryTimes {
-				fmt.Printf("\nretry count:%d:put object:%s.\n", i-1, objectName)
-			}
-		}
-
-		err := bucket.PutObject(objectName, strings.NewReader(content), cc.cpOption.options...)
-		if err == nil {
-			return err
-		}
-
-		// http 4XX error no need to retry
-		// only network error or internal error need to retry
-		serviceError, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-			return ObjectError{err, bucket.BucketName, objectName}
+	policy := cc.command.retryPolicy()
+	err := policy.Do(func(attempt int, respHeader *http.Header) error {
+		if attempt > 1 && int64(attempt) >= policy.MaxAttempts {
+			fmt.Printf("\nretry count:%d:put object:%s.\n", attempt-1, objectName)
 		}
+		return bucket.PutObject(objectName, strings.NewReader(content), withResponseHeader(cc.cpOption.options, respHeader)...)
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, objectName}
 	}
+	return nil
 }
 
 func (cc *CopyCommand) ossUploadFileRetry(bucket *oss.Bucket, objectName string, filePath string, options ...oss.Option) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, cc.command.options)
-	for i := 1; ; i++ {
-		if i > 1 {
-			time.Sleep(time.Duration(3) * time.Second)
-			if int64(i) >= retryTimes {
-				fmt.Printf("\nretry count:%d:upload file:%s\n", i-1, filePath)
-			}
+	if algorithm := cc.compressAlgorithm(filePath); algorithm != "" && cc.cpOption.encryption == nil {
+		// the requests of the compressed content are retried one by one
+		if err := cc.compressedPutObjectFromFile(bucket, objectName, filePath, algorithm, options...); err != nil {
+			LogError("upload compressed file error %s,error:%s\n", filePath, err.Error())
+			return FileError{err, filePath}
+		}
+		return nil
+	}
+
+	policy := cc.command.retryPolicy()
+	err := policy.Do(func(attempt int, respHeader *http.Header) error {
+		if attempt > 1 && int64(attempt) >= policy.MaxAttempts {
+			fmt.Printf("\nretry count:%d:upload file:%s\n", attempt-1, filePath)
 		}
 
 		startT := time.Now()
-		err := bucket.PutObjectFromFile(objectName, filePath, options...)
+		var err error
+		if cc.cpOption.encryption != nil {
+			err = cc.encryptedPutObjectFromFile(bucket, objectName, filePath, options...)
+		} else {
+			err = bucket.PutObjectFromFile(objectName, filePath, withResponseHeader(options, respHeader)...)
+		}
 		cost := time.Now().UnixNano()/1000/1000 - startT.UnixNano()/1000/1000
+		cc.cpOption.tuner.observe(err, time.Since(startT))
 
 		if err == nil {
-			LogDebug("try count:%d,upload file sucess %s,cost:%d(ms)\n", i, filePath, cost)
-			return err
+			LogDebug("try count:%d,upload file sucess %s,cost:%d(ms)\n", attempt, filePath, cost)
 		} else {
-			LogError("try count:%d,upload file error %s,cost:%d(ms),error:%s\n", i, filePath, cost, err.Error())
-		}
-
-		// http 4XX error no need to retry
-		// only network error or internal error need to retry
-		serviceError, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-			return FileError{err, filePath}
+			LogError("try count:%d,upload file error %s,cost:%d(ms),error:%s\n", attempt, filePath, cost, err.Error())
 		}
+		return err
+	})
+	if err != nil {
+		return FileError{err, filePath}
 	}
+	return nil
 }
 
 func (cc *CopyCommand) preparePartOption(fileSize int64) (int64, int) {
@@ -2255,6 +3050,9 @@ func (cc *CopyCommand) preparePartOption(fileSize int64) (int64, int) {
 		partNum = (fileSize-1)/partSize + 1
 	}
 
+	if cc.cpOption.tuner != nil {
+		return partSize, cc.cpOption.tuner.partRoutines(partNum)
+	}
 	if parallel, err := GetInt(OptionParallel, cc.command.options); err == nil {
 		return partSize, int(parallel)
 	}
@@ -2298,28 +3096,32 @@ func (cc *CopyCommand) calcPartSize(fileSize int64) (int64, int64) {
 }
 
 func (cc *CopyCommand) ossResumeUploadRetry(bucket *oss.Bucket, objectName string, filePath string, partSize int64, options ...oss.Option) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, cc.command.options)
-	for i := 1; ; i++ {
-		if i > 1 {
-			time.Sleep(time.Duration(3) * time.Second)
-			if int64(i) >= retryTimes {
-				fmt.Printf("\nretry count:%d,multipart upload file:%s.\n", i-1, filePath)
-			}
+	policy := cc.command.retryPolicy().retryAnyError()
+	err := policy.Do(func(attempt int, respHeader *http.Header) error {
+		if attempt > 1 && int64(attempt) >= policy.MaxAttempts {
+			fmt.Printf("\nretry count:%d,multipart upload file:%s.\n", attempt-1, filePath)
 		}
 		startT := time.Now()
-		err := bucket.UploadFile(objectName, filePath, partSize, options...)
+		var err error
+		if cc.cpOption.encryption != nil {
+			err = cc.encryptedUploadFile(bucket, objectName, filePath, partSize, options...)
+		} else {
+			err = bucket.UploadFile(objectName, filePath, partSize, options...)
+		}
 		cost := time.Now().UnixNano()/1000/1000 - startT.UnixNano()/1000/1000
+		cc.cpOption.tuner.observe(err, time.Since(startT))
 
 		if err == nil {
-			LogDebug("try count:%d,multipart upload file sucess %s,cost:%d(ms)\n", i, filePath, cost)
-			return err
+			LogDebug("try count:%d,multipart upload file sucess %s,cost:%d(ms)\n", attempt, filePath, cost)
 		} else {
-			LogError("try count:%d,multipart upload file error %s,cost:%d(ms),error:%s\n", i, filePath, cost, err.Error())
-		}
-		if int64(i) >= retryTimes {
-			return FileError{err, filePath}
+			LogError("try count:%d,multipart upload file error %s,cost:%d(ms),error:%s\n", attempt, filePath, cost, err.Error())
 		}
+		return err
+	})
+	if err != nil {
+		return FileError{err, filePath}
 	}
+	return nil
 }
 
 func (cc *CopyCommand) report(msg string, err error) {
@@ -2329,6 +3131,21 @@ func (cc *CopyCommand) report(msg string, err error) {
 	}
 }
 
+// writeResult writes the machine readable record of one file(object), size < 0 means unknown
+func (cc *CopyCommand) writeResult(op, src, dest string, size int64, skip bool, err error) {
+	record := OutputRecord{"type": op, "src": src, "dest": dest, "status": "ok"}
+	if size >= 0 {
+		record["size"] = size
+	}
+	if err != nil {
+		record["status"] = "error"
+		record["error"] = err.Error()
+	} else if skip {
+		record["status"] = "skip"
+	}
+	cc.cpOption.output.Write(record)
+}
+
 func (cc *CopyCommand) updateMonitor(skip bool, err error, isDir bool, size int64) {
 	if err != nil {
 		cc.monitor.updateErr(0, 1)
@@ -2406,7 +3223,7 @@ func (cc *CopyCommand) downloadFiles(srcURL CloudURL, destURL FileURL) error {
 		}
 
 		go cc.objectStatistic(bucket, srcURL)
-		err := cc.downloadSingleFileWithReport(bucket, objectInfoType{prefix, relativeKey, -1, time.Now()}, filePath)
+		err := cc.downloadSingleFileWithReport(bucket, objectInfoType{prefix: prefix, relativeKey: relativeKey, size: -1, lastModified: time.Now()}, filePath)
 		return cc.formatResultPrompt(err)
 	}
 	return cc.batchDownloadFiles(bucket, srcURL, filePath)
@@ -2446,7 +3263,7 @@ func (cc *CopyCommand) adjustDestURLForDownload(destURL FileURL) (string, error)
 		}
 	}
 
-	if strings.HasSuffix(filePath, "/") || strings.HasSuffix(filePath, "\\") {
+	if (strings.HasSuffix(filePath, "/") || strings.HasSuffix(filePath, "\\")) && !cc.cpOption.dryRun {
 		if err := os.MkdirAll(filePath, 0755); err != nil {
 			return filePath, err
 		}
@@ -2456,7 +3273,7 @@ func (cc *CopyCommand) adjustDestURLForDownload(destURL FileURL) (string, error)
 
 func (cc *CopyCommand) downloadSingleFileWithReport(bucket *oss.Bucket, objectInfo objectInfoType, filePath string) error {
 	startT := time.Now()
-	skip, err, size, msg := cc.downloadSingleFile(bucket, objectInfo, filePath)
+	skip, err, size, msg, reason := cc.downloadSingleFile(bucket, objectInfo, filePath)
 	cost := time.Now().UnixNano()/1000/1000 - startT.UnixNano()/1000/1000
 	var realSize int64 = objectInfo.size
 	if err != nil {
@@ -2476,77 +3293,124 @@ func (cc *CopyCommand) downloadSingleFileWithReport(bucket *oss.Bucket, objectIn
 		if cost > 0 {
 			speed = (float64(realSize) / 1024) / (float64(cost) / 1000)
 		}
-		objectKey := objectInfo.prefix + objectInfo.relativeKey
+		objectKey := objectInfo.key()
 		LogInfo("download success,object:%s,size:%d,speed:%.2f(KB/s),cost:%d(ms)\n", objectKey, realSize, speed, cost)
 		cc.updateSnapshot(nil, CloudURLToString(bucket.BucketName, objectKey), objectInfo.lastModified.Unix())
 	}
 
+	if cc.cpOption.move != nil {
+		cc.moveAfterDownload(bucket, objectInfo, cc.makeFileName(objectInfo.relativeKey, filePath), skip, reason, err)
+	}
+
 	cc.updateMonitor(skip, err, false, size)
 	cc.report(msg, err)
+	if cc.cpOption.output != nil {
+		objectKey := objectInfo.key()
+		cc.writeResult(opDownload, CloudURLToString(bucket.BucketName, objectKey), cc.makeFileName(objectInfo.relativeKey, filePath), realSize, skip, err)
+	}
 	return err
 }
 
-func (cc *CopyCommand) downloadSingleFile(bucket *oss.Bucket, objectInfo objectInfoType, filePath string) (bool, error, int64, string) {
+func (cc *CopyCommand) downloadSingleFile(bucket *oss.Bucket, objectInfo objectInfoType, filePath string) (bool, error, int64, string, string) {
+	bucket = cc.command.bandwidthBucket(bucket)
+
 	//get object size and last modify time
-	object := objectInfo.prefix + objectInfo.relativeKey
+	object := objectInfo.key()
 	size := objectInfo.size
 	srct := objectInfo.lastModified
 	//make file name
 	fileName := cc.makeFileName(objectInfo.relativeKey, filePath)
 	msg := fmt.Sprintf("%s %s to %s", opDownload, CloudURLToString(bucket.BucketName, object), fileName)
 
+	statOptions := cc.objectStatOptions(objectInfo)
 	if size < 0 {
-		statOptions := cc.cpOption.payerOptions
-		if cc.cpOption.versionId != "" {
-			statOptions = append(statOptions, oss.VersionId(cc.cpOption.versionId))
-		}
 		props, err := cc.command.ossGetObjectStatRetry(bucket, object, statOptions...)
 		if err != nil {
-			return false, err, size, msg
+			return false, err, size, msg, ""
 		}
 		size, err = strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64)
 		if err != nil {
-			return false, err, size, msg
+			return false, err, size, msg, ""
 		}
 		if srct, err = time.Parse(http.TimeFormat, props.Get(oss.HTTPHeaderLastModified)); err != nil {
-			return false, err, size, msg
+			return false, err, size, msg, ""
 		}
 	}
 
 	rsize := cc.getRangeSize(size)
-	if cc.skipDownload(fileName, srct, CloudURLToString(bucket.BucketName, object)) {
-		return true, nil, rsize, msg
+	skip, reason, err := cc.skipDownload(bucket, object, fileName, size, srct, statOptions)
+	if err != nil || skip {
+		cc.dryRunSkip(skip, msg, reason)
+		return skip, err, rsize, msg, reason
+	}
+
+	if cc.cpOption.dryRun {
+		cc.printDryRun(msg, reason)
+		return false, nil, rsize, msg, reason
 	}
 
 	if size == 0 && strings.HasSuffix(object, "/") {
-		return false, os.MkdirAll(fileName, 0755), rsize, msg
+		return false, os.MkdirAll(fileName, 0755), rsize, msg, reason
 	}
 
 	//create parent directory
 	if err := cc.createParentDirectory(fileName); err != nil {
-		return false, err, rsize, msg
+		return false, err, rsize, msg, reason
+	}
+
+	// the cipher text of the object encrypted on client side is downloaded to the temp file,
+	// and decrypted after the download completes
+	var envelope *ClientEnvelope
+	downloadName := fileName
+	if cc.cpOption.encryption != nil {
+		if envelope, err = cc.openObjectEnvelope(bucket, object); err != nil {
+			return false, err, rsize, msg, reason
+		}
+		if envelope != nil {
+			downloadName = fileName + EncryptedTempFileSuffix
+		}
+	}
+
+	// the compressed object is downloaded to the temp file as it is, and decompressed after the download completes
+	var compressedProps http.Header
+	if cc.cpOption.decompress {
+		if compressedProps, err = cc.statCompressedObject(bucket, object); err != nil {
+			return false, err, rsize, msg, reason
+		}
+		if compressedProps != nil {
+			downloadName = fileName + CompressedTempFileSuffix
+		}
 	}
 
 	downloadOptions := cc.cpOption.options
+	if objectInfo.entry != nil && objectInfo.entry.VersionId != "" {
+		downloadOptions = append(downloadOptions, oss.VersionId(objectInfo.entry.VersionId))
+	}
 	if cc.cpOption.vrange != "" {
 		downloadOptions = append(downloadOptions, oss.NormalizedRange(cc.cpOption.vrange))
 	}
+	if compressedProps != nil {
+		// or the http client decompresses the gzip content transparently
+		downloadOptions = append(downloadOptions, oss.AcceptEncoding("identity"))
+	}
 
 	if rsize < cc.cpOption.threshold {
 		var listener *OssProgressListener = &OssProgressListener{&cc.monitor, 0, 0, false}
 		downloadOptions = append(downloadOptions, oss.Progress(listener))
-		return false, cc.ossDownloadFileRetry(bucket, object, fileName, downloadOptions...), 0, msg
+		err = cc.ossDownloadFileRetry(bucket, object, downloadName, downloadOptions...)
+	} else {
+		var listener *OssResumeProgressListener = &OssResumeProgressListener{&cc.monitor, 0, 0, false, false}
+		downloadOptions = append(downloadOptions, oss.Progress(listener))
+		err = cc.ossParallelDownload(bucket, object, downloadName, downloadOptions...)
 	}
 
-	var listener *OssResumeProgressListener = &OssResumeProgressListener{&cc.monitor, 0, 0, false, false}
-	downloadOptions = append(downloadOptions, oss.Progress(listener))
-
-	partSize, rt := cc.preparePartOption(size)
-	cp := oss.CheckpointDir(true, cc.cpOption.cpDir)
-	LogInfo("multipart download,object %s,file size:%d,partSize %d,routin count:%d,checkpoint dir:%s\n",
-		object, size, partSize, rt, cc.cpOption.cpDir)
-	downloadOptions = append(downloadOptions, oss.Routines(rt), cp)
-	return false, cc.ossResumeDownloadRetry(bucket, object, fileName, size, partSize, downloadOptions...), 0, msg
+	if err == nil && envelope != nil {
+		err = cc.decryptDownloadedFile(envelope, downloadName, fileName, size)
+	}
+	if err == nil && compressedProps != nil {
+		err = cc.decompressDownloadedFile(compressedProps, downloadName, fileName)
+	}
+	return false, err, 0, msg, reason
 }
 
 func (cc *CopyCommand) makeFileName(relativeObject, filePath string) string {
@@ -2556,44 +3420,65 @@ func (cc *CopyCommand) makeFileName(relativeObject, filePath string) string {
 	return filePath
 }
 
-func (cc *CopyCommand) skipDownload(fileName string, srcModifiedTime time.Time, object string) bool {
+func (cc *CopyCommand) skipDownload(bucket *oss.Bucket, object, fileName string, size int64, srcModifiedTime time.Time, statOptions []oss.Option) (bool, string, error) {
 	if cc.cpOption.startTime > 0 && srcModifiedTime.Unix() < cc.cpOption.startTime {
-		return true
+		return true, reasonBeforeStartTime, nil
 	}
 
 	if cc.cpOption.endTime > 0 && srcModifiedTime.Unix() > cc.cpOption.endTime {
-		return true
+		return true, reasonAfterEndTime, nil
 	}
 
-	if cc.cpOption.snapshotPath != "" || cc.cpOption.update {
+	if cc.cpOption.snapshotPath != "" || cc.isIncremental() {
 		if cc.cpOption.snapshotPath != "" {
-			tstr, err := cc.cpOption.snapshotldb.Get([]byte(object), nil)
+			tstr, err := cc.cpOption.snapshotldb.Get([]byte(CloudURLToString(bucket.BucketName, object)), nil)
 			if err == nil {
 				t, _ := strconv.ParseInt(string(tstr), 10, 64)
 				if t == srcModifiedTime.Unix() {
-					return true
+					return true, reasonSnapshot, nil
 				}
 			}
 		}
 
 		if f, err := os.Stat(fileName); err == nil {
-			destt := f.ModTime()
-			if destt.Unix() >= srcModifiedTime.Unix() {
-				return true
+			if f.IsDir() {
+				return true, reasonDestIsDir, nil
 			}
+			srcInfo := compareInfoType{size: size, modifiedTime: srcModifiedTime.Unix()}
+			if cc.cpOption.decompress {
+				// the decompressed file is compared with the original content, which is recorded in the user meta
+				props, err := cc.command.ossGetObjectStatRetry(bucket, object, statOptions...)
+				if err != nil {
+					return false, "", err
+				}
+				srcInfo = decompressedCompareInfo(props, remoteCompareInfo(props))
+				srcInfo.modifiedTime = srcModifiedTime.Unix()
+			} else if cc.needObjectHash() {
+				props, err := cc.command.ossGetObjectStatRetry(bucket, object, statOptions...)
+				if err != nil {
+					return false, "", err
+				}
+				srcInfo = remoteCompareInfo(props)
+				srcInfo.size = size
+				srcInfo.modifiedTime = srcModifiedTime.Unix()
+			}
+			same, err := cc.isSameFile(srcInfo, cc.localCompareInfo(fileName, f))
+			return same, cc.compareReason(same), err
 		}
+		return false, reasonNotExist, nil
 	} else {
 		if !cc.cpOption.force {
 			if fileInfo, err := os.Stat(fileName); err == nil {
-				if fileInfo.IsDir() || !cc.confirm(fileName) {
-					return true
+				if fileInfo.IsDir() {
+					return true, reasonDestIsDir, nil
 				}
+				return cc.confirmOverwrite(fileName)
 			}
+			return false, reasonNotExist, nil
 		}
 	}
-	return false
+	return false, "", nil
 }
-
 func (cc *CopyCommand) createParentDirectory(fileName string) error {
 	dir, err := filepath.Abs(filepath.Dir(fileName))
 	if err != nil {
@@ -2604,68 +3489,48 @@ func (cc *CopyCommand) createParentDirectory(fileName string) error {
 }
 
 func (cc *CopyCommand) ossDownloadFileRetry(bucket *oss.Bucket, objectName, fileName string, options ...oss.Option) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, cc.command.options)
-	for i := 1; ; i++ {
-		if i > 1 {
-			time.Sleep(time.Duration(3) * time.Second)
-			if int64(i) >= retryTimes {
-				fmt.Printf("\nretry count:%d:get object to file:%s.\n", i-1, fileName)
-			}
+	policy := cc.command.retryPolicy()
+	err := policy.Do(func(attempt int, respHeader *http.Header) error {
+		if attempt > 1 && int64(attempt) >= policy.MaxAttempts {
+			fmt.Printf("\nretry count:%d:get object to file:%s.\n", attempt-1, fileName)
 		}
 
 		startT := time.Now()
-		err := bucket.GetObjectToFile(objectName, fileName, options...)
+		err := bucket.GetObjectToFile(objectName, fileName, withResponseHeader(options, respHeader)...)
 		cost := time.Now().UnixNano()/1000/1000 - startT.UnixNano()/1000/1000
+		cc.cpOption.tuner.observe(err, time.Since(startT))
 
 		if err == nil {
-			LogDebug("try count:%d,GetObjectToFile sucess %s,cost:%d(ms)\n", i, fileName, cost)
-			return err
+			LogDebug("try count:%d,GetObjectToFile sucess %s,cost:%d(ms)\n", attempt, fileName, cost)
 		} else {
-			LogError("try count:%d,GetObjectToFile error %s,cost:%d(ms),error:%s\n", i, fileName, cost, err.Error())
-		}
-
-		// http 4XX error no need to retry
-		// only network error or internal error need to retry
-		serviceError, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-			return ObjectError{err, bucket.BucketName, objectName}
+			LogError("try count:%d,GetObjectToFile error %s,cost:%d(ms),error:%s\n", attempt, fileName, cost, err.Error())
 		}
+		return err
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, objectName}
 	}
+	return nil
 }
 
-func (cc *CopyCommand) ossResumeDownloadRetry(bucket *oss.Bucket, objectName string, filePath string, size, partSize int64, options ...oss.Option) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, cc.command.options)
-	for i := 1; ; i++ {
-		if i > 1 {
-			time.Sleep(time.Duration(3) * time.Second)
-			if int64(i) >= retryTimes {
-				fmt.Printf("\nretry count:%d:mulitpart download file:%s.\n", i-1, objectName)
-			}
-		}
-
-		err := bucket.DownloadFile(objectName, filePath, partSize, options...)
-		if err == nil {
-			return cc.truncateFile(filePath, size)
-		}
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucket.BucketName, objectName}
-		}
+// printDryRun prints the planned action in dry run mode, only logs it in machine readable output
+func (cc *CopyCommand) printDryRun(msg, reason string) {
+	if cc.cpOption.output != nil {
+		LogInfo("(dryrun) %s, reason: %s\n", msg, reason)
+		return
 	}
+	printDryRun(msg, reason)
 }
 
-func (cc *CopyCommand) truncateFile(filePath string, size int64) error {
-	f, err := os.Stat(filePath)
-	if err != nil {
-		return err
-	}
-	if f.Size() > size {
-		return os.Truncate(filePath, size)
+// dryRunSkip prints the skipped action and its reason in dry run mode
+func (cc *CopyCommand) dryRunSkip(skip bool, msg, reason string) {
+	if cc.cpOption.dryRun && skip {
+		cc.printDryRun("skip "+msg, reason)
 	}
-	return nil
 }
 
 func (cc *CopyCommand) updateSnapshot(err error, spath string, srct int64) error {
-	if cc.cpOption.snapshotPath != "" && err == nil {
+	if cc.cpOption.snapshotPath != "" && err == nil && !cc.cpOption.dryRun {
 		srctstr := fmt.Sprintf("%d", srct)
 		err := cc.cpOption.snapshotldb.Put([]byte(spath), []byte(srctstr), nil)
 		if err != nil {
@@ -2680,9 +3545,13 @@ func (cc *CopyCommand) batchDownloadFiles(bucket *oss.Bucket, srcURL CloudURL, f
 	chObjects := make(chan objectInfoType, ChannelBuf)
 	chError := make(chan error, cc.cpOption.routines)
 	chListError := make(chan error, 1)
-	// both objectStatistic & object Producer will list objects, this is duplicate
-	go cc.objectStatistic(bucket, srcURL)
-	go cc.objectProducer(bucket, srcURL, chObjects, chListError)
+	if cc.cpOption.manifest != nil {
+		go cc.manifestObjectProducer(srcURL, chObjects, chListError)
+	} else {
+		// both objectStatistic & object Producer will list objects, this is duplicate
+		go cc.objectStatistic(bucket, srcURL)
+		go cc.objectProducer(bucket, srcURL, chObjects, chListError)
+	}
 
 	LogInfo("batch download files,routin count:%d,srcurl:%s,filepath:%s\n", cc.cpOption.routines, srcURL.ToString(), filePath)
 	for i := 0; int64(i) < cc.cpOption.routines; i++ {
@@ -2699,6 +3568,11 @@ func (cc *CopyCommand) objectStatistic(bucket *oss.Bucket, cloudURL CloudURL) {
 		if strings.HasSuffix(cloudURL.object, "/") {
 			marker = oss.Marker(cloudURL.object)
 		}
+		// continue from the marker of the job
+		stream := CloudURLToString(bucket.BucketName, cloudURL.object)
+		if jobMarker := cc.cpOption.job.Marker(stream); jobMarker != "" {
+			marker = oss.Marker(jobMarker)
+		}
 
 		del := oss.Delimiter("")
 		if cc.cpOption.onlyCurrentDir {
@@ -2715,7 +3589,7 @@ func (cc *CopyCommand) objectStatistic(bucket *oss.Bucket, cloudURL CloudURL) {
 			}
 
 			for _, object := range lor.Objects {
-				if doesSingleObjectMatchPatterns(object.Key, cc.cpOption.filters) {
+				if doesSingleObjectMatchPatterns(filterRelativeKey(cloudURL.object, object.Key), cc.cpOption.filters) {
 					if cc.cpOption.partitionIndex == 0 || (cc.cpOption.partitionIndex > 0 && matchHash(fnvIns, object.Key, cc.cpOption.partitionIndex-1, cc.cpOption.partitionCount)) {
 						if strings.ToLower(object.Type) == "symlink" && cc.cpOption.opType == operationTypeGet {
 							props, _ := cc.command.ossGetObjectStatRetry(bucket, object.Key, cc.cpOption.payerOptions...)
@@ -2724,6 +3598,17 @@ func (cc *CopyCommand) objectStatistic(bucket *oss.Bucket, cloudURL CloudURL) {
 								object.Size = size
 							}
 						}
+						match, err := cc.cpOption.attrFilter.matchObject(bucket, object, cc.cpOption.payerOptions...)
+						if err != nil {
+							cc.monitor.setScanError(err)
+							return
+						}
+						if !match {
+							continue
+						}
+						if cc.cpOption.job.IsDone(object.Key, objectJobSignature(object.Size, object.LastModified)) {
+							continue
+						}
 						cc.monitor.updateScanSizeNum(cc.getRangeSize(object.Size), 1)
 					}
 				}
@@ -2817,6 +3702,11 @@ func (cc *CopyCommand) objectProducer(bucket *oss.Bucket, cloudURL CloudURL, chO
 	if strings.HasSuffix(cloudURL.object, "/") {
 		marker = oss.Marker(cloudURL.object)
 	}
+	// continue from the marker of the job
+	stream := CloudURLToString(bucket.BucketName, cloudURL.object)
+	if jobMarker := cc.cpOption.job.Marker(stream); jobMarker != "" {
+		marker = oss.Marker(jobMarker)
+	}
 	del := oss.Delimiter("")
 	if cc.cpOption.onlyCurrentDir {
 		del = oss.Delimiter("/")
@@ -2839,7 +3729,7 @@ func (cc *CopyCommand) objectProducer(bucket *oss.Bucket, cloudURL CloudURL, chO
 				relativeKey = object.Key[index+1:]
 			}
 
-			if doesSingleObjectMatchPatterns(object.Key, cc.cpOption.filters) {
+			if doesSingleObjectMatchPatterns(relativeKey, cc.cpOption.filters) {
 				if cc.cpOption.partitionIndex == 0 || (cc.cpOption.partitionIndex > 0 && matchHash(fnvIns, object.Key, cc.cpOption.partitionIndex-1, cc.cpOption.partitionCount)) {
 					if strings.ToLower(object.Type) == "symlink" && cc.cpOption.opType == operationTypeGet {
 						props, _ := cc.command.ossGetObjectStatRetry(bucket, object.Key, cc.cpOption.payerOptions...)
@@ -2848,7 +3738,17 @@ func (cc *CopyCommand) objectProducer(bucket *oss.Bucket, cloudURL CloudURL, chO
 							object.Size = size
 						}
 					}
-					chObjects <- objectInfoType{prefix, relativeKey, int64(object.Size), object.LastModified}
+					match, err := cc.cpOption.attrFilter.matchObject(bucket, object, cc.cpOption.payerOptions...)
+					if err != nil {
+						chError <- err
+						return
+					}
+					if !match {
+						continue
+					}
+					if cc.cpOption.job.Add(stream, object.Key, object.Key, objectJobSignature(object.Size, object.LastModified)) {
+						chObjects <- objectInfoType{prefix: prefix, relativeKey: relativeKey, size: int64(object.Size), lastModified: object.LastModified}
+					}
 				}
 			}
 		}
@@ -2861,12 +3761,103 @@ func (cc *CopyCommand) objectProducer(bucket *oss.Bucket, cloudURL CloudURL, chO
 		}
 	}
 
+	cc.cpOption.job.EndStream(stream)
 	chError <- nil
 }
 
+// manifestObjectProducer sends the objects read from the manifest, and counts them for the progress as it
+// reads the manifest only once. The object is renamed if the manifest specifies its dest key.
+func (cc *CopyCommand) manifestObjectProducer(cloudURL CloudURL, chObjects chan<- objectInfoType, chError chan<- error) {
+	defer close(chObjects)
+	// continue from the position of the job
+	stream := cc.cpOption.manifest.path
+	marker, _ := strconv.ParseInt(cc.cpOption.job.Marker(stream), 10, 64)
+	index := strings.LastIndex(cloudURL.object, "/")
+	fnvIns := fnv.New64()
+	err := cc.cpOption.manifest.forEach(func(entry ManifestEntry) error {
+		if entry.Line <= marker || !doesSingleObjectMatchPatterns(filterRelativeKey(cloudURL.object, entry.Key), cc.cpOption.filters) {
+			return nil
+		}
+		if cc.cpOption.partitionIndex > 0 && !matchHash(fnvIns, entry.Key, cc.cpOption.partitionIndex-1, cc.cpOption.partitionCount) {
+			return nil
+		}
+
+		prefix := ""
+		relativeKey := entry.Key
+		if index > 0 {
+			prefix = entry.Key[:index+1]
+			relativeKey = entry.Key[index+1:]
+		}
+		if entry.DestKey != "" {
+			relativeKey = entry.DestKey
+		}
+		// the size and last modified time are got by the stat before the transfer
+		objectInfo := objectInfoType{prefix: prefix, relativeKey: relativeKey, size: -1, lastModified: time.Now(), entry: &entry}
+		if !cc.cpOption.job.Add(stream, objectInfo.jobKey(), strconv.FormatInt(entry.Line, 10), entry.VersionId) {
+			return nil
+		}
+		size := entry.Size
+		if size < 0 {
+			size = 0
+		}
+		cc.monitor.updateScanSizeNum(cc.getRangeSize(size), 1)
+		chObjects <- objectInfo
+		return nil
+	})
+	if err != nil {
+		cc.monitor.setScanError(err)
+		chError <- err
+		return
+	}
+
+	cc.cpOption.job.EndStream(stream)
+	cc.monitor.setScanEnd()
+	freshProgress()
+	chError <- nil
+}
+
+// objectVersionId returns the version of the object specified by --version-id or the manifest
+func (cc *CopyCommand) objectVersionId(objectInfo objectInfoType) string {
+	if objectInfo.entry != nil {
+		return objectInfo.entry.VersionId
+	}
+	return cc.cpOption.versionId
+}
+
+// objectStatOptions returns the options to stat the version of the source object
+func (cc *CopyCommand) objectStatOptions(objectInfo objectInfoType) []oss.Option {
+	statOptions := append([]oss.Option{}, cc.cpOption.payerOptions...)
+	if versionId := cc.objectVersionId(objectInfo); versionId != "" {
+		statOptions = append(statOptions, oss.VersionId(versionId))
+	}
+	return statOptions
+}
+
+// manifestEntryOptions returns the options of the version and the headers the manifest specifies for the object
+func (cc *CopyCommand) manifestEntryOptions(objectInfo objectInfoType) ([]oss.Option, error) {
+	if objectInfo.entry == nil {
+		return nil, nil
+	}
+	options, err := cc.command.getOSSOptions(headerOptionMap, objectInfo.entry.Meta)
+	if err != nil {
+		return nil, err
+	}
+	if objectInfo.entry.VersionId != "" {
+		options = append(options, oss.VersionId(objectInfo.entry.VersionId))
+	}
+	return options, nil
+}
+
+func objectJobSignature(size int64, lastModified time.Time) string {
+	return fmt.Sprintf("%d,%d", size, lastModified.Unix())
+}
+
 func (cc *CopyCommand) downloadConsumer(bucket *oss.Bucket, filePath string, chObjects <-chan objectInfoType, chError chan<- error) {
 	for objectInfo := range chObjects {
+		cc.cpOption.tuner.acquire()
 		err := cc.downloadSingleFileWithReport(bucket, objectInfo, filePath)
+		cc.cpOption.tuner.release()
+		cc.cpOption.job.Done(objectInfo.jobKey(), err)
 		if err != nil {
 			chError <- err
 			if !cc.cpOption.ctnu {
@@ -2935,7 +3926,7 @@ func (cc *CopyCommand) copyFiles(srcURL, destURL CloudURL) error {
 		}
 
 		go cc.objectStatistic(bucket, srcURL)
-		err := cc.copySingleFileWithReport(bucket, objectInfoType{prefix, relativeKey, -1, time.Now()}, srcURL, destURL)
+		err := cc.copySingleFileWithReport(bucket, objectInfoType{prefix: prefix, relativeKey: relativeKey, size: -1, lastModified: time.Now()}, srcURL, destURL)
 		return cc.formatResultPrompt(err)
 	}
 
@@ -2955,7 +3946,7 @@ func (cc *CopyCommand) checkCopyFileArgs(srcURL, destURL CloudURL) error {
 	srcPrefix := srcURL.object
 	destPrefix := destURL.object
 	if srcPrefix == destPrefix {
-		if cc.cpOption.meta == "" {
+		if cc.cpOption.meta == "" && cc.cpOption.manifest == nil {
 			return fmt.Errorf("\"%s\" and \"%s\" are the same, copy self will do nothing, set meta please use --meta options", srcURL.ToString(), srcURL.ToString())
 		}
 	} else if cc.cpOption.recursive {
@@ -2970,15 +3961,23 @@ func (cc *CopyCommand) checkCopyFileArgs(srcURL, destURL CloudURL) error {
 }
 
 func (cc *CopyCommand) copySingleFileWithReport(bucket *oss.Bucket, objectInfo objectInfoType, srcURL, destURL CloudURL) error {
-	skip, err, size, msg := cc.copySingleFile(bucket, objectInfo, srcURL, destURL)
+	skip, err, size, msg, reason := cc.copySingleFile(bucket, objectInfo, srcURL, destURL)
+	if cc.cpOption.move != nil {
+		cc.moveAfterCopy(bucket, objectInfo, destURL, skip, reason, err)
+	}
 	cc.updateMonitor(skip, err, false, size)
 	cc.report(msg, err)
+	if cc.cpOption.output != nil {
+		srcObject := objectInfo.key()
+		destObject := cc.makeCopyObjectName(objectInfo.relativeKey, destURL.object)
+		cc.writeResult(opCopy, CloudURLToString(srcURL.bucket, srcObject), CloudURLToString(destURL.bucket, destObject), objectInfo.size, skip, err)
+	}
 	return err
 }
 
-func (cc *CopyCommand) copySingleFile(bucket *oss.Bucket, objectInfo objectInfoType, srcURL, destURL CloudURL) (bool, error, int64, string) {
+func (cc *CopyCommand) copySingleFile(bucket *oss.Bucket, objectInfo objectInfoType, srcURL, destURL CloudURL) (bool, error, int64, string, string) {
 	//make object name
-	srcObject := objectInfo.prefix + objectInfo.relativeKey
+	srcObject := objectInfo.key()
 	destObject := cc.makeCopyObjectName(objectInfo.relativeKey, destURL.object)
 	size := objectInfo.size
 	srct := objectInfo.lastModified
@@ -2986,39 +3985,50 @@ func (cc *CopyCommand) copySingleFile(bucket *oss.Bucket, objectInfo objectInfoT
 	msg := fmt.Sprintf("%s %s to %s", opCopy, CloudURLToString(srcURL.bucket, srcObject), CloudURLToString(destURL.bucket, destObject))
 
 	//get object size
+	statOptions := cc.objectStatOptions(objectInfo)
 	if size < 0 {
-		statOptions := cc.cpOption.payerOptions
-		if cc.cpOption.versionId != "" {
-			statOptions = append(statOptions, oss.VersionId(cc.cpOption.versionId))
-		}
 
 		props, err := cc.command.ossGetObjectStatRetry(bucket, srcObject, statOptions...)
 		if err != nil {
-			return false, err, size, msg
+			return false, err, size, msg, ""
 		}
 		size, err = strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64)
 		if err != nil {
-			return false, err, size, msg
+			return false, err, size, msg, ""
 		}
 		if srct, err = time.Parse(http.TimeFormat, props.Get(oss.HTTPHeaderLastModified)); err != nil {
-			return false, err, size, msg
+			return false, err, size, msg, ""
 		}
 	}
 
-	if skip, err := cc.skipCopy(destURL, destObject, srct); err != nil || skip {
-		return skip, err, size, msg
+	skip, reason, err := cc.skipCopy(bucket, srcObject, size, destURL, destObject, srct, statOptions)
+	if err != nil || skip {
+		cc.dryRunSkip(skip, msg, reason)
+		return skip, err, size, msg, reason
+	}
+
+	if cc.cpOption.dryRun {
+		cc.printDryRun(msg, reason)
+		return false, nil, size, msg, reason
+	}
+
+	// the version and the headers of the object specified by the manifest
+	entryOptions, err := cc.manifestEntryOptions(objectInfo)
+	if err != nil {
+		return false, err, size, msg, reason
 	}
 
 	if size < cc.cpOption.threshold {
-		return false, cc.ossCopyObjectRetry(bucket, srcObject, destURL.bucket, destObject), size, msg
+		return false, cc.ossCopyObjectRetry(bucket, srcObject, destURL.bucket, destObject, entryOptions...), size, msg, reason
 	}
 
 	var listener *OssResumeProgressListener = &OssResumeProgressListener{&cc.monitor, 0, 0, false, false}
 	partSize, rt := cc.preparePartOption(size)
 	cp := oss.CheckpointDir(true, cc.cpOption.cpDir)
 	options := cc.cpOption.options
+	options = append(options, entryOptions...)
 	options = append(options, oss.Routines(rt), cp, oss.Progress(listener), oss.MetadataDirective(oss.MetaReplace))
-	return false, cc.ossResumeCopyRetry(srcURL.bucket, srcObject, destURL.bucket, destObject, partSize, options...), 0, msg
+	return false, cc.ossResumeCopyRetry(srcURL.bucket, srcObject, destURL.bucket, destObject, partSize, options...), 0, msg, reason
 }
 
 func (cc *CopyCommand) makeCopyObjectName(srcRelativeObject, destObject string) string {
@@ -3028,87 +4038,198 @@ func (cc *CopyCommand) makeCopyObjectName(srcRelativeObject, destObject string)
 	return destObject
 }
 
-func (cc *CopyCommand) skipCopy(destURL CloudURL, destObject string, srct time.Time) (bool, error) {
+func (cc *CopyCommand) skipCopy(bucket *oss.Bucket, srcObject string, size int64, destURL CloudURL, destObject string, srct time.Time, statOptions []oss.Option) (bool, string, error) {
 	if cc.cpOption.startTime > 0 && srct.Unix() < cc.cpOption.startTime {
-		return true, nil
+		return true, reasonBeforeStartTime, nil
 	}
 
 	if cc.cpOption.endTime > 0 && srct.Unix() > cc.cpOption.endTime {
-		return true, nil
+		return true, reasonAfterEndTime, nil
 	}
 
 	destBucket, err := cc.command.ossBucket(destURL.bucket)
 	if err != nil {
-		return false, err
+		return false, "", err
 	}
 
-	if cc.cpOption.update {
+	if cc.isIncremental() {
 		if props, err := cc.command.ossGetObjectStatRetry(destBucket, destObject, cc.cpOption.payerOptions...); err == nil {
-			destt, err := time.Parse(http.TimeFormat, props.Get(oss.HTTPHeaderLastModified))
-			if err == nil && destt.Unix() >= srct.Unix() {
-				return true, nil
+			srcInfo := compareInfoType{size: size, modifiedTime: srct.Unix()}
+			if cc.needObjectHash() {
+				srcProps, err := cc.command.ossGetObjectStatRetry(bucket, srcObject, statOptions...)
+				if err != nil {
+					return false, "", err
+				}
+				srcInfo = remoteCompareInfo(srcProps)
+				srcInfo.size = size
+				srcInfo.modifiedTime = srct.Unix()
 			}
+			same, err := cc.isSameFile(srcInfo, remoteCompareInfo(props))
+			return same, cc.compareReason(same), err
 		}
+		return false, reasonNotExist, nil
 	} else {
 		if !cc.cpOption.force {
 			if _, err := cc.command.ossGetObjectMetaRetry(destBucket, destObject, cc.cpOption.payerOptions...); err == nil {
-				if !cc.confirm(CloudURLToString(destURL.bucket, destObject)) {
-					return true, nil
-				}
+				return cc.confirmOverwrite(CloudURLToString(destURL.bucket, destObject))
 			}
+			return false, reasonNotExist, nil
 		}
 	}
-	return false, nil
+	return false, "", nil
 }
 
-func (cc *CopyCommand) ossCopyObjectRetry(bucket *oss.Bucket, objectName, destBucketName, destObjectName string) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, cc.command.options)
-	options := cc.cpOption.options
-	options = append(options, oss.MetadataDirective(oss.MetaReplace))
-	options = append(options, oss.TaggingDirective(oss.TaggingReplace))
-	for i := 1; ; i++ {
-		if i > 1 {
-			time.Sleep(time.Duration(3) * time.Second)
-			if int64(i) >= retryTimes {
-				fmt.Printf("\nretry count:%d,copy object:%s.\n", i-1, objectName)
-			}
+// compareInfoType is the information used to decide whether src and dest are the same
+type compareInfoType struct {
+	size         int64
+	modifiedTime int64
+	crc64        string
+	md5          string
+	filePath     string // the local file to hash, it's hashed only when the sizes are the same
+}
+
+// isIncremental returns true if the same files between src and dest should be skipped
+func (cc *CopyCommand) isIncremental() bool {
+	return cc.cpOption.update || cc.cpOption.compare != ""
+}
+
+func (cc *CopyCommand) compareMode() string {
+	if cc.cpOption.compare == "" {
+		return CompareMtime
+	}
+	return cc.cpOption.compare
+}
+
+func (cc *CopyCommand) needObjectHash() bool {
+	mode := cc.compareMode()
+	return mode == CompareCRC64 || mode == CompareMD5
+}
+
+func (cc *CopyCommand) localCompareInfo(filePath string, f os.FileInfo) compareInfoType {
+	return compareInfoType{size: f.Size(), modifiedTime: f.ModTime().Unix(), filePath: filePath}
+}
+
+// hashLocalFile fills the hash of the local file by --compare
+func (cc *CopyCommand) hashLocalFile(info *compareInfoType) error {
+	if info.filePath == "" {
+		return nil
+	}
+	var err error
+	switch cc.compareMode() {
+	case CompareCRC64:
+		info.crc64, err = fileCRC64(info.filePath)
+	case CompareMD5:
+		info.md5, err = fileMD5(info.filePath)
+	}
+	return err
+}
+
+func remoteCompareInfo(props http.Header) compareInfoType {
+	info := compareInfoType{size: -1}
+	if size, err := strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64); err == nil {
+		info.size = size
+	}
+	if t, err := time.Parse(http.TimeFormat, props.Get(oss.HTTPHeaderLastModified)); err == nil {
+		info.modifiedTime = t.Unix()
+	}
+	info.crc64 = props.Get(oss.HTTPHeaderOssCRC64)
+
+	// Content-Md5 is only returned for objects uploaded with it, etag of the
+	// normal object is the md5 too, but etag of multipart or appendable object is not
+	if contentMD5 := props.Get(oss.HTTPHeaderContentMD5); contentMD5 != "" {
+		if md5, err := base64.StdEncoding.DecodeString(contentMD5); err == nil {
+			info.md5 = strings.ToUpper(hex.EncodeToString(md5))
 		}
-		_, err := bucket.CopyObjectTo(destBucketName, destObjectName, objectName, options...)
-		if err == nil {
-			return err
+	} else if strings.EqualFold(props.Get(StatObjectType), "Normal") {
+		etag := strings.Trim(props.Get(oss.HTTPHeaderEtag), "\"")
+		if !strings.Contains(etag, "-") {
+			info.md5 = strings.ToUpper(etag)
 		}
+	}
+	return info
+}
 
-		// http 4XX error no need to retry
-		// only network error or internal error need to retry
-		serviceError, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-			return ObjectError{err, bucket.BucketName, objectName}
+// isSameFile decides whether dest is the same as src by --compare, -u is the same as --compare=mtime
+func (cc *CopyCommand) isSameFile(src, dest compareInfoType) (bool, error) {
+	sameSize := src.size == dest.size
+	mode := cc.compareMode()
+	if (mode == CompareCRC64 || mode == CompareMD5) && sameSize {
+		// hashing the local file is expensive, do it only when the sizes are the same
+		if err := cc.hashLocalFile(&src); err != nil {
+			return false, err
 		}
+		if err := cc.hashLocalFile(&dest); err != nil {
+			return false, err
+		}
+	}
+
+	switch mode {
+	case CompareSize:
+		return sameSize, nil
+	case CompareSizeMtime:
+		return sameSize && dest.modifiedTime >= src.modifiedTime, nil
+	case CompareCRC64:
+		// the object uploaded before oss supports crc64 has no crc64, always treat it as different
+		return sameSize && src.crc64 != "" && src.crc64 == dest.crc64, nil
+	case CompareMD5:
+		return sameSize && src.md5 != "" && src.md5 == dest.md5, nil
+	default:
+		return dest.modifiedTime >= src.modifiedTime, nil
 	}
 }
 
+func (cc *CopyCommand) compareReason(same bool) string {
+	policy := "--update"
+	if cc.cpOption.compare != "" {
+		policy = "--compare=" + cc.cpOption.compare
+	}
+	if same {
+		return fmt.Sprintf("destination is the same by %s", policy)
+	}
+	return fmt.Sprintf("destination is different by %s", policy)
+}
+
+func (cc *CopyCommand) ossCopyObjectRetry(bucket *oss.Bucket, objectName, destBucketName, destObjectName string, entryOptions ...oss.Option) error {
+	options := cc.cpOption.options
+	options = append(options, entryOptions...)
+	options = append(options, oss.MetadataDirective(oss.MetaReplace))
+	options = append(options, oss.TaggingDirective(oss.TaggingReplace))
+	policy := cc.command.retryPolicy()
+	err := policy.Do(func(attempt int, respHeader *http.Header) error {
+		if attempt > 1 && int64(attempt) >= policy.MaxAttempts {
+			fmt.Printf("\nretry count:%d,copy object:%s.\n", attempt-1, objectName)
+		}
+		startT := time.Now()
+		_, err := bucket.CopyObjectTo(destBucketName, destObjectName, objectName, withResponseHeader(options, respHeader)...)
+		cc.cpOption.tuner.observe(err, time.Since(startT))
+		return err
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, objectName}
+	}
+	return nil
+}
+
 func (cc *CopyCommand) ossResumeCopyRetry(bucketName, objectName, destBucketName, destObjectName string, partSize int64, options ...oss.Option) error {
 	bucket, err := cc.command.ossBucket(destBucketName)
 	if err != nil {
 		return err
 	}
-	retryTimes, _ := GetInt(OptionRetryTimes, cc.command.options)
-	for i := 1; ; i++ {
-		if i > 1 {
-			time.Sleep(time.Duration(3) * time.Second)
-			if int64(i) >= retryTimes {
-				fmt.Printf("\nretry count:%d, resume copy object:%s.\n", i-1, objectName)
-			}
+	policy := cc.command.retryPolicy().retryAnyError()
+	err = policy.Do(func(attempt int, respHeader *http.Header) error {
+		if attempt > 1 && int64(attempt) >= policy.MaxAttempts {
+			fmt.Printf("\nretry count:%d, resume copy object:%s.\n", attempt-1, objectName)
 		}
 
+		startT := time.Now()
 		err := bucket.CopyFile(bucketName, objectName, destObjectName, partSize, options...)
-		if err == nil {
-			return err
-		}
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucket.BucketName, objectName}
-		}
+		cc.cpOption.tuner.observe(err, time.Since(startT))
+		return err
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, objectName}
 	}
+	return nil
 }
 
 func (cc *CopyCommand) batchCopyFiles(bucket *oss.Bucket, srcURL, destURL CloudURL) error {
@@ -3116,8 +4237,12 @@ func (cc *CopyCommand) batchCopyFiles(bucket *oss.Bucket, srcURL, destURL CloudU
 	chObjects := make(chan objectInfoType, ChannelBuf)
 	chError := make(chan error, cc.cpOption.routines)
 	chListError := make(chan error, 1)
-	go cc.objectStatistic(bucket, srcURL)
-	go cc.objectProducer(bucket, srcURL, chObjects, chListError)
+	if cc.cpOption.manifest != nil {
+		go cc.manifestObjectProducer(srcURL, chObjects, chListError)
+	} else {
+		go cc.objectStatistic(bucket, srcURL)
+		go cc.objectProducer(bucket, srcURL, chObjects, chListError)
+	}
 
 	for i := 0; int64(i) < cc.cpOption.routines; i++ {
 		go cc.copyConsumer(bucket, srcURL, destURL, chObjects, chError)
@@ -3128,7 +4253,10 @@ func (cc *CopyCommand) batchCopyFiles(bucket *oss.Bucket, srcURL, destURL CloudU
 
 func (cc *CopyCommand) copyConsumer(bucket *oss.Bucket, srcURL, destURL CloudURL, chObjects <-chan objectInfoType, chError chan<- error) {
 	for objectInfo := range chObjects {
+		cc.cpOption.tuner.acquire()
 		err := cc.copySingleFileWithReport(bucket, objectInfo, srcURL, destURL)
+		cc.cpOption.tuner.release()
+		cc.cpOption.job.Done(objectInfo.jobKey(), err)
 		if err != nil {
 			chError <- err
 			if !cc.cpOption.ctnu {
@@ -1,11 +1,14 @@
 package lib
 
 import (
+	"encoding/json"
 	"fmt"
+	"hash/crc64"
 	"hash/fnv"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -1063,12 +1066,12 @@ func (s *OssutilCommandSuite) TestPreparePartOption(c *C) {
 	copyCommand.command.options[OptionParallel] = &str
 }
 
-func (s *OssutilCommandSuite) TestResumeDownloadRetry(c *C) {
+func (s *OssutilCommandSuite) TestParallelDownloadRetry(c *C) {
 	bucketName := bucketNamePrefix + randLowStr(10)
 	bucket, err := copyCommand.command.ossBucket(bucketName)
 	c.Assert(err, IsNil)
 
-	err = copyCommand.ossResumeDownloadRetry(bucket, "", "", 0, 0)
+	err = copyCommand.ossParallelDownload(bucket, "", "")
 	c.Assert(err, NotNil)
 }
 
@@ -1636,14 +1639,6 @@ func (s *OssutilCommandSuite) TestCopyFunction(c *C) {
 	err = copyCommand.ossDownloadFileRetry(bucket, "object", downloadFileName)
 	c.Assert(err, NotNil)
 
-	// test truncateFile
-	err = copyCommand.truncateFile("ossutil_notexistfile", 1)
-	c.Assert(err, NotNil)
-	s.createFile(uploadFileName, "abc", c)
-	err = copyCommand.truncateFile(uploadFileName, 1)
-	c.Assert(err, IsNil)
-	str = s.readFile(uploadFileName, c)
-	c.Assert(str, Equals, "a")
 }
 
 // test fileProducer
@@ -1815,7 +1810,7 @@ func (s *OssutilCommandSuite) TestCPFunction(c *C) {
 	var fileInfo fileInfoType
 	fileInfo.filePath = "a"
 	fileInfo.dir = "notexistdir"
-	_, err, _, _, _ = copyCommand.uploadFile(bucket, destURL, fileInfo)
+	_, err, _, _, _, _ = copyCommand.uploadFile(bucket, destURL, fileInfo)
 	c.Assert(err, NotNil)
 }
 
@@ -2046,8 +2041,8 @@ func (s *OssutilCommandSuite) TestBatchCPObjectWithNonSequenceIncludeEx(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(showElapse, Equals, true)
 
-	res, filters := getFilter(cmdline)
-	c.Assert(res, Equals, true)
+	filters, err := getFilter(cmdline)
+	c.Assert(err, IsNil)
 	files := filterStrsWithInclude(filenames, filters[0].pattern)
 
 	// Verify
@@ -2395,8 +2390,8 @@ func (s *OssutilCommandSuite) TestBatchCPObjectWithNonSequenceExcludeEx(c *C) {
 	c.Assert(showElapse, Equals, true)
 
 	// Get uploaded files (with above conditions: --exclude "*[^0-3]?txt") and use these for verification
-	res, filters := getFilter(cmdline)
-	c.Assert(res, Equals, true)
+	filters, err := getFilter(cmdline)
+	c.Assert(err, IsNil)
 	files := filterStrsWithExclude(filenames, filters[0].pattern)
 
 	// Verify
@@ -2759,7 +2754,7 @@ func (s *OssutilCommandSuite) TestBatchCPObjectWithInvalidIncludeExclude(c *C) {
 	cmdline = []string{"ossutil", "cp", dir, bucketStr, "-f", "--include", "/*.txt", "--exclude", "*2*"}
 	showElapse, err = s.rawCPWithFilter(args, false, true, false, DefaultBigFileThreshold, CheckpointDir, cmdline, "", "")
 	c.Assert(showElapse, Equals, false)
-	c.Assert(err.Error() == "--include or --exclude does not support format containing dir info", Equals, true)
+	c.Assert(err.Error() == "--include or --exclude only work with --recursive", Equals, true)
 
 	// download
 	// e.g., ossutil cp oss://tempb4/ testdownload/ -f --exclude "*.txt"
@@ -2778,7 +2773,7 @@ func (s *OssutilCommandSuite) TestBatchCPObjectWithInvalidIncludeExclude(c *C) {
 	cmdline = []string{"ossutil", "cp", dir, bucketStr, "-f", "--include", "*.txt", "--exclude", "/usr/*/*2*"}
 	showElapse, err = s.rawCPWithFilter(args, false, true, false, DefaultBigFileThreshold, CheckpointDir, cmdline, "", "")
 	c.Assert(showElapse, Equals, false)
-	c.Assert(err.Error() == "--include or --exclude does not support format containing dir info", Equals, true)
+	c.Assert(err.Error() == "--include or --exclude only work with --recursive", Equals, true)
 
 	// download test with --meta, --acl
 	cmdline = []string{"ossutil", "cp", bucketStr, downdir, "-rf", "--meta", "Cache-Control:no-cache"}
@@ -2888,7 +2883,7 @@ func (s *OssutilCommandSuite) TestBatchCPObjectWithInvalidIncludeExcludeEqual(c
 	cmdline = []string{"ossutil", "cp", dir, bucketStr, "-f", "--include=/*.txt", "--exclude=*2*"}
 	showElapse, err = s.rawCPWithFilter(args, false, true, false, DefaultBigFileThreshold, CheckpointDir, cmdline, "", "")
 	c.Assert(showElapse, Equals, false)
-	c.Assert(err.Error() == "--include or --exclude does not support format containing dir info", Equals, true)
+	c.Assert(err.Error() == "--include or --exclude only work with --recursive", Equals, true)
 
 	// download
 	// e.g., ossutil cp oss://tempb4/ testdownload/ -f --exclude "*.txt"
@@ -2907,7 +2902,7 @@ func (s *OssutilCommandSuite) TestBatchCPObjectWithInvalidIncludeExcludeEqual(c
 	cmdline = []string{"ossutil", "cp", dir, bucketStr, "-f", "--include=*.txt", "--exclude=/usr/*/*2*"}
 	showElapse, err = s.rawCPWithFilter(args, false, true, false, DefaultBigFileThreshold, CheckpointDir, cmdline, "", "")
 	c.Assert(showElapse, Equals, false)
-	c.Assert(err.Error() == "--include or --exclude does not support format containing dir info", Equals, true)
+	c.Assert(err.Error() == "--include or --exclude only work with --recursive", Equals, true)
 
 	// download test with --meta, --acl
 	cmdline = []string{"ossutil", "cp", bucketStr, downdir, "-rf", "--meta", "Cache-Control:no-cache"}
@@ -6944,3 +6939,284 @@ func (s *OssutilCommandSuite) TestBatchCPObjectToObjectWithStartTimeAndEndTime(c
 	s.removeBucket(bucketName, true, c)
 	s.removeBucket(bucketName2, true, c)
 }
+
+func (s *OssutilCommandSuite) TestCPObjectCompare(c *C) {
+	bucketName := bucketNamePrefix + randLowStr(10)
+	s.putBucket(bucketName, c)
+
+	// the same size and mtime, but different content
+	fileName := "compareFile" + randStr(5)
+	s.createFile(fileName, "aaaa", c)
+	object := "testobject"
+	s.putObject(bucketName, object, fileName, c)
+
+	s.createFile(fileName, "bbbb", c)
+	past := time.Now().Add(-time.Hour)
+	c.Assert(os.Chtimes(fileName, past, past), IsNil)
+
+	str := ""
+	args := []string{fileName, CloudURLToString(bucketName, object)}
+	for _, mode := range []string{CompareSize, CompareMtime, CompareSizeMtime} {
+		compare := mode
+		options := OptionMapType{
+			"endpoint":        &str,
+			"accessKeyID":     &str,
+			"accessKeySecret": &str,
+			"configFile":      &configFile,
+			"compare":         &compare,
+		}
+		_, err := cm.RunCommand("cp", args, options)
+		c.Assert(err, IsNil)
+
+		s.getObject(bucketName, object, downloadFileName, c)
+		c.Assert(s.readFile(downloadFileName, c), Equals, "aaaa")
+	}
+
+	for _, mode := range []string{CompareCRC64, CompareMD5} {
+		s.createFile(fileName, "cc"+mode[:2], c)
+		c.Assert(os.Chtimes(fileName, past, past), IsNil)
+
+		compare := mode
+		options := OptionMapType{
+			"endpoint":        &str,
+			"accessKeyID":     &str,
+			"accessKeySecret": &str,
+			"configFile":      &configFile,
+			"compare":         &compare,
+		}
+		_, err := cm.RunCommand("cp", args, options)
+		c.Assert(err, IsNil)
+
+		s.getObject(bucketName, object, downloadFileName, c)
+		c.Assert(s.readFile(downloadFileName, c), Equals, "cc"+mode[:2])
+
+		// download is skipped when content is the same, even if mtime is touched
+		s.createFile(downloadFileName, "cc"+mode[:2], c)
+		c.Assert(os.Chtimes(downloadFileName, past, past), IsNil)
+		_, err = cm.RunCommand("cp", []string{CloudURLToString(bucketName, object), downloadFileName}, options)
+		c.Assert(err, IsNil)
+		f, err := os.Stat(downloadFileName)
+		c.Assert(err, IsNil)
+		c.Assert(f.ModTime().Unix(), Equals, past.Unix())
+	}
+
+	os.Remove(fileName)
+	s.removeBucket(bucketName, true, c)
+}
+
+func (s *OssutilCommandSuite) TestCPCompareInfo(c *C) {
+	props := http.Header{}
+	props.Set(oss.HTTPHeaderContentLength, "3")
+	props.Set(oss.HTTPHeaderEtag, "\"900150983CD24FB0D6963F7D28E17F72\"")
+	props.Set(StatObjectType, "Normal")
+	props.Set(oss.HTTPHeaderOssCRC64, "12345")
+	info := remoteCompareInfo(props)
+	c.Assert(info.size, Equals, int64(3))
+	c.Assert(info.md5, Equals, "900150983CD24FB0D6963F7D28E17F72")
+	c.Assert(info.crc64, Equals, "12345")
+
+	// etag of multipart object is not md5
+	props.Set(oss.HTTPHeaderEtag, "\"900150983CD24FB0D6963F7D28E17F72-2\"")
+	props.Set(StatObjectType, "Multipart")
+	c.Assert(remoteCompareInfo(props).md5, Equals, "")
+
+	cc := CopyCommand{}
+	src := compareInfoType{size: 3, modifiedTime: 100, crc64: "1", md5: "A"}
+	dest := compareInfoType{size: 3, modifiedTime: 50, crc64: "2", md5: "A"}
+	for mode, same := range map[string]bool{"": false, CompareSize: true, CompareMtime: false,
+		CompareSizeMtime: false, CompareCRC64: false, CompareMD5: true} {
+		cc.cpOption.compare = mode
+		bSame, err := cc.isSameFile(src, dest)
+		c.Assert(err, IsNil)
+		c.Assert(bSame, Equals, same)
+	}
+
+	// no crc64 is always different
+	cc.cpOption.compare = CompareCRC64
+	bSame, _ := cc.isSameFile(compareInfoType{size: 3}, compareInfoType{size: 3})
+	c.Assert(bSame, Equals, false)
+
+	// the local file is hashed only when the sizes are the same
+	bSame, err := cc.isSameFile(compareInfoType{size: 3}, compareInfoType{size: 4, filePath: "notexist" + randStr(5)})
+	c.Assert(err, IsNil)
+	c.Assert(bSame, Equals, false)
+	_, err = cc.isSameFile(compareInfoType{size: 3}, compareInfoType{size: 3, filePath: "notexist" + randStr(5)})
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestCPDryRun(c *C) {
+	bucketName := bucketNamePrefix + randLowStr(10)
+	s.putBucket(bucketName, c)
+
+	fileName := "dryrunFile" + randStr(5)
+	s.createFile(fileName, "dryrun", c)
+	object := "testobject"
+
+	str := ""
+	dryRun := true
+	options := OptionMapType{
+		"endpoint":        &str,
+		"accessKeyID":     &str,
+		"accessKeySecret": &str,
+		"configFile":      &configFile,
+		"dryRun":          &dryRun,
+	}
+
+	// upload nothing
+	_, err := cm.RunCommand("cp", []string{fileName, CloudURLToString(bucketName, object)}, options)
+	c.Assert(err, IsNil)
+	_, err = s.rawGetStat(bucketName, object)
+	c.Assert(err, NotNil)
+
+	// download nothing
+	s.putObject(bucketName, object, fileName, c)
+	os.Remove(downloadFileName)
+	_, err = cm.RunCommand("cp", []string{CloudURLToString(bucketName, object), downloadFileName}, options)
+	c.Assert(err, IsNil)
+	_, err = os.Stat(downloadFileName)
+	c.Assert(err, NotNil)
+
+	// copy nothing
+	destObject := "testobject-dest"
+	_, err = cm.RunCommand("cp", []string{CloudURLToString(bucketName, object), CloudURLToString(bucketName, destObject)}, options)
+	c.Assert(err, IsNil)
+	_, err = s.rawGetStat(bucketName, destObject)
+	c.Assert(err, NotNil)
+
+	os.Remove(fileName)
+	s.removeBucket(bucketName, true, c)
+}
+
+func (s *OssutilCommandSuite) TestCPDryRunSkipReason(c *C) {
+	fileName := "dryrunFile" + randStr(5)
+	s.createFile(fileName, "dryrun", c)
+	now := time.Now()
+
+	cc := CopyCommand{}
+	cc.cpOption.dryRun = true
+
+	// no prompt in dry run mode
+	skip, reason, err := cc.skipDownload(nil, "object", fileName, 6, now, nil)
+	c.Assert(err, IsNil)
+	c.Assert(skip, Equals, false)
+	c.Assert(reason, Equals, reasonNeedConfirm)
+
+	skip, reason, err = cc.skipDownload(nil, "object", fileName+"-notexist", 6, now, nil)
+	c.Assert(err, IsNil)
+	c.Assert(skip, Equals, false)
+	c.Assert(reason, Equals, reasonNotExist)
+
+	cc.cpOption.startTime = now.Unix() + 100
+	skip, reason, err = cc.skipDownload(nil, "object", fileName, 6, now, nil)
+	c.Assert(err, IsNil)
+	c.Assert(skip, Equals, true)
+	c.Assert(reason, Equals, reasonBeforeStartTime)
+
+	cc.cpOption.startTime = 0
+	cc.cpOption.compare = CompareSize
+	skip, reason, err = cc.skipDownload(nil, "object", fileName, 6, now, nil)
+	c.Assert(err, IsNil)
+	c.Assert(skip, Equals, true)
+	c.Assert(reason, Equals, "destination is the same by --compare=size")
+
+	os.Remove(fileName)
+}
+
+// compareHashServer is a fake oss answering HEAD only, GetObjectMeta gets no crc64 as the real oss does
+type compareHashServer struct {
+	objects   map[string]string
+	versionId string
+}
+
+func (cs *compareHashServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	content, ok := cs.objects[path[len(path)-1]]
+	if !ok || r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set(oss.HTTPHeaderContentLength, strconv.Itoa(len(content)))
+	if _, ok := r.URL.Query()["objectMeta"]; ok {
+		return
+	}
+	cs.versionId = r.URL.Query().Get("versionId")
+	w.Header().Set(oss.HTTPHeaderOssCRC64, strconv.FormatUint(crc64.Checksum([]byte(content), crc64.MakeTable(crc64.ECMA)), 10))
+}
+
+func (s *OssutilCommandSuite) TestCPCompareHashSkip(c *C) {
+	content := "compare hash"
+	cs := &compareHashServer{objects: map[string]string{"src": content, "dest": content}}
+	svr := httptest.NewServer(cs)
+	defer svr.Close()
+
+	str := ""
+	ak := "ak"
+	options := OptionMapType{OptionEndpoint: &svr.URL, OptionAccessKeyID: &ak, OptionAccessKeySecret: &ak, OptionConfigFile: &str}
+	cc := CopyCommand{command: Command{options: options}}
+	bucket, err := cc.command.ossBucket("bucket")
+	c.Assert(err, IsNil)
+
+	fileName := "compareHashFile" + randStr(5)
+	s.createFile(fileName, content, c)
+	defer os.Remove(fileName)
+
+	// the hash of the source is got by HEAD, which returns x-oss-hash-crc64ecma
+	cc.cpOption.compare = CompareCRC64
+	skip, _, err := cc.skipDownload(bucket, "src", fileName, int64(len(content)), time.Now(), []oss.Option{oss.VersionId("v1")})
+	c.Assert(err, IsNil)
+	c.Assert(skip, Equals, true)
+	c.Assert(cs.versionId, Equals, "v1")
+
+	skip, _, err = cc.skipCopy(bucket, "src", int64(len(content)), CloudURL{bucket: "bucket"}, "dest", time.Now(), nil)
+	c.Assert(err, IsNil)
+	c.Assert(skip, Equals, true)
+
+	cs.objects["dest"] = "compare HASH"
+	skip, _, err = cc.skipCopy(bucket, "src", int64(len(content)), CloudURL{bucket: "bucket"}, "dest", time.Now(), nil)
+	c.Assert(err, IsNil)
+	c.Assert(skip, Equals, false)
+}
+
+func (s *OssutilCommandSuite) TestCPOutputFormat(c *C) {
+	bucketName := bucketNamePrefix + randLowStr(10)
+	s.putBucket(bucketName, c)
+
+	fileName := "outputFormatFile" + randStr(5)
+	s.createFile(fileName, "output format", c)
+	object := "testobject"
+
+	str := ""
+	format := OutputFormatJSONL
+	options := OptionMapType{
+		"endpoint":        &str,
+		"accessKeyID":     &str,
+		"accessKeySecret": &str,
+		"configFile":      &configFile,
+		"outputFormat":    &format,
+	}
+
+	testResultFile, _ = os.OpenFile(resultPath, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0664)
+	out := os.Stdout
+	os.Stdout = testResultFile
+	showElapse, err := cm.RunCommand("cp", []string{fileName, CloudURLToString(bucketName, object)}, options)
+	os.Stdout = out
+	c.Assert(err, IsNil)
+	c.Assert(showElapse, Equals, false)
+
+	lines := strings.Split(strings.TrimSpace(s.readFile(resultPath, c)), "\n")
+	os.Remove(resultPath)
+	c.Assert(len(lines), Equals, 2)
+
+	var record, summary map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(lines[0]), &record), IsNil)
+	c.Assert(record["type"], Equals, "upload")
+	c.Assert(record["dest"], Equals, CloudURLToString(bucketName, object))
+	c.Assert(record["status"], Equals, "ok")
+	c.Assert(json.Unmarshal([]byte(lines[1]), &summary), IsNil)
+	c.Assert(summary["type"], Equals, recordSummary)
+	c.Assert(summary["status"], Equals, "succeed")
+	c.Assert(summary["file_count"], Equals, float64(1))
+
+	os.Remove(fileName)
+	s.removeBucket(bucketName, true, c)
+}
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"fmt"
+	"net/http"
 	"strings"
 
 	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
@@ -107,6 +108,7 @@ var createSymlinkCommand = CreateSymlinkCommand{
 		validOptionNames: []string{
 			OptionEncodingType,
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -115,6 +117,9 @@ var createSymlinkCommand = CreateSymlinkCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionLogLevel,
 			OptionRequestPayer,
 			OptionPassword,
@@ -126,6 +131,10 @@ var createSymlinkCommand = CreateSymlinkCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -210,14 +219,11 @@ func (cc *CreateSymlinkCommand) checkArgs(symlinkURL CloudURL, targetURL Storage
 }
 
 func (cc *CreateSymlinkCommand) ossCreateSymlinkRetry(bucket *oss.Bucket, symlinkObject, targetObject string) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, cc.command.options)
-	for i := 1; ; i++ {
-		err := bucket.PutSymlink(symlinkObject, targetObject, cc.commonOptions...)
-		if err == nil {
-			return err
-		}
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucket.BucketName, symlinkObject}
-		}
+	err := cc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		return bucket.PutSymlink(symlinkObject, targetObject, withResponseHeader(cc.commonOptions, respHeader)...)
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, symlinkObject}
 	}
+	return nil
 }
@@ -0,0 +1,323 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// credentialsFetcher gets new credentials, Expiration is empty if the credentials never expire
+type credentialsFetcher func() (STSAkJson, error)
+
+// RefreshCredentialsProvider gets credentials by fetcher, and gets them again before they expire.
+// If cache is not nil, the credentials are shared with other ossutil processes through the cache file.
+type RefreshCredentialsProvider struct {
+	lock     sync.Mutex
+	name     string
+	fetch    credentialsFetcher
+	cache    *CredentialsCache
+	cacheKey string
+	hasGet   bool
+	akJson   STSAkJson
+}
+
+func newRefreshCredentialsProvider(name string, fetch credentialsFetcher, cache *CredentialsCache, keyParts ...string) *RefreshCredentialsProvider {
+	return &RefreshCredentialsProvider{
+		name:     name,
+		fetch:    fetch,
+		cache:    cache,
+		cacheKey: credentialsCacheKey(name, keyParts...),
+	}
+}
+
+func (p *RefreshCredentialsProvider) GetCredentials() oss.Credentials {
+	cred, _ := p.GetCredentialsE()
+	return cred
+}
+
+func (p *RefreshCredentialsProvider) GetCredentialsE() (oss.Credentials, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if !p.hasGet || isCredentialsExpired(p.akJson.Expiration) {
+		akJson, ok := p.cache.Get(p.cacheKey)
+		if ok {
+			LogInfo("get %s credentials from cache,AccessKeyId:%s,Expiration:%s\n", p.name, akJson.AccessKeyId, akJson.Expiration)
+		} else {
+			tStart := time.Now().UnixNano() / 1000 / 1000
+			var err error
+			if akJson, err = p.fetch(); err != nil {
+				LogError("get %s credentials error,%s\n", p.name, err.Error())
+				return &EcsRoleAK{}, err
+			}
+			tEnd := time.Now().UnixNano() / 1000 / 1000
+			LogInfo("get %s credentials success,AccessKeyId:%s,Expiration:%s,cost:%d(ms)\n", p.name, akJson.AccessKeyId, akJson.Expiration, tEnd-tStart)
+			p.cache.Put(p.cacheKey, akJson)
+		}
+		p.akJson = akJson
+		p.hasGet = true
+	}
+
+	return &EcsRoleAK{
+		AccessKeyId:     p.akJson.AccessKeyId,
+		AccessKeySecret: p.akJson.AccessKeySecret,
+		SecurityToken:   p.akJson.SecurityToken,
+	}, nil
+}
+
+// CredentialsProviderChain gets credentials from the providers in order,
+// the first provider which succeeds is used since then.
+type CredentialsProviderChain struct {
+	lock      sync.Mutex
+	providers []oss.CredentialsProviderE
+	current   oss.CredentialsProviderE
+}
+
+func NewCredentialsProviderChain(providers ...oss.CredentialsProviderE) *CredentialsProviderChain {
+	return &CredentialsProviderChain{providers: providers}
+}
+
+func (chain *CredentialsProviderChain) GetCredentials() oss.Credentials {
+	cred, _ := chain.GetCredentialsE()
+	return cred
+}
+
+func (chain *CredentialsProviderChain) GetCredentialsE() (oss.Credentials, error) {
+	chain.lock.Lock()
+	defer chain.lock.Unlock()
+
+	if chain.current != nil {
+		return chain.current.GetCredentialsE()
+	}
+
+	errs := []string{}
+	for _, provider := range chain.providers {
+		cred, err := provider.GetCredentialsE()
+		if err == nil {
+			chain.current = provider
+			return cred, nil
+		}
+		if len(chain.providers) == 1 {
+			return cred, err
+		}
+		errs = append(errs, err.Error())
+	}
+	return &EcsRoleAK{}, fmt.Errorf("no credentials provider succeeds: %s", strings.Join(errs, "; "))
+}
+
+// newRamRoleArnProvider assumes the ram role with the AccessKey
+func newRamRoleArnProvider(accessKeyID, accessKeySecret, roleArn, sessionName string, tokenTimeout uint, stsEndPoint string,
+	cache *CredentialsCache) *RefreshCredentialsProvider {
+	fetch := func() (STSAkJson, error) {
+		name := sessionName
+		if name == "" {
+			name = "SessNameRand" + randStr(5)
+		}
+		stsClient := NewClient(accessKeyID, accessKeySecret, roleArn, name)
+		resp, err := stsClient.AssumeRole(tokenTimeout, stsEndPoint)
+		if err != nil {
+			return STSAkJson{}, err
+		}
+		return stsResponseToAkJson(resp), nil
+	}
+	return newRefreshCredentialsProvider("ram role", fetch, cache, accessKeyID, roleArn, sessionName,
+		fmt.Sprint(tokenTimeout), stsEndPoint)
+}
+
+// newProcessCredentialsProvider runs the external command to get credentials,
+// the command prints the credentials in json format like STSAkJson to stdout.
+func newProcessCredentialsProvider(command string, cache *CredentialsCache) *RefreshCredentialsProvider {
+	fetch := func() (STSAkJson, error) {
+		return runCredentialProcess(command)
+	}
+	return newRefreshCredentialsProvider("credential process", fetch, cache, command)
+}
+
+func runCredentialProcess(command string) (STSAkJson, error) {
+	akJson := STSAkJson{}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.Env = os.Environ()
+	if err := cmd.Run(); err != nil {
+		return akJson, fmt.Errorf("run credential process error, %s, %s", err.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), &akJson); err != nil {
+		return akJson, fmt.Errorf("credential process output is not valid json, %s", err.Error())
+	}
+	return akJson, checkAkJson(akJson)
+}
+
+// newOIDCCredentialsProvider assumes the ram role with the OIDC token in tokenFile, which is used by
+// RRSA(RAM Roles for Service Accounts) in kubernetes, the token file is read again on every refresh.
+func newOIDCCredentialsProvider(roleArn, oidcProviderArn, tokenFile, sessionName string, tokenTimeout uint, stsEndPoint string,
+	cache *CredentialsCache) *RefreshCredentialsProvider {
+	fetch := func() (STSAkJson, error) {
+		token, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			return STSAkJson{}, fmt.Errorf("read oidc token file error, %s", err.Error())
+		}
+
+		name := sessionName
+		if name == "" {
+			name = "SessNameRand" + randStr(5)
+		}
+		stsClient := NewClient("", "", roleArn, name)
+		resp, err := stsClient.AssumeRoleWithOIDC(oidcProviderArn, strings.TrimSpace(string(token)), tokenTimeout, stsEndPoint)
+		if err != nil {
+			return STSAkJson{}, err
+		}
+		return stsResponseToAkJson(resp), nil
+	}
+	return newRefreshCredentialsProvider("oidc role", fetch, cache, roleArn, oidcProviderArn, tokenFile, sessionName,
+		fmt.Sprint(tokenTimeout), stsEndPoint)
+}
+
+func stsResponseToAkJson(resp *Response) STSAkJson {
+	return STSAkJson{
+		AccessKeyId:     resp.Credentials.AccessKeyId,
+		AccessKeySecret: resp.Credentials.AccessKeySecret,
+		SecurityToken:   resp.Credentials.SecurityToken,
+		Expiration:      resp.Credentials.Expiration.UTC().Format(TimeFormat),
+	}
+}
+
+func checkAkJson(akJson STSAkJson) error {
+	if akJson.Code != "" && strings.ToUpper(akJson.Code) != "SUCCESS" {
+		return fmt.Errorf("get credentials error,code:%s", akJson.Code)
+	}
+	if akJson.AccessKeyId == "" || akJson.AccessKeySecret == "" {
+		return fmt.Errorf("get credentials error,AccessKeyId or AccessKeySecret is empty")
+	}
+	if akJson.Expiration != "" {
+		if _, err := time.Parse(TimeFormat, akJson.Expiration); err != nil {
+			return fmt.Errorf("get credentials error,invalid Expiration %s", akJson.Expiration)
+		}
+	}
+	return nil
+}
+
+// isCredentialsExpired returns true if the credentials expire in AdvanceSeconds
+func isCredentialsExpired(expiration string) bool {
+	if expiration == "" {
+		return false
+	}
+
+	// expiration is UTC time
+	utcExpirationTime, err := time.Parse(TimeFormat, expiration)
+	if err != nil {
+		return true
+	}
+	return utcExpirationTime.Unix()-time.Now().Unix()-AdvanceSeconds <= 0
+}
+
+// CredentialsCache is the file to cache temporary credentials, so that the ossutil processes
+// run one after another do not need to get new credentials each time.
+type CredentialsCache struct {
+	lock sync.Mutex
+	path string
+}
+
+// NewCredentialsCache returns nil if the cache file is not specified or the cache is turned off
+func NewCredentialsCache(path string) *CredentialsCache {
+	if path == "" || strings.EqualFold(path, CredentialsCacheOff) {
+		return nil
+	}
+	return &CredentialsCache{path: DecideConfigFile(path)}
+}
+
+func credentialsCacheKey(name string, keyParts ...string) string {
+	sum := sha256.Sum256([]byte(name + "\n" + strings.Join(keyParts, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the credentials which are not expired
+func (cc *CredentialsCache) Get(key string) (STSAkJson, bool) {
+	if cc == nil {
+		return STSAkJson{}, false
+	}
+
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+
+	akJson, ok := cc.load()[key]
+	if !ok || akJson.Expiration == "" || isCredentialsExpired(akJson.Expiration) {
+		return STSAkJson{}, false
+	}
+	return akJson, true
+}
+
+// Put saves the credentials, the credentials without Expiration are not saved
+func (cc *CredentialsCache) Put(key string, akJson STSAkJson) {
+	if cc == nil || akJson.Expiration == "" {
+		return
+	}
+
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+
+	items := cc.load()
+	for k, v := range items {
+		if isCredentialsExpired(v.Expiration) {
+			delete(items, k)
+		}
+	}
+	items[key] = akJson
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return
+	}
+
+	// write to a temp file first, the other processes never read a partial file
+	tmpFile, err := ioutil.TempFile(filepath.Dir(cc.path), filepath.Base(cc.path)+".tmp")
+	if err != nil {
+		LogError("write credentials cache %s error,%s\n", cc.path, err.Error())
+		return
+	}
+	_, err = tmpFile.Write(data)
+	tmpFile.Close()
+	if err == nil {
+		err = os.Chmod(tmpFile.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), cc.path)
+	}
+	if err != nil {
+		os.Remove(tmpFile.Name())
+		LogError("write credentials cache %s error,%s\n", cc.path, err.Error())
+	}
+}
+
+func (cc *CredentialsCache) load() map[string]STSAkJson {
+	items := map[string]STSAkJson{}
+	data, err := ioutil.ReadFile(cc.path)
+	if err != nil {
+		return items
+	}
+	if err = json.Unmarshal(data, &items); err != nil {
+		LogError("invalid credentials cache %s,%s\n", cc.path, err.Error())
+		return map[string]STSAkJson{}
+	}
+	return items
+}
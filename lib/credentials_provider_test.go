@@ -0,0 +1,299 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func testAkJson(id string, expire time.Duration) STSAkJson {
+	return STSAkJson{
+		AccessKeyId:     id,
+		AccessKeySecret: id + "-secret",
+		SecurityToken:   id + "-token",
+		Expiration:      time.Now().Add(expire).UTC().Format(TimeFormat),
+	}
+}
+
+func (s *OssutilCommandSuite) TestRefreshCredentialsProvider(c *C) {
+	count := 0
+	expire := time.Hour
+	fetch := func() (STSAkJson, error) {
+		count++
+		return testAkJson(fmt.Sprintf("ak%d", count), expire), nil
+	}
+
+	provider := newRefreshCredentialsProvider("test", fetch, nil, "key")
+	cred, err := provider.GetCredentialsE()
+	c.Assert(err, IsNil)
+	c.Assert(cred.GetAccessKeyID(), Equals, "ak1")
+	c.Assert(cred.GetAccessKeySecret(), Equals, "ak1-secret")
+	c.Assert(cred.GetSecurityToken(), Equals, "ak1-token")
+
+	// not expired
+	cred = provider.GetCredentials()
+	c.Assert(cred.GetAccessKeyID(), Equals, "ak1")
+	c.Assert(count, Equals, 1)
+
+	// expired in AdvanceSeconds, fetch again
+	expire = time.Duration(AdvanceSeconds/2) * time.Second
+	provider = newRefreshCredentialsProvider("test", fetch, nil, "key")
+	c.Assert(provider.GetCredentials().GetAccessKeyID(), Equals, "ak2")
+	c.Assert(provider.GetCredentials().GetAccessKeyID(), Equals, "ak3")
+
+	// error
+	provider = newRefreshCredentialsProvider("test", func() (STSAkJson, error) {
+		return STSAkJson{}, fmt.Errorf("fetch error")
+	}, nil, "key")
+	_, err = provider.GetCredentialsE()
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestCredentialsCache(c *C) {
+	cacheFile := randStr(10)
+	cache := NewCredentialsCache(cacheFile)
+	c.Assert(cache, NotNil)
+	c.Assert(NewCredentialsCache(CredentialsCacheOff), IsNil)
+	c.Assert(NewCredentialsCache(""), IsNil)
+
+	count := 0
+	fetch := func() (STSAkJson, error) {
+		count++
+		return testAkJson(fmt.Sprintf("ak%d", count), time.Hour), nil
+	}
+
+	// the second process gets the credentials from cache
+	provider := newRefreshCredentialsProvider("test", fetch, cache, "key")
+	c.Assert(provider.GetCredentials().GetAccessKeyID(), Equals, "ak1")
+	provider = newRefreshCredentialsProvider("test", fetch, NewCredentialsCache(cacheFile), "key")
+	c.Assert(provider.GetCredentials().GetAccessKeyID(), Equals, "ak1")
+	c.Assert(count, Equals, 1)
+
+	// different key
+	provider = newRefreshCredentialsProvider("test", fetch, cache, "other")
+	c.Assert(provider.GetCredentials().GetAccessKeyID(), Equals, "ak2")
+
+	f, err := os.Stat(cacheFile)
+	c.Assert(err, IsNil)
+	if runtime.GOOS != "windows" {
+		c.Assert(f.Mode().Perm(), Equals, os.FileMode(0600))
+	}
+
+	// expired and never expired credentials are not cached
+	cache.Put(credentialsCacheKey("test", "expired"), testAkJson("expired", time.Second))
+	_, ok := cache.Get(credentialsCacheKey("test", "expired"))
+	c.Assert(ok, Equals, false)
+
+	akJson := testAkJson("forever", time.Hour)
+	akJson.Expiration = ""
+	cache.Put(credentialsCacheKey("test", "forever"), akJson)
+	_, ok = cache.Get(credentialsCacheKey("test", "forever"))
+	c.Assert(ok, Equals, false)
+
+	// invalid cache file
+	s.createFile(cacheFile, "invalid", c)
+	_, ok = cache.Get(credentialsCacheKey("test", "key"))
+	c.Assert(ok, Equals, false)
+	os.Remove(cacheFile)
+}
+
+func (s *OssutilCommandSuite) TestCredentialsProviderChain(c *C) {
+	failed := newRefreshCredentialsProvider("failed", func() (STSAkJson, error) {
+		return STSAkJson{}, fmt.Errorf("failed provider")
+	}, nil)
+	count := 0
+	ok := newRefreshCredentialsProvider("ok", func() (STSAkJson, error) {
+		count++
+		return testAkJson("ok", time.Hour), nil
+	}, nil)
+
+	chain := NewCredentialsProviderChain(failed, ok)
+	cred, err := chain.GetCredentialsE()
+	c.Assert(err, IsNil)
+	c.Assert(cred.GetAccessKeyID(), Equals, "ok")
+	c.Assert(chain.GetCredentials().GetAccessKeyID(), Equals, "ok")
+	c.Assert(count, Equals, 1)
+
+	chain = NewCredentialsProviderChain(failed, failed)
+	_, err = chain.GetCredentialsE()
+	c.Assert(err, NotNil)
+
+	chain = NewCredentialsProviderChain(failed)
+	_, err = chain.GetCredentialsE()
+	c.Assert(err.Error(), Equals, "failed provider")
+}
+
+func (s *OssutilCommandSuite) TestCredentialProcess(c *C) {
+	if runtime.GOOS == "windows" {
+		c.Skip("the test command needs sh")
+	}
+
+	akJson := testAkJson("process", time.Hour)
+	data, _ := json.Marshal(akJson)
+	outFile := randStr(10)
+	s.createFile(outFile, string(data), c)
+	defer os.Remove(outFile)
+
+	provider := newProcessCredentialsProvider("cat "+outFile, nil)
+	cred, err := provider.GetCredentialsE()
+	c.Assert(err, IsNil)
+	c.Assert(cred.GetAccessKeyID(), Equals, akJson.AccessKeyId)
+	c.Assert(cred.GetAccessKeySecret(), Equals, akJson.AccessKeySecret)
+	c.Assert(cred.GetSecurityToken(), Equals, akJson.SecurityToken)
+
+	// command error
+	_, err = runCredentialProcess("echo failed >&2; exit 1")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*failed.*")
+
+	// invalid output
+	_, err = runCredentialProcess("echo invalid")
+	c.Assert(err, NotNil)
+	_, err = runCredentialProcess(`echo '{"AccessKeyId":"id"}'`)
+	c.Assert(err, NotNil)
+	_, err = runCredentialProcess(`echo '{"AccessKeyId":"id","AccessKeySecret":"secret","Expiration":"tomorrow"}'`)
+	c.Assert(err, NotNil)
+
+	// used by ossClient in CredentialProcess mode
+	str := ""
+	mode := "CredentialProcess"
+	process := "cat " + outFile
+	cache := CredentialsCacheOff
+	options := OptionMapType{
+		OptionEndpoint:          &str,
+		OptionMode:              &mode,
+		OptionCredentialProcess: &process,
+		OptionCredentialsCache:  &cache,
+	}
+	cmd := Command{options: options, configOptions: OptionMapType{}}
+	client, err := cmd.ossClient("")
+	c.Assert(err, IsNil)
+	c.Assert(client.Config.GetCredentials().GetAccessKeyID(), Equals, akJson.AccessKeyId)
+
+	// the chain is used if mode is empty
+	mode = ""
+	client, err = cmd.ossClient("")
+	c.Assert(err, IsNil)
+	c.Assert(client.Config.GetCredentials().GetAccessKeyID(), Equals, akJson.AccessKeyId)
+
+	process = ""
+	_, err = cmd.ossClient("")
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestOIDCCredentialsProvider(c *C) {
+	expiration := time.Now().Add(time.Hour).UTC().Format(TimeFormat)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Method != "POST" || r.Form.Get("Action") != "AssumeRoleWithOIDC" || r.Form.Get("OIDCToken") != "oidc-token" ||
+			r.Form.Get("RoleArn") != "role-arn" || r.Form.Get("OIDCProviderArn") != "provider-arn" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Code":"InvalidParameter","Message":"invalid parameter"}`))
+			return
+		}
+		w.Write([]byte(`{"RequestId":"id","Credentials":{"AccessKeyId":"STS.oidc","AccessKeySecret":"secret",` +
+			`"SecurityToken":"token","Expiration":"` + expiration + `"}}`))
+	}
+	svr := httptest.NewServer(http.HandlerFunc(handler))
+	defer svr.Close()
+
+	tokenFile := randStr(10)
+	s.createFile(tokenFile, "oidc-token\n", c)
+	defer os.Remove(tokenFile)
+
+	provider := newOIDCCredentialsProvider("role-arn", "provider-arn", tokenFile, "", 3600, svr.URL, nil)
+	cred, err := provider.GetCredentialsE()
+	c.Assert(err, IsNil)
+	c.Assert(cred.GetAccessKeyID(), Equals, "STS.oidc")
+	c.Assert(cred.GetSecurityToken(), Equals, "token")
+	c.Assert(provider.akJson.Expiration, Equals, expiration)
+
+	// service error
+	provider = newOIDCCredentialsProvider("other-arn", "provider-arn", tokenFile, "", 3600, svr.URL, nil)
+	_, err = provider.GetCredentialsE()
+	c.Assert(err, NotNil)
+
+	// token file not exist
+	provider = newOIDCCredentialsProvider("role-arn", "provider-arn", tokenFile+"-notexist", "", 3600, svr.URL, nil)
+	_, err = provider.GetCredentialsE()
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestLoadCredentialsOptionsFromConfig(c *C) {
+	cfile := randStr(10)
+	data := "[Credentials]\nendpoint=oss-cn-hangzhou.aliyuncs.com\nmode=CredentialProcess\n" +
+		"credential_process=cat creds.json\noidc-token-file=/var/run/token\ncredentialsCache=off\n"
+	err := ioutil.WriteFile(cfile, []byte(data), 0600)
+	c.Assert(err, IsNil)
+
+	opts, err := LoadConfig(cfile)
+	c.Assert(err, IsNil)
+	c.Assert(opts[OptionCredentialProcess], Equals, "cat creds.json")
+	c.Assert(opts[OptionOIDCTokenFile], Equals, "/var/run/token")
+	c.Assert(opts[OptionCredentialsCache], Equals, CredentialsCacheOff)
+	os.Remove(cfile)
+}
+
+func (s *OssutilCommandSuite) TestRamRoleArnProviderRefresh(c *C) {
+	count := 0
+	expire := time.Duration(AdvanceSeconds/2) * time.Second
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("Action") != "AssumeRole" || r.URL.Query().Get("RoleArn") != "role-arn" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"Code":"InvalidParameter","Message":"invalid parameter"}`))
+			return
+		}
+		count++
+		expiration := time.Now().Add(expire).UTC().Format(TimeFormat)
+		w.Write([]byte(fmt.Sprintf(`{"RequestId":"id","Credentials":{"AccessKeyId":"STS.%d","AccessKeySecret":"secret",`+
+			`"SecurityToken":"token","Expiration":"%s"}}`, count, expiration)))
+	}
+	svr := httptest.NewServer(http.HandlerFunc(handler))
+	defer svr.Close()
+
+	stsHost := StsHost
+	StsHost = svr.URL
+	defer func() { StsHost = stsHost }()
+
+	endpoint := "oss-cn-hangzhou.aliyuncs.com"
+	accessKeyID := "id"
+	accessKeySecret := "secret"
+	mode := "RamRoleArn"
+	roleArn := "role-arn"
+	cache := CredentialsCacheOff
+	options := OptionMapType{
+		OptionEndpoint:         &endpoint,
+		OptionAccessKeyID:      &accessKeyID,
+		OptionAccessKeySecret:  &accessKeySecret,
+		OptionMode:             &mode,
+		OptionRamRoleArn:       &roleArn,
+		OptionCredentialsCache: &cache,
+	}
+	cmd := Command{options: options, configOptions: OptionMapType{}}
+	client, err := cmd.ossClient("")
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, 1)
+
+	// the credentials expire in AdvanceSeconds, the role is assumed again
+	c.Assert(client.Config.GetCredentials().GetAccessKeyID(), Equals, "STS.2")
+	c.Assert(client.Config.GetCredentials().GetAccessKeyID(), Equals, "STS.3")
+
+	// not expired
+	expire = time.Hour
+	c.Assert(client.Config.GetCredentials().GetAccessKeyID(), Equals, "STS.4")
+	c.Assert(client.Config.GetCredentials().GetAccessKeyID(), Equals, "STS.4")
+	c.Assert(client.Config.GetCredentials().GetSecurityToken(), Equals, "token")
+	c.Assert(count, Equals, 4)
+
+	// assume role error
+	roleArn = "other-arn"
+	_, err = cmd.ossClient("")
+	c.Assert(err, NotNil)
+}
@@ -3,6 +3,7 @@ package lib
 import (
 	"fmt"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,6 +29,14 @@ var specChineseDu = SpecText{
 
     1) ossutil du oss://bucket[/prefix] [options]
       查询bucket或者指定前缀(目录)所占存储空间大小
+
+    如果指定了--output-format选项，ossutil以json、jsonl或csv格式输出结果，每种存储类型输出一条
+    type为storage_class的记录，最后输出一条type为summary的汇总记录，大小的单位总是字节，
+    --block-size选项不生效
+
+    可以使用--min-size、--max-size、--older-than、--newer-than、--storage-class、--has-tag和
+    --content-type选项只统计满足条件的objects，选项的格式请参考ls命令的帮助，未完成上传的块
+    不受这些选项影响，不支持--all-versions
 `,
 
 	sampleText: ` 
@@ -42,6 +51,9 @@ var specChineseDu = SpecText{
     
     4) 统计结果以KB为单位显示, 支持MB, GB, TB
        ossutil du oss://bucket/prefix --block-size KB
+
+    5) 统计180天以前修改的标准存储objects的大小
+       ossutil du oss://bucket/prefix --older-than 180d --storage-class Standard
 `,
 }
 
@@ -63,6 +75,14 @@ Usages：
 
     1) ossutil du oss://bucket[/prefix] [options]
        Gets the bucket or the specified prefix(directory) storage size
+
+    If --output-format option is specified, ossutil outputs the result in json, jsonl or csv format, 
+    one record whose type is storage_class per storage class, and a summary record whose type is 
+    summary at last, the sizes are always in bytes, and --block-size option doesn't take effect
+
+    --min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag and --content-type
+    options count only the objects matching them, see the help of ls command for the format of them,
+    the uncompleted parts are not affected by them, and --all-versions is not supported
 `,
 
 	sampleText: ` 
@@ -77,6 +97,9 @@ Usages：
 
     4) The du results are displayed in KB block size, Support MB, GB, TB
        ossutil du oss://bucket/prefix --block-size KB
+
+    5) get the size of the standard objects modified 180 days ago
+       ossutil du oss://bucket/prefix --older-than 180d --storage-class Standard
 `,
 }
 
@@ -89,6 +112,7 @@ type duSizeOptionType struct {
 	bucketName       string
 	object           string
 	payer            string
+	attrFilter       *attributeFilter
 	countTypeMap     map[string]int64
 	sizeTypeMap      map[string]int64
 	totalObjectCount int64
@@ -103,8 +127,12 @@ type duSizeOptionType struct {
 type DuCommand struct {
 	command  Command
 	duOption duSizeOptionType
+	output   *OutputWriter
 }
 
+var duColumns = []string{"type", "storage_class", "count", "size",
+	"object_count", "object_size", "part_count", "part_size", "total_size"}
+
 var duSizeCommand = DuCommand{
 	command: Command{
 		name:        "du",
@@ -116,6 +144,7 @@ var duSizeCommand = DuCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -125,6 +154,13 @@ var duSizeCommand = DuCommand{
 			OptionProxyPwd,
 			OptionLogLevel,
 			OptionRequestPayer,
+			OptionMinSize,
+			OptionMaxSize,
+			OptionOlderThan,
+			OptionNewerThan,
+			OptionStorageClass,
+			OptionHasTag,
+			OptionContentType,
 			OptionAllversions,
 			OptionPassword,
 			OptionBlockSize,
@@ -136,12 +172,17 @@ var duSizeCommand = DuCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
 			OptionRegion,
 			OptionCloudBoxID,
 			OptionForcePathStyle,
+			OptionOutputFormat,
 		},
 	},
 }
@@ -193,6 +234,13 @@ func (duc *DuCommand) RunCommand() error {
 	}
 	allVersions, _ := GetBool(OptionAllversions, duc.command.options)
 
+	if duc.duOption.attrFilter, err = duc.command.attributeFilter(); err != nil {
+		return err
+	}
+	if allVersions && duc.duOption.attrFilter != nil {
+		return fmt.Errorf("--all-versions can't be used with the options filtering by size, time, storage class, tag or content type")
+	}
+
 	strBlockSize, _ := GetString(OptionBlockSize, duc.command.options)
 	strBlockSize = strings.ToUpper(strBlockSize)
 	if strBlockSize == "" {
@@ -213,6 +261,9 @@ func (duc *DuCommand) RunCommand() error {
 		return err
 	}
 
+	duc.output = NewOutputWriter(getOutputFormat(duc.command.options), duColumns)
+	defer duc.output.Close()
+
 	// first:get all object size
 	if allVersions {
 		err = duc.getAllObjectVersionsSize(bucket)
@@ -224,6 +275,13 @@ func (duc *DuCommand) RunCommand() error {
 		return err
 	}
 
+	if duc.output != nil {
+		if err = duc.GetAllPartSize(bucket); err != nil {
+			return err
+		}
+		return duc.writeOutput()
+	}
+
 	printHeader := false
 	for k, v := range duc.duOption.countTypeMap {
 		if !printHeader {
@@ -259,12 +317,43 @@ func (duc *DuCommand) RunCommand() error {
 	return nil
 }
 
+// writeOutput writes the records of storage classes and the summary, the sizes are in bytes
+func (duc *DuCommand) writeOutput() error {
+	classes := []string{}
+	for k := range duc.duOption.countTypeMap {
+		classes = append(classes, k)
+	}
+	sort.Strings(classes)
+
+	for _, k := range classes {
+		if err := duc.output.Write(OutputRecord{
+			"type":          recordStorageClass,
+			"storage_class": k,
+			"count":         duc.duOption.countTypeMap[k],
+			"size":          duc.duOption.sizeTypeMap[k],
+		}); err != nil {
+			return err
+		}
+	}
+
+	return duc.output.Write(OutputRecord{
+		"type":         recordSummary,
+		"object_count": duc.duOption.totalObjectCount,
+		"object_size":  duc.duOption.sumObjectSize,
+		"part_count":   duc.duOption.totalPartCount,
+		"part_size":    duc.duOption.sumPartSize,
+		"total_size":   duc.duOption.sumObjectSize + duc.duOption.sumPartSize,
+	})
+}
+
 func (duc *DuCommand) getAllObjectSize(bucket *oss.Bucket) error {
 	pre := oss.Prefix(duc.duOption.object)
 	marker := oss.Marker("")
 	listOptions := []oss.Option{pre, marker, oss.MaxKeys(1000)}
+	var payerOptions []oss.Option
 	if duc.duOption.payer != "" {
-		listOptions = append(listOptions, oss.RequestPayer(oss.PayerType(duc.duOption.payer)))
+		payerOptions = append(payerOptions, oss.RequestPayer(oss.PayerType(duc.duOption.payer)))
+		listOptions = append(listOptions, payerOptions...)
 	}
 
 	for i := 1; ; i++ {
@@ -273,8 +362,15 @@ func (duc *DuCommand) getAllObjectSize(bucket *oss.Bucket) error {
 			return err
 		}
 
-		duc.duOption.totalObjectCount += int64(len(lor.Objects))
 		for _, object := range lor.Objects {
+			match, err := duc.duOption.attrFilter.matchObject(bucket, object, payerOptions...)
+			if err != nil {
+				return err
+			}
+			if !match {
+				continue
+			}
+			duc.duOption.totalObjectCount++
 			duc.duOption.sumObjectSize += object.Size
 			if _, ok := duc.duOption.countTypeMap[object.StorageClass]; ok {
 				duc.duOption.countTypeMap[object.StorageClass]++
@@ -285,7 +381,9 @@ func (duc *DuCommand) getAllObjectSize(bucket *oss.Bucket) error {
 			}
 		}
 
-		fmt.Printf("\robject count:%d\tobject sum size:%d", duc.duOption.totalObjectCount, duc.duOption.sumObjectSize)
+		if duc.output == nil {
+			fmt.Printf("\robject count:%d\tobject sum size:%d", duc.duOption.totalObjectCount, duc.duOption.sumObjectSize)
+		}
 
 		pre = oss.Prefix(lor.Prefix)
 		marker = oss.Marker(lor.NextMarker)
@@ -327,7 +425,9 @@ func (duc *DuCommand) getAllObjectVersionsSize(bucket *oss.Bucket) error {
 				duc.duOption.sizeTypeMap[object.StorageClass] = object.Size
 			}
 		}
-		fmt.Printf("\robject count:%d\tobject sum size:%d", duc.duOption.totalObjectCount, duc.duOption.sumObjectSize)
+		if duc.output == nil {
+			fmt.Printf("\robject count:%d\tobject sum size:%d", duc.duOption.totalObjectCount, duc.duOption.sumObjectSize)
+		}
 		keyMarker = oss.KeyMarker(lor.NextKeyMarker)
 		versionIdMarker := oss.VersionIdMarker(lor.NextVersionIdMarker)
 		listOptions = []oss.Option{pre, keyMarker, versionIdMarker, oss.MaxKeys(1000)}
@@ -395,7 +495,9 @@ func (duc *DuCommand) statPartSize(bucket *oss.Bucket, object MultiPartObject) e
 			for _, v := range lpRes.UploadedParts {
 				duc.duOption.sumPartSize += int64(v.Size)
 			}
-			fmt.Printf("\rpart count:%d\tpart sum size:%d", duc.duOption.totalPartCount, duc.duOption.sumPartSize)
+			if duc.output == nil {
+				fmt.Printf("\rpart count:%d\tpart sum size:%d", duc.duOption.totalPartCount, duc.duOption.sumPartSize)
+			}
 			duc.duOption.mutex.Unlock()
 		}
 
@@ -102,21 +102,7 @@ func (roleBuild *EcsRoleAKBuild) GetCredentialsE() (oss.Credentials, error) {
 }
 
 func (roleBuild *EcsRoleAKBuild) IsTimeOut() bool {
-	if roleBuild.Expiration == "" {
-		return false
-	}
-
-	// attention: can't use time.ParseInLocation(),ecsRole.Expiration is UTC time
-	utcExpirationTime, _ := time.Parse("2006-01-02T15:04:05Z", roleBuild.Expiration)
-
-	// Now() returns the current local time
-	nowLocalTime := time.Now()
-
-	// Unix() returns the number of seconds elapsedsince January 1, 1970 UTC.
-	if utcExpirationTime.Unix()-nowLocalTime.Unix()-AdvanceSeconds <= 0 {
-		return true
-	}
-	return false
+	return isCredentialsExpired(roleBuild.Expiration)
 }
 
 func (roleBuild *EcsRoleAKBuild) HttpReqAk() (STSAkJson, error) {
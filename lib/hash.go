@@ -3,11 +3,13 @@ package lib
 import (
 	"crypto/md5"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"hash"
 	"hash/crc64"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -171,13 +173,11 @@ func (hc *HashCommand) RunCommand() error {
 }
 
 func hashMD5(f io.Reader) error {
-	md5Ins := md5.New()
-	w, _ := md5Ins.(hash.Hash)
-	if _, err := io.Copy(w, f); err != nil {
+	result, err := calcMD5(f)
+	if err != nil {
 		return err
 	}
 
-	result := md5Ins.Sum(nil)
 	fmt.Printf("%-28s: %X\n", HashMD5, result)
 
 	encoded := base64.StdEncoding.EncodeToString(result)
@@ -186,13 +186,59 @@ func hashMD5(f io.Reader) error {
 }
 
 func hashCRC64(f io.Reader) error {
-	crc64Ins := crc64.New(crc64.MakeTable(crc64.ECMA))
-	w, _ := crc64Ins.(hash.Hash)
-	if _, err := io.Copy(w, f); err != nil {
+	result, err := calcCRC64(f)
+	if err != nil {
 		return err
 	}
 
-	result := crc64Ins.Sum64()
 	fmt.Printf("%-28s: %d\n", HashCRC64, result)
 	return nil
 }
+
+func calcMD5(f io.Reader) ([]byte, error) {
+	md5Ins := md5.New()
+	w, _ := md5Ins.(hash.Hash)
+	if _, err := io.Copy(w, f); err != nil {
+		return nil, err
+	}
+	return md5Ins.Sum(nil), nil
+}
+
+func calcCRC64(f io.Reader) (uint64, error) {
+	crc64Ins := crc64.New(crc64.MakeTable(crc64.ECMA))
+	w, _ := crc64Ins.(hash.Hash)
+	if _, err := io.Copy(w, f); err != nil {
+		return 0, err
+	}
+	return crc64Ins.Sum64(), nil
+}
+
+// fileMD5 returns the md5 of local file as upper case hex string, which is the same as etag
+func fileMD5(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	result, err := calcMD5(f)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(result)), nil
+}
+
+// fileCRC64 returns the crc64 of local file as decimal string, which is the same as x-oss-hash-crc64ecma
+func fileCRC64(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	result, err := calcCRC64(f)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(result, 10), nil
+}
@@ -0,0 +1,593 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	leveldb "github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// the status of job
+const (
+	JobStatusRunning     = "running"
+	JobStatusCompleted   = "completed"
+	JobStatusFailed      = "failed"
+	JobStatusInterrupted = "interrupted"
+)
+
+const (
+	jobMetaFile      = "job.json"
+	jobJournalDir    = "journal"
+	jobCheckpointDir = "checkpoint"
+
+	jobMarkerPrefix = "marker/"
+	jobDonePrefix   = "done/"
+	jobUploadPrefix = "upload/"
+
+	// the listing marker advances by pages, all items of the page must be done before the marker passes it
+	jobPageSize = 1000
+)
+
+var jobIDRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// JobMeta is the information of the job, it's saved in job.json so that it can be read while the job is running
+type JobMeta struct {
+	ID            string   `json:"id"`
+	Command       string   `json:"command"`
+	Args          []string `json:"args"`
+	Status        string   `json:"status"`
+	CheckpointDir string   `json:"checkpoint_dir"`
+	DestBucket    string   `json:"dest_bucket,omitempty"`
+	CreateTime    int64    `json:"create_time"`
+	UpdateTime    int64    `json:"update_time"`
+	RunCount      int64    `json:"run_count"`
+	DoneCount     int64    `json:"done_count"`
+	ErrorCount    int64    `json:"error_count"`
+	Error         string   `json:"error,omitempty"`
+}
+
+// JobUpload is the multipart upload in flight, it's aborted when the job is canceled
+type JobUpload struct {
+	Bucket   string `json:"bucket"`
+	Object   string `json:"object"`
+	UploadID string `json:"upload_id"`
+	CpFile   string `json:"cp_file,omitempty"`
+}
+
+type jobItem struct {
+	page      *jobPage
+	signature string
+}
+
+type jobPage struct {
+	stream  *jobStream
+	last    string
+	count   int
+	pending int
+	failed  bool
+	closed  bool
+	keys    []string
+}
+
+// jobStream is one listing in order, its marker is the last position before which all items are done
+type jobStream struct {
+	name    string
+	pages   []*jobPage
+	blocked bool
+}
+
+// JobJournal records the listing markers, the done items and the multipart uploads in flight of
+// a batch cp or sync, so that the job can continue where it stopped.
+type JobJournal struct {
+	lock      sync.Mutex
+	dir       string
+	db        *leveldb.DB
+	meta      JobMeta
+	streams   map[string]*jobStream
+	items     map[string]jobItem
+	markers   map[string]string
+	runErrors int64
+}
+
+// DecideJobsDir returns the directory of jobs, ~ is replaced with home directory
+func DecideJobsDir(jobsDir string) string {
+	if jobsDir == "" {
+		jobsDir = DefaultJobsDir
+	}
+	return DecideConfigFile(jobsDir)
+}
+
+func checkJobID(id string) error {
+	if !jobIDRegexp.MatchString(id) {
+		return fmt.Errorf("invalid job id %s, it can only contain letters, digits, _, . and -, and is no longer than 64", id)
+	}
+	return nil
+}
+
+// OpenJob opens the journal of job id, the job is created if it does not exist
+func OpenJob(jobsDir, id string) (*JobJournal, error) {
+	if err := checkJobID(id); err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(DecideJobsDir(jobsDir), id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := leveldb.OpenFile(filepath.Join(dir, jobJournalDir), nil)
+	if err != nil {
+		return nil, fmt.Errorf("open job %s error, the job may be running, %s", id, err.Error())
+	}
+
+	job := &JobJournal{
+		dir:     dir,
+		db:      db,
+		streams: map[string]*jobStream{},
+		items:   map[string]jobItem{},
+		markers: map[string]string{},
+	}
+	if meta, err := readJobMeta(dir); err == nil {
+		job.meta = meta
+	} else if !os.IsNotExist(err) {
+		db.Close()
+		return nil, err
+	}
+	return job, nil
+}
+
+func readJobMeta(dir string) (JobMeta, error) {
+	meta := JobMeta{}
+	data, err := ioutil.ReadFile(filepath.Join(dir, jobMetaFile))
+	if err != nil {
+		return meta, err
+	}
+	if err = json.Unmarshal(data, &meta); err != nil {
+		return meta, fmt.Errorf("invalid job file %s, %s", filepath.Join(dir, jobMetaFile), err.Error())
+	}
+	return meta, nil
+}
+
+// Meta returns the information of the job
+func (j *JobJournal) Meta() JobMeta {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.meta
+}
+
+// Resumed returns true if the job has been run before
+func (j *JobJournal) Resumed() bool {
+	return j.meta.RunCount > 1
+}
+
+// Start begins a new run of the job, the command and args must be the same as the first run.
+// A completed job starts over.
+func (j *JobJournal) Start(command string, args []string, cpDir, destBucket string) error {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	now := time.Now().Unix()
+	if j.meta.ID == "" {
+		if cpDir == "" {
+			cpDir = filepath.Join(j.dir, jobCheckpointDir)
+		}
+		absDir, err := filepath.Abs(cpDir)
+		if err != nil {
+			return err
+		}
+		j.meta = JobMeta{
+			ID:            filepath.Base(j.dir),
+			Command:       command,
+			Args:          args,
+			CheckpointDir: absDir,
+			DestBucket:    destBucket,
+			CreateTime:    now,
+		}
+	} else {
+		if j.meta.Command != command || strings.Join(j.meta.Args, "\n") != strings.Join(args, "\n") {
+			return fmt.Errorf("job %s is \"%s %s\", the command to resume must be the same", j.meta.ID, j.meta.Command, strings.Join(j.meta.Args, " "))
+		}
+		if j.meta.Status == JobStatusCompleted {
+			LogInfo("job %s is completed, start over\n", j.meta.ID)
+			if err := j.reset(); err != nil {
+				return err
+			}
+		}
+	}
+
+	j.meta.Status = JobStatusRunning
+	j.meta.Error = ""
+	j.meta.UpdateTime = now
+	j.meta.RunCount++
+	return j.saveMeta()
+}
+
+func (j *JobJournal) reset() error {
+	batch := new(leveldb.Batch)
+	iter := j.db.NewIterator(nil, nil)
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	j.meta.RunCount = 0
+	j.meta.DoneCount = 0
+	j.meta.ErrorCount = 0
+	return j.db.Write(batch, nil)
+}
+
+// saveMeta must be called with lock held
+func (j *JobJournal) saveMeta() error {
+	data, err := json.MarshalIndent(j.meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile := filepath.Join(j.dir, jobMetaFile+".tmp")
+	if err = ioutil.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, filepath.Join(j.dir, jobMetaFile))
+}
+
+// CheckpointDir returns the checkpoint directory of the job, the multipart uploads continue from it
+func (j *JobJournal) CheckpointDir() string {
+	return j.meta.CheckpointDir
+}
+
+// Marker returns the position of stream before which all items are done
+func (j *JobJournal) Marker(stream string) string {
+	if j == nil {
+		return ""
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if marker, ok := j.markers[stream]; ok {
+		return marker
+	}
+	marker := ""
+	if value, err := j.db.Get([]byte(jobMarkerPrefix+stream), nil); err == nil {
+		marker = string(value)
+	}
+	j.markers[stream] = marker
+	return marker
+}
+
+// IsDone returns true if the item is done in the job and it has not changed since then
+func (j *JobJournal) IsDone(key, signature string) bool {
+	if j == nil {
+		return false
+	}
+	value, err := j.db.Get([]byte(jobDonePrefix+key), nil)
+	return err == nil && string(value) == signature
+}
+
+// Add records the item at position of stream, it returns false if the item is done already
+func (j *JobJournal) Add(stream, key, position, signature string) bool {
+	if j == nil {
+		return true
+	}
+	if j.IsDone(key, signature) {
+		return false
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if _, ok := j.items[key]; ok {
+		return false
+	}
+
+	s := j.getStream(stream)
+	var page *jobPage
+	if len(s.pages) > 0 && !s.pages[len(s.pages)-1].closed {
+		page = s.pages[len(s.pages)-1]
+	} else {
+		page = &jobPage{stream: s}
+		s.pages = append(s.pages, page)
+	}
+	page.last = position
+	page.count++
+	page.pending++
+	if page.count >= jobPageSize {
+		page.closed = true
+	}
+	j.items[key] = jobItem{page: page, signature: signature}
+	return true
+}
+
+// EndStream marks that all items of stream are added
+func (j *JobJournal) EndStream(stream string) {
+	if j == nil {
+		return
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	s := j.getStream(stream)
+	if len(s.pages) > 0 {
+		s.pages[len(s.pages)-1].closed = true
+	}
+	j.commit(s)
+}
+
+// Done records the result of the item added before, its signature is saved if it succeeds
+func (j *JobJournal) Done(key string, err error) {
+	if j == nil {
+		return
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	item, ok := j.items[key]
+	if !ok {
+		return
+	}
+	delete(j.items, key)
+	page := item.page
+	page.pending--
+
+	if err != nil {
+		page.failed = true
+		j.meta.ErrorCount++
+		j.runErrors++
+	} else {
+		if errPut := j.db.Put([]byte(jobDonePrefix+key), []byte(item.signature), nil); errPut != nil {
+			LogError("job %s record done item %s error,%s\n", j.meta.ID, key, errPut.Error())
+		}
+		if !page.stream.blocked {
+			page.keys = append(page.keys, key)
+		}
+		j.meta.DoneCount++
+	}
+	j.commit(page.stream)
+}
+
+func (j *JobJournal) getStream(stream string) *jobStream {
+	s, ok := j.streams[stream]
+	if !ok {
+		s = &jobStream{name: stream}
+		j.streams[stream] = s
+	}
+	return s
+}
+
+// commit advances the marker of stream past the leading pages whose items are all done,
+// the done records before the marker are not needed any more. It must be called with lock held.
+func (j *JobJournal) commit(s *jobStream) {
+	if s.blocked {
+		return
+	}
+
+	batch := new(leveldb.Batch)
+	for len(s.pages) > 0 {
+		page := s.pages[0]
+		if !page.closed || page.pending > 0 {
+			break
+		}
+		if page.failed {
+			// the items after the failed one are not listed again, they are skipped by their done records
+			s.blocked = true
+			s.pages = nil
+			break
+		}
+		if page.count > 0 {
+			batch.Put([]byte(jobMarkerPrefix+s.name), []byte(page.last))
+			j.markers[s.name] = page.last
+		}
+		for _, key := range page.keys {
+			batch.Delete([]byte(jobDonePrefix + key))
+		}
+		s.pages = s.pages[1:]
+	}
+
+	if batch.Len() == 0 {
+		return
+	}
+	if err := j.db.Write(batch, nil); err != nil {
+		LogError("job %s commit marker of %s error,%s\n", j.meta.ID, s.name, err.Error())
+		return
+	}
+	j.meta.UpdateTime = time.Now().Unix()
+	if err := j.saveMeta(); err != nil {
+		LogError("job %s save error,%s\n", j.meta.ID, err.Error())
+	}
+}
+
+// End finishes the run of the job, the job is completed if there is no error,
+// the multipart uploads in flight are recorded so that they can be aborted when the job is canceled.
+func (j *JobJournal) End(err error) {
+	if j == nil {
+		return
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.meta.Status != JobStatusRunning {
+		return
+	}
+
+	if err == nil && j.runErrors == 0 {
+		j.meta.Status = JobStatusCompleted
+	} else {
+		j.meta.Status = JobStatusFailed
+		if err != nil {
+			j.meta.Error = err.Error()
+		} else {
+			j.meta.Error = fmt.Sprintf("%d items failed", j.runErrors)
+		}
+	}
+	j.meta.UpdateTime = time.Now().Unix()
+	if errSave := j.saveMeta(); errSave != nil {
+		LogError("job %s save error,%s\n", j.meta.ID, errSave.Error())
+	}
+
+	batch := new(leveldb.Batch)
+	iter := j.db.NewIterator(util.BytesPrefix([]byte(jobUploadPrefix)), nil)
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+	for _, upload := range scanCheckpointUploads(j.meta.CheckpointDir, j.meta.DestBucket) {
+		data, _ := json.Marshal(upload)
+		batch.Put([]byte(jobUploadPrefix+upload.Bucket+"/"+upload.Object), data)
+	}
+	if errWrite := j.db.Write(batch, nil); errWrite != nil {
+		LogError("job %s record uploads error,%s\n", j.meta.ID, errWrite.Error())
+	}
+}
+
+// Close ends the job with error if it's not ended, and closes the journal
+func (j *JobJournal) Close() {
+	if j == nil {
+		return
+	}
+	j.End(fmt.Errorf("job is interrupted"))
+	j.db.Close()
+}
+
+// Markers returns the markers of all streams
+func (j *JobJournal) Markers() map[string]string {
+	markers := map[string]string{}
+	iter := j.db.NewIterator(util.BytesPrefix([]byte(jobMarkerPrefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		markers[strings.TrimPrefix(string(iter.Key()), jobMarkerPrefix)] = string(iter.Value())
+	}
+	return markers
+}
+
+// Uploads returns the multipart uploads in flight, including the ones recorded
+// at the end of the last run and the ones in the checkpoint directory
+func (j *JobJournal) Uploads() []JobUpload {
+	uploadMap := map[string]JobUpload{}
+	iter := j.db.NewIterator(util.BytesPrefix([]byte(jobUploadPrefix)), nil)
+	for iter.Next() {
+		var upload JobUpload
+		if json.Unmarshal(iter.Value(), &upload) == nil {
+			uploadMap[upload.UploadID] = upload
+		}
+	}
+	iter.Release()
+
+	for _, upload := range scanCheckpointUploads(j.meta.CheckpointDir, j.meta.DestBucket) {
+		uploadMap[upload.UploadID] = upload
+	}
+
+	uploads := []JobUpload{}
+	for _, upload := range uploadMap {
+		uploads = append(uploads, upload)
+	}
+	sort.Slice(uploads, func(i, k int) bool {
+		return uploads[i].Bucket+"/"+uploads[i].Object < uploads[k].Bucket+"/"+uploads[k].Object
+	})
+	return uploads
+}
+
+// scanCheckpointUploads reads the upload ids in the checkpoint files of multipart upload and copy,
+// the checkpoint file of upload does not contain bucket, so the bucket of the job is used.
+func scanCheckpointUploads(cpDir, destBucket string) []JobUpload {
+	uploads := []JobUpload{}
+	files, err := ioutil.ReadDir(cpDir)
+	if err != nil {
+		return uploads
+	}
+
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		cpFile := filepath.Join(cpDir, f.Name())
+		data, err := ioutil.ReadFile(cpFile)
+		if err != nil {
+			continue
+		}
+		var cp struct {
+			ObjectKey      string
+			UploadID       string
+			DestBucketName string
+			DestObjectKey  string
+			CopyID         string
+		}
+		if json.Unmarshal(data, &cp) != nil {
+			continue
+		}
+		if cp.UploadID != "" && cp.ObjectKey != "" && destBucket != "" {
+			uploads = append(uploads, JobUpload{destBucket, cp.ObjectKey, cp.UploadID, cpFile})
+		} else if cp.CopyID != "" && cp.DestObjectKey != "" {
+			uploads = append(uploads, JobUpload{cp.DestBucketName, cp.DestObjectKey, cp.CopyID, cpFile})
+		}
+	}
+	return uploads
+}
+
+// ListJobs returns the jobs in jobsDir, the status of the job which is running is JobStatusRunning,
+// and the one stopped while running is JobStatusInterrupted.
+func ListJobs(jobsDir string) ([]JobMeta, error) {
+	jobs := []JobMeta{}
+	dirs, err := ioutil.ReadDir(DecideJobsDir(jobsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return jobs, nil
+		}
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		meta, err := GetJobMeta(jobsDir, dir.Name())
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, meta)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].CreateTime < jobs[k].CreateTime })
+	return jobs, nil
+}
+
+// GetJobMeta returns the information of job id
+func GetJobMeta(jobsDir, id string) (JobMeta, error) {
+	if err := checkJobID(id); err != nil {
+		return JobMeta{}, err
+	}
+
+	dir := filepath.Join(DecideJobsDir(jobsDir), id)
+	meta, err := readJobMeta(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return meta, fmt.Errorf("job %s does not exist", id)
+		}
+		return meta, err
+	}
+
+	if meta.Status == JobStatusRunning {
+		// the journal is locked by the running job
+		db, err := leveldb.OpenFile(filepath.Join(dir, jobJournalDir), &opt.Options{ErrorIfMissing: true})
+		if err == nil {
+			db.Close()
+			meta.Status = JobStatusInterrupted
+		}
+	}
+	return meta, nil
+}
+
+// RemoveJob removes the journal of job id, and the checkpoint directory in it
+func RemoveJob(jobsDir, id string) error {
+	if err := checkJobID(id); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(DecideJobsDir(jobsDir), id))
+}
@@ -0,0 +1,264 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) TestJobJournalMarker(c *C) {
+	jobsDir := "ossutil-jobs-" + randLowStr(6)
+	defer os.RemoveAll(jobsDir)
+
+	job, err := OpenJob(jobsDir, "job1")
+	c.Assert(err, IsNil)
+	c.Assert(job.Start("cp", []string{"dir", "oss://bucket"}, "", "bucket"), IsNil)
+	c.Assert(job.Resumed(), Equals, false)
+	cpDir, _ := filepath.Abs(filepath.Join(jobsDir, "job1", jobCheckpointDir))
+	c.Assert(job.CheckpointDir(), Equals, cpDir)
+
+	// the job is running, the journal is locked
+	_, err = OpenJob(jobsDir, "job1")
+	c.Assert(err, NotNil)
+	meta, err := GetJobMeta(jobsDir, "job1")
+	c.Assert(err, IsNil)
+	c.Assert(meta.Status, Equals, JobStatusRunning)
+
+	// the marker advances only after all items of the page are done
+	for i := 0; i < jobPageSize+2; i++ {
+		key := fmt.Sprintf("key%05d", i)
+		c.Assert(job.Add("stream", key, key, "sig"), Equals, true)
+	}
+	c.Assert(job.Add("stream", "key00000", "key00000", "sig"), Equals, false)
+	for i := 1; i < jobPageSize; i++ {
+		job.Done(fmt.Sprintf("key%05d", i), nil)
+	}
+	c.Assert(job.Marker("stream"), Equals, "")
+	job.Done("key00000", nil)
+	c.Assert(job.Marker("stream"), Equals, fmt.Sprintf("key%05d", jobPageSize-1))
+	c.Assert(job.IsDone("key00000", "sig"), Equals, false)
+
+	// the last page is closed by EndStream
+	job.Done(fmt.Sprintf("key%05d", jobPageSize), nil)
+	job.Done(fmt.Sprintf("key%05d", jobPageSize+1), nil)
+	c.Assert(job.Marker("stream"), Equals, fmt.Sprintf("key%05d", jobPageSize-1))
+	job.EndStream("stream")
+	c.Assert(job.Marker("stream"), Equals, fmt.Sprintf("key%05d", jobPageSize+1))
+
+	job.End(nil)
+	c.Assert(job.Meta().Status, Equals, JobStatusCompleted)
+	c.Assert(job.Meta().DoneCount, Equals, int64(jobPageSize+2))
+	job.Close()
+
+	// the completed job starts over
+	job, err = OpenJob(jobsDir, "job1")
+	c.Assert(err, IsNil)
+	c.Assert(job.Start("cp", []string{"dir", "oss://bucket"}, "", "bucket"), IsNil)
+	c.Assert(job.Meta().RunCount, Equals, int64(1))
+	c.Assert(job.Meta().DoneCount, Equals, int64(0))
+	c.Assert(job.Marker("stream"), Equals, "")
+	job.Close()
+}
+
+func (s *OssutilCommandSuite) TestJobJournalResume(c *C) {
+	jobsDir := "ossutil-jobs-" + randLowStr(6)
+	defer os.RemoveAll(jobsDir)
+
+	_, err := OpenJob(jobsDir, "invalid/id")
+	c.Assert(err, NotNil)
+
+	job, err := OpenJob(jobsDir, "job2")
+	c.Assert(err, IsNil)
+	c.Assert(job.Start("sync", []string{"oss://bucket/dir/", "dir"}, "cpdir", ""), IsNil)
+	c.Assert(job.Add("stream", "a", "a", "1"), Equals, true)
+	c.Assert(job.Add("stream", "b", "b", "1"), Equals, true)
+	c.Assert(job.Add("stream", "c", "c", "1"), Equals, true)
+	job.EndStream("stream")
+
+	// the failed item blocks the marker, the done items are skipped by their records
+	job.Done("a", nil)
+	job.Done("b", fmt.Errorf("failed"))
+	job.Done("c", nil)
+	c.Assert(job.Marker("stream"), Equals, "")
+	c.Assert(job.IsDone("a", "1"), Equals, true)
+	c.Assert(job.IsDone("a", "2"), Equals, false)
+	c.Assert(job.IsDone("b", "1"), Equals, false)
+	job.End(nil)
+	c.Assert(job.Meta().Status, Equals, JobStatusFailed)
+	c.Assert(job.Meta().ErrorCount, Equals, int64(1))
+	job.Close()
+
+	job, err = OpenJob(jobsDir, "job2")
+	c.Assert(err, IsNil)
+	c.Assert(job.Start("sync", []string{"oss://bucket/dir/", "other"}, "", ""), NotNil)
+	c.Assert(job.Start("cp", []string{"oss://bucket/dir/", "dir"}, "", ""), NotNil)
+	c.Assert(job.Start("sync", []string{"oss://bucket/dir/", "dir"}, "", ""), IsNil)
+	c.Assert(job.Resumed(), Equals, true)
+	absDir, _ := filepath.Abs("cpdir")
+	c.Assert(job.CheckpointDir(), Equals, absDir)
+	c.Assert(job.Add("stream", "a", "a", "1"), Equals, false)
+	c.Assert(job.Add("stream", "b", "b", "1"), Equals, true)
+	c.Assert(job.Add("stream", "c", "c", "1"), Equals, false)
+
+	// stop without ending the job
+	job.db.Close()
+	meta, err := GetJobMeta(jobsDir, "job2")
+	c.Assert(err, IsNil)
+	c.Assert(meta.Status, Equals, JobStatusInterrupted)
+	c.Assert(meta.RunCount, Equals, int64(2))
+
+	jobs, err := ListJobs(jobsDir)
+	c.Assert(err, IsNil)
+	c.Assert(len(jobs), Equals, 1)
+	c.Assert(jobs[0].ID, Equals, "job2")
+
+	c.Assert(RemoveJob(jobsDir, "job2"), IsNil)
+	_, err = GetJobMeta(jobsDir, "job2")
+	c.Assert(err, NotNil)
+	jobs, err = ListJobs(jobsDir + "-notexist")
+	c.Assert(err, IsNil)
+	c.Assert(len(jobs), Equals, 0)
+}
+
+func (s *OssutilCommandSuite) TestJobCheckpointUploads(c *C) {
+	jobsDir := "ossutil-jobs-" + randLowStr(6)
+	defer os.RemoveAll(jobsDir)
+
+	job, err := OpenJob(jobsDir, "job3")
+	c.Assert(err, IsNil)
+	c.Assert(job.Start("cp", []string{"dir", "oss://bucket/dir/"}, "", "bucket"), IsNil)
+
+	cpDir := job.CheckpointDir()
+	c.Assert(os.MkdirAll(cpDir, 0755), IsNil)
+	s.createFile(filepath.Join(cpDir, "upload.cp"), `{"ObjectKey":"dir/a","UploadID":"id1"}`, c)
+	s.createFile(filepath.Join(cpDir, "copy.cp"), `{"DestBucketName":"bucket2","DestObjectKey":"dir/b","CopyID":"id2"}`, c)
+	s.createFile(filepath.Join(cpDir, "download.cp"), `{"Object":"dir/c"}`, c)
+	s.createFile(filepath.Join(cpDir, "invalid.cp"), `invalid`, c)
+
+	uploads := scanCheckpointUploads(cpDir, "bucket")
+	sort.Slice(uploads, func(i, k int) bool { return uploads[i].UploadID < uploads[k].UploadID })
+	c.Assert(len(uploads), Equals, 2)
+	c.Assert(uploads[0], Equals, JobUpload{"bucket", "dir/a", "id1", filepath.Join(cpDir, "upload.cp")})
+	c.Assert(uploads[1], Equals, JobUpload{"bucket2", "dir/b", "id2", filepath.Join(cpDir, "copy.cp")})
+
+	// the uploads are recorded at the end, they are kept even if the checkpoint files are removed
+	job.End(fmt.Errorf("interrupted"))
+	c.Assert(os.RemoveAll(cpDir), IsNil)
+	c.Assert(len(job.Uploads()), Equals, 2)
+	job.Close()
+}
+
+func (s *OssutilCommandSuite) TestComparePathInWalkOrder(c *C) {
+	c.Assert(comparePathInWalkOrder("a", "a"), Equals, 0)
+	c.Assert(comparePathInWalkOrder("a", "b") < 0, Equals, true)
+	c.Assert(comparePathInWalkOrder("a", filepath.Join("a", "b")) < 0, Equals, true)
+	c.Assert(comparePathInWalkOrder(filepath.Join("a", "z"), "a-b") < 0, Equals, true)
+	c.Assert(comparePathInWalkOrder(filepath.Join("b", "a"), filepath.Join("a", "z")) > 0, Equals, true)
+
+	c.Assert(isAncestorPath("a", "a"), Equals, true)
+	c.Assert(isAncestorPath("a", filepath.Join("a", "b")), Equals, true)
+	c.Assert(isAncestorPath("a", "ab"), Equals, false)
+	c.Assert(isAncestorPath(filepath.Join("a", "b"), "a"), Equals, false)
+}
+
+func (s *OssutilCommandSuite) TestJobFileList(c *C) {
+	jobsDir := "ossutil-jobs-" + randLowStr(6)
+	defer os.RemoveAll(jobsDir)
+	dir := "ossutil-job-dir-" + randLowStr(6)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a/1", "a/2", "b/1", "c"} {
+		c.Assert(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755), IsNil)
+		s.createFile(filepath.Join(dir, name), name, c)
+	}
+
+	cc := CopyCommand{}
+	startJob := func() {
+		job, err := OpenJob(jobsDir, "job4")
+		c.Assert(err, IsNil)
+		c.Assert(job.Start("cp", []string{dir, "oss://bucket"}, "", "bucket"), IsNil)
+		cc.cpOption.job = job
+	}
+	listFiles := func() []fileInfoType {
+		chFiles := make(chan fileInfoType, 100)
+		c.Assert(cc.getFileList(dir+string(os.PathSeparator), chFiles), IsNil)
+		close(chFiles)
+		files := []fileInfoType{}
+		for file := range chFiles {
+			files = append(files, file)
+		}
+		return files
+	}
+
+	// a, a/1, a/2, b, b/1, c
+	startJob()
+	files := listFiles()
+	c.Assert(len(files), Equals, 6)
+	c.Assert(files[2].filePath, Equals, filepath.Join("a", "2"))
+	for _, file := range files[:3] {
+		cc.cpOption.job.Done(fileJobKey(file), nil)
+	}
+	cc.closeJob()
+
+	// the done items are not listed again
+	startJob()
+	files = listFiles()
+	c.Assert(len(files), Equals, 3)
+	c.Assert(files[0].filePath, Equals, "b"+string(os.PathSeparator))
+	cc.closeJob()
+
+	// the modified file is listed again, the marker advances after all items are done
+	s.createFile(filepath.Join(dir, "a", "2"), "modified", c)
+	startJob()
+	files = listFiles()
+	c.Assert(len(files), Equals, 4)
+	c.Assert(files[0].filePath, Equals, filepath.Join("a", "2"))
+	for _, file := range files {
+		cc.cpOption.job.Done(fileJobKey(file), nil)
+	}
+	c.Assert(cc.cpOption.job.Marker(dir+string(os.PathSeparator)), Equals, "c")
+	cc.closeJob()
+
+	startJob()
+	c.Assert(len(listFiles()), Equals, 0)
+	cc.closeJob()
+}
+
+func (s *OssutilCommandSuite) TestCopyResumeJobOptions(c *C) {
+	jobsDir := "ossutil-jobs-" + randLowStr(6)
+	defer os.RemoveAll(jobsDir)
+
+	jobID := "job5"
+	options := OptionMapType{
+		OptionResumeJob: &jobID,
+		OptionJobsDir:   &jobsDir,
+	}
+	cc := CopyCommand{command: Command{options: options, args: []string{"dir", "oss://bucket"}}}
+	destURL, err := StorageURLFromString("oss://bucket", "")
+	c.Assert(err, IsNil)
+
+	c.Assert(cc.openJob(destURL, operationTypePut), NotNil)
+	cc.cpOption.recursive = true
+	cc.cpOption.dryRun = true
+	c.Assert(cc.openJob(destURL, operationTypePut), NotNil)
+
+	cc.cpOption.dryRun = false
+	cc.cpOption.cpDir = CheckpointDir
+	c.Assert(cc.openJob(destURL, operationTypePut), IsNil)
+	c.Assert(cc.cpOption.job, NotNil)
+	c.Assert(cc.cpOption.cpDir, Equals, cc.cpOption.job.CheckpointDir())
+	c.Assert(cc.cpOption.job.Meta().DestBucket, Equals, "bucket")
+	cc.endJob(nil)
+	cc.closeJob()
+
+	meta, err := GetJobMeta(jobsDir, jobID)
+	c.Assert(err, IsNil)
+	c.Assert(meta.Status, Equals, JobStatusCompleted)
+
+	jobID = ""
+	c.Assert(cc.openJob(destURL, operationTypePut), IsNil)
+	c.Assert(cc.cpOption.job, IsNil)
+}
@@ -0,0 +1,296 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+var specChineseJobs = SpecText{
+	synopsisText: "查看、取消cp或sync的批量任务",
+
+	paramText: "command_name [job_id] [options]",
+
+	syntaxText: `
+    ossutil jobs list [--jobs-dir dir]
+    ossutil jobs show job_id [--jobs-dir dir]
+    ossutil jobs cancel job_id [--jobs-dir dir]
+`,
+	detailHelpText: `
+    cp或者sync命令使用--resume-job选项运行时，任务的进度记录在任务日志中，包括列举的位置、
+    已完成的文件以及未完成的分片上传。jobs命令通过设置第一个参数为list、show、cancel，可以
+    查看或者取消这些任务。任务日志缺省保存在` + DefaultJobsDir + `目录下，可以通过--jobs-dir选项指定。
+
+用法:
+    该命令有三种用法:
+
+    1) ossutil jobs list
+        这个命令列出所有任务的ID、命令、状态、已完成数量、失败数量、更新时间和参数
+        状态为running的任务正在运行，interrupted的任务在运行中被中断，failed的任务有文件失败，
+        这些任务都可以使用相同的命令和--resume-job选项继续运行
+
+    2) ossutil jobs show job_id
+        这个命令显示任务的详细信息，包括各个列举的位置和未完成的分片上传
+
+    3) ossutil jobs cancel job_id
+        这个命令取消任务，未完成的分片上传被删除，任务日志和checkpoint文件也被删除
+        正在运行的任务不能取消
+`,
+	sampleText: `
+    1) 列出所有任务
+        ossutil jobs list
+
+    2) 显示任务backup的详细信息
+        ossutil jobs show backup
+
+    3) 取消任务backup
+        ossutil jobs cancel backup
+`,
+}
+
+var specEnglishJobs = SpecText{
+	synopsisText: "List, show or cancel the batch jobs of cp or sync",
+
+	paramText: "command_name [job_id] [options]",
+
+	syntaxText: `
+    ossutil jobs list [--jobs-dir dir]
+    ossutil jobs show job_id [--jobs-dir dir]
+    ossutil jobs cancel job_id [--jobs-dir dir]
+`,
+	detailHelpText: `
+    When cp or sync runs with --resume-job option, the progress of the job is recorded in its journal,
+    including the listing positions, the finished files and the multipart uploads in flight. The jobs
+    command can list, show or cancel the jobs by setting the first parameter to list, show and cancel.
+    The journals are saved in ` + DefaultJobsDir + ` by default, which can be changed by --jobs-dir option.
+
+Usage:
+    There are 3 usages for this command:
+
+    1) ossutil jobs list
+        This command lists the id, command, status, done count, error count, update time and args of all jobs.
+        The running job is still running, the interrupted job stopped while running, the failed job has
+        some files failed, they can continue by running the same command with --resume-job option.
+
+    2) ossutil jobs show job_id
+        This command shows the detail of the job, including the listing positions and the multipart
+        uploads in flight.
+
+    3) ossutil jobs cancel job_id
+        This command cancels the job, the multipart uploads in flight are aborted, the journal and the
+        checkpoint files of the job are removed. The running job can not be canceled.
+`,
+	sampleText: `
+    1) list all jobs
+        ossutil jobs list
+
+    2) show the detail of job backup
+        ossutil jobs show backup
+
+    3) cancel job backup
+        ossutil jobs cancel backup
+`,
+}
+
+type JobsCommand struct {
+	command Command
+	jobsDir string
+}
+
+var jobsCommand = JobsCommand{
+	command: Command{
+		name:        "jobs",
+		nameAlias:   []string{"jobs"},
+		minArgc:     1,
+		maxArgc:     2,
+		specChinese: specChineseJobs,
+		specEnglish: specEnglishJobs,
+		group:       GroupTypeAdditionalCommand,
+		validOptionNames: []string{
+			OptionJobsDir,
+			OptionConfigFile,
+			OptionProfile,
+			OptionEndpoint,
+			OptionAccessKeyID,
+			OptionAccessKeySecret,
+			OptionSTSToken,
+			OptionProxyHost,
+			OptionProxyUser,
+			OptionProxyPwd,
+			OptionLogLevel,
+			OptionPassword,
+			OptionMode,
+			OptionECSRoleName,
+			OptionTokenTimeout,
+			OptionRamRoleArn,
+			OptionRoleSessionName,
+			OptionReadTimeout,
+			OptionConnectTimeout,
+			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
+			OptionSkipVerifyCert,
+			OptionUserAgent,
+			OptionSignVersion,
+			OptionRegion,
+			OptionCloudBoxID,
+			OptionForcePathStyle,
+		},
+	},
+}
+
+// function for FormatHelper interface
+func (jc *JobsCommand) formatHelpForWhole() string {
+	return jc.command.formatHelpForWhole()
+}
+
+func (jc *JobsCommand) formatIndependHelp() string {
+	return jc.command.formatIndependHelp()
+}
+
+// Init simulate inheritance, and polymorphism
+func (jc *JobsCommand) Init(args []string, options OptionMapType) error {
+	return jc.command.Init(args, options, jc)
+}
+
+// RunCommand simulate inheritance, and polymorphism
+func (jc *JobsCommand) RunCommand() error {
+	// init all command name
+	commandDict := make(map[string]string)
+	commandDict["list"] = "list"
+	commandDict["show"] = "show"
+	commandDict["cancel"] = "cancel"
+
+	// check command name
+	strCommand := jc.command.args[0]
+	_, ok := commandDict[strCommand]
+	if !ok {
+		return fmt.Errorf("invalid parameter %s,which must be list, show, cancel", strCommand)
+	}
+
+	jc.jobsDir, _ = GetString(OptionJobsDir, jc.command.options)
+	if strCommand == "list" {
+		return jc.listJobs()
+	}
+
+	if len(jc.command.args) < 2 {
+		return fmt.Errorf("missing parameter,the job id is empty")
+	}
+	if strCommand == "show" {
+		return jc.showJob(jc.command.args[1])
+	}
+	return jc.cancelJob(jc.command.args[1])
+}
+
+func (jc *JobsCommand) listJobs() error {
+	jobs, err := ListJobs(jc.jobsDir)
+	if err != nil {
+		return err
+	}
+
+	if len(jobs) > 0 {
+		fmt.Printf("%-20s%s%-8s%s%-12s%s%10s%s%10s%s%-20s%s%s\n", "JobID", FormatTAB, "Command", FormatTAB, "Status", FormatTAB,
+			"Done", FormatTAB, "Error", FormatTAB, "UpdateTime", FormatTAB, "Args")
+	}
+	for _, meta := range jobs {
+		fmt.Printf("%-20s%s%-8s%s%-12s%s%10d%s%10d%s%-20s%s%s\n", meta.ID, FormatTAB, meta.Command, FormatTAB, meta.Status, FormatTAB,
+			meta.DoneCount, FormatTAB, meta.ErrorCount, FormatTAB, formatJobTime(meta.UpdateTime), FormatTAB, strings.Join(meta.Args, " "))
+	}
+	fmt.Printf("\nJob Number is: %d\n\n", len(jobs))
+	return nil
+}
+
+func (jc *JobsCommand) showJob(id string) error {
+	meta, err := GetJobMeta(jc.jobsDir, id)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-18s: %s\n", "JobID", meta.ID)
+	fmt.Printf("%-18s: %s %s\n", "Command", meta.Command, strings.Join(meta.Args, " "))
+	fmt.Printf("%-18s: %s\n", "Status", meta.Status)
+	if meta.Error != "" {
+		fmt.Printf("%-18s: %s\n", "Error", meta.Error)
+	}
+	fmt.Printf("%-18s: %d\n", "RunCount", meta.RunCount)
+	fmt.Printf("%-18s: %d\n", "DoneCount", meta.DoneCount)
+	fmt.Printf("%-18s: %d\n", "ErrorCount", meta.ErrorCount)
+	fmt.Printf("%-18s: %s\n", "CreateTime", formatJobTime(meta.CreateTime))
+	fmt.Printf("%-18s: %s\n", "UpdateTime", formatJobTime(meta.UpdateTime))
+	fmt.Printf("%-18s: %s\n", "CheckpointDir", meta.CheckpointDir)
+
+	if meta.Status == JobStatusRunning {
+		// the journal is locked by the running job
+		return nil
+	}
+
+	job, err := OpenJob(jc.jobsDir, id)
+	if err != nil {
+		return err
+	}
+	defer job.db.Close()
+
+	fmt.Printf("\nMarkers:\n")
+	for stream, marker := range job.Markers() {
+		fmt.Printf("    %s%s%s\n", stream, FormatTAB, marker)
+	}
+	fmt.Printf("\nUploads:\n")
+	for _, upload := range job.Uploads() {
+		fmt.Printf("    %s%s%s\n", CloudURLToString(upload.Bucket, upload.Object), FormatTAB, upload.UploadID)
+	}
+	fmt.Printf("\n")
+	return nil
+}
+
+func (jc *JobsCommand) cancelJob(id string) error {
+	if _, err := GetJobMeta(jc.jobsDir, id); err != nil {
+		return err
+	}
+
+	job, err := OpenJob(jc.jobsDir, id)
+	if err != nil {
+		return err
+	}
+	uploads := job.Uploads()
+	job.db.Close()
+
+	for _, upload := range uploads {
+		if err = jc.abortUpload(upload); err != nil {
+			return err
+		}
+		if upload.CpFile != "" {
+			os.Remove(upload.CpFile)
+		}
+		fmt.Printf("abort upload %s %s\n", CloudURLToString(upload.Bucket, upload.Object), upload.UploadID)
+	}
+
+	if err = RemoveJob(jc.jobsDir, id); err != nil {
+		return err
+	}
+	fmt.Printf("job %s is canceled\n", id)
+	return nil
+}
+
+func (jc *JobsCommand) abortUpload(upload JobUpload) error {
+	bucket, err := jc.command.ossBucket(upload.Bucket)
+	if err != nil {
+		return err
+	}
+
+	imur := oss.InitiateMultipartUploadResult{Bucket: upload.Bucket, Key: upload.Object, UploadID: upload.UploadID}
+	err = bucket.AbortMultipartUpload(imur)
+	if serviceErr, ok := err.(oss.ServiceError); ok && serviceErr.Code == "NoSuchUpload" {
+		// the upload is completed or aborted already
+		return nil
+	}
+	return err
+}
+
+func formatJobTime(t int64) string {
+	return time.Unix(t, 0).Format("2006-01-02 15:04:05")
+}
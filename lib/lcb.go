@@ -2,6 +2,7 @@ package lib
 
 import (
 	"fmt"
+	"net/http"
 
 	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
@@ -60,6 +61,7 @@ var lcbCommand = LcbCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -68,6 +70,9 @@ var lcbCommand = LcbCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionLogLevel,
 			OptionPassword,
 			OptionMode,
@@ -78,6 +83,10 @@ var lcbCommand = LcbCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionRegion,
@@ -161,11 +170,11 @@ func (lc *LcbCommand) RunCommand() error {
 }
 
 func (lc *LcbCommand) ossListCloudBoxesRetry(client *oss.Client, options ...oss.Option) (oss.ListCloudBoxResult, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, lc.command.options)
-	for i := 1; ; i++ {
-		lbr, err := client.ListCloudBoxes(options...)
-		if err == nil || int64(i) >= retryTimes {
-			return lbr, err
-		}
-	}
+	var lbr oss.ListCloudBoxResult
+	err := lc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		lbr, err = client.ListCloudBoxes(withResponseHeader(options, respHeader)...)
+		return err
+	})
+	return lbr, err
 }
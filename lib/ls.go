@@ -2,6 +2,7 @@ package lib
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 
@@ -15,7 +16,7 @@ var specChineseList = SpecText{
 	paramText: "[cloud_url] [options]",
 
 	syntaxText: ` 
-    ossutil ls [oss://bucket[/prefix]] [-s] [-d] [-m] [--limited-num num] [--marker marker] [--upload-id-marker umarker] [--payer requester] [--include include-pattern] [--exclude exclude-pattern]  [--version-id-marker id_marker] [--all-versions]  [-c file] 
+    ossutil ls [oss://bucket[/prefix]] [-s] [-d] [-m] [--limited-num num] [--marker marker] [--upload-id-marker umarker] [--payer requester] [--include include-pattern] [--exclude exclude-pattern]  [--version-id-marker id_marker] [--all-versions] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types] [--output-format json|jsonl|csv] [-c file] 
 `,
 
 	detailHelpText: ` 
@@ -39,10 +40,42 @@ var specChineseList = SpecText{
     ?：匹配单个字符
     [sequence]：匹配sequence的任意字符
     [!sequence]：匹配不在sequence的任意字符
-    注意：规则不支持带目录的格式，e.g.，--include "/usr/*/test/*.jpg"。
+    **：匹配任意层目录
+    含有/或**的规则匹配相对于源目录或object前缀所在目录的路径，以/开头时同样从该目录开始匹配，以/结尾时匹配
+    该目录下的所有文件，e.g.，--include "logs/**/*.gz"，--exclude "tmp/"；其它规则只匹配文件名。
 
     --include和--exclude可以出现多次。当多个规则出现时，这些规则按从左往右的顺序应用
 
+--include-regex、--exclude-regex和--exclude-from选项
+
+    --include-regex和--exclude-regex以正则表达式匹配相对路径（同上），未指定^和$时匹配路径的任意部分，
+    e.g.，--exclude-regex "\.(tmp|bak)$"。
+    --exclude-from从文件中读取排除规则，文件格式同.gitignore，如.ossignore：每行一条规则，#开头为注释，
+    !开头表示重新包含之前排除的路径，以/结尾只匹配目录，含有/的规则从根目录开始匹配，否则匹配任意层的名称，
+    已被排除的目录下的文件不能再被包含。
+    这些选项和--include、--exclude可以混合出现多次，按从左往右的顺序应用，--exclude-from整体作为一个排除规则。
+
+--min-size、--max-size、--older-than、--newer-than、--storage-class、--has-tag和--content-type选项
+
+    按属性筛选要操作的object，可以和--include、--exclude同时使用，只操作满足所有条件的object。
+    --min-size、--max-size：大小范围，单位可以为B/K/M/G/T，例如：--min-size 1M --max-size 1G
+    --older-than、--newer-than：最后修改时间范围，取值为时长，单位可以为s/m/h/d/w，例如：30d，
+        或者为时间，例如：2023-01-01、2023-01-01T08:00:00+08:00
+    --storage-class：存储方式，多个以逗号分隔，例如：IA,Archive
+    --has-tag：带有的标签，格式为k1=v1,k2=v2，只指定k表示带有该标签即可
+    --content-type：Content-Type，多个以逗号分隔，支持image/*的格式
+    --has-tag和--content-type需要为每个object发送一次请求，其他条件由列举结果判断，不需要额外请求。
+    只作用于object，不作用于Multipart Upload事件和目录，不支持--all-versions。
+
+--output-format选项
+
+    指定以机器可读的格式输出列举结果，取值为json、jsonl或csv。每个bucket、object、object版本、
+    目录或者Multipart Upload事件输出一条记录，记录的type字段表示记录类型，最后输出一条type为
+    summary的汇总记录，包含数量和总大小。此时不会输出表头和耗时等信息，-s选项不生效。
+    json：输出一个包含所有记录的数组
+    jsonl：每行输出一条记录
+    csv：第一行为列名，之后每行输出一条记录
+
 用法：
 
     该命令有两种用法：
@@ -176,6 +209,9 @@ var specChineseList = SpecText{
         Object Number is: 2
 
     15) ossutil ls oss://bucket --all-versions
+
+    16) 列举30天以前修改的大于100M的objects
+    ossutil ls oss://bucket/dir/ --min-size 100M --older-than 30d
 `,
 }
 
@@ -186,7 +222,7 @@ var specEnglishList = SpecText{
 	paramText: "[cloud_url] [options]",
 
 	syntaxText: ` 
-    ossutil ls [oss://bucket[/prefix]] [-s] [-d] [-m] [--limited-num num] [--marker marker] [--upload-id-marker umarker] [--payer requester] [--include include-pattern] [--exclude exclude-pattern]  [--version-id-marker id_marker] [--all-versions]  [-c file] 
+    ossutil ls [oss://bucket[/prefix]] [-s] [-d] [-m] [--limited-num num] [--marker marker] [--upload-id-marker umarker] [--payer requester] [--include include-pattern] [--exclude exclude-pattern]  [--version-id-marker id_marker] [--all-versions] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types] [--output-format json|jsonl|csv] [-c file] 
 `,
 
 	detailHelpText: ` 
@@ -214,7 +250,11 @@ var specEnglishList = SpecText{
     ?: Matches any single character
     [sequence]: Matches any character in sequence
     [!sequence]: Matches any character not in sequence
-    Note: does not support patterns containing directory info. e.g., --include "/usr/*/test/*.jpg" 
+    **: Matches any directories
+    The pattern containing / or ** matches the path relative to the source directory or the directory of
+    the object prefix, the leading / also starts from the directory, the trailing / matches all the files
+    in the directory, e.g., --include "logs/**/*.gz", --exclude "tmp/". The other patterns only match the
+    file name.
 
     Any number of these parameters can be passed to a command. You can do this by providing an --exclude
     or --include argument multiple times, e.g.,
@@ -222,6 +262,43 @@ var specEnglishList = SpecText{
     When there are multi filters, the rule is the filters that appear later in the command take precedence
     over filters that appear earlier in the command
 
+--include-regex, --exclude-regex and --exclude-from option
+
+    --include-regex and --exclude-regex match the relative path(the same as above) by the regular
+    expression, it matches any part of the path without ^ and $, e.g., --exclude-regex "\.(tmp|bak)$".
+    --exclude-from reads the exclude rules from the file in .gitignore syntax, like .ossignore: a rule per
+    line, the line starting with # is comment, the rule starting with ! includes the path excluded by the
+    former rules again, the rule ending with / only matches directories, the rule containing / matches
+    from the root, otherwise it matches the name at any level, the file can't be included again if its
+    parent directory is excluded.
+    These options can be mixed with --include and --exclude for multiple times, they are applied from
+    left to right, the whole --exclude-from works as an exclude filter.
+
+--min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag and --content-type option
+
+    Filter the objects to operate by their attributes, they can be used with --include and --exclude,
+    only the objects matching all the conditions are operated.
+    --min-size, --max-size: the range of size, the unit can be B/K/M/G/T, eg: --min-size 1M --max-size 1G
+    --older-than, --newer-than: the range of last modified time, the value is a duration with unit 
+        s/m/h/d/w, eg: 30d, or a time, eg: 2023-01-01, 2023-01-01T08:00:00+08:00
+    --storage-class: the storage classes separated by comma, eg: IA,Archive
+    --has-tag: the tags of the object, the format is k1=v1,k2=v2, only k means the tag of any value
+    --content-type: the content types separated by comma, image/* is supported
+    --has-tag and --content-type send a request for each object, the other conditions are decided by 
+    the listing result without extra requests.
+    They only work on objects but not multipart uploads or directories, and don't support --all-versions.
+
+--output-format option
+
+    Output the list result in machine readable format, the value can be json, jsonl or csv. One 
+    record is output per bucket, object, object version, directory or multipart upload, the type 
+    field of the record tells the kind of it, and a summary record whose type is summary is output 
+    at last, with the counts and total size. Table header and elapsed time are not output, and -s 
+    option doesn't take effect.
+    json:  an array of all the records
+    jsonl: one record per line
+    csv:   the column names in the first line, then one record per line
+
 Usage:
 
     There are two usages:
@@ -357,6 +434,9 @@ Usage:
         2019-05-30 14:24:05 +0800 CST         1030      Standard   4A902D176BE0EE4224BC196BBB8CCC69      oss://bucket/test.mp4
         Object Number is: 2
     15) ossutil ls oss://bucket[/prefix] --all-versions
+
+    16) list the objects larger than 100M and modified 30 days ago
+    ossutil ls oss://bucket/dir/ --min-size 100M --older-than 30d
 `,
 }
 
@@ -365,8 +445,25 @@ type ListCommand struct {
 	command     Command
 	payerOption oss.Option
 	filters     []filterOptionType
+	attrFilter  *attributeFilter
+	output      *OutputWriter
+	summary     listSummaryType
+}
+
+// listSummaryType is the summary record of machine readable output
+type listSummaryType struct {
+	objectNum    int64
+	directoryNum int64
+	uploadNum    int64
+	totalSize    int64
 }
 
+var listBucketColumns = []string{"type", "bucket", "location", "storage_class", "creation_date", "bucket_count"}
+
+var listObjectColumns = []string{"type", "bucket", "key", "size", "etag", "storage_class", "last_modified",
+	"version_id", "is_latest", "delete_marker", "upload_id", "initiated",
+	"object_count", "directory_count", "upload_count", "total_size"}
+
 var listCommand = ListCommand{
 	command: Command{
 		name:        "ls",
@@ -378,6 +475,7 @@ var listCommand = ListCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -386,6 +484,9 @@ var listCommand = ListCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionLogLevel,
 			OptionRequestPayer,
 			OptionShortFormat,
@@ -398,6 +499,16 @@ var listCommand = ListCommand{
 			OptionEncodingType,
 			OptionInclude,
 			OptionExclude,
+			OptionIncludeRegex,
+			OptionExcludeRegex,
+			OptionExcludeFrom,
+			OptionMinSize,
+			OptionMaxSize,
+			OptionOlderThan,
+			OptionNewerThan,
+			OptionStorageClass,
+			OptionHasTag,
+			OptionContentType,
 			OptionAllversions,
 			OptionVersionIdMarker,
 			OptionPassword,
@@ -409,12 +520,17 @@ var listCommand = ListCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
 			OptionRegion,
 			OptionCloudBoxID,
 			OptionForcePathStyle,
+			OptionOutputFormat,
 		},
 	},
 }
@@ -435,6 +551,8 @@ func (lc *ListCommand) Init(args []string, options OptionMapType) error {
 
 // RunCommand simulate inheritance, and polymorphism
 func (lc *ListCommand) RunCommand() error {
+	lc.output = nil
+	lc.summary = listSummaryType{}
 	if len(lc.command.args) == 0 {
 		return lc.listBuckets("")
 	}
@@ -458,10 +576,17 @@ func (lc *ListCommand) RunCommand() error {
 		return lc.listBuckets("")
 	}
 
-	var res bool
-	res, lc.filters = getFilter(os.Args)
-	if !res {
-		return fmt.Errorf("--include or --exclude does not support format containing dir info")
+	filters, err := getFilter(os.Args)
+	if err != nil {
+		return err
+	}
+	lc.filters = filters
+
+	if lc.attrFilter, err = lc.command.attributeFilter(); err != nil {
+		return err
+	}
+	if allVersions, _ := GetBool(OptionAllversions, lc.command.options); allVersions && lc.attrFilter != nil {
+		return fmt.Errorf("--all-versions can't be used with the options filtering by size, time, storage class, tag or content type")
 	}
 
 	return lc.listFiles(cloudURL)
@@ -488,6 +613,9 @@ func (lc *ListCommand) listBuckets(prefix string) error {
 		return err
 	}
 
+	lc.output = NewOutputWriter(getOutputFormat(lc.command.options), listBucketColumns)
+	defer lc.output.Close()
+
 	// list all buckets
 	pre := oss.Prefix(prefix)
 	marker := oss.Marker(vmarker)
@@ -499,14 +627,22 @@ func (lc *ListCommand) listBuckets(prefix string) error {
 		}
 		pre = oss.Prefix(lbr.Prefix)
 		marker = oss.Marker(lbr.NextMarker)
-		if num == 0 && !shortFormat && len(lbr.Buckets) > 0 {
+		if num == 0 && !shortFormat && lc.output == nil && len(lbr.Buckets) > 0 {
 			fmt.Printf("%-30s %20s%s%12s%s%s\n", "CreationTime", "Region", FormatTAB, "StorageClass", FormatTAB, "BucketName")
 		}
 		for _, bucket := range lbr.Buckets {
 			if limitedNum >= 0 && num >= limitedNum {
 				break
 			}
-			if !shortFormat {
+			if lc.output != nil {
+				lc.output.Write(OutputRecord{
+					"type":          recordBucket,
+					"bucket":        bucket.Name,
+					"location":      bucket.Location,
+					"storage_class": bucket.StorageClass,
+					"creation_date": bucket.CreationDate,
+				})
+			} else if !shortFormat {
 				fmt.Printf("%-30s %20s%s%12s%s%s\n", utcToLocalTime(bucket.CreationDate), bucket.Location, FormatTAB, bucket.StorageClass, FormatTAB, CloudURLToString(bucket.Name, ""))
 			} else {
 				fmt.Println(CloudURLToString(bucket.Name, ""))
@@ -517,6 +653,9 @@ func (lc *ListCommand) listBuckets(prefix string) error {
 			break
 		}
 	}
+	if lc.output != nil {
+		return lc.output.Write(OutputRecord{"type": recordSummary, "bucket_count": num})
+	}
 	fmt.Printf("Bucket Number is: %d\n", num)
 	return nil
 }
@@ -529,13 +668,13 @@ func (lc *ListCommand) lbCheckArgOptions() error {
 }
 
 func (lc *ListCommand) ossListBucketsRetry(client *oss.Client, options ...oss.Option) (oss.ListBucketsResult, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, lc.command.options)
-	for i := 1; ; i++ {
-		lbr, err := client.ListBuckets(options...)
-		if err == nil || int64(i) >= retryTimes {
-			return lbr, err
-		}
-	}
+	var lbr oss.ListBucketsResult
+	err := lc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		lbr, err = client.ListBuckets(withResponseHeader(options, respHeader)...)
+		return err
+	})
+	return lbr, err
 }
 
 func (lc *ListCommand) listFiles(cloudURL CloudURL) error {
@@ -549,6 +688,9 @@ func (lc *ListCommand) listFiles(cloudURL CloudURL) error {
 	limitedNum, _ := GetInt(OptionLimitedNum, lc.command.options)
 	allVersions, _ := GetBool(OptionAllversions, lc.command.options)
 	typeSet := lc.getSubjectType()
+
+	lc.output = NewOutputWriter(getOutputFormat(lc.command.options), listObjectColumns)
+	defer lc.output.Close()
 	if typeSet&objectType != 0 {
 		if !allVersions {
 			_, err = lc.listObjects(bucket, cloudURL, shortFormat, directory, &limitedNum)
@@ -565,6 +707,20 @@ func (lc *ListCommand) listFiles(cloudURL CloudURL) error {
 			return err
 		}
 	}
+
+	if lc.output != nil {
+		record := OutputRecord{"type": recordSummary, "total_size": lc.summary.totalSize}
+		if typeSet&objectType != 0 {
+			record["object_count"] = lc.summary.objectNum
+			if directory {
+				record["directory_count"] = lc.summary.directoryNum
+			}
+		}
+		if typeSet&multipartType != 0 {
+			record["upload_count"] = lc.summary.uploadNum
+		}
+		return lc.output.Write(record)
+	}
 	return nil
 }
 
@@ -611,16 +767,23 @@ func (lc *ListCommand) listObjects(bucket *oss.Bucket, cloudURL CloudURL, shortF
 		}
 		pre = oss.Prefix(lor.Prefix)
 		marker = oss.Marker(lor.NextMarker)
-		num += lc.displayObjectsResult(lor, cloudURL.bucket, shortFormat, directory, i, limitedNum)
+		n, err := lc.displayObjectsResult(bucket, lor, shortFormat, directory, i, limitedNum)
+		num += n
+		if err != nil {
+			return num, err
+		}
 		if !lor.IsTruncated {
 			break
 		}
 	}
 
-	if !directory {
-		fmt.Printf("Object Number is: %d\n", num)
-	} else {
-		fmt.Printf("Object and Directory Number is: %d\n", num)
+	// the summary of machine readable output is written after all types are listed
+	if lc.output == nil {
+		if !directory {
+			fmt.Printf("Object Number is: %d\n", num)
+		} else {
+			fmt.Printf("Object and Directory Number is: %d\n", num)
+		}
 	}
 
 	return num, nil
@@ -668,32 +831,35 @@ func (lc *ListCommand) listObjectVersions(bucket *oss.Bucket, cloudURL CloudURL,
 		}
 	}
 
-	if !directory {
-		fmt.Printf("Object Number is: %d\n", num)
-	} else {
-		fmt.Printf("Object and Directory Number is: %d\n", num)
+	// the summary of machine readable output is written after all types are listed
+	if lc.output == nil {
+		if !directory {
+			fmt.Printf("Object Number is: %d\n", num)
+		} else {
+			fmt.Printf("Object and Directory Number is: %d\n", num)
+		}
 	}
 	return num, nil
 }
 
-func (lc *ListCommand) displayObjectsResult(lor oss.ListObjectsResult, bucket string, shortFormat bool, directory bool, i int64, limitedNum *int64) int64 {
-	if i == 0 && !shortFormat && !directory && len(lor.Objects) > 0 {
+func (lc *ListCommand) displayObjectsResult(bucket *oss.Bucket, lor oss.ListObjectsResult, shortFormat bool, directory bool, i int64, limitedNum *int64) (int64, error) {
+	if i == 0 && !shortFormat && !directory && lc.output == nil && len(lor.Objects) > 0 {
 		fmt.Printf("%-30s%12s%s%12s%s%-36s%s%s\n", "LastModifiedTime", "Size(B)", "  ", "StorageClass", "   ", "ETAG", "  ", "ObjectName")
 	}
 
-	var num int64
 	if !directory {
-		num = lc.showObjects(lor, bucket, shortFormat, limitedNum)
-	} else {
-		num = lc.showObjects(lor, bucket, true, limitedNum)
-		num1 := lc.showDirectories(lor, bucket, limitedNum)
-		num += num1
+		return lc.showObjects(bucket, lor, shortFormat, limitedNum)
 	}
-	return num
+	num, err := lc.showObjects(bucket, lor, true, limitedNum)
+	if err != nil {
+		return num, err
+	}
+	num += lc.showDirectories(lor, bucket.BucketName, limitedNum)
+	return num, nil
 }
 
 func (lc *ListCommand) displayObjectVersionsResult(lor oss.ListObjectVersionsResult, bucket string, shortFormat bool, directory bool, i int64, limitedNum *int64) int64 {
-	if i == 0 && (len(lor.ObjectDeleteMarkers) > 0 || len(lor.ObjectVersions) > 0) {
+	if i == 0 && lc.output == nil && (len(lor.ObjectDeleteMarkers) > 0 || len(lor.ObjectVersions) > 0) {
 		if directory {
 			fmt.Printf("%-6s%s%-30s%12s%s%12s%s%-36s%s%-66s%s%-10s%s%-13s%s%s\n", "COMMON-PREFIX", "  ", "LastModifiedTime", "Size(B)", "  ", "StorageClass", "  ", "ETAG", "  ", "VERSIONID", "  ", "IS-LATEST", "  ", "DELETE-MARKER", "  ", "ObjectName")
 		} else {
@@ -710,19 +876,39 @@ func (lc *ListCommand) displayObjectVersionsResult(lor oss.ListObjectVersionsRes
 	return num
 }
 
-func (lc *ListCommand) showObjects(lor oss.ListObjectsResult, bucket string, shortFormat bool, limitedNum *int64) int64 {
+func (lc *ListCommand) showObjects(ossBucket *oss.Bucket, lor oss.ListObjectsResult, shortFormat bool, limitedNum *int64) (int64, error) {
 	var num int64
 	num = 0
+	bucket := ossBucket.BucketName
 	for _, object := range lor.Objects {
 		if *limitedNum == 0 {
 			break
 		}
 
-		if !doesSingleObjectMatchPatterns(object.Key, lc.filters) {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, object.Key), lc.filters) {
+			continue
+		}
+		match, err := lc.attrFilter.matchObject(ossBucket, object, lc.payerOption)
+		if err != nil {
+			return num, err
+		}
+		if !match {
 			continue
 		}
 
-		if !shortFormat {
+		if lc.output != nil {
+			lc.output.Write(OutputRecord{
+				"type":          recordObject,
+				"bucket":        bucket,
+				"key":           object.Key,
+				"size":          object.Size,
+				"etag":          strings.Trim(object.ETag, "\""),
+				"storage_class": object.StorageClass,
+				"last_modified": object.LastModified,
+			})
+			lc.summary.objectNum++
+			lc.summary.totalSize += object.Size
+		} else if !shortFormat {
 			fmt.Printf("%-30s%12d%s%12s%s%-36s%s%s\n", utcToLocalTime(object.LastModified), object.Size, "  ", object.StorageClass, "   ", strings.Trim(object.ETag, "\""), "  ", CloudURLToString(bucket, object.Key))
 		} else {
 			fmt.Printf("%s\n", CloudURLToString(bucket, object.Key))
@@ -730,7 +916,7 @@ func (lc *ListCommand) showObjects(lor oss.ListObjectsResult, bucket string, sho
 		*limitedNum--
 		num++
 	}
-	return num
+	return num, nil
 }
 
 func (lc *ListCommand) showObjectVersions(lor oss.ListObjectVersionsResult, bucket string, limitedNum *int64, directory bool) int64 {
@@ -741,12 +927,24 @@ func (lc *ListCommand) showObjectVersions(lor oss.ListObjectVersionsResult, buck
 			break
 		}
 
-		if !doesSingleObjectMatchPatterns(object.Key, lc.filters) {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, object.Key), lc.filters) {
 			continue
 		}
 
 		//COMMON-PREFIX LastModifiedTime  Size(B)  StorageClass  ETAG VERSIONID  IS-LATEST  DELETE-MARKER  ObjectName
-		if directory {
+		if lc.output != nil {
+			lc.output.Write(OutputRecord{
+				"type":          recordVersion,
+				"bucket":        bucket,
+				"key":           object.Key,
+				"size":          0,
+				"last_modified": object.LastModified,
+				"version_id":    object.VersionId,
+				"is_latest":     object.IsLatest,
+				"delete_marker": true,
+			})
+			lc.summary.objectNum++
+		} else if directory {
 			fmt.Printf("%-13t%s%-30s%12d%s%12s%s%-36s%s%-66s%s%-10t%s%-13t%s%s\n",
 				false, "  ",
 				utcToLocalTime(object.LastModified),
@@ -778,12 +976,27 @@ func (lc *ListCommand) showObjectVersions(lor oss.ListObjectVersionsResult, buck
 			break
 		}
 
-		if !doesSingleObjectMatchPatterns(object.Key, lc.filters) {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, object.Key), lc.filters) {
 			continue
 		}
 
 		//COMMON-PREFIX LastModifiedTime  Size(B)  StorageClass  ETAG VERSIONID  IS-LATEST  DELETE-MARKER  ObjectName
-		if directory {
+		if lc.output != nil {
+			lc.output.Write(OutputRecord{
+				"type":          recordVersion,
+				"bucket":        bucket,
+				"key":           object.Key,
+				"size":          object.Size,
+				"etag":          strings.Trim(object.ETag, "\""),
+				"storage_class": object.StorageClass,
+				"last_modified": object.LastModified,
+				"version_id":    object.VersionId,
+				"is_latest":     object.IsLatest,
+				"delete_marker": false,
+			})
+			lc.summary.objectNum++
+			lc.summary.totalSize += object.Size
+		} else if directory {
 			fmt.Printf("%-13t%s%-30s%12d%s%12s%s%-36s%s%-66s%s%-10t%s%-13t%s%s\n",
 				false, "  ",
 				utcToLocalTime(object.LastModified),
@@ -821,11 +1034,16 @@ func (lc *ListCommand) showDirectories(lor oss.ListObjectsResult, bucket string,
 			break
 		}
 
-		if !doesSingleObjectMatchPatterns(strings.TrimSuffix(prefix, "/"), lc.filters) {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, strings.TrimSuffix(prefix, "/")), lc.filters) {
 			continue
 		}
 
-		fmt.Printf("%s\n", CloudURLToString(bucket, prefix))
+		if lc.output != nil {
+			lc.output.Write(OutputRecord{"type": recordDirectory, "bucket": bucket, "key": prefix})
+			lc.summary.directoryNum++
+		} else {
+			fmt.Printf("%s\n", CloudURLToString(bucket, prefix))
+		}
 		*limitedNum--
 		num++
 	}
@@ -840,7 +1058,15 @@ func (lc *ListCommand) showDirectoriesVersion(lor oss.ListObjectVersionsResult,
 			break
 		}
 
-		if !doesSingleObjectMatchPatterns(strings.TrimSuffix(prefix, "/"), lc.filters) {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, strings.TrimSuffix(prefix, "/")), lc.filters) {
+			continue
+		}
+
+		if lc.output != nil {
+			lc.output.Write(OutputRecord{"type": recordDirectory, "bucket": bucket, "key": prefix})
+			lc.summary.directoryNum++
+			*limitedNum--
+			num++
 			continue
 		}
 
@@ -901,7 +1127,9 @@ func (lc *ListCommand) listMultipartUploads(bucket *oss.Bucket, cloudURL CloudUR
 			break
 		}
 	}
-	fmt.Printf("UploadID Number is: %d\n", multipartNum)
+	if lc.output == nil {
+		fmt.Printf("UploadID Number is: %d\n", multipartNum)
+	}
 	return multipartNum, nil
 }
 
@@ -910,7 +1138,7 @@ func (lc *ListCommand) displayMultipartUploadsResult(lmr oss.ListMultipartUpload
 		shortFormat = true
 	}
 
-	if i == 0 && len(lmr.Uploads) > 0 {
+	if i == 0 && lc.output == nil && len(lmr.Uploads) > 0 {
 		if shortFormat {
 			fmt.Printf("%-32s%s%s\n", "UploadID", FormatTAB, "ObjectName")
 		} else {
@@ -930,11 +1158,20 @@ func (lc *ListCommand) showMultipartUploads(lmr oss.ListMultipartUploadResult, b
 			break
 		}
 
-		if !doesSingleObjectMatchPatterns(upload.Key, lc.filters) {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(lmr.Prefix, upload.Key), lc.filters) {
 			continue
 		}
 
-		if shortFormat {
+		if lc.output != nil {
+			lc.output.Write(OutputRecord{
+				"type":      recordUpload,
+				"bucket":    bucket,
+				"key":       upload.Key,
+				"upload_id": upload.UploadID,
+				"initiated": upload.Initiated,
+			})
+			lc.summary.uploadNum++
+		} else if shortFormat {
 			fmt.Printf("%-32s%s%s\n", upload.UploadID, FormatTAB, CloudURLToString(bucket, upload.Key))
 		} else {
 			fmt.Printf("%-30s%s%-32s%s%s\n", utcToLocalTime(upload.Initiated), FormatTAB, upload.UploadID, FormatTAB, CloudURLToString(bucket, upload.Key))
@@ -1,6 +1,7 @@
 package lib
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/url"
@@ -1484,3 +1485,38 @@ func (s *OssutilCommandSuite) TestListDirectoryfilterIncludeVersions(c *C) {
 	os.RemoveAll(dir2)
 	s.removeBucket(bucketName, true, c)
 }
+
+func (s *OssutilCommandSuite) TestListObjectsOutputFormat(c *C) {
+	bucketName := bucketNamePrefix + randLowStr(10)
+	s.putBucket(bucketName, c)
+
+	num := 3
+	for i := 0; i < num; i++ {
+		s.putObject(bucketName, fmt.Sprintf("lsformat%d", i), uploadFileName, c)
+	}
+
+	testResultFile, _ = os.OpenFile(resultPath, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0664)
+	out := os.Stdout
+	os.Stdout = testResultFile
+	args := []string{CloudURLToString(bucketName, "lsformat")}
+	showElapse, err := s.rawList(args, "ls -s", OptionPair{Key: "outputFormat", Value: OutputFormatJSONL})
+	os.Stdout = out
+	c.Assert(err, IsNil)
+	c.Assert(showElapse, Equals, false)
+
+	lines := strings.Split(strings.TrimSpace(s.readFile(resultPath, c)), "\n")
+	os.Remove(resultPath)
+	c.Assert(len(lines), Equals, num+1)
+	for i := 0; i < num; i++ {
+		var record map[string]interface{}
+		c.Assert(json.Unmarshal([]byte(lines[i]), &record), IsNil)
+		c.Assert(record["type"], Equals, recordObject)
+		c.Assert(record["key"], Equals, fmt.Sprintf("lsformat%d", i))
+	}
+	var summary map[string]interface{}
+	c.Assert(json.Unmarshal([]byte(lines[num]), &summary), IsNil)
+	c.Assert(summary["type"], Equals, recordSummary)
+	c.Assert(summary["object_count"], Equals, float64(num))
+
+	s.removeBucket(bucketName, true, c)
+}
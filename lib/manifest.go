@@ -0,0 +1,451 @@
+package lib
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// ManifestEntry is an object listed in the manifest, the fields other than Key are optional
+type ManifestEntry struct {
+	Key       string
+	VersionId string
+	DestKey   string            // the name of the destination object or file relative to the dest url
+	Meta      map[string]string // the headers set on the destination object
+	Size      int64             // -1 if it's unknown
+	Line      int64             // the position in the manifest starting from 1
+}
+
+// manifestSource reads the objects to operate from a manifest instead of listing the bucket. The manifest is
+// a plain key list, a csv or jsonl file with the per object overrides, or the manifest.json of the bucket
+// inventory report, the local file or the object on oss are both supported.
+type manifestSource struct {
+	command      *Command
+	path         string
+	format       string
+	encodingType string
+	bucket       string // the bucket the keys belong to
+	prefix       string // the keys out of the prefix are skipped
+}
+
+// inventoryManifest is the manifest.json of the bucket inventory report
+type inventoryManifest struct {
+	SourceBucket      string `json:"sourceBucket"`
+	DestinationBucket string `json:"destinationBucket"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	Files             []struct {
+		Key         string `json:"key"`
+		Size        int64  `json:"size"`
+		MD5checksum string `json:"MD5checksum"`
+	} `json:"files"`
+}
+
+type manifestJSONLine struct {
+	Key       string            `json:"key"`
+	VersionId string            `json:"versionId"`
+	DestKey   string            `json:"destKey"`
+	Meta      map[string]string `json:"meta"`
+	Size      *int64            `json:"size"`
+}
+
+// openManifest returns the manifest specified by --manifest, nil if it's not specified. Only the keys under
+// the object prefix of cloudURL are read from the manifest.
+func (cmd *Command) openManifest(cloudURL CloudURL) (*manifestSource, error) {
+	path, _ := GetString(OptionManifest, cmd.options)
+	if path == "" {
+		return nil, nil
+	}
+
+	format, _ := GetString(OptionManifestFormat, cmd.options)
+	format = strings.ToLower(format)
+	if format == "" {
+		format = detectManifestFormat(path)
+	}
+	switch format {
+	case ManifestFormatPlain, ManifestFormatCSV, ManifestFormatJSONL, ManifestFormatInventory:
+	default:
+		return nil, fmt.Errorf("invalid manifest format: %s, value range is: %s/%s/%s/%s", format, ManifestFormatPlain, ManifestFormatCSV, ManifestFormatJSONL, ManifestFormatInventory)
+	}
+
+	if !strings.HasPrefix(strings.ToLower(path), SchemePrefix) {
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if fileInfo.IsDir() {
+			return nil, fmt.Errorf("%s is dir, not the expected manifest file", path)
+		}
+	}
+
+	encodingType, _ := GetString(OptionEncodingType, cmd.options)
+	return &manifestSource{
+		command:      cmd,
+		path:         path,
+		format:       format,
+		encodingType: encodingType,
+		bucket:       cloudURL.bucket,
+		prefix:       cloudURL.object,
+	}, nil
+}
+
+// detectManifestFormat decides the format by the extension of the manifest
+func detectManifestFormat(path string) string {
+	name := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(name, ".csv"):
+		return ManifestFormatCSV
+	case strings.HasSuffix(name, ".jsonl"), strings.HasSuffix(name, ".ndjson"):
+		return ManifestFormatJSONL
+	case strings.HasSuffix(name, ".json"):
+		return ManifestFormatInventory
+	}
+	return ManifestFormatPlain
+}
+
+// forEach calls fn for the entries of the manifest in order, it stops at the first error
+func (ms *manifestSource) forEach(fn func(entry ManifestEntry) error) error {
+	reader, err := ms.open(ms.path)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	emit := func(entry ManifestEntry) error {
+		if entry.Key == "" {
+			return fmt.Errorf("object can't be '' in manifest %s, line %d", ms.path, entry.Line)
+		}
+		if !strings.HasPrefix(entry.Key, ms.prefix) {
+			return nil
+		}
+		return fn(entry)
+	}
+
+	switch ms.format {
+	case ManifestFormatCSV:
+		return ms.readCSV(reader, emit)
+	case ManifestFormatJSONL:
+		return ms.readJSONL(reader, emit)
+	case ManifestFormatInventory:
+		return ms.readInventory(reader, emit)
+	}
+	return ms.readPlain(reader, emit)
+}
+
+// open opens the local file or the object of oss url
+func (ms *manifestSource) open(path string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(strings.ToLower(path), SchemePrefix) {
+		return os.Open(path)
+	}
+	cloudURL, err := ObjectURLFromString(path, "")
+	if err != nil {
+		return nil, err
+	}
+	return ms.openObject(cloudURL.bucket, cloudURL.object)
+}
+
+func (ms *manifestSource) openObject(bucketName, object string) (io.ReadCloser, error) {
+	bucket, err := ms.command.ossBucket(bucketName)
+	if err != nil {
+		return nil, err
+	}
+	var body io.ReadCloser
+	err = ms.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		body, err = bucket.GetObject(object, oss.GetResponseHeader(respHeader))
+		return err
+	})
+	if err != nil {
+		return nil, ObjectError{err, bucketName, object}
+	}
+	return body, nil
+}
+
+func (ms *manifestSource) decodeKey(key string) (string, error) {
+	if ms.encodingType != URLEncodingType {
+		return key, nil
+	}
+	decoded, err := url.QueryUnescape(key)
+	if err != nil {
+		return "", fmt.Errorf("invalid object url: %s, object name is not url encoded, %s", key, err.Error())
+	}
+	return decoded, nil
+}
+
+// readPlain reads one key per line, the blank lines are ignored
+func (ms *manifestSource) readPlain(reader io.Reader, fn func(entry ManifestEntry) error) error {
+	scanner := bufio.NewScanner(reader)
+	var line int64
+	for scanner.Scan() {
+		line++
+		key := strings.TrimSpace(scanner.Text())
+		if key == "" {
+			continue
+		}
+		key, err := ms.decodeKey(key)
+		if err != nil {
+			return err
+		}
+		if err := fn(ManifestEntry{Key: key, Size: -1, Line: line}); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// readCSV reads the rows of key, versionId and destKey. If the first row names the columns and one of them
+// is key, the columns are matched by the names, and the columns other than key, versionId, destKey and size
+// are the headers set on the destination object.
+func (ms *manifestSource) readCSV(reader io.Reader, fn func(entry ManifestEntry) error) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	var header []string
+	var line int64
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read manifest %s error, %s", ms.path, err.Error())
+		}
+		line++
+		if line == 1 && isManifestCSVHeader(record) {
+			header = record
+			continue
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		entry := ManifestEntry{Size: -1, Line: line}
+		for i, value := range record {
+			name := ""
+			if header != nil {
+				if i < len(header) {
+					name = strings.TrimSpace(header[i])
+				}
+			} else if i < 3 {
+				name = []string{"key", "versionId", "destKey"}[i]
+			}
+			switch strings.ToLower(name) {
+			case "":
+			case "key":
+				entry.Key = value
+			case "versionid":
+				entry.VersionId = strings.TrimSpace(value)
+			case "destkey":
+				entry.DestKey = value
+			case "size":
+				if value = strings.TrimSpace(value); value == "" {
+					break
+				}
+				if entry.Size, err = strconv.ParseInt(value, 10, 64); err != nil {
+					return fmt.Errorf("invalid size: %s in manifest %s, line %d", value, ms.path, line)
+				}
+			default:
+				if value != "" {
+					if entry.Meta == nil {
+						entry.Meta = map[string]string{}
+					}
+					entry.Meta[name] = value
+				}
+			}
+		}
+		if entry.Key, err = ms.decodeKey(entry.Key); err != nil {
+			return err
+		}
+		if entry.DestKey, err = ms.decodeKey(entry.DestKey); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+func isManifestCSVHeader(record []string) bool {
+	for _, name := range record {
+		if strings.ToLower(strings.TrimSpace(name)) == "key" {
+			return true
+		}
+	}
+	return false
+}
+
+// readJSONL reads one json object per line
+func (ms *manifestSource) readJSONL(reader io.Reader, fn func(entry ManifestEntry) error) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var line int64
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var item manifestJSONLine
+		if err := json.Unmarshal([]byte(text), &item); err != nil {
+			return fmt.Errorf("invalid json in manifest %s, line %d, %s", ms.path, line, err.Error())
+		}
+
+		entry := ManifestEntry{VersionId: item.VersionId, Meta: item.Meta, Size: -1, Line: line}
+		if item.Size != nil {
+			entry.Size = *item.Size
+		}
+		var err error
+		if entry.Key, err = ms.decodeKey(item.Key); err != nil {
+			return err
+		}
+		if entry.DestKey, err = ms.decodeKey(item.DestKey); err != nil {
+			return err
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// readInventory reads the csv files listed in the manifest.json of the bucket inventory report, they are
+// gzipped and saved in the destination bucket of the inventory. The delete markers are skipped.
+func (ms *manifestSource) readInventory(reader io.Reader, fn func(entry ManifestEntry) error) error {
+	var manifest inventoryManifest
+	if err := json.NewDecoder(reader).Decode(&manifest); err != nil {
+		return fmt.Errorf("invalid inventory manifest %s, %s", ms.path, err.Error())
+	}
+	if manifest.FileFormat != "" && strings.ToUpper(manifest.FileFormat) != "CSV" {
+		return fmt.Errorf("inventory file format %s is not supported, only CSV is supported", manifest.FileFormat)
+	}
+	if ms.bucket != "" && manifest.SourceBucket != "" && manifest.SourceBucket != ms.bucket {
+		return fmt.Errorf("the inventory of bucket %s can't be used for bucket %s", manifest.SourceBucket, ms.bucket)
+	}
+
+	columns := map[string]int{}
+	for i, name := range strings.Split(manifest.FileSchema, ",") {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	keyIndex, ok := columns["key"]
+	if !ok {
+		return fmt.Errorf("invalid inventory manifest %s, no Key in fileSchema", ms.path)
+	}
+	destBucket := strings.TrimPrefix(manifest.DestinationBucket, "acs:oss:::")
+
+	var line int64
+	for _, file := range manifest.Files {
+		body, err := ms.openObject(destBucket, file.Key)
+		if err != nil {
+			return err
+		}
+		err = ms.readInventoryFile(body, file.Key, file.MD5checksum, func(record []string) error {
+			line++
+			entry := ManifestEntry{Size: -1, Line: line}
+			value := func(name string) string {
+				if i, ok := columns[name]; ok && i < len(record) {
+					return record[i]
+				}
+				return ""
+			}
+			if keyIndex >= len(record) {
+				return fmt.Errorf("invalid inventory file %s, row %d has no key", file.Key, line)
+			}
+			if strings.ToLower(value("isdeletemarker")) == "true" {
+				return nil
+			}
+			var err error
+			if entry.Key, err = url.QueryUnescape(record[keyIndex]); err != nil {
+				return fmt.Errorf("invalid key %s in inventory file %s, %s", record[keyIndex], file.Key, err.Error())
+			}
+			entry.VersionId = value("versionid")
+			if size := value("size"); size != "" {
+				if entry.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+					entry.Size = -1
+				}
+			}
+			return fn(entry)
+		})
+		body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ms *manifestSource) readInventoryFile(body io.Reader, name, md5sum string, fn func(record []string) error) error {
+	var md5Hash hash.Hash
+	if md5sum != "" {
+		md5Hash = md5.New()
+		body = io.TeeReader(body, md5Hash)
+	}
+	gzipReader, err := gzip.NewReader(body)
+	if err != nil {
+		return fmt.Errorf("read inventory file %s error, %s", name, err.Error())
+	}
+	defer gzipReader.Close()
+
+	csvReader := csv.NewReader(gzipReader)
+	csvReader.FieldsPerRecord = -1
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read inventory file %s error, %s", name, err.Error())
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	if md5Hash != nil {
+		// the checksum covers the whole gzipped file
+		io.Copy(ioutil.Discard, body)
+		if sum := hex.EncodeToString(md5Hash.Sum(nil)); !strings.EqualFold(sum, md5sum) {
+			return fmt.Errorf("inventory file %s is corrupted, md5 %s is not the same as %s in manifest", name, sum, md5sum)
+		}
+	}
+	return nil
+}
+
+// manifestProducer sends the entries of the manifest matching the filters to chEntries and counts them in monitor,
+// so that the manifest is read only once
+func (cmd *Command) manifestProducer(ms *manifestSource, filters []filterOptionType, chEntries chan<- ManifestEntry, chError chan<- error, monitor Monitorer) {
+	defer close(chEntries)
+	err := ms.forEach(func(entry ManifestEntry) error {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(ms.prefix, entry.Key), filters) {
+			return nil
+		}
+		if monitor != nil {
+			monitor.updateScanNum(1)
+		}
+		chEntries <- entry
+		return nil
+	})
+	if err != nil {
+		if monitor != nil {
+			monitor.setScanError(err)
+		}
+		chError <- err
+		return
+	}
+	if monitor != nil {
+		monitor.setScanEnd()
+	}
+	chError <- nil
+}
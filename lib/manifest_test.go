@@ -0,0 +1,141 @@
+package lib
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+
+	. "gopkg.in/check.v1"
+)
+
+func readManifestEntries(c *C, content, format, prefix, encodingType string) ([]ManifestEntry, error) {
+	fileName := "ossutil-manifest-" + randLowStr(6)
+	if format == "" {
+		fileName += ".csv"
+	}
+	c.Assert(ioutil.WriteFile(fileName, []byte(content), 0600), IsNil)
+	defer os.Remove(fileName)
+
+	command := Command{options: OptionMapType{
+		OptionManifest:       &fileName,
+		OptionManifestFormat: &format,
+		OptionEncodingType:   &encodingType,
+	}}
+	ms, err := command.openManifest(CloudURL{bucket: "bucket", object: prefix})
+	c.Assert(err, IsNil)
+	c.Assert(ms, NotNil)
+
+	var entries []ManifestEntry
+	err = ms.forEach(func(entry ManifestEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+func (s *OssutilCommandSuite) TestManifestPlain(c *C) {
+	entries, err := readManifestEntries(c, "dir/a.txt\n\n  dir/b c.txt \nother/c.txt\n", ManifestFormatPlain, "dir/", "")
+	c.Assert(err, IsNil)
+	c.Assert(entries, DeepEquals, []ManifestEntry{
+		{Key: "dir/a.txt", Size: -1, Line: 1},
+		{Key: "dir/b c.txt", Size: -1, Line: 3},
+	})
+
+	entries, err = readManifestEntries(c, "dir%2F%E4%B8%AD%E6%96%87\n", ManifestFormatPlain, "", URLEncodingType)
+	c.Assert(err, IsNil)
+	c.Assert(entries[0].Key, Equals, "dir/中文")
+
+	_, err = readManifestEntries(c, "dir%2\n", ManifestFormatPlain, "", URLEncodingType)
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestManifestCSV(c *C) {
+	// the columns are matched by the header, the others are the headers of the object
+	content := "versionId,key,Content-Type,x-oss-meta-owner,size\n" +
+		"v1,a.txt,text/plain,alice,10\n" +
+		",\"b,1.txt\",,,\n"
+	entries, err := readManifestEntries(c, content, "", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(entries, DeepEquals, []ManifestEntry{
+		{Key: "a.txt", VersionId: "v1", Meta: map[string]string{"Content-Type": "text/plain", "x-oss-meta-owner": "alice"}, Size: 10, Line: 2},
+		{Key: "b,1.txt", Size: -1, Line: 3},
+	})
+
+	// the columns are key, versionId and destKey without the header
+	entries, err = readManifestEntries(c, "a.txt,v1,renamed.txt\nb.txt\n", ManifestFormatCSV, "", "")
+	c.Assert(err, IsNil)
+	c.Assert(entries, DeepEquals, []ManifestEntry{
+		{Key: "a.txt", VersionId: "v1", DestKey: "renamed.txt", Size: -1, Line: 1},
+		{Key: "b.txt", Size: -1, Line: 2},
+	})
+
+	_, err = readManifestEntries(c, "key,size\na.txt,big\n", ManifestFormatCSV, "", "")
+	c.Assert(err, NotNil)
+	_, err = readManifestEntries(c, "key,versionId\n,v1\n", ManifestFormatCSV, "", "")
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestManifestJSONL(c *C) {
+	content := `{"key":"dir/a.txt","versionId":"v1","destKey":"b.txt","meta":{"Cache-Control":"no-cache"},"size":5}` + "\n\n" +
+		`{"key":"dir/c.txt"}` + "\n" +
+		`{"key":"other.txt"}` + "\n"
+	entries, err := readManifestEntries(c, content, ManifestFormatJSONL, "dir/", "")
+	c.Assert(err, IsNil)
+	c.Assert(entries, DeepEquals, []ManifestEntry{
+		{Key: "dir/a.txt", VersionId: "v1", DestKey: "b.txt", Meta: map[string]string{"Cache-Control": "no-cache"}, Size: 5, Line: 1},
+		{Key: "dir/c.txt", Size: -1, Line: 3},
+	})
+
+	_, err = readManifestEntries(c, "{\"key\":\n", ManifestFormatJSONL, "", "")
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestManifestInventoryFile(c *C) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write([]byte("\"bucket\",\"dir%2Fa.txt\",\"v1\",\"false\",\"10\"\n\"bucket\",\"dir%2Fb.txt\",\"v2\",\"true\",\"0\"\n"))
+	writer.Close()
+	sum := md5.Sum(buf.Bytes())
+
+	ms := &manifestSource{}
+	var records [][]string
+	fn := func(record []string) error {
+		records = append(records, record)
+		return nil
+	}
+	c.Assert(ms.readInventoryFile(bytes.NewReader(buf.Bytes()), "data.csv.gz", hex.EncodeToString(sum[:]), fn), IsNil)
+	c.Assert(records, DeepEquals, [][]string{
+		{"bucket", "dir%2Fa.txt", "v1", "false", "10"},
+		{"bucket", "dir%2Fb.txt", "v2", "true", "0"},
+	})
+
+	// the corrupted file is detected by the md5 of the manifest
+	err := ms.readInventoryFile(bytes.NewReader(buf.Bytes()), "data.csv.gz", "0123456789ABCDEF0123456789ABCDEF", fn)
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestManifestOptions(c *C) {
+	c.Assert(detectManifestFormat("keys.txt"), Equals, ManifestFormatPlain)
+	c.Assert(detectManifestFormat("objects.CSV"), Equals, ManifestFormatCSV)
+	c.Assert(detectManifestFormat("objects.ndjson"), Equals, ManifestFormatJSONL)
+	c.Assert(detectManifestFormat("oss://bucket/inventory/manifest.json"), Equals, ManifestFormatInventory)
+
+	// not specified
+	ms, err := (&Command{options: OptionMapType{}}).openManifest(CloudURL{bucket: "bucket"})
+	c.Assert(err, IsNil)
+	c.Assert(ms, IsNil)
+
+	fileName := "ossutil-manifest-" + randLowStr(6)
+	format := "xml"
+	command := Command{options: OptionMapType{OptionManifest: &fileName, OptionManifestFormat: &format}}
+	_, err = command.openManifest(CloudURL{bucket: "bucket"})
+	c.Assert(err, NotNil)
+
+	// the local file does not exist
+	format = ""
+	_, err = command.openManifest(CloudURL{bucket: "bucket"})
+	c.Assert(err, NotNil)
+}
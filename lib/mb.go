@@ -3,6 +3,7 @@ package lib
 import (
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"strings"
 
@@ -164,6 +165,7 @@ var makeBucketCommand = MakeBucketCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -172,6 +174,9 @@ var makeBucketCommand = MakeBucketCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionLanguage,
 			OptionACL,
 			OptionStorageClass,
@@ -187,6 +192,10 @@ var makeBucketCommand = MakeBucketCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -227,6 +236,12 @@ func (mc *MakeBucketCommand) RunCommand() error {
 		return fmt.Errorf("invalid cloud url: %s, object not empty, upload object please use \"cp\" command", mc.command.args[0])
 	}
 
+	// the option is shared with the filter of the other commands, so it's checked here
+	storageClass, _ := GetString(OptionStorageClass, mc.command.options)
+	if storageClass != "" && FindPosCaseInsen(storageClass, storageClassList) == -1 {
+		return fmt.Errorf("invalid option value of %s, the value: %s is not anyone of %s", OptionStorageClass, storageClass, formatStorageClassString("/"))
+	}
+
 	client, err := mc.command.ossClient(cloudURL.bucket)
 	if err != nil {
 		return err
@@ -313,16 +328,13 @@ func (mc *MakeBucketCommand) ossCreateBucketRetry(client *oss.Client, bucket str
 	if storageClass != oss.StorageStandard {
 		options = append(options, oss.StorageClass(storageClass))
 	}
-	retryTimes, _ := GetInt(OptionRetryTimes, mc.command.options)
-	for i := 1; ; i++ {
-		err := client.CreateBucket(bucket, options...)
-		if err == nil {
-			return err
-		}
-		if int64(i) >= retryTimes {
-			return BucketError{err, bucket}
-		}
+	err := mc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		return client.CreateBucket(bucket, withResponseHeader(options, respHeader)...)
+	})
+	if err != nil {
+		return BucketError{err, bucket}
 	}
+	return nil
 }
 
 func (mc *MakeBucketCommand) getStorageClass() oss.StorageClassType {
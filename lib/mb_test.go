@@ -222,6 +222,7 @@ func (s *OssutilCommandSuite) TestMbCreateBucketWithRedundancy(c *C) {
 func (s *OssutilCommandSuite) TestMbCreateBucketWithConfigFile(c *C) {
 	bucketName := bucketNamePrefix + randLowStr(10)
 	inputFile := "test-ossutil-file-" + randLowStr(5)
+	defer os.Remove(inputFile)
 	command := "mb"
 	args := []string{CloudURLToString(bucketName, ""), inputFile}
 	str := ""
@@ -257,6 +258,7 @@ func (s *OssutilCommandSuite) TestMbCreateBucketWithServerEncryption(c *C) {
 
 	bucketName := bucketNamePrefix + randLowStr(10)
 	inputFile := "test-ossutil-file-" + randLowStr(5)
+	defer os.Remove(inputFile)
 	command := "mb"
 	xmlBody := `
 	<?xml version="1.0" encoding="UTF-8"?>
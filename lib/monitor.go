@@ -193,6 +193,7 @@ type RMMonitor struct {
 	seekAheadError   error
 	seekAheadEnd     bool
 	finish           bool
+	dryRun           bool
 	_                uint32 //Add padding to make sure the next data 64bits alignment
 }
 
@@ -207,6 +208,7 @@ func (m *RMMonitor) init() {
 	m.errObjectNum = 0
 	m.errUploadIdNum = 0
 	m.finish = false
+	m.dryRun = false
 	m.removedBucket = ""
 }
 
@@ -313,6 +315,9 @@ func (m *RMMonitor) getOKInfo(snap *RMMonitorSnap) string {
 	if m.op&multipartType != 0 {
 		strList = append(strList, fmt.Sprintf("%d uploadIds", snap.uploadIdNum))
 	}
+	if m.dryRun {
+		return fmt.Sprintf("Would remove %s.", strings.Join(strList, ", "))
+	}
 	return fmt.Sprintf("Removed %s.", strings.Join(strList, ", "))
 }
 
@@ -372,6 +377,9 @@ func (m *RMMonitor) getExitStat(snap *RMMonitorSnap, exitStat int) int {
 
 func (m *RMMonitor) getBucketFinishBar(snap *RMMonitorSnap) string {
 	if m.op&bucketType != 0 && snap.removedBucket != "" {
+		if m.dryRun {
+			return getClearStr(fmt.Sprintf("Would remove Bucket: %s\n", snap.removedBucket))
+		}
 		return getClearStr(fmt.Sprintf("Removed Bucket: %s\n", snap.removedBucket))
 	}
 	return getClearStr("")
@@ -412,15 +420,19 @@ type CPMonitor struct {
 	lastSnapSize   int64
 	tickDuration   int64
 	seekAheadError error
+	output         *OutputWriter
 	op             operationType
 	seekAheadEnd   bool
 	finish         bool
+	dryRun         bool
 	_              uint32 //Add padding to make sure the next data 64bits alignment
 	lastSnapTime   time.Time
 }
 
 func (m *CPMonitor) init(op operationType) {
 	m.op = op
+	m.dryRun = false
+	m.output = nil
 	m.totalSize = 0
 	m.totalNum = 0
 	m.seekAheadEnd = false
@@ -513,12 +525,49 @@ func (m *CPMonitor) progressBar(finish bool, exitStat int) string {
 		return ""
 	}
 	m.finish = m.finish || finish
+	if m.output != nil {
+		// no progress in machine readable output, only the summary record
+		if finish {
+			m.output.Write(m.getSummaryRecord(exitStat))
+		}
+		return ""
+	}
 	if !finish {
 		return m.getProgressBar()
 	}
 	return m.getFinishBar(exitStat)
 }
 
+func (m *CPMonitor) getSummaryRecord(exitStat int) OutputRecord {
+	snap := m.getSnapshot()
+	status := "succeed"
+	if exitStat != normalExit {
+		status = "error"
+	} else if snap.errNum != 0 {
+		status = "finish_with_error"
+	}
+
+	totalNum, totalSize := m.totalNum, m.totalSize
+	if !m.seekAheadEnd || m.seekAheadError != nil {
+		totalNum, totalSize = max(m.totalNum, snap.dealNum), max(m.totalSize, snap.dealSize)
+	}
+	return OutputRecord{
+		"type":          recordSummary,
+		"status":        status,
+		"dry_run":       m.dryRun,
+		"total_count":   totalNum,
+		"total_size":    totalSize,
+		"ok_count":      snap.okNum,
+		"file_count":    snap.fileNum,
+		"dir_count":     snap.dirNum,
+		"skip_count":    snap.skipNum,
+		"skip_dir":      snap.skipNumDir,
+		"error_count":   snap.errNum,
+		"transfer_size": snap.transferSize,
+		"skip_size":     snap.skipSize,
+	}
+}
+
 func (m *CPMonitor) getProgressBar() string {
 	mu.RLock()
 	defer mu.RUnlock()
@@ -624,14 +673,17 @@ func (m *CPMonitor) getSpeed(snap *CPMonitorSnap) float64 {
 }
 
 func (m *CPMonitor) getOPStr() string {
+	opStr := "copy"
 	switch m.op {
 	case operationTypePut:
-		return "upload"
+		opStr = "upload"
 	case operationTypeGet:
-		return "download"
-	default:
-		return "copy"
+		opStr = "download"
+	}
+	if m.dryRun {
+		return "would " + opStr
 	}
+	return opStr
 }
 
 func (m *CPMonitor) getDealSizeDetail(snap *CPMonitorSnap) string {
@@ -0,0 +1,497 @@
+package lib
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+var specChineseMove = SpecText{
+
+	synopsisText: "移动文件或者object，上传、下载或拷贝成功并校验后删除源端",
+
+	paramText: "src_url dest_url [options]",
+
+	syntaxText: `
+    ossutil mv file_url cloud_url [-r] [-f] [-u] [--dryrun] [--enable-symlink-dir] [--disable-all-symlink] [--disable-ignore-error] [--only-current-dir] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
+    ossutil mv cloud_url file_url [-r] [-f] [-u] [--dryrun] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
+    ossutil mv cloud_url cloud_url [-r] [-f] [-u] [--dryrun] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
+`,
+
+	detailHelpText: `
+    该命令和cp命令类似:支持从本地文件系统上传文件到oss，从oss下载object到本地文件系统，在oss
+    上进行object拷贝(使用CopyObject或者分片拷贝，数据不经过本地); 每个文件或object传输成功后，
+    ossutil校验目的端的大小和源端一致(object之间拷贝时两端都有crc64的，同时校验crc64)，然后删除源端
+    的文件或object，从而实现移动或者重命名。
+
+    mv命令和cp命令不同之处如下:
+    1、只有传输成功并且校验通过后，才删除对应的源端，删除失败不影响其他文件。传输失败、被跳过
+       (例如使用-u时目的端较新)或者校验失败的文件或object保留在源端，称为遗留项；使用--compare=size、
+       size-mtime、crc64或md5时，因与目的端相同而被跳过的源端，校验通过后同样被删除
+    2、结束时输出移动的数量和遗留的数量，遗留项及原因记录在--output-dir目录下的报告文件中，
+       有遗留项时命令返回错误
+    3、上传时，目录中的文件全部移动后，空目录也被删除
+    4、源端和目的端为同一个object时，不做删除
+    5、不支持--range、--version-id、--partition-download选项
+
+--dryrun
+    只输出将要执行的传输以及删除源端的操作，不实际修改源端和目的端的任何数据
+
+    其他选项说明、用法和cp命令相同
+`,
+	sampleText: `
+    1) 上传目录到oss，上传成功后删除本地文件
+    ossutil mv -r local_dir oss://bucket/prefix/
+
+    2) 下载object到本地，下载成功后删除object
+    ossutil mv oss://bucket/object local_file
+
+    3) 在oss上重命名目录
+    ossutil mv -r oss://bucket/dir1/ oss://bucket/dir2/
+`,
+}
+
+var specEnglishMove = SpecText{
+
+	synopsisText: "Move files or objects, the source is deleted after it's uploaded, downloaded or copied and verified",
+
+	paramText: "src_url dest_url [options]",
+
+	syntaxText: `
+    ossutil mv file_url cloud_url [-r] [-f] [-u] [--dryrun] [--enable-symlink-dir] [--disable-all-symlink] [--disable-ignore-error] [--only-current-dir] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
+    ossutil mv cloud_url file_url [-r] [-f] [-u] [--dryrun] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
+    ossutil mv cloud_url cloud_url [-r] [-f] [-u] [--dryrun] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
+`,
+
+	detailHelpText: `
+    This command is similar to the cp command: it supports uploading files from the local file
+    system to oss, downloading objects from oss to the local file system, and copying objects
+    in oss(by CopyObject or multipart copy, the data does not pass through local); After each file
+    or object is transferred, ossutil verifies that the size of the destination is the same as
+    the source(and the crc64 too when copying objects if both of them have it), then deletes the
+    source file or object, so that it's moved or renamed.
+
+    The differences between the mv command and the cp command are as follows:
+    1、The source is deleted only after it's transferred and verified, the failure of deleting one
+       does not affect the others. The files or objects which fail, are skipped(eg: the destination
+       is newer with -u) or fail to verify are kept in the source, they are left behind. The ones
+       skipped as the same as the destination by --compare=size, size-mtime, crc64 or md5 are
+       deleted after they are verified too.
+    2、The number of moved and left behind is printed at the end, the left behind items and the
+       reasons are recorded in the report file in --output-dir, the command returns error if
+       anything is left behind.
+    3、When uploading, the empty directories are removed after all files in them are moved.
+    4、The source is not deleted if it's the same object as the destination.
+    5、--range, --version-id and --partition-download are not supported.
+
+--dryrun
+    Only print the transfer and the deletion of source to be done, without modifying any data
+    on source or destination
+
+    Other options and usages are the same as the cp command
+`,
+	sampleText: `
+    1) upload the directory to oss, and delete the local files after they are uploaded
+    ossutil mv -r local_dir oss://bucket/prefix/
+
+    2) download the object, and delete it after it's downloaded
+    ossutil mv oss://bucket/object local_file
+
+    3) rename the directory in oss
+    ossutil mv -r oss://bucket/dir1/ oss://bucket/dir2/
+`,
+}
+
+// MoveCommand moves files or objects by the copy command, the source is deleted after its copy is verified
+type MoveCommand struct {
+	command Command
+}
+
+var moveCommand = MoveCommand{
+	command: Command{
+		name:        "mv",
+		nameAlias:   []string{"mv", "move"},
+		minArgc:     2,
+		maxArgc:     MaxInt,
+		specChinese: specChineseMove,
+		specEnglish: specEnglishMove,
+		group:       GroupTypeNormalCommand,
+		validOptionNames: []string{
+			// The following options are supported by cp command too
+			OptionRecursion,
+			OptionForce,
+			OptionUpdate,
+			OptionContinue,
+			OptionOutputDir,
+			OptionBigFileThreshold,
+			OptionPartSize,
+			OptionCheckpointDir,
+			OptionEncodingType,
+			OptionInclude,
+			OptionExclude,
+			OptionIncludeRegex,
+			OptionExcludeRegex,
+			OptionExcludeFrom,
+			OptionMeta,
+			OptionACL,
+			OptionConfigFile,
+			OptionProfile,
+			OptionEndpoint,
+			OptionAccessKeyID,
+			OptionAccessKeySecret,
+			OptionSTSToken,
+			OptionProxyHost,
+			OptionProxyUser,
+			OptionProxyPwd,
+			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
+			OptionRoutines,
+			OptionParallel,
+			OptionSnapshotPath,
+			OptionDisableCRC64,
+			OptionRequestPayer,
+			OptionLogLevel,
+			OptionMaxUpSpeed,
+			OptionLocalHost,
+			OptionEnableSymlinkDir,
+			OptionOnlyCurrentDir,
+			OptionDisableDirObject,
+			OptionDisableAllSymlink,
+			OptionDisableIgnoreError,
+			OptionTagging,
+			OptionPassword,
+			OptionMode,
+			OptionECSRoleName,
+			OptionTokenTimeout,
+			OptionRamRoleArn,
+			OptionRoleSessionName,
+			OptionReadTimeout,
+			OptionConnectTimeout,
+			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
+			OptionSkipVerifyCert,
+			OptionMaxDownSpeed,
+			OptionBwLimit,
+			OptionBwLimitFile,
+			OptionResumeJob,
+			OptionJobsDir,
+			OptionEncryptionPubKey,
+			OptionEncryptionPriKey,
+			OptionEncryptionAesKey,
+			OptionEncryptionMatDesc,
+			OptionCompress,
+			OptionCompressInclude,
+			OptionDecompress,
+			OptionAutoTune,
+			OptionUserAgent,
+			OptionSignVersion,
+			OptionRegion,
+			OptionCloudBoxID,
+			OptionForcePathStyle,
+			OptionStartTime,
+			OptionEndTime,
+			OptionCompare,
+			OptionDryRun,
+			OptionOutputFormat,
+		},
+	},
+}
+
+// function for FormatHelper interface
+func (mc *MoveCommand) formatHelpForWhole() string {
+	return mc.command.formatHelpForWhole()
+}
+
+func (mc *MoveCommand) formatIndependHelp() string {
+	return mc.command.formatIndependHelp()
+}
+
+// Init simulate inheritance, and polymorphism
+func (mc *MoveCommand) Init(args []string, options OptionMapType) error {
+	// the options are assembled by cp command, keep the original ones for mv command
+	copyOptions := make(OptionMapType)
+	for k, v := range options {
+		copyOptions[k] = v
+	}
+
+	if err := mc.command.Init(args, options, mc); err != nil {
+		return err
+	}
+	if err := (&copyCommand).Init(args, copyOptions); err != nil {
+		return err
+	}
+	copyCommand.cpOption.move = &moveRecorder{}
+	return nil
+}
+
+// RunCommand simulate inheritance, and polymorphism
+func (mc *MoveCommand) RunCommand() error {
+	move := copyCommand.cpOption.move
+	// the cp command run later in the same process must not delete its source
+	defer func() { copyCommand.cpOption.move = nil }()
+	for _, arg := range mc.command.args[:len(mc.command.args)-1] {
+		if f, err := os.Stat(arg); err == nil && f.IsDir() && !strings.HasPrefix(arg, SchemePrefix) {
+			move.addDir(arg, true)
+		}
+	}
+	err := copyCommand.RunCommand()
+
+	if copyCommand.cpOption.opType == operationTypePut && !copyCommand.cpOption.dryRun {
+		move.removeDirs(err == nil)
+	}
+
+	moved := atomic.LoadInt64(&move.moved)
+	left := atomic.LoadInt64(&move.left)
+	LogInfo("move end, moved:%d, left behind:%d\n", moved, left)
+	if copyCommand.cpOption.output == nil && !copyCommand.cpOption.dryRun {
+		fmt.Printf("\nmoved: %d, left behind: %d\n", moved, left)
+	}
+	if err == nil && left > 0 {
+		err = fmt.Errorf("%d files(objects) are left behind in source", left)
+	}
+	return err
+}
+
+// moveRecorder counts what moved and what was left behind, the uploaded directories are removed
+// at the end when they are empty.
+type moveRecorder struct {
+	// keep the int64 fields first for atomic operations
+	moved int64
+	left  int64
+
+	lock       sync.Mutex
+	dirs       []string
+	roots      []string
+	destBucket *oss.Bucket
+}
+
+func (mr *moveRecorder) addDir(dir string, isRoot bool) {
+	mr.lock.Lock()
+	defer mr.lock.Unlock()
+	if isRoot {
+		mr.roots = append(mr.roots, dir)
+	} else {
+		mr.dirs = append(mr.dirs, dir)
+	}
+}
+
+// removeDirs removes the empty directories from the deepest one, the root directories of source
+// are removed only if the move succeeds.
+func (mr *moveRecorder) removeDirs(removeRoots bool) {
+	dirs := mr.dirs
+	if removeRoots {
+		dirs = append(dirs, mr.roots...)
+	}
+	sort.Slice(dirs, func(i, k int) bool { return len(dirs[i]) > len(dirs[k]) })
+	for _, dir := range dirs {
+		if err := os.Remove(dir); err == nil {
+			LogInfo("move remove directory %s\n", dir)
+			atomic.AddInt64(&mr.moved, 1)
+		}
+	}
+}
+
+// leaveSource records the source which is not deleted
+func (cc *CopyCommand) leaveSource(src, reason string) {
+	atomic.AddInt64(&cc.cpOption.move.left, 1)
+	LogError("move left behind %s, reason: %s\n", src, reason)
+	if cc.cpOption.reporter != nil {
+		cc.cpOption.reporter.ReportError(fmt.Sprintf("%s is left behind, reason: %s", src, reason))
+	}
+}
+
+// moveReason returns why the source can't be deleted by the result of transfer, empty if it can be.
+// The source skipped as the same as the destination is deleted after it's verified, but the one
+// skipped by the time range or just because the destination is newer is kept.
+func (cc *CopyCommand) moveReason(skip bool, skipReason string, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if skip && (skipReason != cc.compareReason(true) || cc.compareMode() == CompareMtime) {
+		return "skipped, " + skipReason
+	}
+	return ""
+}
+
+// moveAfterUpload deletes the local file after the object is verified
+func (cc *CopyCommand) moveAfterUpload(bucket *oss.Bucket, objectName string, file fileInfoType, skip, isDir bool, skipReason string, err error) {
+	filePath := filepath.Join(file.dir, file.filePath)
+	if isDir && err == nil {
+		// removed at the end if it's empty
+		if !cc.cpOption.dryRun {
+			cc.cpOption.move.addDir(filePath, false)
+		}
+		return
+	}
+	if reason := cc.moveReason(skip, skipReason, err); reason != "" {
+		cc.leaveSource(filePath, reason)
+		return
+	}
+	if cc.cpOption.dryRun {
+		cc.printDryRun("remove "+filePath, "moved")
+		return
+	}
+
+	f, errF := os.Stat(filePath)
+	if errF != nil {
+		cc.leaveSource(filePath, errF.Error())
+		return
+	}
+	if errV := cc.verifyObject(bucket, objectName, compareInfoType{size: f.Size()}); errV != nil {
+		cc.leaveSource(filePath, errV.Error())
+		return
+	}
+	if errR := os.Remove(filePath); errR != nil {
+		cc.leaveSource(filePath, errR.Error())
+		return
+	}
+	atomic.AddInt64(&cc.cpOption.move.moved, 1)
+}
+
+// moveAfterDownload deletes the object after the local file is verified
+func (cc *CopyCommand) moveAfterDownload(bucket *oss.Bucket, objectInfo objectInfoType, fileName string, skip bool, skipReason string, err error) {
+	object := objectInfo.key()
+	src := CloudURLToString(bucket.BucketName, object)
+	if reason := cc.moveReason(skip, skipReason, err); reason != "" {
+		cc.leaveSource(src, reason)
+		return
+	}
+	if cc.cpOption.dryRun {
+		cc.printDryRun("remove "+src, "moved")
+		return
+	}
+
+	f, errF := os.Stat(fileName)
+	if errF != nil {
+		cc.leaveSource(src, errF.Error())
+		return
+	}
+	if !f.IsDir() {
+		size := objectInfo.size
+		if size < 0 || cc.cpOption.decompress {
+			var errS error
+			if size, errS = cc.getObjectSize(bucket, object); errS != nil {
+				cc.leaveSource(src, errS.Error())
+				return
+			}
+		}
+		if f.Size() != size {
+			cc.leaveSource(src, fmt.Sprintf("the size of %s is %d, not the same as %d of source", fileName, f.Size(), size))
+			return
+		}
+	}
+	cc.removeSourceObject(bucket, object)
+}
+
+// moveAfterCopy deletes the source object after the dest object is verified
+func (cc *CopyCommand) moveAfterCopy(bucket *oss.Bucket, objectInfo objectInfoType, destURL CloudURL, skip bool, skipReason string, err error) {
+	object := objectInfo.key()
+	src := CloudURLToString(bucket.BucketName, object)
+	if reason := cc.moveReason(skip, skipReason, err); reason != "" {
+		cc.leaveSource(src, reason)
+		return
+	}
+
+	destObject := cc.makeCopyObjectName(objectInfo.relativeKey, destURL.object)
+	if bucket.BucketName == destURL.bucket && object == destObject {
+		cc.leaveSource(src, "the source and dest are the same")
+		return
+	}
+	if cc.cpOption.dryRun {
+		cc.printDryRun("remove "+src, "moved")
+		return
+	}
+
+	destBucket, errB := cc.moveDestBucket(destURL.bucket)
+	if errB != nil {
+		cc.leaveSource(src, errB.Error())
+		return
+	}
+	// the source is got again for its crc64
+	srcInfo, errS := cc.getObjectVerifyInfo(bucket, object)
+	if errS != nil {
+		cc.leaveSource(src, errS.Error())
+		return
+	}
+	if errV := cc.verifyObject(destBucket, destObject, srcInfo); errV != nil {
+		cc.leaveSource(src, errV.Error())
+		return
+	}
+	cc.removeSourceObject(bucket, object)
+}
+
+func (cc *CopyCommand) moveDestBucket(bucketName string) (*oss.Bucket, error) {
+	move := cc.cpOption.move
+	move.lock.Lock()
+	defer move.lock.Unlock()
+	if move.destBucket == nil {
+		bucket, err := cc.command.ossBucket(bucketName)
+		if err != nil {
+			return nil, err
+		}
+		move.destBucket = bucket
+	}
+	return move.destBucket, nil
+}
+
+func (cc *CopyCommand) getObjectSize(bucket *oss.Bucket, object string) (int64, error) {
+	info, err := cc.getObjectVerifyInfo(bucket, object)
+	return info.size, err
+}
+
+// getObjectVerifyInfo returns the size and the crc64 of the object, the crc64 is empty if it's not returned,
+// or the object is compressed
+func (cc *CopyCommand) getObjectVerifyInfo(bucket *oss.Bucket, object string) (compareInfoType, error) {
+	props, err := cc.command.ossGetObjectStatRetry(bucket, object, cc.cpOption.payerOptions...)
+	if err != nil {
+		return compareInfoType{}, err
+	}
+	size, err := strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64)
+	if err != nil {
+		return compareInfoType{}, err
+	}
+	if cc.cpOption.compress != "" || cc.cpOption.decompress {
+		// the size of the original content of the compressed object
+		return compareInfoType{size: decompressedCompareInfo(props, compareInfoType{size: size}).size}, nil
+	}
+	return compareInfoType{size: size, crc64: props.Get(oss.HTTPHeaderOssCRC64)}, nil
+}
+
+// verifyObject checks the dest object has the size of source, and the crc64 too if both of them have it
+func (cc *CopyCommand) verifyObject(bucket *oss.Bucket, object string, src compareInfoType) error {
+	dest, err := cc.getObjectVerifyInfo(bucket, object)
+	if err != nil {
+		return fmt.Errorf("verify %s error, %s", CloudURLToString(bucket.BucketName, object), err.Error())
+	}
+	if dest.size != src.size {
+		return fmt.Errorf("the size of %s is %d, not the same as %d of source", CloudURLToString(bucket.BucketName, object), dest.size, src.size)
+	}
+	if src.crc64 != "" && dest.crc64 != "" && dest.crc64 != src.crc64 {
+		return fmt.Errorf("the crc64 of %s is %s, not the same as %s of source", CloudURLToString(bucket.BucketName, object), dest.crc64, src.crc64)
+	}
+	return nil
+}
+
+func (cc *CopyCommand) removeSourceObject(bucket *oss.Bucket, object string) {
+	err := cc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		return bucket.DeleteObject(object, withResponseHeader(cc.cpOption.payerOptions, respHeader)...)
+	})
+	if err != nil {
+		cc.leaveSource(CloudURLToString(bucket.BucketName, object), ObjectError{err, bucket.BucketName, object}.Error())
+		return
+	}
+	atomic.AddInt64(&cc.cpOption.move.moved, 1)
+}
@@ -0,0 +1,120 @@
+package lib
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) TestMoveInvalidOption(c *C) {
+	vrange := "0-9"
+	versionId := "id"
+	for _, options := range []OptionMapType{{OptionRange: &vrange}, {OptionVersionId: &versionId}} {
+		_, err := cm.RunCommand("mv", []string{"oss://bucket/object", "file"}, options)
+		c.Assert(err, NotNil)
+	}
+	c.Assert(copyCommand.cpOption.move, IsNil)
+}
+
+func (s *OssutilCommandSuite) TestMoveLeaveSource(c *C) {
+	bucket, err := oss.New("oss-cn-hangzhou.aliyuncs.com", "ak", "sk")
+	c.Assert(err, IsNil)
+	srcBucket, err := bucket.Bucket("bucket")
+	c.Assert(err, IsNil)
+
+	cc := CopyCommand{}
+	cc.cpOption.move = &moveRecorder{}
+
+	// the failed and skipped ones are left behind
+	file := fileInfoType{"file", "dir"}
+	cc.moveAfterUpload(srcBucket, "object", file, false, false, "", fmt.Errorf("upload error"))
+	cc.moveAfterUpload(srcBucket, "object", file, true, false, reasonBeforeStartTime, nil)
+	objectInfo := objectInfoType{prefix: "dir/", relativeKey: "object", size: 10, lastModified: time.Now()}
+	cc.moveAfterDownload(srcBucket, objectInfo, "file", false, "", fmt.Errorf("download error"))
+	cc.moveAfterCopy(srcBucket, objectInfo, CloudURL{bucket: "bucket", object: "dest/"}, true, reasonAfterEndTime, nil)
+	c.Assert(cc.cpOption.move.left, Equals, int64(4))
+
+	// the destination is newer with -u
+	cc.cpOption.update = true
+	cc.moveAfterCopy(srcBucket, objectInfo, CloudURL{bucket: "bucket", object: "dest/"}, true, cc.compareReason(true), nil)
+	c.Assert(cc.cpOption.move.left, Equals, int64(5))
+
+	// the source is not deleted if it's the dest
+	cc.moveAfterCopy(srcBucket, objectInfo, CloudURL{bucket: "bucket", object: "dir/object"}, false, "", nil)
+	c.Assert(cc.cpOption.move.left, Equals, int64(6))
+
+	// the local file which does not exist is left behind
+	cc.moveAfterUpload(srcBucket, "object", fileInfoType{"notexist", randLowStr(10)}, false, false, "", nil)
+	c.Assert(cc.cpOption.move.left, Equals, int64(7))
+
+	// nothing is deleted in dry run mode
+	cc.cpOption.dryRun = true
+	cc.moveAfterDownload(srcBucket, objectInfo, "file", false, "", nil)
+	c.Assert(cc.cpOption.move.left, Equals, int64(7))
+	c.Assert(cc.cpOption.move.moved, Equals, int64(0))
+}
+
+func (s *OssutilCommandSuite) TestMoveVerifyCRC64(c *C) {
+	as := &appendObjectServer{objects: map[string]string{"src": "abc", "dest": "abd"}}
+	svr := httptest.NewServer(as)
+	defer svr.Close()
+	client, err := oss.New(svr.URL, "ak", "sk")
+	c.Assert(err, IsNil)
+	bucket, err := client.Bucket("bucket")
+	c.Assert(err, IsNil)
+
+	cc := CopyCommand{}
+	cc.cpOption.move = &moveRecorder{destBucket: bucket}
+	objectInfo := objectInfoType{relativeKey: "src", size: 3, lastModified: time.Now()}
+
+	// the same size but different crc64
+	cc.moveAfterCopy(bucket, objectInfo, CloudURL{bucket: "bucket", object: "dest"}, false, "", nil)
+	c.Assert(cc.cpOption.move.left, Equals, int64(1))
+	c.Assert(as.objects["src"], Equals, "abc")
+
+	// the source skipped as the same as the destination is verified and deleted
+	as.set("dest", "abc")
+	cc.cpOption.compare = CompareCRC64
+	cc.moveAfterCopy(bucket, objectInfo, CloudURL{bucket: "bucket", object: "dest"}, true, cc.compareReason(true), nil)
+	c.Assert(cc.cpOption.move.moved, Equals, int64(1))
+	_, ok := as.objects["src"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *OssutilCommandSuite) TestMoveRemoveDirs(c *C) {
+	root := "ossutil-mv-" + randLowStr(6)
+	defer os.RemoveAll(root)
+	c.Assert(os.MkdirAll(filepath.Join(root, "a", "b"), 0755), IsNil)
+	c.Assert(os.MkdirAll(filepath.Join(root, "c"), 0755), IsNil)
+	s.createFile(filepath.Join(root, "c", "file"), "left", c)
+
+	move := &moveRecorder{}
+	move.addDir(root, true)
+	move.addDir(filepath.Join(root, "a"), false)
+	move.addDir(filepath.Join(root, "a", "b"), false)
+	move.addDir(filepath.Join(root, "c"), false)
+
+	// the directory which is not empty is kept
+	move.removeDirs(true)
+	c.Assert(move.moved, Equals, int64(2))
+	_, err := os.Stat(filepath.Join(root, "a"))
+	c.Assert(os.IsNotExist(err), Equals, true)
+	_, err = os.Stat(filepath.Join(root, "c", "file"))
+	c.Assert(err, IsNil)
+
+	// the root is removed only if the move succeeds
+	c.Assert(os.RemoveAll(filepath.Join(root, "c")), IsNil)
+	move = &moveRecorder{}
+	move.addDir(root, true)
+	move.removeDirs(false)
+	_, err = os.Stat(root)
+	c.Assert(err, IsNil)
+	move.removeDirs(true)
+	_, err = os.Stat(root)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
@@ -14,9 +14,9 @@ var specChineseObjectTag = SpecText{
 	paramText: "cloud_url [tag_parameter] [options]",
 
 	syntaxText: ` 
-    ossutil object-tagging --method put oss://bucket[/prefix] key#value [--encoding-type url] [-r] [--payer requester] [--version-id versionId] [-c file] 
-    ossutil object-tagging --method get oss://bucket[/prefix] [--encoding-type url] [-r]  [--payer requester] [--version-id versionId] [-c file] 
-    ossutil object-tagging --method delete oss://bucket[/prefix] [--encoding-type url] [-r] [--payer requester] [--version-id versionId] [-c file] 
+    ossutil object-tagging --method put oss://bucket[/prefix] key#value [--encoding-type url] [-r] [--payer requester] [--version-id versionId] [--manifest file] [-c file] 
+    ossutil object-tagging --method get oss://bucket[/prefix] [--encoding-type url] [-r]  [--payer requester] [--version-id versionId] [--manifest file] [-c file] 
+    ossutil object-tagging --method delete oss://bucket[/prefix] [--encoding-type url] [-r] [--payer requester] [--version-id versionId] [--manifest file] [-c file] 
 `,
 	detailHelpText: ` 
     object-tagging命令通过设置method选项值为put、get、delete,可以设置、查询或者删除object的tag配置
@@ -33,6 +33,9 @@ var specChineseObjectTag = SpecText{
 	
     3) ossutil object-tagging --method delete oss://bucket/object
         这个命令删除object的tag配置
+
+    如果指定了--manifest选项，ossutil从清单中读取cloud_url前缀下的objects批量操作，而不是列举bucket，
+    此时可以不指定-r选项，清单中指定了版本号时操作该版本。清单的格式请参考cp命令帮助。
 `,
 	sampleText: ` 
     1) 设置object的tag配置
@@ -55,6 +58,9 @@ var specChineseObjectTag = SpecText{
     
     7) 批量删除object的tag配置
        ossutil object-tagging --method delete oss://bucket/prefix -r
+
+    8) 批量设置清单中objects的tag配置
+       ossutil object-tagging --method put oss://bucket --manifest objects.jsonl tagkey#tagvalue
 `,
 }
 
@@ -64,9 +70,9 @@ var specEnglishObjectTag = SpecText{
 	paramText: "cloud_url [tag_parameter] [options]",
 
 	syntaxText: ` 
-    ossutil object-tagging --method put oss://bucket[/prefix] key#value [--encoding-type url] [-r] [--payer requester] [--version-id versionId] [-c file] 
-    ossutil object-tagging --method get oss://bucket[/prefix] [--encoding-type url] [-r]  [--payer requester] [--version-id versionId] [-c file] 
-    ossutil object-tagging --method delete oss://bucket[/prefix] [--encoding-type url] [-r] [--payer requester] [--version-id versionId] [-c file] 
+    ossutil object-tagging --method put oss://bucket[/prefix] key#value [--encoding-type url] [-r] [--payer requester] [--version-id versionId] [--manifest file] [-c file] 
+    ossutil object-tagging --method get oss://bucket[/prefix] [--encoding-type url] [-r]  [--payer requester] [--version-id versionId] [--manifest file] [-c file] 
+    ossutil object-tagging --method delete oss://bucket[/prefix] [--encoding-type url] [-r] [--payer requester] [--version-id versionId] [--manifest file] [-c file] 
 `,
 	detailHelpText: ` 
     object-tagging command can set, get and delete the tag configuration of the oss object by set method option value to put, get, delete
@@ -82,6 +88,10 @@ Usage:
 
     3) ossutil object-tagging --method delete oss://bucket/object
         The command deletes the tag configuration of bucket/object
+
+    If --manifest option is specified, ossutil reads the objects under the prefix of cloud_url from the manifest
+    instead of listing the bucket, -r option can be omitted then, and the version is operated if the manifest
+    specifies it. The format of the manifest, please refer cp command help.
 `,
 	sampleText: ` 
     1) set object tag configuration with one tag   
@@ -104,6 +114,9 @@ Usage:
     
     7) batch delete objects tag configuration
        ossutil object-tagging --method delete oss://bucket/prefix -r
+
+    8) batch set the tag configuration of the objects in the manifest
+       ossutil object-tagging --method put oss://bucket --manifest objects.jsonl tagkey#tagvalue
 `,
 }
 
@@ -117,6 +130,7 @@ type ObjectTagCommand struct {
 	printHeader   bool
 	objectIndex   int32
 	reportOption  batchOptionType
+	manifest      *manifestSource
 }
 
 var objectTagCommand = ObjectTagCommand{
@@ -130,6 +144,7 @@ var objectTagCommand = ObjectTagCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -141,6 +156,8 @@ var objectTagCommand = ObjectTagCommand{
 			OptionMethod,
 			OptionLogLevel,
 			OptionEncodingType,
+			OptionManifest,
+			OptionManifestFormat,
 			OptionRecursion,
 			OptionVersionId,
 			OptionRequestPayer,
@@ -153,6 +170,10 @@ var objectTagCommand = ObjectTagCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -208,6 +229,10 @@ func (otc *ObjectTagCommand) RunCommand() error {
 
 	recursive, _ := GetBool(OptionRecursion, otc.command.options)
 	versionId, _ := GetString(OptionVersionId, otc.command.options)
+	// the objects of the manifest are operated in batch as the recursive ones
+	if manifest, _ := GetString(OptionManifest, otc.command.options); manifest != "" {
+		recursive = true
+	}
 	if len(versionId) > 0 {
 		if recursive {
 			return fmt.Errorf("--version-id and -r can't be both used")
@@ -235,6 +260,9 @@ func (otc *ObjectTagCommand) RunCommand() error {
 	if err != nil {
 		return err
 	}
+	if otc.manifest, err = otc.command.openManifest(*cloudUrL); err != nil {
+		return err
+	}
 
 	if !recursive {
 		err = otc.SingleObjectTagging(bucket, cloudUrL.object)
@@ -244,16 +272,18 @@ func (otc *ObjectTagCommand) RunCommand() error {
 	return err
 }
 
-func (otc *ObjectTagCommand) SingleObjectTagging(bucket *oss.Bucket, objectName string) error {
+func (otc *ObjectTagCommand) SingleObjectTagging(bucket *oss.Bucket, objectName string, options ...oss.Option) error {
 	if len(objectName) == 0 {
 		return fmt.Errorf("object key is empty")
 	}
 
+	options = append(append([]oss.Option{}, otc.commonOptions...), options...)
+
 	var tagError error
 	if otc.method == "put" {
-		tagError = bucket.PutObjectTagging(objectName, otc.tagging, otc.commonOptions...)
+		tagError = bucket.PutObjectTagging(objectName, otc.tagging, options...)
 	} else if otc.method == "get" {
-		resutl, err := bucket.GetObjectTagging(objectName, otc.commonOptions...)
+		resutl, err := bucket.GetObjectTagging(objectName, options...)
 		tagError = err
 		if err == nil {
 			otc.lock.Lock()
@@ -272,7 +302,7 @@ func (otc *ObjectTagCommand) SingleObjectTagging(bucket *oss.Bucket, objectName
 			otc.lock.Unlock()
 		}
 	} else if otc.method == "delete" {
-		tagError = bucket.DeleteObjectTagging(objectName, otc.commonOptions...)
+		tagError = bucket.DeleteObjectTagging(objectName, options...)
 	}
 
 	if tagError == nil {
@@ -299,6 +329,15 @@ func (otc *ObjectTagCommand) BatchObjectTagging(bucket *oss.Bucket, cloudURL Clo
 	chError := make(chan error, routines+1)
 	chListError := make(chan error, 1)
 
+	if otc.manifest != nil {
+		chEntries := make(chan ManifestEntry, ChannelBuf)
+		go otc.command.manifestProducer(otc.manifest, []filterOptionType{}, chEntries, chListError, &otc.monitor)
+		for i := 0; int64(i) < routines; i++ {
+			go otc.manifestObjectTaggingConsumer(bucket, chEntries, chError)
+		}
+		return otc.waitRoutinueComplete(chError, chListError, routines)
+	}
+
 	go otc.command.objectStatistic(bucket, cloudURL, &otc.monitor, []filterOptionType{}, otc.commonOptions...)
 	go otc.command.objectProducer(bucket, cloudURL, chObjects, chListError, []filterOptionType{}, otc.commonOptions...)
 	for i := 0; int64(i) < routines; i++ {
@@ -321,8 +360,27 @@ func (otc *ObjectTagCommand) objectTaggingConsumer(bucket *oss.Bucket, chObjects
 	chError <- nil
 }
 
-func (otc *ObjectTagCommand) objectTaggingWithReport(bucket *oss.Bucket, object string) error {
-	err := otc.SingleObjectTagging(bucket, object)
+// manifestObjectTaggingConsumer operates the tagging of the objects read from the manifest, on the versions it specifies
+func (otc *ObjectTagCommand) manifestObjectTaggingConsumer(bucket *oss.Bucket, chEntries <-chan ManifestEntry, chError chan<- error) {
+	for entry := range chEntries {
+		var options []oss.Option
+		if entry.VersionId != "" {
+			options = append(options, oss.VersionId(entry.VersionId))
+		}
+		err := otc.objectTaggingWithReport(bucket, entry.Key, options...)
+		if err != nil {
+			chError <- err
+			if !otc.reportOption.ctnu {
+				return
+			}
+			continue
+		}
+	}
+	chError <- nil
+}
+
+func (otc *ObjectTagCommand) objectTaggingWithReport(bucket *oss.Bucket, object string, options ...oss.Option) error {
+	err := otc.SingleObjectTagging(bucket, object, options...)
 	if otc.method != "get" {
 		otc.command.updateMonitor(err, &otc.monitor)
 		msg := fmt.Sprintf("%s %s object tagging", otc.method, CloudURLToString(bucket.BucketName, object))
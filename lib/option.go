@@ -56,9 +56,9 @@ var OptionMap = map[string]Option{
 	OptionAllType:         Option{"-a", "--all-type", "", OptionTypeFlagTrue, "", "", "指定操作的对象为bucket中的object和未完成的Multipart事件。", "Indicate that the subject of the command contains both objects and uncompleted Multipart Uploads."},
 	OptionRecursion:       Option{"-r", "--recursive", "", OptionTypeFlagTrue, "", "", "递归进行操作。对于支持该选项的命令，当指定该选项时，命令会对bucket下所有符合条件的objects进行操作，否则只对url中指定的单个object进行操作。", "operate recursively, for those commands which support the option, when use them, if the option is specified, the command will operate on all match objects under the bucket, else we will search the specified object and operate on the single object."},
 	OptionBucket:          Option{"-b", "--bucket", "", OptionTypeFlagTrue, "", "", "对bucket进行操作，该选项用于确认操作作用于bucket", "the option used to make sure the operation will operate on bucket"},
-	OptionStorageClass: Option{"", "--storage-class", DefaultStorageClass, OptionTypeAlternative, fmt.Sprintf("%s/%s/%s/%s", StorageStandard, StorageIA, StorageArchive, StorageColdArchive), "",
-		fmt.Sprintf("设置对象的存储方式，默认值：%s，取值范围：%s/%s/%s/%s。", DefaultStorageClass, StorageStandard, StorageIA, StorageArchive, StorageColdArchive),
-		fmt.Sprintf("set the storage class of bucket(default: %s), value range is: %s/%s/%s/%s.", DefaultStorageClass, StorageStandard, StorageIA, StorageArchive, StorageColdArchive)},
+	OptionStorageClass: Option{"", "--storage-class", "", OptionTypeString, "", "",
+		fmt.Sprintf("mb命令中设置bucket的存储方式，默认值：%s，取值范围：%s/%s/%s/%s。其他命令中只操作指定存储方式的objects，多个存储方式以逗号分隔，例如：%s,%s。", DefaultStorageClass, StorageStandard, StorageIA, StorageArchive, StorageColdArchive, StorageIA, StorageArchive),
+		fmt.Sprintf("set the storage class of bucket in mb command(default: %s), value range is: %s/%s/%s/%s. In the other commands, only operate the objects of the storage classes, separated by comma, eg: %s,%s.", DefaultStorageClass, StorageStandard, StorageIA, StorageArchive, StorageColdArchive, StorageIA, StorageArchive)},
 	OptionForce:  Option{"-f", "--force", "", OptionTypeFlagTrue, "", "", "强制操作，不进行询问提示。", "operate silently without asking user to confirm the operation."},
 	OptionUpdate: Option{"-u", "--update", "", OptionTypeFlagTrue, "", "", "更新操作", "update"},
 	OptionDelete: Option{"", "--delete", "", OptionTypeFlagTrue, "", "", "删除操作", "delete"},
@@ -81,6 +81,15 @@ var OptionMap = map[string]Option{
 	OptionRetryTimes: Option{"", "--retry-times", strconv.Itoa(RetryTimes), OptionTypeInt64, strconv.FormatInt(MinRetryTimes, 10), strconv.FormatInt(MaxRetryTimes, 10),
 		fmt.Sprintf("当错误发生时的重试次数，默认值：%d，取值范围：%d-%d", RetryTimes, MinRetryTimes, MaxRetryTimes),
 		fmt.Sprintf("retry times when fail(default: %d), value range is: %d-%d", RetryTimes, MinRetryTimes, MaxRetryTimes)},
+	OptionRetryBaseDelay: Option{"", "--retry-base-delay", strconv.Itoa(RetryBaseDelay), OptionTypeInt64, strconv.FormatInt(MinRetryDelay, 10), strconv.FormatInt(MaxRetryDelay, 10),
+		fmt.Sprintf("第一次重试前的等待时间，单位为毫秒，之后每次重试的等待时间翻倍并加入随机抖动，默认值：%d，取值范围：%d-%d", RetryBaseDelay, MinRetryDelay, MaxRetryDelay),
+		fmt.Sprintf("the delay before the first retry in milliseconds, it's doubled with jitter for each retry since then(default: %d), value range is: %d-%d", RetryBaseDelay, MinRetryDelay, MaxRetryDelay)},
+	OptionRetryMaxDelay: Option{"", "--retry-max-delay", strconv.Itoa(RetryMaxDelay), OptionTypeInt64, strconv.FormatInt(MinRetryDelay, 10), strconv.FormatInt(MaxRetryDelay, 10),
+		fmt.Sprintf("两次重试之间的最大等待时间，单位为毫秒，服务端返回的Retry-After也不超过该值，默认值：%d，取值范围：%d-%d", RetryMaxDelay, MinRetryDelay, MaxRetryDelay),
+		fmt.Sprintf("the max delay between two retries in milliseconds, the Retry-After returned by the server is capped by it too(default: %d), value range is: %d-%d", RetryMaxDelay, MinRetryDelay, MaxRetryDelay)},
+	OptionRetryMaxElapsed: Option{"", "--retry-max-elapsed", strconv.Itoa(RetryMaxElapsed), OptionTypeInt64, strconv.FormatInt(MinRetryMaxElapsed, 10), strconv.FormatInt(MaxRetryMaxElapsed, 10),
+		fmt.Sprintf("一个请求从第一次尝试开始的最长重试时间，单位为秒，超过后不再重试，0表示不限制，默认值：%d，取值范围：%d-%d", RetryMaxElapsed, MinRetryMaxElapsed, MaxRetryMaxElapsed),
+		fmt.Sprintf("the max time in seconds to retry a request since its first attempt, it's not retried any more after that, 0 means no limit(default: %d), value range is: %d-%d", RetryMaxElapsed, MinRetryMaxElapsed, MaxRetryMaxElapsed)},
 	OptionRoutines: Option{"-j", "--jobs", strconv.Itoa(Routines), OptionTypeInt64, strconv.FormatInt(MinRoutines, 10), strconv.FormatInt(MaxRoutines, 10),
 		fmt.Sprintf("多文件操作时的并发任务数，默认值：%d，取值范围：%d-%d", Routines, MinRoutines, MaxRoutines),
 		fmt.Sprintf("amount of concurrency tasks between multi-files(default: %d), value range is: %d-%d", Routines, MinRoutines, MaxRoutines)},
@@ -92,11 +101,59 @@ var OptionMap = map[string]Option{
 		fmt.Sprintf("输入或者输出的object名或文件名的编码方式，目前只支持url encode，即指定该选项时，取值范围为：%s，如果不指定该选项，则表示object名或文件名未经过编码。bucket名不支持url encode。注意，如果指定了该选项，则形如oss://bucket/object的cloud_url，输入形式为：oss://bucket/url_encode(object)，其中oss://bucket/字符串不需要编码。", URLEncodingType),
 		fmt.Sprintf("the encoding type of object name or file name that user inputs or outputs, currently ossutil only supports url encode, which means the value range of the option is: %s, if you do not specify the option, it means the object name or file name that user inputed or outputed was not encoded. bucket name does not support url encode. Note, if the option is specified, the cloud_url like: oss://bucket/object should be inputted as: oss://bucket/url_encode(object), the string: oss://bucket/ should not be url encoded.", URLEncodingType)},
 	OptionInclude: Option{"", "--include", DefaultNonePattern, OptionTypeString, "", "",
-		fmt.Sprintf("包含对象匹配模式，如：*.jpg"),
-		fmt.Sprintf("Include Pattern of key, e.g., *.jpg")},
+		fmt.Sprintf("包含对象匹配模式，如：*.jpg，含有目录的模式匹配相对路径，**匹配任意层目录，如：logs/**/*.gz"),
+		fmt.Sprintf("Include Pattern of key, e.g., *.jpg, the pattern containing dir matches the relative path, ** matches any directories, e.g., logs/**/*.gz")},
 	OptionExclude: Option{"", "--exclude", DefaultNonePattern, OptionTypeString, "", "",
-		fmt.Sprintf("不包含对象匹配模式，如：*.txt"),
-		fmt.Sprintf("Exclude Pattern of key, e.g., *.txt")},
+		fmt.Sprintf("不包含对象匹配模式，如：*.txt，含有目录的模式匹配相对路径，**匹配任意层目录，如：**/tmp/*"),
+		fmt.Sprintf("Exclude Pattern of key, e.g., *.txt, the pattern containing dir matches the relative path, ** matches any directories, e.g., **/tmp/*")},
+	OptionIncludeRegex: Option{"", "--include-regex", "", OptionTypeString, "", "",
+		fmt.Sprintf("包含对象匹配的正则表达式，匹配相对路径，如：^logs/.*\\.gz$"),
+		fmt.Sprintf("Include regular expression of the relative path, e.g., ^logs/.*\\.gz$")},
+	OptionExcludeRegex: Option{"", "--exclude-regex", "", OptionTypeString, "", "",
+		fmt.Sprintf("不包含对象匹配的正则表达式，匹配相对路径，如：\\.tmp$"),
+		fmt.Sprintf("Exclude regular expression of the relative path, e.g., \\.tmp$")},
+	OptionExcludeFrom: Option{"", "--exclude-from", "", OptionTypeString, "", "",
+		fmt.Sprintf("从文件中读取不包含对象的规则，文件格式同.gitignore，支持!取反，如：.ossignore"),
+		fmt.Sprintf("Read the exclude rules from the file in .gitignore syntax, ! negates the rule, e.g., .ossignore")},
+	OptionUploadID: Option{"", "--upload-id", "", OptionTypeString, "", "",
+		"分片上传的uploadID，生成上传分片的签名url",
+		"the upload id of the multipart upload, to generate the signed url of uploading parts"},
+	OptionPartNumber: Option{"", "--part-number", "", OptionTypeString, "", "",
+		fmt.Sprintf("分片号或分片号范围，如：1或1-100，取值范围：1-%d", MaxPartNum),
+		fmt.Sprintf("the part number or the range of part numbers, e.g., 1 or 1-100, value range is: 1-%d", MaxPartNum)},
+	OptionPostPolicy: Option{"", "--post-policy", "", OptionTypeFlagTrue, "", "",
+		"生成浏览器表单上传的POST policy和签名，而不是签名url",
+		"Generate the POST policy and signature of the browser form upload instead of the signed url"},
+	OptionContentLengthRange: Option{"", "--content-length-range", "", OptionTypeString, "", "",
+		"POST policy允许上传的文件大小范围，单位可以为B/K/M/G/T，如：1K-10M",
+		"the range of the file size allowed by the POST policy, the unit can be B/K/M/G/T, e.g., 1K-10M"},
+	OptionHead: Option{"", "--head", "", OptionTypeInt64, "0", "",
+		"只输出object开头的N行，指定--bytes时为N字节",
+		"output only the first N lines of the object, or N bytes if --bytes is specified"},
+	OptionTail: Option{"", "--tail", "", OptionTypeInt64, "0", "",
+		"只输出object末尾的N行，指定--bytes时为N字节，通过从末尾开始的范围下载实现，不下载整个object",
+		"output only the last N lines of the object, or N bytes if --bytes is specified, by the range downloads from the end without downloading the whole object"},
+	OptionBytes: Option{"", "--bytes", "", OptionTypeFlagTrue, "", "",
+		"--head和--tail按字节计数，而不是按行",
+		"--head and --tail count bytes instead of lines"},
+	OptionFollow: Option{"", "--follow", "", OptionTypeFlagTrue, "", "",
+		"输出后持续轮询追加类型object的x-oss-next-append-position，输出新追加的内容，类似tail -f，按Ctrl+C退出",
+		"after output, poll the x-oss-next-append-position of the appendable object and output the appended content, like tail -f, press Ctrl+C to exit"},
+	OptionLines: Option{"", "--lines", strconv.Itoa(DefaultTailLines), OptionTypeInt64, "0", "",
+		fmt.Sprintf("开始跟踪前输出object末尾的N行，指定--bytes时为N字节，默认值：%d", DefaultTailLines),
+		fmt.Sprintf("output the last N lines of the object before following, or N bytes if --bytes is specified(default: %d)", DefaultTailLines)},
+	OptionInterval: Option{"", "--interval", strconv.Itoa(FollowInterval), OptionTypeInt64, strconv.FormatInt(MinFollowInterval, 10), strconv.FormatInt(MaxFollowInterval, 10),
+		fmt.Sprintf("跟踪追加类型object时的轮询间隔，单位为毫秒，没有新内容时逐次翻倍，最大为--max-interval，默认值：%d，取值范围：%d-%d", FollowInterval, MinFollowInterval, MaxFollowInterval),
+		fmt.Sprintf("the interval of polling the appendable objects in milliseconds, it's doubled each time there is no new content, up to --max-interval(default: %d), value range is: %d-%d", FollowInterval, MinFollowInterval, MaxFollowInterval)},
+	OptionMaxInterval: Option{"", "--max-interval", strconv.Itoa(FollowMaxInterval), OptionTypeInt64, strconv.FormatInt(MinFollowInterval, 10), strconv.FormatInt(MaxFollowInterval, 10),
+		fmt.Sprintf("跟踪追加类型object时的最大轮询间隔，单位为毫秒，默认值：%d，取值范围：%d-%d", FollowMaxInterval, MinFollowInterval, MaxFollowInterval),
+		fmt.Sprintf("the max interval of polling the appendable objects in milliseconds(default: %d), value range is: %d-%d", FollowMaxInterval, MinFollowInterval, MaxFollowInterval)},
+	OptionBatchSize: Option{"", "--batch-size", AppendBatchSize, OptionTypeString, "", "",
+		fmt.Sprintf("从标准输入追加上传时，缓存的数据达到该大小即追加一次，支持单位B/K/M/G，默认值：%s", AppendBatchSize),
+		fmt.Sprintf("when appending from stdin, append once the buffered data reaches the size, the unit B/K/M/G is supported(default: %s)", AppendBatchSize)},
+	OptionBatchInterval: Option{"", "--batch-interval", strconv.Itoa(AppendBatchInterval), OptionTypeInt64, strconv.FormatInt(MinAppendBatchInterval, 10), strconv.FormatInt(MaxAppendBatchInterval, 10),
+		fmt.Sprintf("从标准输入追加上传时，缓存的数据最多等待的时间，单位为毫秒，默认值：%d，取值范围：%d-%d", AppendBatchInterval, MinAppendBatchInterval, MaxAppendBatchInterval),
+		fmt.Sprintf("when appending from stdin, the max time the buffered data waits before it's appended, in milliseconds(default: %d), value range is: %d-%d", AppendBatchInterval, MinAppendBatchInterval, MaxAppendBatchInterval)},
 	OptionMeta: Option{"", "--meta", "", OptionTypeString, "", "",
 		fmt.Sprintf("设置object的meta为[header:value#header:value...]，如：Cache-Control:no-cache#Content-Encoding:gzip"),
 		fmt.Sprintf("Set object meta as [header:value#header:value...], e.g., Cache-Control:no-cache#Content-Encoding:gzip")},
@@ -234,14 +291,14 @@ var OptionMap = map[string]Option{
 		"表示du命令字节显示的单位,取值可以为KB, MB, GB, TB",
 		"specifies the unit of byte display for du command, the value can be KB, MB, GB, TB"},
 	OptionMode: Option{"", "--mode", "", OptionTypeString, "", "",
-		"表示鉴权模式，取值可以为AK，StsToken，RamRoleArn，EcsRamRole，缺省值为空",
-		"specifies the authentication mode, the value can be AK，StsToken，RamRoleArn，EcsRamRole, default value is empty."},
+		"表示鉴权模式，取值可以为AK，StsToken，RamRoleArn，EcsRamRole，CredentialProcess，OIDCRoleArn，缺省值为空，表示依次尝试AK、credentialProcess、OIDCRoleArn以及配置文件中的AkService",
+		"specifies the authentication mode, the value can be AK，StsToken，RamRoleArn，EcsRamRole，CredentialProcess，OIDCRoleArn, default value is empty, which means AK, credentialProcess, OIDCRoleArn and AkService in config file are tried in order."},
 	OptionECSRoleName: Option{"", "--ecs-role-name", "", OptionTypeString, "", "",
 		"表示角色名，主要用于EcsRamRole模式",
 		"specifies the authentication mode, primarily used in EcsRamRole mode."},
 	OptionTokenTimeout: Option{"", "--token-timeout", "", OptionTypeInt64, "", "",
-		"表示token的有效时间，单位为秒, 缺省值为3600，主要用于RamRoleArn模式下的AssumeRole参数",
-		"specifies the valid time of a token, the unit is: s, default value is 3600, primarily used for AssumeRole parameters in RamRoleArn mode"},
+		"表示token的有效时间，单位为秒, 缺省值为3600，主要用于RamRoleArn模式下的AssumeRole参数，token过期前ossutil会自动重新获取",
+		"specifies the valid time of a token, the unit is: s, default value is 3600, primarily used for AssumeRole parameters in RamRoleArn mode, ossutil gets a new token before it expires"},
 	OptionRamRoleArn: Option{"", "--ram-role-arn", "", OptionTypeString, "", "",
 		"表示RAM角色的ARN，主要用于RamRoleArn模式",
 		"specifies the ARN of ram role, primarily used in RamRoleArn mode."},
@@ -257,6 +314,18 @@ var OptionMap = map[string]Option{
 	OptionSTSRegion: Option{"", "--sts-region", "", OptionTypeString, "", "",
 		"指定sts endpoint的地区，比如cn-shenzhen，其中，cn指代的是国家，shenzhen指代的是地区，用于构造sts endpoint，该选项缺省时，sts endpoint为sts.aliyuncs.com，主要用于RamRoleArn模式",
 		"specifies the region of sts endpoint, such as cn-shenzhen, in this case, cn refers to the country and shenzhen refers to the region, to construct sts endpoint, when this option defaults, the sts endpoint is sts.aliyuncs.com, primarily used in RamRoleArn mode."},
+	OptionCredentialProcess: Option{"", "--credential-process", "", OptionTypeString, "", "",
+		"获取访问凭证的外部命令，命令需要向标准输出打印json格式的凭证，包含AccessKeyId、AccessKeySecret、SecurityToken和Expiration，主要用于CredentialProcess模式",
+		"specifies the external command to get credentials, the command prints the credentials in json format to stdout, including AccessKeyId, AccessKeySecret, SecurityToken and Expiration, primarily used in CredentialProcess mode."},
+	OptionOIDCProviderArn: Option{"", "--oidc-provider-arn", "", OptionTypeString, "", "",
+		"表示OIDC身份提供商的ARN，与--ram-role-arn和--oidc-token-file一起用于OIDCRoleArn模式，比如kubernetes的RRSA",
+		"specifies the ARN of OIDC identity provider, used in OIDCRoleArn mode together with --ram-role-arn and --oidc-token-file, such as RRSA of kubernetes."},
+	OptionOIDCTokenFile: Option{"", "--oidc-token-file", "", OptionTypeString, "", "",
+		"表示OIDC token文件的路径，每次刷新凭证时重新读取，主要用于OIDCRoleArn模式",
+		"specifies the path of OIDC token file, which is read again on every refresh of credentials, primarily used in OIDCRoleArn mode."},
+	OptionCredentialsCache: Option{"", "--credentials-cache", "", OptionTypeString, "", "",
+		fmt.Sprintf("缓存临时访问凭证的文件，多次运行ossutil时在凭证过期前不再重新获取，缺省不缓存，取值为%s时不缓存，用于RamRoleArn、CredentialProcess以及OIDCRoleArn模式", CredentialsCacheOff),
+		fmt.Sprintf("specifies the file to cache temporary credentials, so that ossutil run again does not get new credentials before they expire, no cache by default, %s means no cache, used in RamRoleArn, CredentialProcess and OIDCRoleArn mode.", CredentialsCacheOff)},
 	OptionSkipVerifyCert: Option{"", "--skip-verify-cert", "", OptionTypeFlagTrue, "", "",
 		"表示不校验服务端的数字证书",
 		"specifies that the oss server's digital certificate file will not be verified"},
@@ -287,6 +356,79 @@ var OptionMap = map[string]Option{
 	OptionRuntime: Option{"", "--runtime", "", OptionTypeInt64, "", "",
 		"设置命令的持续的运行时间",
 		"specifies the max running time of the command."},
+	OptionDryRun: Option{"", "--dryrun", "", OptionTypeFlagTrue, "", "",
+		"只列举并输出将要执行的操作(上传、下载、拷贝、跳过、删除等)及原因，不实际修改任何数据",
+		"only list and print the actions to be done(upload, download, copy, skip, delete, etc.) with reasons, without modifying any data"},
+	OptionCompare: Option{"", "--compare", "", OptionTypeAlternative, fmt.Sprintf("%s/%s/%s/%s/%s", CompareSize, CompareMtime, CompareSizeMtime, CompareCRC64, CompareMD5), "",
+		fmt.Sprintf("增量上传/下载/拷贝时判断源和目标是否相同的方式，取值范围：%s/%s/%s/%s/%s，指定该选项时，相同的文件会被跳过，不同的文件会被覆盖", CompareSize, CompareMtime, CompareSizeMtime, CompareCRC64, CompareMD5),
+		fmt.Sprintf("the way to decide whether the source and destination are the same in incremental upload/download/copy, value range is: %s/%s/%s/%s/%s, when specified, the same files are skipped and the different files are overwritten", CompareSize, CompareMtime, CompareSizeMtime, CompareCRC64, CompareMD5)},
+	OptionProfile: Option{"", "--profile", "", OptionTypeString, "", "",
+		fmt.Sprintf("使用配置文件中[profile name]节的配置，未配置的项从[Credentials]节继承。未指定该选项时使用环境变量%s，%s表示[Credentials]节。", EnvOssutilProfile, DefaultProfile),
+		fmt.Sprintf("Use the configuration of [profile name] section in config file, the items not configured are inherited from [Credentials] section. If the option is not specified, the environment variable %s is used, %s means [Credentials] section.", EnvOssutilProfile, DefaultProfile)},
+	OptionListProfiles: Option{"", "--list-profiles", "", OptionTypeFlagTrue, "", "", "列出配置文件中的所有profile。", "List all the profiles in config file."},
+	OptionOutputFormat: Option{"", "--output-format", "", OptionTypeAlternative, fmt.Sprintf("%s/%s/%s", OutputFormatJSON, OutputFormatJSONL, OutputFormatCSV), "",
+		fmt.Sprintf("以机器可读的格式输出结果，取值范围：%s/%s/%s，每个object、版本或者Multipart Upload事件输出一条记录，最后输出一条汇总记录，不指定时输出便于阅读的文本", OutputFormatJSON, OutputFormatJSONL, OutputFormatCSV),
+		fmt.Sprintf("output the result in machine readable format, value range is: %s/%s/%s, one record per object, version or multipart upload, and a summary record at last, human readable text is output if not specified", OutputFormatJSON, OutputFormatJSONL, OutputFormatCSV)},
+	OptionManifest: Option{"", "--manifest", "", OptionTypeString, "", "",
+		"从清单中读取待处理的objects，而不是列举bucket，取值为本地文件路径或者oss://bucket/object，只处理url前缀下的objects",
+		"read the objects to operate from the manifest instead of listing the bucket, the value is a local file path or oss://bucket/object, only the objects under the prefix of the url are operated"},
+	OptionManifestFormat: Option{"", "--manifest-format", "", OptionTypeAlternative, fmt.Sprintf("%s/%s/%s/%s", ManifestFormatPlain, ManifestFormatCSV, ManifestFormatJSONL, ManifestFormatInventory), "",
+		fmt.Sprintf("清单的格式，取值范围：%s/%s/%s/%s，%s每行一个object，%s和%s可以为每个object指定versionId、destKey和meta，%s为bucket清单报告的manifest.json。不指定时根据扩展名判断，.csv为%s，.jsonl为%s，.json为%s，其他为%s", ManifestFormatPlain, ManifestFormatCSV, ManifestFormatJSONL, ManifestFormatInventory, ManifestFormatPlain, ManifestFormatCSV, ManifestFormatJSONL, ManifestFormatInventory, ManifestFormatCSV, ManifestFormatJSONL, ManifestFormatInventory, ManifestFormatPlain),
+		fmt.Sprintf("the format of the manifest, value range is: %s/%s/%s/%s, %s is one object per line, %s and %s can specify versionId, destKey and meta of each object, %s is the manifest.json of the bucket inventory report. It's decided by the extension if not specified, .csv is %s, .jsonl is %s, .json is %s, and the others are %s", ManifestFormatPlain, ManifestFormatCSV, ManifestFormatJSONL, ManifestFormatInventory, ManifestFormatPlain, ManifestFormatCSV, ManifestFormatJSONL, ManifestFormatInventory, ManifestFormatCSV, ManifestFormatJSONL, ManifestFormatInventory, ManifestFormatPlain)},
+	OptionMinSize: Option{"", "--min-size", "", OptionTypeString, "", "",
+		"只操作大小不小于该值的objects或文件，单位可以为B/K/M/G/T，不带单位时为字节，例如：10M",
+		"only operate the objects or files whose size is not less than the value, the unit can be B/K/M/G/T, bytes if no unit, eg: 10M"},
+	OptionMaxSize: Option{"", "--max-size", "", OptionTypeString, "", "",
+		"只操作大小不大于该值的objects或文件，格式同--min-size",
+		"only operate the objects or files whose size is not larger than the value, the format is the same as --min-size"},
+	OptionOlderThan: Option{"", "--older-than", "", OptionTypeString, "", "",
+		"只操作最后修改时间早于该时间的objects或文件，取值为时长，单位可以为s/m/h/d/w，例如：30d表示30天以前，或者为时间，例如：2006-01-02、2006-01-02T15:04:05+08:00",
+		"only operate the objects or files last modified before the time, the value is a duration with unit s/m/h/d/w, eg: 30d means 30 days ago, or a time, eg: 2006-01-02, 2006-01-02T15:04:05+08:00"},
+	OptionNewerThan: Option{"", "--newer-than", "", OptionTypeString, "", "",
+		"只操作最后修改时间晚于该时间的objects或文件，格式同--older-than",
+		"only operate the objects or files last modified after the time, the format is the same as --older-than"},
+	OptionHasTag: Option{"", "--has-tag", "", OptionTypeString, "", "",
+		"只操作带有指定标签的objects，格式为k1=v1,k2=v2，只指定k表示带有该标签即可，需要为每个object读取标签",
+		"only operate the objects with the tags, the format is k1=v1,k2=v2, only k means the object has the tag of any value, the tags of each object need to be read"},
+	OptionContentType: Option{"", "--content-type", "", OptionTypeString, "", "",
+		"只操作指定Content-Type的objects，多个以逗号分隔，支持image/*的格式，需要为每个object读取meta",
+		"only operate the objects of the content types, separated by comma, image/* is supported, the meta of each object need to be read"},
+	OptionBwLimit: Option{"", "--bwlimit", "", OptionTypeString, "", "",
+		"按时间段限制上传和下载速度，格式为\"HH:MM,RATE HH:MM,RATE ...\"，例如\"08:00,10M 18:00,off\"，每个速度从指定时间起生效直到下一个时间。RATE的单位可以为K/M/G(每秒字节数)，不带单位时为KB/s，off表示不限速，UP:DOWN格式分别限制上传和下载速度。只指定RATE时全天生效",
+		"limit upload and download speed by time of day, the format is \"HH:MM,RATE HH:MM,RATE ...\", eg: \"08:00,10M 18:00,off\", each rate takes effect from its time until the next time. The unit of RATE can be K/M/G(bytes per second), KB/s if no unit, off means unlimited, UP:DOWN limits upload and download separately. Only RATE means the limit for the whole day"},
+	OptionBwLimitFile: Option{"", "--bwlimit-file", "", OptionTypeString, "", "",
+		"从文件读取--bwlimit格式的限速时间表，ossutil收到SIGUSR1信号时重新读取该文件，用于在运行中调整限速(windows不支持)",
+		"read the bandwidth schedule in --bwlimit format from the file, ossutil reads the file again when receiving SIGUSR1, so that the limit can be adjusted while running(not supported on windows)"},
+	OptionResumeJob: Option{"", "--resume-job", "", OptionTypeString, "", "",
+		"批量操作的任务ID，任务的进度记录在任务日志中，中断后使用相同的ID和命令运行可以从中断处继续，不再重新扫描已完成的部分",
+		"specifies the id of the batch job, the progress of the job is recorded in its journal, if the job stops, run the same command with the same id to continue where it stopped without scanning the finished part again."},
+	OptionJobsDir: Option{"", "--jobs-dir", "", OptionTypeString, "", "",
+		fmt.Sprintf("保存任务日志的目录，缺省值为%s", DefaultJobsDir),
+		fmt.Sprintf("specifies the directory to save the job journals, default value is %s", DefaultJobsDir)},
+	OptionEncryptionPubKey: Option{"", "--encryption-rsa-public-key", "", OptionTypeString, "", "",
+		"客户端加密的RSA公钥文件(PEM格式)，上传时使用该公钥加密数据密钥",
+		"the RSA public key file(PEM format) of client side encryption, the data key is encrypted by it when uploading"},
+	OptionEncryptionPriKey: Option{"", "--encryption-rsa-private-key", "", OptionTypeString, "", "",
+		"客户端加密的RSA私钥文件(PEM格式)，下载时使用该私钥解密数据密钥，未指定公钥时上传也使用该密钥",
+		"the RSA private key file(PEM format) of client side encryption, the data key is decrypted by it when downloading, it's also used for uploading if the public key is not specified"},
+	OptionEncryptionAesKey: Option{"", "--encryption-aes-key", "", OptionTypeString, "", "",
+		"客户端加密的AES主密钥文件，内容为base64编码的16、24或32字节密钥，不能与RSA密钥同时使用",
+		"the AES master key file of client side encryption, the content is the base64 encoded key of 16, 24 or 32 bytes, can't be used with the RSA keys"},
+	OptionEncryptionMatDesc: Option{"", "--encryption-matdesc", "", OptionTypeString, "", "",
+		"客户端加密的主密钥描述信息，json格式，保存在object的meta中",
+		"the description of the master key of client side encryption in json format, it's saved in the meta of the object"},
+	OptionCompress: Option{"", "--compress", "", OptionTypeAlternative, fmt.Sprintf("%s/%s", CompressGzip, CompressZstd), "",
+		fmt.Sprintf("上传时压缩文件内容，取值范围：%s/%s，object的Content-Encoding设置为压缩算法，原始内容的大小和crc64保存在object的meta中", CompressGzip, CompressZstd),
+		fmt.Sprintf("compress the content of files when uploading, value range is: %s/%s, the Content-Encoding of the object is set to the algorithm, the size and crc64 of the original content are saved in the meta of the object", CompressGzip, CompressZstd)},
+	OptionCompressInclude: Option{"", "--compress-include", "", OptionTypeStrings, "", "",
+		"只压缩文件名匹配该模式的文件，例如\"*.log\"，可以指定多次，未指定时压缩所有文件",
+		"only compress the files whose name matches the pattern, eg: \"*.log\", it can be specified multiple times, all files are compressed if not specified"},
+	OptionDecompress: Option{"", "--decompress", "", OptionTypeFlagTrue, "", "",
+		fmt.Sprintf("下载时解压Content-Encoding为%s或%s的object，并校验原始内容的大小和crc64", CompressGzip, CompressZstd),
+		fmt.Sprintf("decompress the objects whose Content-Encoding is %s or %s when downloading, and validate the size and crc64 of the original content", CompressGzip, CompressZstd)},
+	OptionAutoTune: Option{"", "--auto-tune", "", OptionTypeFlagTrue, "", "",
+		"根据观测到的吞吐量、延迟和限流错误自动调整文件级和分片级的并发数，--jobs和--parallel作为初始值",
+		"adjust the concurrency of files and parts automatically by the observed throughput, latency and throttling errors, --jobs and --parallel are the initial values"},
 }
 
 func (T *Option) getHelp(language string) string {
@@ -0,0 +1,181 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the record types of machine readable output
+const (
+	recordBucket       = "bucket"
+	recordObject       = "object"
+	recordVersion      = "version"
+	recordDirectory    = "directory"
+	recordUpload       = "upload"
+	recordStorageClass = "storage_class"
+	recordSummary      = "summary"
+)
+
+// OutputRecord is one record of machine readable output, keyed by column name
+type OutputRecord map[string]interface{}
+
+// OutputWriter writes records in json, jsonl or csv format, it's safe for concurrent use.
+//
+// json writes all the records as one array, jsonl writes one json object per line,
+// csv writes a header line of the columns first. The fields of a record are written
+// in the order of the columns, the fields not in the columns are ignored.
+type OutputWriter struct {
+	format  string
+	columns []string
+	out     io.Writer
+	csv     *csv.Writer
+	count   int64
+	closed  bool
+	mutex   sync.Mutex
+}
+
+// NewOutputWriter returns nil if format is empty, which means the human readable output
+func NewOutputWriter(format string, columns []string) *OutputWriter {
+	return newOutputWriterTo(os.Stdout, format, columns)
+}
+
+func newOutputWriterTo(out io.Writer, format string, columns []string) *OutputWriter {
+	if format == "" {
+		return nil
+	}
+	ow := &OutputWriter{format: strings.ToLower(format), columns: columns, out: out}
+	if ow.format == OutputFormatCSV {
+		ow.csv = csv.NewWriter(out)
+	}
+	return ow
+}
+
+func getOutputFormat(options OptionMapType) string {
+	format, _ := GetString(OptionOutputFormat, options)
+	return strings.ToLower(format)
+}
+
+// Write writes one record
+func (ow *OutputWriter) Write(record OutputRecord) error {
+	if ow == nil {
+		return nil
+	}
+
+	ow.mutex.Lock()
+	defer ow.mutex.Unlock()
+
+	var err error
+	switch ow.format {
+	case OutputFormatCSV:
+		if ow.count == 0 {
+			ow.csv.Write(ow.columns)
+		}
+		ow.csv.Write(ow.csvFields(record))
+		ow.csv.Flush()
+		err = ow.csv.Error()
+	case OutputFormatJSONL:
+		_, err = fmt.Fprintf(ow.out, "%s\n", ow.jsonObject(record))
+	default:
+		sep := ",\n"
+		if ow.count == 0 {
+			sep = "[\n"
+		}
+		_, err = fmt.Fprintf(ow.out, "%s%s", sep, ow.jsonObject(record))
+	}
+	ow.count++
+	return err
+}
+
+// Close ends the output, it must be called once after all the records are written
+func (ow *OutputWriter) Close() error {
+	if ow == nil {
+		return nil
+	}
+
+	ow.mutex.Lock()
+	defer ow.mutex.Unlock()
+
+	if ow.closed {
+		return nil
+	}
+	ow.closed = true
+
+	switch ow.format {
+	case OutputFormatCSV:
+		if ow.count == 0 {
+			ow.csv.Write(ow.columns)
+		}
+		ow.csv.Flush()
+		return ow.csv.Error()
+	case OutputFormatJSONL:
+		return nil
+	default:
+		if ow.count == 0 {
+			_, err := fmt.Fprintf(ow.out, "[]\n")
+			return err
+		}
+		_, err := fmt.Fprintf(ow.out, "\n]\n")
+		return err
+	}
+}
+
+func (ow *OutputWriter) jsonObject(record OutputRecord) string {
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	first := true
+	for _, column := range ow.columns {
+		value, ok := record[column]
+		if !ok {
+			continue
+		}
+		if !first {
+			buffer.WriteString(",")
+		}
+		first = false
+		name, _ := json.Marshal(column)
+		data, err := json.Marshal(formatOutputValue(value))
+		if err != nil {
+			data, _ = json.Marshal(fmt.Sprint(value))
+		}
+		buffer.Write(name)
+		buffer.WriteString(":")
+		buffer.Write(data)
+	}
+	buffer.WriteString("}")
+	return buffer.String()
+}
+
+func (ow *OutputWriter) csvFields(record OutputRecord) []string {
+	fields := make([]string, len(ow.columns))
+	for i, column := range ow.columns {
+		value, ok := record[column]
+		if !ok || value == nil {
+			continue
+		}
+		switch v := formatOutputValue(value).(type) {
+		case string:
+			fields[i] = v
+		case map[string]string:
+			data, _ := json.Marshal(v)
+			fields[i] = string(data)
+		default:
+			fields[i] = fmt.Sprint(v)
+		}
+	}
+	return fields
+}
+
+// formatOutputValue formats time in RFC3339, other values are kept
+func formatOutputValue(value interface{}) interface{} {
+	if t, ok := value.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339)
+	}
+	return value
+}
@@ -0,0 +1,84 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) TestOutputWriterJSON(c *C) {
+	columns := []string{"type", "key", "size", "last_modified"}
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var buffer bytes.Buffer
+	ow := newOutputWriterTo(&buffer, OutputFormatJSON, columns)
+	c.Assert(ow.Write(OutputRecord{"type": recordObject, "key": "a\"b", "size": int64(10), "last_modified": modified, "ignored": 1}), IsNil)
+	c.Assert(ow.Write(OutputRecord{"type": recordSummary, "size": int64(10)}), IsNil)
+	c.Assert(ow.Close(), IsNil)
+	c.Assert(ow.Close(), IsNil)
+
+	var records []map[string]interface{}
+	c.Assert(json.Unmarshal(buffer.Bytes(), &records), IsNil)
+	c.Assert(len(records), Equals, 2)
+	c.Assert(records[0]["key"], Equals, "a\"b")
+	c.Assert(records[0]["size"], Equals, float64(10))
+	c.Assert(records[0]["last_modified"], Equals, "2020-01-02T03:04:05Z")
+	_, ok := records[0]["ignored"]
+	c.Assert(ok, Equals, false)
+	c.Assert(records[1]["type"], Equals, recordSummary)
+
+	// the fields are written in the order of the columns
+	c.Assert(strings.HasPrefix(buffer.String(), "[\n{\"type\":\"object\",\"key\":"), Equals, true)
+
+	// empty output is still a valid array
+	buffer.Reset()
+	ow = newOutputWriterTo(&buffer, OutputFormatJSON, columns)
+	c.Assert(ow.Close(), IsNil)
+	c.Assert(json.Unmarshal(buffer.Bytes(), &records), IsNil)
+	c.Assert(len(records), Equals, 0)
+}
+
+func (s *OssutilCommandSuite) TestOutputWriterJSONL(c *C) {
+	var buffer bytes.Buffer
+	ow := newOutputWriterTo(&buffer, "JSONL", []string{"type", "key"})
+	c.Assert(ow.Write(OutputRecord{"type": recordObject, "key": "a"}), IsNil)
+	c.Assert(ow.Write(OutputRecord{"type": recordObject, "key": "b"}), IsNil)
+	c.Assert(ow.Close(), IsNil)
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	c.Assert(len(lines), Equals, 2)
+	c.Assert(lines[0], Equals, "{\"type\":\"object\",\"key\":\"a\"}")
+	c.Assert(lines[1], Equals, "{\"type\":\"object\",\"key\":\"b\"}")
+}
+
+func (s *OssutilCommandSuite) TestOutputWriterCSV(c *C) {
+	var buffer bytes.Buffer
+	ow := newOutputWriterTo(&buffer, OutputFormatCSV, []string{"type", "key", "size", "user_meta"})
+	c.Assert(ow.Write(OutputRecord{"type": recordObject, "key": "a,b", "size": int64(3), "user_meta": map[string]string{"k": "v"}}), IsNil)
+	c.Assert(ow.Write(OutputRecord{"type": recordSummary}), IsNil)
+	c.Assert(ow.Close(), IsNil)
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	c.Assert(err, IsNil)
+	c.Assert(len(rows), Equals, 3)
+	c.Assert(rows[0], DeepEquals, []string{"type", "key", "size", "user_meta"})
+	c.Assert(rows[1], DeepEquals, []string{"object", "a,b", "3", "{\"k\":\"v\"}"})
+	c.Assert(rows[2], DeepEquals, []string{"summary", "", "", ""})
+
+	// only the header if there is no record
+	buffer.Reset()
+	ow = newOutputWriterTo(&buffer, OutputFormatCSV, []string{"type", "key"})
+	c.Assert(ow.Close(), IsNil)
+	c.Assert(buffer.String(), Equals, "type,key\n")
+}
+
+func (s *OssutilCommandSuite) TestOutputWriterNil(c *C) {
+	ow := NewOutputWriter("", []string{"type"})
+	c.Assert(ow, IsNil)
+	c.Assert(ow.Write(OutputRecord{"type": recordObject}), IsNil)
+	c.Assert(ow.Close(), IsNil)
+}
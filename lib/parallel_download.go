@@ -0,0 +1,345 @@
+package lib
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	parallelDownloadCpMagic  = "3A7C5E19-8B2D-4F60-A1C4-9D0E6B2F7A58"
+	parallelDownloadCpSuffix = ".pdl.cp"
+
+	// the checkpoint is dumped at most once an interval while the parts finish
+	parallelDownloadDumpInterval = time.Second
+)
+
+// parallelDownloadCheckpoint is the checkpoint of parallel ranged download. The finished parts are recorded
+// in a bitmap and their crc64 are packed in 8 bytes each, so that it keeps small for an object of 10000 parts.
+type parallelDownloadCheckpoint struct {
+	Magic        string
+	FilePath     string
+	ObjectKey    string
+	ObjectSize   int64
+	LastModified string
+	ETag         string
+	Start        int64 // the range of the object downloaded, End is exclusive
+	End          int64
+	PartSize     int64
+	Bitmap       []byte
+	PartCRC64    []byte
+}
+
+func parallelDownloadCpFile(cpDir, filePath, bucketName, objectName string) string {
+	absPath, _ := filepath.Abs(filePath)
+	src := md5.Sum([]byte(CloudURLToString(bucketName, objectName)))
+	dest := md5.Sum([]byte(absPath))
+	return filepath.Join(cpDir, hex.EncodeToString(src[:])+"-"+hex.EncodeToString(dest[:])+parallelDownloadCpSuffix)
+}
+
+func newParallelDownloadCheckpoint(filePath, objectName string, props http.Header, size, start, end, partSize int64) *parallelDownloadCheckpoint {
+	cp := &parallelDownloadCheckpoint{
+		Magic:        parallelDownloadCpMagic,
+		FilePath:     filePath,
+		ObjectKey:    objectName,
+		ObjectSize:   size,
+		LastModified: props.Get(oss.HTTPHeaderLastModified),
+		ETag:         props.Get(oss.HTTPHeaderEtag),
+		Start:        start,
+		End:          end,
+		PartSize:     partSize,
+	}
+	partNum := cp.partNum()
+	cp.Bitmap = make([]byte, (partNum+7)/8)
+	cp.PartCRC64 = make([]byte, partNum*8)
+	return cp
+}
+
+func (cp *parallelDownloadCheckpoint) load(cpFile string) error {
+	data, err := ioutil.ReadFile(cpFile)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, cp)
+}
+
+func (cp *parallelDownloadCheckpoint) dump(cpFile string) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(cpFile, data, 0600)
+}
+
+func (cp *parallelDownloadCheckpoint) dumpLogged(cpFile string) {
+	if err := cp.dump(cpFile); err != nil {
+		LogError("dump checkpoint %s error,%s\n", cpFile, err.Error())
+	}
+}
+
+// isValid returns true if the checkpoint is of the same download and the object is not changed since then
+func (cp *parallelDownloadCheckpoint) isValid(expect *parallelDownloadCheckpoint) bool {
+	partNum := cp.partNum()
+	return cp.Magic == parallelDownloadCpMagic && cp.FilePath == expect.FilePath && cp.ObjectKey == expect.ObjectKey &&
+		cp.ObjectSize == expect.ObjectSize && cp.LastModified == expect.LastModified && cp.ETag == expect.ETag &&
+		cp.Start == expect.Start && cp.End == expect.End && cp.PartSize == expect.PartSize &&
+		len(cp.Bitmap) == (partNum+7)/8 && len(cp.PartCRC64) == partNum*8
+}
+
+func (cp *parallelDownloadCheckpoint) partNum() int {
+	if cp.PartSize <= 0 || cp.End <= cp.Start {
+		return 0
+	}
+	return int((cp.End-cp.Start-1)/cp.PartSize + 1)
+}
+
+// partRange returns the range of the part in the object, the end is exclusive
+func (cp *parallelDownloadCheckpoint) partRange(index int) (int64, int64) {
+	from := cp.Start + int64(index)*cp.PartSize
+	to := from + cp.PartSize
+	if to > cp.End {
+		to = cp.End
+	}
+	return from, to
+}
+
+func (cp *parallelDownloadCheckpoint) isDone(index int) bool {
+	return cp.Bitmap[index/8]&(1<<uint(index%8)) != 0
+}
+
+func (cp *parallelDownloadCheckpoint) setDone(index int, crc uint64) {
+	cp.Bitmap[index/8] |= 1 << uint(index%8)
+	binary.BigEndian.PutUint64(cp.PartCRC64[index*8:], crc)
+}
+
+func (cp *parallelDownloadCheckpoint) completedBytes() int64 {
+	var completed int64
+	for i := 0; i < cp.partNum(); i++ {
+		if cp.isDone(i) {
+			from, to := cp.partRange(i)
+			completed += to - from
+		}
+	}
+	return completed
+}
+
+// combinedCRC64 combines the crc64 of all parts into the crc64 of the downloaded content
+func (cp *parallelDownloadCheckpoint) combinedCRC64() uint64 {
+	var crc uint64
+	for i := 0; i < cp.partNum(); i++ {
+		from, to := cp.partRange(i)
+		crc = oss.CRC64Combine(crc, binary.BigEndian.Uint64(cp.PartCRC64[i*8:]), uint64(to-from))
+	}
+	return crc
+}
+
+// sectionWriter writes to the file from the offset, the parts are written concurrently by WriteAt
+type sectionWriter struct {
+	file   *os.File
+	offset int64
+}
+
+func (w *sectionWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+type parallelDownloadResult struct {
+	index int
+	crc   uint64
+	err   error
+}
+
+// ossParallelDownload downloads the object by ranged GETs in parallel, each part is written to its offset in the
+// preallocated temp file directly. The finished parts are recorded in the checkpoint, so that the download is
+// resumed from them next time, and the crc64 of the whole object is verified by combining the crc64 of the parts.
+func (cc *CopyCommand) ossParallelDownload(bucket *oss.Bucket, objectName, filePath string, options ...oss.Option) error {
+	listener := oss.GetProgressListener(options)
+	options = oss.DeleteOption(options, oss.HTTPHeaderRange)
+
+	props, err := cc.command.ossGetObjectStatRetry(bucket, objectName, oss.ChoiceHeadObjectOption(options)...)
+	if err != nil {
+		return err
+	}
+	size, err := strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64)
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, objectName}
+	}
+
+	start, end := int64(0), size
+	if cc.cpOption.vrange != "" {
+		ur, err := oss.ParseRange("bytes=" + cc.cpOption.vrange)
+		if err != nil {
+			return err
+		}
+		start, end = oss.AdjustRange(ur, size)
+	}
+	if end < start {
+		end = start
+	}
+
+	partSize, routines := cc.preparePartOption(end - start)
+	absPath, _ := filepath.Abs(filePath)
+	cpFile := parallelDownloadCpFile(cc.cpOption.cpDir, filePath, bucket.BucketName, objectName)
+	tempFile := filePath + oss.TempFileSuffix
+
+	cp := newParallelDownloadCheckpoint(absPath, objectName, props, size, start, end, partSize)
+	loaded := &parallelDownloadCheckpoint{}
+	if err := loaded.load(cpFile); err == nil && loaded.isValid(cp) {
+		if f, err := os.Stat(tempFile); err == nil && f.Size() == end-start {
+			cp = loaded
+		}
+	}
+	LogInfo("parallel download,object %s,range %d-%d,partSize %d,routine count:%d,checkpoint file:%s\n",
+		objectName, start, end, partSize, routines, cpFile)
+
+	fd, err := os.OpenFile(tempFile, os.O_WRONLY|os.O_CREATE, oss.FilePermMode)
+	if err != nil {
+		return err
+	}
+	if cp != loaded {
+		os.Remove(cpFile)
+		if err = fd.Truncate(end - start); err != nil {
+			fd.Close()
+			return err
+		}
+	}
+
+	var todo []int
+	for i := 0; i < cp.partNum(); i++ {
+		if !cp.isDone(i) {
+			todo = append(todo, i)
+		}
+	}
+
+	completed := cp.completedBytes()
+	publishDownloadProgress(listener, oss.TransferStartedEvent, completed, end-start, 0)
+
+	jobs := make(chan int, len(todo))
+	for _, index := range todo {
+		jobs <- index
+	}
+	close(jobs)
+
+	results := make(chan parallelDownloadResult)
+	die := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < routines && w < len(todo); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				select {
+				case <-die:
+					return
+				default:
+				}
+				from, to := cp.partRange(index)
+				crc, err := cc.ossDownloadPartRetry(cc.command.bandwidthBucket(bucket), objectName, fd, from, to, from-start, options)
+				results <- parallelDownloadResult{index, crc, err}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// the results are collected until all workers exit, so that the parts finished are recorded even if one fails,
+	// the checkpoint is not rewritten after each part, which is O(n^2) for thousands of parts
+	var downloadErr error
+	dirty, lastDump := false, time.Now()
+	for result := range results {
+		if result.err != nil {
+			if downloadErr == nil {
+				downloadErr = result.err
+				close(die)
+			}
+			continue
+		}
+		cp.setDone(result.index, result.crc)
+		dirty = true
+		if time.Since(lastDump) >= parallelDownloadDumpInterval {
+			cp.dumpLogged(cpFile)
+			dirty, lastDump = false, time.Now()
+		}
+		from, to := cp.partRange(result.index)
+		completed += to - from
+		publishDownloadProgress(listener, oss.TransferDataEvent, completed, end-start, to-from)
+	}
+
+	if dirty {
+		cp.dumpLogged(cpFile)
+	}
+
+	if errClose := fd.Close(); downloadErr == nil {
+		downloadErr = errClose
+	}
+	if downloadErr != nil {
+		publishDownloadProgress(listener, oss.TransferFailedEvent, completed, end-start, 0)
+		return ObjectError{downloadErr, bucket.BucketName, objectName}
+	}
+	publishDownloadProgress(listener, oss.TransferCompletedEvent, completed, end-start, 0)
+
+	// the ranged GETs are not checked by sdk, the whole object is checked here
+	if serverCRC := props.Get(oss.HTTPHeaderOssCRC64); bucket.GetConfig().IsEnableCRC && serverCRC != "" && start == 0 && end == size {
+		if clientCRC := strconv.FormatUint(cp.combinedCRC64(), 10); clientCRC != serverCRC {
+			os.Remove(cpFile)
+			os.Remove(tempFile)
+			return ObjectError{fmt.Errorf("crc64 of the downloaded content is %s, not the same as %s of the object", clientCRC, serverCRC), bucket.BucketName, objectName}
+		}
+	}
+
+	if err = os.Rename(tempFile, filePath); err != nil {
+		return err
+	}
+	os.Remove(cpFile)
+	return nil
+}
+
+func publishDownloadProgress(listener oss.ProgressListener, eventType oss.ProgressEventType, consumed, total, rw int64) {
+	if listener != nil {
+		listener.ProgressChanged(&oss.ProgressEvent{ConsumedBytes: consumed, TotalBytes: total, RwBytes: rw, EventType: eventType})
+	}
+}
+
+// ossDownloadPartRetry downloads the range [from, to) of the object to the offset of the file, and returns its crc64
+func (cc *CopyCommand) ossDownloadPartRetry(bucket *oss.Bucket, objectName string, fd *os.File, from, to, offset int64, options []oss.Option) (uint64, error) {
+	options = append(append([]oss.Option{}, options...), oss.Progress(&discardProgressListener{}), oss.Range(from, to-1))
+	var sum uint64
+	err := cc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		startT := time.Now()
+		crc := crc64.New(crc64.MakeTable(crc64.ECMA))
+		body, err := bucket.GetObject(objectName, withResponseHeader(options, respHeader)...)
+		if err == nil {
+			var n int64
+			n, err = io.Copy(&sectionWriter{fd, offset}, io.TeeReader(body, crc))
+			body.Close()
+			if err == nil && n != to-from {
+				err = fmt.Errorf("the size of part %d-%d is %d, not the same as %d", from, to-1, n, to-from)
+			}
+		}
+		cc.cpOption.tuner.observe(err, time.Since(startT))
+		if err == nil {
+			sum = crc.Sum64()
+			return nil
+		}
+		LogError("try count:%d,download range %d-%d of %s error,%s\n", attempt, from, to-1, objectName, err.Error())
+		return err
+	})
+	return sum, err
+}
@@ -0,0 +1,67 @@
+package lib
+
+import (
+	"crypto/rand"
+	"hash/crc64"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) TestParallelDownloadCheckpoint(c *C) {
+	props := http.Header{}
+	props.Set(oss.HTTPHeaderLastModified, "Mon, 02 Jan 2006 15:04:05 GMT")
+	props.Set(oss.HTTPHeaderEtag, "\"etag\"")
+
+	data := make([]byte, 1000)
+	rand.Read(data)
+	cp := newParallelDownloadCheckpoint("/tmp/file", "object", props, 2000, 100, 1100, 64)
+	c.Assert(cp.partNum(), Equals, 16)
+	c.Assert(len(cp.Bitmap), Equals, 2)
+	c.Assert(len(cp.PartCRC64), Equals, 16*8)
+
+	from, to := cp.partRange(0)
+	c.Assert(from, Equals, int64(100))
+	c.Assert(to, Equals, int64(164))
+	from, to = cp.partRange(15)
+	c.Assert(from, Equals, int64(1060))
+	c.Assert(to, Equals, int64(1100))
+
+	table := crc64.MakeTable(crc64.ECMA)
+	for i := cp.partNum() - 1; i >= 0; i-- {
+		c.Assert(cp.isDone(i), Equals, false)
+		from, to := cp.partRange(i)
+		cp.setDone(i, crc64.Checksum(data[from-100:to-100], table))
+		c.Assert(cp.isDone(i), Equals, true)
+		if i == 10 {
+			c.Assert(cp.completedBytes(), Equals, int64(1000-640))
+		}
+	}
+	c.Assert(cp.completedBytes(), Equals, int64(1000))
+	c.Assert(cp.combinedCRC64(), Equals, crc64.Checksum(data, table))
+
+	cpFile := filepath.Join(os.TempDir(), "ossutil-pdl-"+randLowStr(6)+parallelDownloadCpSuffix)
+	defer os.Remove(cpFile)
+	c.Assert(cp.dump(cpFile), IsNil)
+	loaded := &parallelDownloadCheckpoint{}
+	c.Assert(loaded.load(cpFile), IsNil)
+	c.Assert(loaded.isValid(cp), Equals, true)
+	c.Assert(loaded.combinedCRC64(), Equals, crc64.Checksum(data, table))
+
+	// the object or the range is changed
+	props.Set(oss.HTTPHeaderEtag, "\"changed\"")
+	c.Assert(loaded.isValid(newParallelDownloadCheckpoint("/tmp/file", "object", props, 2000, 100, 1100, 64)), Equals, false)
+	props.Set(oss.HTTPHeaderEtag, "\"etag\"")
+	c.Assert(loaded.isValid(newParallelDownloadCheckpoint("/tmp/file", "object", props, 2000, 0, 1100, 64)), Equals, false)
+	c.Assert(loaded.isValid(newParallelDownloadCheckpoint("/tmp/file", "object", props, 2000, 100, 1100, 128)), Equals, false)
+	c.Assert(loaded.isValid(newParallelDownloadCheckpoint("/tmp/file2", "object", props, 2000, 100, 1100, 64)), Equals, false)
+
+	empty := newParallelDownloadCheckpoint("/tmp/file", "object", props, 0, 0, 0, 64)
+	c.Assert(empty.partNum(), Equals, 0)
+	c.Assert(empty.combinedCRC64(), Equals, uint64(0))
+
+	c.Assert(parallelDownloadCpFile("cp", "a", "bucket", "object"), Not(Equals), parallelDownloadCpFile("cp", "b", "bucket", "object"))
+}
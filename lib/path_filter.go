@@ -0,0 +1,229 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// filterMatcher matches the path relative to the source directory or the object prefix with a filter
+type filterMatcher struct {
+	pattern string
+	regex   *regexp.Regexp // nil for the glob without dir info, which only matches the name
+	rules   []ignoreRule   // the rules of --exclude-from
+}
+
+// ignoreRule is a line of the file in .gitignore syntax
+type ignoreRule struct {
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// the filters are parsed from the command line once, the matchers are compiled for them and shared
+var filterMatchers sync.Map
+
+var filterPrompts = []string{IncludeRegexPrompt, ExcludeRegexPrompt, ExcludeFromPrompt, IncludePrompt, ExcludePrompt}
+
+func isIncludeFilter(name string) bool {
+	return name == IncludePrompt || name == IncludeRegexPrompt
+}
+
+func getFilterMatcher(filter filterOptionType) (*filterMatcher, error) {
+	if m, ok := filterMatchers.Load(filter); ok {
+		return m.(*filterMatcher), nil
+	}
+
+	m := &filterMatcher{pattern: filter.pattern}
+	var err error
+	switch filter.name {
+	case IncludeRegexPrompt, ExcludeRegexPrompt:
+		m.regex, err = regexp.Compile(filter.pattern)
+	case ExcludeFromPrompt:
+		m.rules, err = loadIgnoreRules(filter.pattern)
+	default:
+		if strings.Contains(filter.pattern, "/") || strings.Contains(filter.pattern, "**") {
+			pattern := strings.TrimPrefix(filter.pattern, "/")
+			if strings.HasSuffix(pattern, "/") {
+				// the directory matches all the files in it
+				pattern += "**"
+			}
+			m.regex, err = globRegexp(pattern, true)
+		} else {
+			_, err = filepath.Match(filter.pattern, "")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid option value of %s: %s, %s", filter.name, filter.pattern, err.Error())
+	}
+
+	filterMatchers.Store(filter, m)
+	return m, nil
+}
+
+// matchFilterPattern checks if the relative path matches the pattern of the filter, no matter it's
+// including or excluding
+func matchFilterPattern(v string, filter filterOptionType) bool {
+	m, err := getFilterMatcher(filter)
+	if err != nil {
+		return false
+	}
+
+	if m.rules != nil {
+		return matchIgnoreRules(m.rules, filepath.ToSlash(v))
+	}
+	if m.regex != nil {
+		return m.regex.MatchString(strings.TrimPrefix(filepath.ToSlash(v), "/"))
+	}
+	return filterSingleStr(v, m.pattern, true)
+}
+
+// filterRelativeKey returns the key relative to the directory of the prefix, which the patterns with dir info
+// are matched against, the name of the key is kept
+func filterRelativeKey(prefix, key string) string {
+	index := strings.LastIndex(prefix, "/")
+	if index >= 0 && strings.HasPrefix(key, prefix[:index+1]) {
+		return key[index+1:]
+	}
+	return key
+}
+
+// globRegexp converts the glob to the regular expression, * and ? don't match /, ** matches any directories,
+// the expression is anchored to the whole path if anchored, otherwise it matches the path in any directory
+func globRegexp(glob string, anchored bool) (*regexp.Regexp, error) {
+	var buf strings.Builder
+	buf.WriteString("^")
+	if !anchored {
+		buf.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(glob); i++ {
+		ch := glob[i]
+		switch ch {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if (i == 1 || glob[i-2] == '/') && i+1 < len(glob) && glob[i+1] == '/' {
+					// **/ matches zero or more directories
+					i++
+					buf.WriteString("(?:.*/)?")
+				} else {
+					buf.WriteString(".*")
+				}
+			} else {
+				buf.WriteString("[^/]*")
+			}
+		case '?':
+			buf.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == 0 && i+2 < len(glob) {
+				// ] as the first character of the class
+				end = strings.IndexByte(glob[i+2:], ']') + 1
+			}
+			if end <= 0 {
+				return nil, fmt.Errorf("the character class is not closed")
+			}
+			class := glob[i+1 : i+1+end]
+			if class[0] == '!' || class[0] == '^' {
+				class = "^" + class[1:]
+			}
+			buf.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			buf.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
+
+// loadIgnoreRules reads the file in .gitignore syntax, like .ossignore
+func loadIgnoreRules(fileName string) ([]ignoreRule, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rules := make([]ignoreRule, 0)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		rule, ok, err := parseIgnoreRule(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d, %s", line, err.Error())
+		}
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// parseIgnoreRule parses a line of .gitignore, ok is false for the blank line and comment
+func parseIgnoreRule(line string) (rule ignoreRule, ok bool, err error) {
+	line = strings.TrimSuffix(line, "\r")
+	// the trailing spaces are ignored unless they are escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false, nil
+	}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false, nil
+	}
+
+	// the pattern with a slash at the beginning or middle is relative to the root, otherwise it matches
+	// the name at any level
+	anchored := strings.Contains(line, "/")
+	rule.regex, err = globRegexp(strings.TrimPrefix(line, "/"), anchored)
+	return rule, err == nil, err
+}
+
+// matchIgnoreRules checks if the path is ignored, the last matching rule decides, and the path in an ignored
+// directory can't be included again, the path ended with / is a directory
+func matchIgnoreRules(rules []ignoreRule, path string) bool {
+	path = strings.TrimPrefix(path, "/")
+	isDir := strings.HasSuffix(path, "/")
+	path = strings.TrimSuffix(path, "/")
+
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		if matchIgnoreRulesSingle(rules, strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return matchIgnoreRulesSingle(rules, path, isDir)
+}
+
+func matchIgnoreRulesSingle(rules []ignoreRule, path string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.regex.MatchString(path) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
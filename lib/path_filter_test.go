@@ -0,0 +1,153 @@
+package lib
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) TestPathFilterGlob(c *C) {
+	cases := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"*.txt", "dir/a.txt", true},
+		{"logs/*.gz", "logs/a.gz", true},
+		{"logs/*.gz", "logs/2020/a.gz", false},
+		{"logs/*.gz", "old/logs/a.gz", false},
+		{"/logs/*.gz", "logs/a.gz", true},
+		{"logs/**/*.gz", "logs/a.gz", true},
+		{"logs/**/*.gz", "logs/2020/01/a.gz", true},
+		{"**/tmp/*", "tmp/a", true},
+		{"**/tmp/*", "a/b/tmp/c", true},
+		{"**/tmp/*", "a/b/tmp/c/d", false},
+		{"**.bak", "a/b/c.bak", true},
+		{"tmp/", "tmp/a/b.txt", true},
+		{"tmp/", "a/tmp/b.txt", false},
+		{"dir/file?.[!0-3]*", "dir/file1.4x", true},
+		{"dir/file?.[^0-3]*", "dir/file1.3x", false},
+		{`dir/\*.txt`, "dir/*.txt", true},
+		{`dir/\*.txt`, "dir/a.txt", false},
+	}
+	for _, t := range cases {
+		c.Assert(matchFilterPattern(t.path, filterOptionType{IncludePrompt, t.pattern}), Equals, t.match, Commentf("%s %s", t.pattern, t.path))
+	}
+}
+
+func (s *OssutilCommandSuite) TestPathFilterRegex(c *C) {
+	filters, err := getFilter([]string{"ossutil", "ls", "oss://bucket/", "--exclude-regex", `\.(tmp|bak)$`, "--include-regex=^keep/"})
+	c.Assert(err, IsNil)
+	c.Assert(len(filters), Equals, 2)
+
+	c.Assert(matchFiltersForStr("a/b.txt", filters), Equals, true)
+	c.Assert(matchFiltersForStr("a/b.tmp", filters), Equals, false)
+	c.Assert(matchFiltersForStr("keep/b.tmp", filters), Equals, true)
+
+	// mixed with the glob, from left to right
+	filters, err = getFilter([]string{"ossutil", "cp", ".", "oss://bucket/", "-r", "--include-regex", "^data/", "--exclude", "*.log"})
+	c.Assert(err, IsNil)
+	c.Assert(matchFiltersForStr("data/a.txt", filters), Equals, true)
+	c.Assert(matchFiltersForStr("data/a.log", filters), Equals, false)
+	c.Assert(matchFiltersForStr("other/a.txt", filters), Equals, false)
+}
+
+func (s *OssutilCommandSuite) TestPathFilterExcludeFrom(c *C) {
+	content := "# comment\n" +
+		"\n" +
+		"*.log\n" +
+		"!important.log\n" +
+		"build/\n" +
+		"!build/keep.txt\n" +
+		"/vendor\n" +
+		"docs/*\n" +
+		"!docs/README.md\n" +
+		"cache/**\n" +
+		"!cache/keep.txt\n" +
+		`\#notes` + "\n" +
+		"trailing.txt   \n"
+	fileName := "ossutil-ossignore-" + randLowStr(6)
+	c.Assert(ioutil.WriteFile(fileName, []byte(content), 0600), IsNil)
+	defer os.Remove(fileName)
+
+	filters, err := getFilter([]string{"ossutil", "cp", ".", "oss://bucket/", "-r", "--exclude-from", fileName})
+	c.Assert(err, IsNil)
+
+	cases := map[string]bool{
+		"a.txt":              true,
+		"a.log":              false,
+		"sub/dir/a.log":      false,
+		"sub/important.log":  true,
+		"build/out.bin":      false,
+		"src/build/out.bin":  false,
+		"build":              true, // build/ only matches directories
+		"vendor/lib.go":      false,
+		"src/vendor/lib.go":  true,
+		"docs/guide.md":      false,
+		"docs/README.md":     true,
+		"cache/a.txt":        false,
+		"cache/keep.txt":     true,
+		"build/keep.txt":     false, // the parent directory is excluded
+		"#notes":             false,
+		"trailing.txt":       false,
+		"sub/trailing.txt":   false,
+		"sub/README.md":      true,
+		"sub/docs/README.md": true,
+	}
+	for path, match := range cases {
+		c.Assert(matchFiltersForStr(path, filters), Equals, match, Commentf("%s", path))
+	}
+
+	// include again after the file
+	filters, err = getFilter([]string{"ossutil", "cp", ".", "oss://bucket/", "-r", "--exclude-from=" + fileName, "--include", "a.log"})
+	c.Assert(err, IsNil)
+	c.Assert(matchFiltersForStr("sub/a.log", filters), Equals, true)
+	c.Assert(matchFiltersForStr("sub/b.log", filters), Equals, false)
+
+	invalidFileName := "ossutil-ossignore-" + randLowStr(6)
+	c.Assert(ioutil.WriteFile(invalidFileName, []byte("logs/[a-\n"), 0600), IsNil)
+	defer os.Remove(invalidFileName)
+	_, err = getFilter([]string{"ossutil", "cp", ".", "oss://bucket/", "-r", "--exclude-from", invalidFileName})
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestPathFilterRelativeKey(c *C) {
+	c.Assert(filterRelativeKey("", "dir/a.txt"), Equals, "dir/a.txt")
+	c.Assert(filterRelativeKey("dir/", "dir/sub/a.txt"), Equals, "sub/a.txt")
+	c.Assert(filterRelativeKey("dir/su", "dir/sub/a.txt"), Equals, "sub/a.txt")
+	c.Assert(filterRelativeKey("dir/sub/a.txt", "dir/sub/a.txt"), Equals, "a.txt")
+	c.Assert(filterRelativeKey("other/", "dir/a.txt"), Equals, "dir/a.txt")
+
+	filters, err := getFilter([]string{"ossutil", "ls", "oss://bucket/data/", "--include", "2020/**", "--exclude", "*.tmp"})
+	c.Assert(err, IsNil)
+	c.Assert(doesSingleObjectMatchPatterns(filterRelativeKey("data/", "data/2020/01/a.txt"), filters), Equals, true)
+	c.Assert(doesSingleObjectMatchPatterns(filterRelativeKey("data/", "data/2020/01/a.tmp"), filters), Equals, false)
+	c.Assert(doesSingleObjectMatchPatterns(filterRelativeKey("data/", "data/2021/a.txt"), filters), Equals, false)
+}
+
+func (s *OssutilCommandSuite) TestPathFilterFileList(c *C) {
+	dir := "ossutil-path-filter-" + randLowStr(6)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a.txt", "logs/a.log", "logs/2020/b.log", "tmp/c.txt", "src/tmp/d.txt"} {
+		fileName := filepath.Join(dir, filepath.FromSlash(name))
+		c.Assert(os.MkdirAll(filepath.Dir(fileName), 0755), IsNil)
+		c.Assert(ioutil.WriteFile(fileName, []byte(name), 0600), IsNil)
+	}
+
+	filters, err := getFilter([]string{"ossutil", "cp", dir, "oss://bucket/", "-r", "--exclude", "/tmp/", "--exclude-regex", `^logs/\d+/`})
+	c.Assert(err, IsNil)
+
+	chFiles := make(chan fileInfoType, 100)
+	c.Assert(getFileListCommon(dir, chFiles, false, false, false, filters, nil), IsNil)
+	var files []string
+	for f := range chFiles {
+		if f.filePath[len(f.filePath)-1] != os.PathSeparator {
+			files = append(files, filepath.ToSlash(f.filePath))
+		}
+	}
+	sort.Strings(files)
+	c.Assert(files, DeepEquals, []string{"a.txt", "logs/a.log", "src/tmp/d.txt"})
+}
@@ -0,0 +1,165 @@
+package lib
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+const (
+	postPolicyAlgorithmV4 = "OSS4-HMAC-SHA256"
+	postPolicyRequestV4   = "aliyun_v4_request"
+)
+
+// postPolicyOption is what the browser form POST policy is built from
+type postPolicyOption struct {
+	authVersion     oss.AuthVersionType
+	region          string
+	product         string
+	accessKeyID     string
+	accessKeySecret string
+	securityToken   string
+	prefix          string            // the key must start with it
+	minSize         int64             // -1 if the content length is not limited
+	maxSize         int64             // -1 if the content length is not limited
+	headers         map[string]string // the form fields must be exactly the values
+	expires         int64             // seconds
+}
+
+// postPolicyForm is the url to post to and the form fields to post with the file
+type postPolicyForm struct {
+	URL        string            `json:"url"`
+	Expiration string            `json:"expiration"`
+	Fields     map[string]string `json:"fields"`
+}
+
+type postPolicyDocument struct {
+	Expiration string        `json:"expiration"`
+	Conditions []interface{} `json:"conditions"`
+}
+
+// newPostPolicyForm builds the policy and signs it by the signature V1, or V4 if the client signs by V4,
+// there is no signature V2 for the POST policy, so V2 uses V1 too
+func newPostPolicyForm(bucketName, url string, opt postPolicyOption, now time.Time) (*postPolicyForm, error) {
+	if opt.accessKeyID == "" || opt.accessKeySecret == "" {
+		return nil, fmt.Errorf("the access key is empty, the post policy can't be signed")
+	}
+
+	now = now.UTC()
+	expiration := now.Add(time.Duration(opt.expires) * time.Second).Format("2006-01-02T15:04:05.000Z")
+	form := &postPolicyForm{
+		URL:        url,
+		Expiration: expiration,
+		Fields:     map[string]string{"key": opt.prefix + "${filename}"},
+	}
+
+	conditions := []interface{}{
+		map[string]string{"bucket": bucketName},
+		[]string{"starts-with", "$key", opt.prefix},
+	}
+	if opt.minSize >= 0 || opt.maxSize >= 0 {
+		minSize, maxSize := opt.minSize, opt.maxSize
+		if minSize < 0 {
+			minSize = 0
+		}
+		if maxSize < 0 {
+			maxSize = MaxInt64
+		}
+		conditions = append(conditions, []interface{}{"content-length-range", minSize, maxSize})
+	}
+	names := make([]string, 0, len(opt.headers))
+	for name := range opt.headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		conditions = append(conditions, []string{"eq", "$" + name, opt.headers[name]})
+		form.Fields[name] = opt.headers[name]
+	}
+	if opt.securityToken != "" {
+		conditions = append(conditions, map[string]string{"x-oss-security-token": opt.securityToken})
+		form.Fields["x-oss-security-token"] = opt.securityToken
+	}
+
+	var signingKey []byte
+	if opt.authVersion == oss.AuthV4 {
+		if opt.region == "" {
+			return nil, fmt.Errorf("In the v4 signature scenario, please enter the region")
+		}
+		day := now.Format("20060102")
+		credential := fmt.Sprintf("%s/%s/%s/%s/%s", opt.accessKeyID, day, opt.region, opt.product, postPolicyRequestV4)
+		date := now.Format("20060102T150405Z")
+		conditions = append(conditions,
+			map[string]string{"x-oss-signature-version": postPolicyAlgorithmV4},
+			map[string]string{"x-oss-credential": credential},
+			map[string]string{"x-oss-date": date})
+		form.Fields["x-oss-signature-version"] = postPolicyAlgorithmV4
+		form.Fields["x-oss-credential"] = credential
+		form.Fields["x-oss-date"] = date
+
+		signingKey = hmacSHA256([]byte("aliyun_v4"+opt.accessKeySecret), day)
+		for _, scope := range []string{opt.region, opt.product, postPolicyRequestV4} {
+			signingKey = hmacSHA256(signingKey, scope)
+		}
+	}
+
+	document, err := json.Marshal(postPolicyDocument{Expiration: expiration, Conditions: conditions})
+	if err != nil {
+		return nil, err
+	}
+	policy := base64.StdEncoding.EncodeToString(document)
+	form.Fields["policy"] = policy
+
+	if opt.authVersion == oss.AuthV4 {
+		form.Fields["x-oss-signature"] = hex.EncodeToString(hmacSHA256(signingKey, policy))
+	} else {
+		mac := hmac.New(sha1.New, []byte(opt.accessKeySecret))
+		mac.Write([]byte(policy))
+		form.Fields["OSSAccessKeyId"] = opt.accessKeyID
+		form.Fields["Signature"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+	return form, nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// parseContentLengthRange parses the range like 0-10M, either side can be omitted
+func parseContentLengthRange(str string) (int64, int64, error) {
+	pos := strings.Index(str, "-")
+	if pos < 0 {
+		return -1, -1, fmt.Errorf("invalid content length range %s, it should be like min-max, e.g., 1K-10M", str)
+	}
+
+	minSize, maxSize := int64(-1), int64(-1)
+	var err error
+	if min := strings.TrimSpace(str[:pos]); min != "" {
+		if minSize, err = parseFilterSize(min); err != nil {
+			return -1, -1, err
+		}
+	}
+	if max := strings.TrimSpace(str[pos+1:]); max != "" {
+		if maxSize, err = parseFilterSize(max); err != nil {
+			return -1, -1, err
+		}
+	}
+	if minSize < 0 && maxSize < 0 {
+		return -1, -1, fmt.Errorf("invalid content length range %s, it should be like min-max, e.g., 1K-10M", str)
+	}
+	if maxSize >= 0 && minSize > maxSize {
+		return -1, -1, fmt.Errorf("invalid content length range %s, min is larger than max", str)
+	}
+	return minSize, maxSize, nil
+}
@@ -336,6 +336,7 @@ var probeCommand = ProbeCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -361,6 +362,10 @@ var probeCommand = ProbeCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -1018,24 +1023,13 @@ func (pc *ProbeCommand) prepareRandomObject(objectName string) (err error) {
 }
 
 func (pc *ProbeCommand) deleteObject(objectName string) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, pc.command.options)
-	for i := 1; ; i++ {
+	return pc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
 		bucket, err := pc.command.ossBucket(pc.pbOption.bucketName)
-		if err == nil {
-			err = bucket.DeleteObject(objectName)
-			if err == nil {
-				return nil
-			}
-		}
-
-		_, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || noNeedRetry {
+		if err != nil {
 			return err
 		}
-
-		// wait 1 second
-		time.Sleep(time.Duration(1) * time.Second)
-	}
+		return bucket.DeleteObject(objectName, oss.GetResponseHeader(respHeader))
+	})
 }
 
 func (pc *ProbeCommand) ossNetDetection(pingPath string) {
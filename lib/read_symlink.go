@@ -104,6 +104,7 @@ var readSymlinkCommand = ReadSymlinkCommand{
 		validOptionNames: []string{
 			OptionEncodingType,
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -112,6 +113,9 @@ var readSymlinkCommand = ReadSymlinkCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionLogLevel,
 			OptionVersionId,
 			OptionRequestPayer,
@@ -124,6 +128,10 @@ var readSymlinkCommand = ReadSymlinkCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -218,14 +226,14 @@ func (rc *ReadSymlinkCommand) linkStat(bucket *oss.Bucket, cloudURL CloudURL, op
 }
 
 func (rc *ReadSymlinkCommand) ossGetSymlinkRetry(bucket *oss.Bucket, symlinkObject string, options ...oss.Option) (http.Header, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, rc.command.options)
-	for i := 1; ; i++ {
-		props, err := bucket.GetSymlink(symlinkObject, options...)
-		if err == nil {
-			return props, err
-		}
-		if int64(i) >= retryTimes {
-			return props, ObjectError{err, bucket.BucketName, symlinkObject}
-		}
+	var props http.Header
+	err := rc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		props, err = bucket.GetSymlink(symlinkObject, withResponseHeader(options, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return props, ObjectError{err, bucket.BucketName, symlinkObject}
 	}
+	return props, nil
 }
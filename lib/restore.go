@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
@@ -19,6 +20,7 @@ type batchOptionType struct {
 	reporter     *Reporter
 	snapshotPath string
 	snapshotldb  *leveldb.DB
+	dryRun       bool
 }
 
 var specChineseRestore = SpecText{
@@ -28,7 +30,7 @@ var specChineseRestore = SpecText{
 	paramText: "cloud_url [local_xml_file] [options]",
 
 	syntaxText: ` 
-    ossutil restore cloud_url [local_xml_file] [--encoding-type url] [-r] [-f] [--output-dir=odir] [--version-id versionId] [--payer requester] [-c file] [--object-file file] [--snapshot-path dir] [--disable-ignore-error]
+    ossutil restore cloud_url [local_xml_file] [--encoding-type url] [-r] [-f] [--output-dir=odir] [--version-id versionId] [--payer requester] [-c file] [--object-file file] [--snapshot-path dir] [--disable-ignore-error] [--dryrun] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types]
 `,
 
 	detailHelpText: ` 
@@ -46,6 +48,10 @@ var specChineseRestore = SpecText{
 
     更多信息见官网文档：https://help.aliyun.com/document_detail/52930.html?spm=5176.doc31947.6.874.8GjVvu 
 
+    批量恢复时，可以使用--min-size、--max-size、--older-than、--newer-than、--storage-class、
+    --has-tag和--content-type选项筛选objects，例如只恢复--storage-class ColdArchive的objects，
+    选项的格式请参考ls命令的帮助。
+
 
 用法：
 
@@ -83,6 +89,9 @@ var specChineseRestore = SpecText{
     object1
     object2
     object3
+
+    如果指定了--dryrun选项，ossutil只列举并输出将要恢复的objects，不会实际恢复任何object，
+    也不会进行询问提示和更新快照信息。
 `,
 
 	sampleText: ` 
@@ -104,7 +113,7 @@ var specEnglishRestore = SpecText{
 	paramText: "cloud_url [local_xml_file] [options]",
 
 	syntaxText: ` 
-    ossutil restore cloud_url [local_xml_file] [--encoding-type url] [-r] [-f] [--output-dir=odir] [--version-id versionId] [--payer requester] [-c file] [--object-file file] [--snapshot-path dir] [--disable-ignore-error]
+    ossutil restore cloud_url [local_xml_file] [--encoding-type url] [-r] [-f] [--output-dir=odir] [--version-id versionId] [--payer requester] [-c file] [--object-file file] [--snapshot-path dir] [--disable-ignore-error] [--dryrun] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types]
 `,
 
 	detailHelpText: ` 
@@ -125,6 +134,10 @@ var specEnglishRestore = SpecText{
 
     More information about restore see: https://help.aliyun.com/document_detail/52930.html?spm=5176.doc31947.6.874.8GjVvu  
 
+    When restoring in batch, --min-size, --max-size, --older-than, --newer-than, --storage-class, 
+    --has-tag and --content-type options can filter the objects, eg: only restore the objects of 
+    --storage-class ColdArchive, see the help of ls command for the format of them.
+
 
 Usage:
 
@@ -165,6 +178,9 @@ Usage:
     object1
     object2
     object3
+
+    If --dryrun option is specified, ossutil only lists and prints the objects to be restored, 
+    without restoring any object, showing prompt question or updating the snapshot.
 `,
 
 	sampleText: ` 
@@ -205,7 +221,15 @@ var restoreCommand = RestoreCommand{
 			OptionRecursion,
 			OptionForce,
 			OptionEncodingType,
+			OptionMinSize,
+			OptionMaxSize,
+			OptionOlderThan,
+			OptionNewerThan,
+			OptionStorageClass,
+			OptionHasTag,
+			OptionContentType,
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -214,6 +238,9 @@ var restoreCommand = RestoreCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionRoutines,
 			OptionOutputDir,
 			OptionLogLevel,
@@ -228,6 +255,10 @@ var restoreCommand = RestoreCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionObjectFile,
@@ -237,6 +268,7 @@ var restoreCommand = RestoreCommand{
 			OptionRegion,
 			OptionCloudBoxID,
 			OptionForcePathStyle,
+			OptionDryRun,
 		},
 	},
 }
@@ -257,7 +289,12 @@ func (rc *RestoreCommand) Init(args []string, options OptionMapType) error {
 
 // RunCommand simulate inheritance, and polymorphism
 func (rc *RestoreCommand) RunCommand() error {
-	rc.monitor.init("Restore")
+	rc.reOption.dryRun = rc.command.isDryRun()
+	if rc.reOption.dryRun {
+		rc.monitor.init("Would restore")
+	} else {
+		rc.monitor.init("Restore")
+	}
 	encodingType, _ := GetString(OptionEncodingType, rc.command.options)
 	recursive, _ := GetBool(OptionRecursion, rc.command.options)
 	versionid, _ := GetString(OptionVersionId, rc.command.options)
@@ -287,7 +324,7 @@ func (rc *RestoreCommand) RunCommand() error {
 	if err != nil {
 		return err
 	}
-	if err = rc.checkOptions(cloudURL, recursive, force, versionid, objFileXml); err != nil {
+	if err = rc.checkOptions(cloudURL, recursive, force || rc.reOption.dryRun, versionid, objFileXml); err != nil {
 		return err
 	}
 	bucket, err := rc.command.ossBucket(cloudURL.bucket)
@@ -340,6 +377,14 @@ func (rc *RestoreCommand) checkOptions(cloudURL CloudURL, recursive, force bool,
 		return fmt.Errorf("restore bucket dose not support the --version-id=%s argument.", versionid)
 	}
 
+	attrFilter, err := rc.command.attributeFilter()
+	if err != nil {
+		return err
+	}
+	if attrFilter != nil && (!recursive || objectFile != "") {
+		return fmt.Errorf("--min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag and --content-type only work with --recursive, and can't be used with --object-file")
+	}
+
 	if !force {
 		var val string
 		if !recursive && objectFile == "" {
@@ -394,18 +439,17 @@ func (rc *RestoreCommand) ossRestoreObject(bucket *oss.Bucket, object, versionid
 }
 
 func (rc *RestoreCommand) ossRestoreObjectRetry(bucket *oss.Bucket, object string, options ...oss.Option) error {
-	var err error
-	retryTimes, _ := GetInt(OptionRetryTimes, rc.command.options)
+	if rc.reOption.dryRun {
+		printDryRun("restore "+CloudURLToString(bucket.BucketName, object), "")
+		return nil
+	}
 
-	for i := 1; ; i++ {
+	err := rc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
 		if rc.hasConfig {
-			err = bucket.RestoreObjectXML(object, rc.configXml, options...)
+			err = bucket.RestoreObjectXML(object, rc.configXml, withResponseHeader(options, respHeader)...)
 		} else {
-			err = bucket.RestoreObject(object, options...)
-		}
-
-		if err == nil {
-			return nil
+			err = bucket.RestoreObject(object, withResponseHeader(options, respHeader)...)
 		}
 
 		switch err.(type) {
@@ -414,11 +458,12 @@ func (rc *RestoreCommand) ossRestoreObjectRetry(bucket *oss.Bucket, object strin
 				return nil
 			}
 		}
-
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucket.BucketName, object}
-		}
+		return err
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, object}
 	}
+	return nil
 }
 
 func (rc *RestoreCommand) batchRestoreObjects(bucket *oss.Bucket, cloudURL CloudURL, recursive bool) error {
@@ -626,7 +671,7 @@ func (rc *RestoreCommand) skipRestore(spath string) bool {
 }
 
 func (rc *RestoreCommand) updateSnapshot(err error, spath string, srct int64) error {
-	if rc.reOption.snapshotPath != "" && err == nil {
+	if rc.reOption.snapshotPath != "" && err == nil && !rc.reOption.dryRun {
 		srctstr := fmt.Sprintf("%d", srct)
 		err := rc.reOption.snapshotldb.Put([]byte(spath), []byte(srctstr), nil)
 		if err != nil {
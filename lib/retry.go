@@ -0,0 +1,146 @@
+package lib
+
+import (
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// RetryPolicy decides whether and when a failed request is retried, all the commands retry by it so that
+// they behave the same. The delay starts from BaseDelay and is doubled for each retry up to MaxDelay, half
+// of it is random to spread the retries of concurrent requests, and the Retry-After of the response is
+// honored up to MaxDelay if the server returns it.
+type RetryPolicy struct {
+	MaxAttempts int64
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxElapsed  time.Duration // no limit if it's 0
+
+	anyError bool // retry any error, not only the one IsRetryableError returns true for
+	sleep    func(time.Duration)
+}
+
+// retryPolicy builds the policy by the options, which can also be set in the config file
+func (cmd *Command) retryPolicy() *RetryPolicy {
+	policy := &RetryPolicy{
+		BaseDelay:  time.Duration(RetryBaseDelay) * time.Millisecond,
+		MaxDelay:   time.Duration(RetryMaxDelay) * time.Millisecond,
+		MaxElapsed: time.Duration(RetryMaxElapsed) * time.Second,
+	}
+	policy.MaxAttempts, _ = GetInt(OptionRetryTimes, cmd.options)
+	if delay, err := GetInt(OptionRetryBaseDelay, cmd.options); err == nil {
+		policy.BaseDelay = time.Duration(delay) * time.Millisecond
+	}
+	if delay, err := GetInt(OptionRetryMaxDelay, cmd.options); err == nil {
+		policy.MaxDelay = time.Duration(delay) * time.Millisecond
+	}
+	if elapsed, err := GetInt(OptionRetryMaxElapsed, cmd.options); err == nil {
+		policy.MaxElapsed = time.Duration(elapsed) * time.Second
+	}
+	return policy
+}
+
+// Do calls fn until it succeeds, the error is not retryable, or the attempts or the time are used up, the error
+// of the last attempt is returned. fn gets the attempt number starting from 1 and the header to pass to oss by
+// oss.GetResponseHeader, so that the Retry-After of the response can be honored up to MaxDelay.
+func (p *RetryPolicy) Do(fn func(attempt int, respHeader *http.Header) error) error {
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		var respHeader http.Header
+		err := fn(attempt, &respHeader)
+		if err == nil || int64(attempt) >= p.MaxAttempts || (!p.anyError && !IsRetryableError(err)) {
+			return err
+		}
+
+		delay, ok := retryAfter(respHeader)
+		if !ok {
+			delay = p.backoff(attempt)
+		} else if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+		if p.MaxElapsed > 0 && time.Since(start)+delay > p.MaxElapsed {
+			LogError("give up retrying after %d(ms), attempt %d, error: %s\n", time.Since(start).Nanoseconds()/1000/1000, attempt, err.Error())
+			return err
+		}
+		LogDebug("retry after %d(ms), attempt %d, error: %s\n", delay.Nanoseconds()/1000/1000, attempt, err.Error())
+		if p.sleep != nil {
+			p.sleep(delay)
+		} else {
+			time.Sleep(delay)
+		}
+	}
+}
+
+// retryAnyError makes the policy retry the client errors too, like the resumable upload and copy which
+// retried any error before the policy, and the delete of a single object
+func (p *RetryPolicy) retryAnyError() *RetryPolicy {
+	p.anyError = true
+	return p
+}
+
+// backoff returns the delay before the retry following the attempt, it's between the half and the whole of
+// the exponential delay
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// retryAfter parses the Retry-After header, which is either the seconds to wait or a http date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(t); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// withResponseHeader returns a copy of the options which receives the response header into respHeader,
+// it should only be used for the requests sent once by the sdk, not the multipart ones
+func withResponseHeader(options []oss.Option, respHeader *http.Header) []oss.Option {
+	return append(append([]oss.Option{}, options...), oss.GetResponseHeader(respHeader))
+}
+
+// IsRetryableError returns true if the request may succeed by retrying: the server errors, the throttling,
+// and the network errors such as connection reset, timeout and dns failure. The other client errors of oss
+// and the errors of local files fail in the same way however many times retried.
+func IsRetryableError(err error) bool {
+	switch e := err.(type) {
+	case nil:
+		return false
+	case oss.ServiceError:
+		return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests || e.Code == "SlowDown"
+	case oss.UnexpectedStatusCodeError:
+		return e.Got() >= 500 || e.Got() == http.StatusTooManyRequests
+	case *os.PathError:
+		return false
+	case net.Error:
+		return true
+	}
+	// the connection closed by the server, the crc mismatch and the other errors not classified
+	return true
+}
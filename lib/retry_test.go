@@ -0,0 +1,147 @@
+package lib
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) TestRetryPolicyBackoff(c *C) {
+	policy := &RetryPolicy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(1)
+		c.Assert(delay >= 50*time.Millisecond && delay <= 100*time.Millisecond, Equals, true)
+		delay = policy.backoff(3)
+		c.Assert(delay >= 200*time.Millisecond && delay <= 400*time.Millisecond, Equals, true)
+		delay = policy.backoff(30)
+		c.Assert(delay >= 500*time.Millisecond && delay <= time.Second, Equals, true)
+	}
+
+	policy.BaseDelay = 0
+	c.Assert(policy.backoff(5), Equals, time.Duration(0))
+}
+
+func (s *OssutilCommandSuite) TestRetryPolicyDo(c *C) {
+	var delays []time.Duration
+	policy := &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond,
+		sleep: func(d time.Duration) { delays = append(delays, d) }}
+
+	// retried until the attempts are used up
+	attempts := 0
+	err := policy.Do(func(attempt int, respHeader *http.Header) error {
+		attempts = attempt
+		return oss.ServiceError{StatusCode: 500, Code: "InternalError"}
+	})
+	c.Assert(err, NotNil)
+	c.Assert(attempts, Equals, 3)
+	c.Assert(len(delays), Equals, 2)
+
+	// not retried on the client error
+	attempts = 0
+	err = policy.Do(func(attempt int, respHeader *http.Header) error {
+		attempts = attempt
+		return oss.ServiceError{StatusCode: 403, Code: "AccessDenied"}
+	})
+	c.Assert(err, NotNil)
+	c.Assert(attempts, Equals, 1)
+
+	// the client error is retried if any error is retried
+	attempts = 0
+	err = (&RetryPolicy{MaxAttempts: 3, sleep: policy.sleep}).retryAnyError().Do(func(attempt int, respHeader *http.Header) error {
+		attempts = attempt
+		return oss.ServiceError{StatusCode: 403, Code: "AccessDenied"}
+	})
+	c.Assert(err, NotNil)
+	c.Assert(attempts, Equals, 3)
+
+	// the Retry-After of the response is honored up to the max delay
+	delays = nil
+	policy.MaxDelay = 10 * time.Second
+	err = policy.Do(func(attempt int, respHeader *http.Header) error {
+		if attempt > 2 {
+			return nil
+		}
+		*respHeader = http.Header{"Retry-After": []string{strconv.Itoa(attempt * 7)}}
+		return oss.ServiceError{StatusCode: 503, Code: "SlowDown"}
+	})
+	c.Assert(err, IsNil)
+	c.Assert(delays, DeepEquals, []time.Duration{7 * time.Second, 10 * time.Second})
+
+	// not retried if it would exceed the max elapsed time
+	policy.MaxElapsed = time.Second
+	attempts = 0
+	err = policy.Do(func(attempt int, respHeader *http.Header) error {
+		attempts = attempt
+		*respHeader = http.Header{"Retry-After": []string{"5"}}
+		return oss.ServiceError{StatusCode: 503, Code: "SlowDown"}
+	})
+	c.Assert(err, NotNil)
+	c.Assert(attempts, Equals, 1)
+
+	// a single attempt if retry times is not set
+	policy = &RetryPolicy{}
+	attempts = 0
+	policy.Do(func(attempt int, respHeader *http.Header) error {
+		attempts = attempt
+		return fmt.Errorf("network error")
+	})
+	c.Assert(attempts, Equals, 1)
+}
+
+func (s *OssutilCommandSuite) TestRetryAfter(c *C) {
+	_, ok := retryAfter(http.Header{})
+	c.Assert(ok, Equals, false)
+
+	delay, ok := retryAfter(http.Header{"Retry-After": []string{"3"}})
+	c.Assert(ok, Equals, true)
+	c.Assert(delay, Equals, 3*time.Second)
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	delay, ok = retryAfter(http.Header{"Retry-After": []string{date}})
+	c.Assert(ok, Equals, true)
+	c.Assert(delay > 50*time.Second && delay <= time.Minute, Equals, true)
+
+	_, ok = retryAfter(http.Header{"Retry-After": []string{"soon"}})
+	c.Assert(ok, Equals, false)
+}
+
+func (s *OssutilCommandSuite) TestIsRetryableError(c *C) {
+	c.Assert(IsRetryableError(nil), Equals, false)
+	c.Assert(IsRetryableError(oss.ServiceError{StatusCode: 500}), Equals, true)
+	c.Assert(IsRetryableError(oss.ServiceError{StatusCode: 503, Code: "SlowDown"}), Equals, true)
+	c.Assert(IsRetryableError(oss.ServiceError{StatusCode: 429}), Equals, true)
+	c.Assert(IsRetryableError(oss.ServiceError{StatusCode: 404, Code: "NoSuchKey"}), Equals, false)
+	c.Assert(IsRetryableError(oss.ServiceError{StatusCode: 403, Code: "RequestTimeTooSkewed"}), Equals, false)
+	c.Assert(IsRetryableError(&net.OpError{Op: "read", Net: "tcp", Err: fmt.Errorf("connection reset by peer")}), Equals, true)
+	c.Assert(IsRetryableError(&net.DNSError{Err: "no such host", Name: "oss.example.com"}), Equals, true)
+	c.Assert(IsRetryableError(&os.PathError{Op: "open", Path: "file", Err: os.ErrNotExist}), Equals, false)
+}
+
+func (s *OssutilCommandSuite) TestRetryPolicyOptions(c *C) {
+	retryTimes := int64(5)
+	baseDelay := int64(200)
+	maxDelay := int64(2000)
+	maxElapsed := int64(60)
+	command := Command{options: OptionMapType{
+		OptionRetryTimes:      &retryTimes,
+		OptionRetryBaseDelay:  &baseDelay,
+		OptionRetryMaxDelay:   &maxDelay,
+		OptionRetryMaxElapsed: &maxElapsed,
+	}}
+	policy := command.retryPolicy()
+	c.Assert(policy.MaxAttempts, Equals, int64(5))
+	c.Assert(policy.BaseDelay, Equals, 200*time.Millisecond)
+	c.Assert(policy.MaxDelay, Equals, 2*time.Second)
+	c.Assert(policy.MaxElapsed, Equals, time.Minute)
+
+	// the defaults if the options are not supported by the command
+	policy = (&Command{options: OptionMapType{}}).retryPolicy()
+	c.Assert(policy.BaseDelay, Equals, time.Duration(RetryBaseDelay)*time.Millisecond)
+	c.Assert(policy.MaxDelay, Equals, time.Duration(RetryMaxDelay)*time.Millisecond)
+}
@@ -125,6 +125,7 @@ var revertCommand = RevertCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -139,6 +140,9 @@ var revertCommand = RevertCommand{
 			OptionEndTime,
 			OptionInclude,
 			OptionExclude,
+			OptionIncludeRegex,
+			OptionExcludeRegex,
+			OptionExcludeFrom,
 			OptionEncodingType,
 			OptionPassword,
 			OptionMode,
@@ -149,6 +153,10 @@ var revertCommand = RevertCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -203,11 +211,11 @@ func (revert *RevertCommand) RunCommand() error {
 		revert.revertOption.options = append(revert.revertOption.options, oss.RequestPayer(oss.PayerType(revert.revertOption.payer)))
 	}
 
-	var res bool
-	res, revert.revertOption.filters = getFilter(os.Args)
-	if !res {
-		return fmt.Errorf("--include or --exclude does not support format containing dir info")
+	filters, err := getFilter(os.Args)
+	if err != nil {
+		return err
 	}
+	revert.revertOption.filters = filters
 
 	bucket, err := revert.command.ossBucket(revert.revertOption.bucketName)
 	if err != nil {
@@ -286,7 +294,7 @@ func (revert *RevertCommand) revertObjects(bucket *oss.Bucket) error {
 }
 
 func (revert *RevertCommand) filterDeleteMarker(deleteMarker *oss.ObjectDeleteMarkerProperties) bool {
-	if !doesSingleObjectMatchPatterns(deleteMarker.Key, revert.revertOption.filters) {
+	if !doesSingleObjectMatchPatterns(filterRelativeKey(revert.revertOption.object, deleteMarker.Key), revert.revertOption.filters) {
 		return false
 	}
 
@@ -2,9 +2,9 @@ package lib
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
-	"time"
 
 	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
@@ -22,6 +22,11 @@ type removeOptionType struct {
 	//version
 	versionId   string
 	allVersions bool
+
+	dryRun bool
+
+	// the objects to remove are read from the manifest
+	manifest *manifestSource
 }
 
 var specChineseRemove = SpecText{
@@ -31,7 +36,7 @@ var specChineseRemove = SpecText{
 	paramText: "cloud_url [options]",
 
 	syntaxText: ` 
-    ossutil rm oss://bucket[/prefix] [-r] [-b] [-m] [-a] [-f]  [--include include-pattern] [--exclude exclude-pattern]  [--version-id versionId | --all-versions] [--payer requester] [-c file]
+    ossutil rm oss://bucket[/prefix] [-r] [-b] [-m] [-a] [-f]  [--include include-pattern] [--exclude exclude-pattern]  [--version-id versionId | --all-versions] [--payer requester] [--dryrun] [--manifest file] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types] [-c file]
 `,
 
 	detailHelpText: ` 
@@ -70,10 +75,43 @@ var specChineseRemove = SpecText{
     ?：匹配单个字符
     [sequence]：匹配sequence的任意字符
     [!sequence]：匹配不在sequence的任意字符
-    注意：规则不支持带目录的格式，e.g.，--include "/usr/*/test/*.jpg"。
+    **：匹配任意层目录
+    含有/或**的规则匹配相对于源目录或object前缀所在目录的路径，以/开头时同样从该目录开始匹配，以/结尾时匹配
+    该目录下的所有文件，e.g.，--include "logs/**/*.gz"，--exclude "tmp/"；其它规则只匹配文件名。
 
     --include和--exclude可以出现多次。当多个规则出现时，这些规则按从左往右的顺序应用
 
+--include-regex、--exclude-regex和--exclude-from选项
+
+    --include-regex和--exclude-regex以正则表达式匹配相对路径（同上），未指定^和$时匹配路径的任意部分，
+    e.g.，--exclude-regex "\.(tmp|bak)$"。
+    --exclude-from从文件中读取排除规则，文件格式同.gitignore，如.ossignore：每行一条规则，#开头为注释，
+    !开头表示重新包含之前排除的路径，以/结尾只匹配目录，含有/的规则从根目录开始匹配，否则匹配任意层的名称，
+    已被排除的目录下的文件不能再被包含。
+    这些选项和--include、--exclude可以混合出现多次，按从左往右的顺序应用，--exclude-from整体作为一个排除规则。
+
+--min-size、--max-size、--older-than、--newer-than、--storage-class、--has-tag和--content-type选项
+
+    按属性筛选要操作的object，可以和--include、--exclude同时使用，只操作满足所有条件的object。
+    --min-size、--max-size：大小范围，单位可以为B/K/M/G/T，例如：--min-size 1M --max-size 1G
+    --older-than、--newer-than：最后修改时间范围，取值为时长，单位可以为s/m/h/d/w，例如：30d，
+        或者为时间，例如：2023-01-01、2023-01-01T08:00:00+08:00
+    --storage-class：存储方式，多个以逗号分隔，例如：IA,Archive
+    --has-tag：带有的标签，格式为k1=v1,k2=v2，只指定k表示带有该标签即可
+    --content-type：Content-Type，多个以逗号分隔，支持image/*的格式
+    --has-tag和--content-type需要为每个object发送一次请求，其他条件由列举结果判断，不需要额外请求。
+    需要同时指定-r选项，只作用于object，不作用于Multipart Upload事件，不支持--all-versions。
+
+--dryrun选项
+
+    如果指定了该选项，ossutil只列举并输出将要删除的bucket、object和Multipart Upload事件，
+    不会实际删除任何数据，也不会进行询问提示。建议在批量删除前先使用该选项确认删除范围。
+
+--manifest选项
+
+    如果指定了该选项，ossutil从清单中读取cloud_url前缀下的objects批量删除，而不是列举bucket，
+    此时可以不指定--recursive选项，清单中指定了版本号时删除该版本。该选项不支持与--multipart、
+    --all-type、--bucket、--version-id和--all-versions选项同时使用。清单的格式请参考cp命令帮助。
 
 用法：
 
@@ -142,6 +180,8 @@ var specChineseRemove = SpecText{
     ossutil rm oss://bucket1/objdir -r  --all-versions
     ossutil rm oss://bucket1 -r -b --all-versions
     ossutil rm oss://bucket1 -r --payer requester
+    ossutil rm oss://bucket1/logs/ -r --older-than 90d --storage-class Standard,IA
+    ossutil rm oss://bucket1 --manifest oss://bucket2/inventory/bucket1/daily/2024-01-01T00-00Z/manifest.json -f
 `,
 }
 
@@ -152,7 +192,7 @@ var specEnglishRemove = SpecText{
 	paramText: "cloud_url [options]",
 
 	syntaxText: ` 
-    ossutil rm oss://bucket[/prefix] [-r] [-b] [-m] [-a] [-f]  [--include include-pattern] [--exclude exclude-pattern]  [--version-id versionId | --all-versions] [--payer requester] [-c file]
+    ossutil rm oss://bucket[/prefix] [-r] [-b] [-m] [-a] [-f]  [--include include-pattern] [--exclude exclude-pattern]  [--version-id versionId | --all-versions] [--payer requester] [--dryrun] [--manifest file] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types] [-c file]
 `,
 
 	detailHelpText: ` 
@@ -198,7 +238,11 @@ var specEnglishRemove = SpecText{
     ?: Matches any single character
     [sequence]: Matches any character in sequence
     [!sequence]: Matches any character not in sequence
-    Note: does not support patterns containing directory info. e.g., --include "/usr/*/test/*.jpg" 
+    **: Matches any directories
+    The pattern containing / or ** matches the path relative to the source directory or the directory of
+    the object prefix, the leading / also starts from the directory, the trailing / matches all the files
+    in the directory, e.g., --include "logs/**/*.gz", --exclude "tmp/". The other patterns only match the
+    file name.
 
     Any number of these parameters can be passed to a command. You can do this by providing an --exclude
     or --include argument multiple times, e.g.,
@@ -206,6 +250,46 @@ var specEnglishRemove = SpecText{
     When there are multi filters, the rule is the filters that appear later in the command take precedence
     over filters that appear earlier in the command
 
+--include-regex, --exclude-regex and --exclude-from option
+
+    --include-regex and --exclude-regex match the relative path(the same as above) by the regular
+    expression, it matches any part of the path without ^ and $, e.g., --exclude-regex "\.(tmp|bak)$".
+    --exclude-from reads the exclude rules from the file in .gitignore syntax, like .ossignore: a rule per
+    line, the line starting with # is comment, the rule starting with ! includes the path excluded by the
+    former rules again, the rule ending with / only matches directories, the rule containing / matches
+    from the root, otherwise it matches the name at any level, the file can't be included again if its
+    parent directory is excluded.
+    These options can be mixed with --include and --exclude for multiple times, they are applied from
+    left to right, the whole --exclude-from works as an exclude filter.
+
+--min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag and --content-type option
+
+    Filter the objects to operate by their attributes, they can be used with --include and --exclude,
+    only the objects matching all the conditions are operated.
+    --min-size, --max-size: the range of size, the unit can be B/K/M/G/T, eg: --min-size 1M --max-size 1G
+    --older-than, --newer-than: the range of last modified time, the value is a duration with unit 
+        s/m/h/d/w, eg: 30d, or a time, eg: 2023-01-01, 2023-01-01T08:00:00+08:00
+    --storage-class: the storage classes separated by comma, eg: IA,Archive
+    --has-tag: the tags of the object, the format is k1=v1,k2=v2, only k means the tag of any value
+    --content-type: the content types separated by comma, image/* is supported
+    --has-tag and --content-type send a request for each object, the other conditions are decided by 
+    the listing result without extra requests.
+    It needs -r option, only works on objects but not multipart uploads, and doesn't support 
+    --all-versions.
+
+--dryrun option
+
+    If the option is specified, ossutil only lists and prints the buckets, objects and multipart 
+    upload tasks to be removed, without removing any data or asking user to confirm. It's 
+    recommended to check the range with the option before batch removing.
+
+--manifest option
+
+    If the option is specified, ossutil reads the objects under the prefix of cloud_url from 
+    the manifest and removes them in batch instead of listing the bucket, --recursive option 
+    can be omitted then, and the version is removed if the manifest specifies it. The option 
+    can't be used with --multipart, --all-type, --bucket, --version-id and --all-versions. 
+    The format of the manifest, please refer cp command help.
 
 Usage:
 
@@ -285,6 +369,8 @@ Usage:
     ossutil rm oss://bucket1/objdir -r  --all-versions
     ossutil rm oss://bucket1 -r -b --all-versions
     ossutil rm oss://bucket1 -r --payer requester
+    ossutil rm oss://bucket1/logs/ -r --older-than 90d --storage-class Standard,IA
+    ossutil rm oss://bucket1 --manifest oss://bucket2/inventory/bucket1/daily/2024-01-01T00-00Z/manifest.json -f
 `,
 }
 
@@ -295,6 +381,7 @@ type RemoveCommand struct {
 	rmOption      removeOptionType
 	commonOptions []oss.Option
 	filters       []filterOptionType
+	attrFilter    *attributeFilter
 }
 
 var removeCommand = RemoveCommand{
@@ -308,6 +395,7 @@ var removeCommand = RemoveCommand{
 		group:       GroupTypeNormalCommand,
 		validOptionNames: []string{
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -316,6 +404,9 @@ var removeCommand = RemoveCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionLogLevel,
 			OptionRecursion,
 			OptionBucket,
@@ -323,8 +414,20 @@ var removeCommand = RemoveCommand{
 			OptionMultipart,
 			OptionAllType,
 			OptionEncodingType,
+			OptionManifest,
+			OptionManifestFormat,
 			OptionInclude,
 			OptionExclude,
+			OptionIncludeRegex,
+			OptionExcludeRegex,
+			OptionExcludeFrom,
+			OptionMinSize,
+			OptionMaxSize,
+			OptionOlderThan,
+			OptionNewerThan,
+			OptionStorageClass,
+			OptionHasTag,
+			OptionContentType,
 			OptionVersionId,
 			OptionAllversions,
 			OptionRequestPayer,
@@ -337,12 +440,17 @@ var removeCommand = RemoveCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
 			OptionRegion,
 			OptionCloudBoxID,
 			OptionForcePathStyle,
+			OptionDryRun,
 		},
 	},
 }
@@ -393,16 +501,25 @@ func (rc *RemoveCommand) RunCommand() error {
 		return err
 	}
 
-	var res bool
-	res, rc.filters = getFilter(os.Args)
-	if !res {
-		return fmt.Errorf("--include or --exclude does not support format containing dir info")
+	filters, err := getFilter(os.Args)
+	if err != nil {
+		return err
 	}
+	rc.filters = filters
 
 	if !rc.rmOption.recursive && len(rc.filters) > 0 {
 		return fmt.Errorf("--include or --exclude only work with --recursive")
 	}
 
+	if rc.attrFilter, err = rc.command.attributeFilter(); err != nil {
+		return err
+	}
+	if rc.attrFilter != nil {
+		if !rc.rmOption.recursive || rc.rmOption.manifest != nil || len(rc.rmOption.versionId) > 0 || rc.rmOption.allVersions {
+			return fmt.Errorf("--min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag and --content-type only work with --recursive, and can't be used with --manifest, --version-id or --all-versions")
+		}
+	}
+
 	// confirm remove objects/multiparts/allTypes before statistic
 	if !rc.confirmRemoveObject(cloudURL) {
 		return nil
@@ -427,6 +544,23 @@ func (rc *RemoveCommand) assembleOption(cloudURL CloudURL) error {
 	toBucket, _ := GetBool(OptionBucket, rc.command.options)
 	rc.rmOption.versionId, _ = GetString(OptionVersionId, rc.command.options)
 	rc.rmOption.allVersions, _ = GetBool(OptionAllversions, rc.command.options)
+	rc.rmOption.dryRun = rc.command.isDryRun()
+	rc.monitor.dryRun = rc.rmOption.dryRun
+
+	// the objects of the manifest are removed in batch as the recursive ones
+	var err error
+	if rc.rmOption.manifest, err = rc.command.openManifest(cloudURL); err != nil {
+		return err
+	}
+	if rc.rmOption.manifest != nil {
+		if isMultipart || isAllType || toBucket {
+			return fmt.Errorf("--manifest only work on objects, it can't be used with --multipart, --all-type or --bucket")
+		}
+		if len(rc.rmOption.versionId) > 0 || rc.rmOption.allVersions {
+			return fmt.Errorf("--manifest can't be used with --version-id or --all-versions, please specify the version of each object in the manifest")
+		}
+		rc.rmOption.recursive = true
+	}
 
 	if err := rc.checkOption(cloudURL, isMultipart, isAllType, toBucket); err != nil {
 		return err
@@ -491,7 +625,7 @@ func (rc *RemoveCommand) checkOption(cloudURL CloudURL, isMultipart, isAllType,
 }
 
 func (rc *RemoveCommand) confirmRemoveObject(cloudURL CloudURL) bool {
-	if !rc.rmOption.force && rc.rmOption.recursive && rc.rmOption.typeSet&allType != 0 {
+	if !rc.rmOption.force && !rc.rmOption.dryRun && rc.rmOption.recursive && rc.rmOption.typeSet&allType != 0 {
 		stringList := []string{}
 		if rc.rmOption.typeSet&objectType != 0 {
 			stringList = append(stringList, "objects")
@@ -511,6 +645,10 @@ func (rc *RemoveCommand) confirmRemoveObject(cloudURL CloudURL) bool {
 }
 
 func (rc *RemoveCommand) entryStatistic(bucket *oss.Bucket, cloudURL CloudURL) {
+	// the objects of the manifest are counted while removing them
+	if rc.rmOption.manifest != nil {
+		return
+	}
 	if rc.rmOption.typeSet&objectType != 0 {
 		rc.objectStatistic(bucket, cloudURL)
 	}
@@ -543,16 +681,16 @@ func (rc *RemoveCommand) touchObject(bucket *oss.Bucket, cloudURL CloudURL) (boo
 }
 
 func (rc *RemoveCommand) ossIsObjectExistRetry(bucket *oss.Bucket, object string) (bool, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, rc.command.options)
-	for i := 1; ; i++ {
-		exist, err := bucket.IsObjectExist(object, rc.commonOptions...)
-		if err == nil {
-			return exist, err
-		}
-		if int64(i) >= retryTimes {
-			return false, ObjectError{err, bucket.BucketName, object}
-		}
+	var exist bool
+	err := rc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		exist, err = bucket.IsObjectExist(object, withResponseHeader(rc.commonOptions, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return false, ObjectError{err, bucket.BucketName, object}
 	}
+	return exist, nil
 }
 
 func (rc *RemoveCommand) batchObjectStatistic(bucket *oss.Bucket, cloudURL CloudURL) error {
@@ -566,14 +704,15 @@ func (rc *RemoveCommand) batchObjectStatistic(bucket *oss.Bucket, cloudURL Cloud
 			return err
 		}
 
-		if len(rc.filters) == 0 {
+		if len(rc.filters) == 0 && rc.attrFilter == nil {
 			rc.monitor.updateScanNum(int64(len(lor.Objects)))
 		} else {
-			for _, object := range lor.Objects {
-				if doesSingleObjectMatchPatterns(object.Key, rc.filters) {
-					rc.monitor.updateScanNum(int64(1))
-				}
+			objects, err := rc.getObjectsFromListResult(bucket, lor)
+			if err != nil {
+				rc.monitor.setScanError(err)
+				return err
 			}
+			rc.monitor.updateScanNum(int64(len(objects)))
 		}
 
 		pre = oss.Prefix(lor.Prefix)
@@ -602,7 +741,7 @@ func (rc *RemoveCommand) multipartUploadsStatistic(bucket *oss.Bucket, cloudURL
 				rc.monitor.updateScanUploadIdNum(int64(len(lmr.Uploads)))
 			} else {
 				for _, upload := range lmr.Uploads {
-					if doesSingleObjectMatchPatterns(upload.Key, rc.filters) {
+					if doesSingleObjectMatchPatterns(filterRelativeKey(lmr.Prefix, upload.Key), rc.filters) {
 						rc.monitor.updateScanUploadIdNum(int64(1))
 					}
 				}
@@ -638,7 +777,7 @@ func (rc *RemoveCommand) removeEntry(bucket *oss.Bucket, cloudURL CloudURL) erro
 			return err
 		}
 
-		if rc.rmOption.recursive && len(rc.filters) == 0 {
+		if rc.rmOption.recursive && len(rc.filters) == 0 && rc.attrFilter == nil && rc.rmOption.manifest == nil {
 			// check again
 			// the key including special character can't be deleted by function removeObjectEntry
 			// so delete them one by one
@@ -662,6 +801,10 @@ func (rc *RemoveCommand) removeEntry(bucket *oss.Bucket, cloudURL CloudURL) erro
 }
 
 func (rc *RemoveCommand) removeObjectEntry(bucket *oss.Bucket, cloudURL CloudURL) error {
+	if rc.rmOption.manifest != nil {
+		return rc.batchDeleteManifestObjects(bucket)
+	}
+
 	//version mode
 	if len(rc.rmOption.versionId) > 0 || rc.rmOption.allVersions {
 		if len(rc.rmOption.versionId) > 0 {
@@ -705,16 +848,17 @@ func (rc *RemoveCommand) deleteObjectWithMonitor(bucket *oss.Bucket, object stri
 }
 
 func (rc *RemoveCommand) ossDeleteObjectRetry(bucket *oss.Bucket, object string) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, rc.command.options)
-	for i := 1; ; i++ {
-		err := bucket.DeleteObject(object, rc.commonOptions...)
-		if err == nil {
-			return err
-		}
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucket.BucketName, object}
-		}
+	if rc.rmOption.dryRun {
+		printDryRun("remove "+CloudURLToString(bucket.BucketName, object), "")
+		return nil
+	}
+	err := rc.command.retryPolicy().retryAnyError().Do(func(attempt int, respHeader *http.Header) error {
+		return bucket.DeleteObject(object, withResponseHeader(rc.commonOptions, respHeader)...)
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, object}
 	}
+	return nil
 }
 
 func (rc *RemoveCommand) updateObjectMonitor(okNum, errNum int64) {
@@ -735,7 +879,10 @@ func (rc *RemoveCommand) batchDeleteObjects(bucket *oss.Bucket, cloudURL CloudUR
 		}
 
 		// batch delete
-		skipLor := rc.getObjectsFromListResult(lor)
+		skipLor, err := rc.getObjectsFromListResult(bucket, lor)
+		if err != nil {
+			return err
+		}
 		delNum, err := rc.ossBatchDeleteObjectsRetry(bucket, skipLor)
 		rc.updateObjectMonitor(int64(delNum), int64(len(skipLor)-delNum))
 		if err != nil {
@@ -752,40 +899,47 @@ func (rc *RemoveCommand) batchDeleteObjects(bucket *oss.Bucket, cloudURL CloudUR
 }
 
 func (rc *RemoveCommand) ossBatchDeleteObjectsRetry(bucket *oss.Bucket, objects []string) (int, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, rc.command.options)
 	num := len(objects)
 	if num <= 0 {
 		return 0, nil
 	}
 
+	if rc.rmOption.dryRun {
+		for _, object := range objects {
+			printDryRun("remove "+CloudURLToString(bucket.BucketName, object), "")
+		}
+		return num, nil
+	}
+
 	deletedNum := 0
-	for i := 1; ; i++ {
-		listOptions := append(rc.commonOptions, oss.DeleteObjectsQuiet(true))
-		delRes, err := bucket.DeleteObjects(objects, listOptions...)
-		if err == nil {
+	listOptions := append(rc.commonOptions, oss.DeleteObjectsQuiet(true))
+	err := rc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		delRes, err := bucket.DeleteObjects(objects, withResponseHeader(listOptions, respHeader)...)
+		// when 4XX,5XX error,delRes.DeletedObjects is empty
+		if len(delRes.DeletedObjects) > 0 || err == nil {
 			deletedNum += (len(objects) - len(delRes.DeletedObjects))
-			if len(delRes.DeletedObjects) == 0 {
-				return deletedNum, nil
-			}
-			objects = delRes.DeletedObjects
-		} else {
-			// when 4XX,5XX error,delRes.DeletedObjects is empty
-			if len(delRes.DeletedObjects) > 0 {
-				deletedNum += (len(objects) - len(delRes.DeletedObjects))
-				objects = delRes.DeletedObjects
-			}
 		}
-
-		if err != nil {
-			serviceError, noNeedRetry := err.(oss.ServiceError)
-			if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-				return deletedNum, fmt.Errorf("%s,delete objects: %#v failed", err.Error(), objects)
+		if len(delRes.DeletedObjects) > 0 {
+			objects = delRes.DeletedObjects
+			if err == nil {
+				// the objects failed to delete are deleted again by the policy, as many times as the request
+				return fmt.Errorf("%d objects are not deleted", len(objects))
 			}
 		}
+		return err
+	})
+	if err != nil {
+		return deletedNum, fmt.Errorf("%s,delete objects: %#v failed", err.Error(), objects)
 	}
+	return deletedNum, nil
 }
 
 func (rc *RemoveCommand) removeSpecialCharacterObjects(bucket *oss.Bucket, cloudURL CloudURL) error {
+	if rc.rmOption.dryRun {
+		// nothing is removed in dry run mode, all the objects are left
+		return nil
+	}
+
 	pre := oss.Prefix(cloudURL.object)
 	marker := oss.Marker("")
 	for {
@@ -810,14 +964,21 @@ func (rc *RemoveCommand) removeSpecialCharacterObjects(bucket *oss.Bucket, cloud
 	return nil
 }
 
-func (rc *RemoveCommand) getObjectsFromListResult(lor oss.ListObjectsResult) []string {
+func (rc *RemoveCommand) getObjectsFromListResult(bucket *oss.Bucket, lor oss.ListObjectsResult) ([]string, error) {
 	objects := []string{}
 	for _, object := range lor.Objects {
-		if doesSingleObjectMatchPatterns(object.Key, rc.filters) {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, object.Key), rc.filters) {
+			continue
+		}
+		match, err := rc.attrFilter.matchObject(bucket, object, rc.commonOptions...)
+		if err != nil {
+			return objects, err
+		}
+		if match {
 			objects = append(objects, object.Key)
 		}
 	}
-	return objects
+	return objects, nil
 }
 
 func (rc *RemoveCommand) removeMultipartUploadsEntry(bucket *oss.Bucket, cloudURL CloudURL) error {
@@ -865,7 +1026,7 @@ func (rc *RemoveCommand) multipartUploadsProducer(bucket *oss.Bucket, cloudURL C
 			if !rc.rmOption.recursive && uploadId.Key != cloudURL.object {
 				break
 			}
-			if doesSingleObjectMatchPatterns(uploadId.Key, rc.filters) {
+			if doesSingleObjectMatchPatterns(filterRelativeKey(lmr.Prefix, uploadId.Key), rc.filters) {
 				chUploadIds <- uploadIdInfoType{uploadId.Key, uploadId.UploadID}
 			}
 		}
@@ -903,14 +1064,13 @@ func (rc *RemoveCommand) updateUploadIdMonitor(err error) {
 }
 
 func (rc *RemoveCommand) ossAbortMultipartUploadRetry(bucket *oss.Bucket, key, uploadId string) error {
+	if rc.rmOption.dryRun {
+		printDryRun(fmt.Sprintf("abort uploadId %s of %s", uploadId, CloudURLToString(bucket.BucketName, key)), "")
+		return nil
+	}
 	var imur = oss.InitiateMultipartUploadResult{Bucket: bucket.BucketName, Key: key, UploadID: uploadId}
-	retryTimes, _ := GetInt(OptionRetryTimes, rc.command.options)
-	for i := 1; ; i++ {
-		err := bucket.AbortMultipartUpload(imur, rc.commonOptions...)
-
-		if err == nil {
-			return err
-		}
+	err := rc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		err := bucket.AbortMultipartUpload(imur, withResponseHeader(rc.commonOptions, respHeader)...)
 
 		switch err.(type) {
 		case oss.ServiceError:
@@ -918,11 +1078,12 @@ func (rc *RemoveCommand) ossAbortMultipartUploadRetry(bucket *oss.Bucket, key, u
 				return nil
 			}
 		}
-
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucket.BucketName, key}
-		}
+		return err
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, key}
 	}
+	return nil
 }
 
 func (rc *RemoveCommand) removeBucket(bucket *oss.Bucket, cloudURL CloudURL) error {
@@ -939,7 +1100,7 @@ func (rc *RemoveCommand) removeBucket(bucket *oss.Bucket, cloudURL CloudURL) err
 }
 
 func (rc *RemoveCommand) confirmRemoveBucket(cloudURL CloudURL) bool {
-	if !rc.rmOption.force {
+	if !rc.rmOption.force && !rc.rmOption.dryRun {
 		var val string
 		fmt.Printf(getClearStr(fmt.Sprintf("Do you really mean to remove the Bucket: %s(y or N)? ", cloudURL.bucket)))
 		if _, err := fmt.Scanln(&val); err != nil || (strings.ToLower(val) != "yes" && strings.ToLower(val) != "y") {
@@ -952,26 +1113,20 @@ func (rc *RemoveCommand) confirmRemoveBucket(cloudURL CloudURL) bool {
 }
 
 func (rc *RemoveCommand) ossDeleteBucketRetry(client *oss.Client, bucket string) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, rc.command.options)
-	for i := 1; ; i++ {
-		err := client.DeleteBucket(bucket)
-		if err == nil {
-			return err
-		}
-
-		// http 4XX error no need to retry
-		// only network error or internal error need to retry
-		serviceError, noNeedRetry := err.(oss.ServiceError)
-		if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-			if strings.Contains(err.Error(), "bucket you tried to delete is not empty") {
-				fmt.Printf("\nWhether new objects were uploaded during the deletion?\n\n")
-			}
-			return BucketError{err, bucket}
+	if rc.rmOption.dryRun {
+		printDryRun("remove bucket "+CloudURLToString(bucket, ""), "")
+		return nil
+	}
+	err := rc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		return client.DeleteBucket(bucket, oss.GetResponseHeader(respHeader))
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "bucket you tried to delete is not empty") {
+			fmt.Printf("\nWhether new objects were uploaded during the deletion?\n\n")
 		}
-
-		// wait 1 second
-		time.Sleep(time.Duration(1) * time.Second)
+		return BucketError{err, bucket}
 	}
+	return nil
 }
 
 // version
@@ -996,17 +1151,18 @@ func (rc *RemoveCommand) deleteObjectWithMonitorVersion(bucket *oss.Bucket, obje
 }
 
 func (rc *RemoveCommand) ossDeleteObjectRetryVersion(bucket *oss.Bucket, object string, versionId string) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, rc.command.options)
-	for i := 1; ; i++ {
-		listOptions := append(rc.commonOptions, oss.VersionId(versionId))
-		err := bucket.DeleteObject(object, listOptions...)
-		if err == nil {
-			return err
-		}
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucket.BucketName, object}
-		}
+	if rc.rmOption.dryRun {
+		printDryRun(fmt.Sprintf("remove %s, versionId: %s", CloudURLToString(bucket.BucketName, object), versionId), "")
+		return nil
+	}
+	listOptions := append(rc.commonOptions, oss.VersionId(versionId))
+	err := rc.command.retryPolicy().retryAnyError().Do(func(attempt int, respHeader *http.Header) error {
+		return bucket.DeleteObject(object, withResponseHeader(listOptions, respHeader)...)
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, object}
 	}
+	return nil
 }
 
 func (rc *RemoveCommand) removeObjectAllVersion(bucket *oss.Bucket, cloudURL CloudURL) error {
@@ -1085,13 +1241,13 @@ func (rc *RemoveCommand) batchObjectStatisticVersion(bucket *oss.Bucket, cloudUR
 			rc.monitor.updateScanNum(int64(len(lor.ObjectDeleteMarkers) + len(lor.ObjectVersions)))
 		} else {
 			for _, object := range lor.ObjectDeleteMarkers {
-				if doesSingleObjectMatchPatterns(object.Key, rc.filters) {
+				if doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, object.Key), rc.filters) {
 					rc.monitor.updateScanNum(int64(1))
 				}
 			}
 
 			for _, object := range lor.ObjectVersions {
-				if doesSingleObjectMatchPatterns(object.Key, rc.filters) {
+				if doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, object.Key), rc.filters) {
 					rc.monitor.updateScanNum(int64(1))
 				}
 			}
@@ -1123,7 +1279,7 @@ func (rc *RemoveCommand) batchDeleteObjectsVersion(bucket *oss.Bucket, cloudURL
 
 		objectsToDelete := make([]oss.DeleteObject, 0)
 		for _, object := range lor.ObjectDeleteMarkers {
-			if doesSingleObjectMatchPatterns(object.Key, rc.filters) {
+			if doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, object.Key), rc.filters) {
 				objectsToDelete = append(objectsToDelete, oss.DeleteObject{
 					Key:       object.Key,
 					VersionId: object.VersionId,
@@ -1132,7 +1288,7 @@ func (rc *RemoveCommand) batchDeleteObjectsVersion(bucket *oss.Bucket, cloudURL
 		}
 
 		for _, object := range lor.ObjectVersions {
-			if doesSingleObjectMatchPatterns(object.Key, rc.filters) {
+			if doesSingleObjectMatchPatterns(filterRelativeKey(lor.Prefix, object.Key), rc.filters) {
 				objectsToDelete = append(objectsToDelete, oss.DeleteObject{
 					Key:       object.Key,
 					VersionId: object.VersionId,
@@ -1156,40 +1312,64 @@ func (rc *RemoveCommand) batchDeleteObjectsVersion(bucket *oss.Bucket, cloudURL
 	return nil
 }
 
+// batchDeleteManifestObjects removes the objects read from the manifest 1000 at a time, the version is removed
+// if the manifest specifies it. The objects are counted while reading the manifest, which is read only once.
+func (rc *RemoveCommand) batchDeleteManifestObjects(bucket *oss.Bucket) error {
+	objectsToDelete := make([]oss.DeleteObject, 0)
+	deleteObjects := func() error {
+		delNum, err := rc.ossBatchDeleteObjectsRetryVersion(bucket, objectsToDelete)
+		rc.updateObjectMonitor(int64(delNum), int64(len(objectsToDelete)-delNum))
+		objectsToDelete = make([]oss.DeleteObject, 0)
+		return err
+	}
+
+	err := rc.rmOption.manifest.forEach(func(entry ManifestEntry) error {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(rc.rmOption.manifest.prefix, entry.Key), rc.filters) {
+			return nil
+		}
+		rc.monitor.updateScanNum(1)
+		objectsToDelete = append(objectsToDelete, oss.DeleteObject{
+			Key:       entry.Key,
+			VersionId: entry.VersionId,
+		})
+		if len(objectsToDelete) >= 1000 {
+			return deleteObjects()
+		}
+		return nil
+	})
+	if err == nil {
+		err = deleteObjects()
+	}
+	rc.monitor.setScanEnd()
+	return err
+}
+
 func (rc *RemoveCommand) ossBatchDeleteObjectsRetryVersion(bucket *oss.Bucket, objectVersions []oss.DeleteObject) (int, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, rc.command.options)
 	num := len(objectVersions)
 	if num <= 0 {
 		return 0, nil
 	}
 
-	deletedNum := 0
-	for i := 1; ; i++ {
-		listOptions := append(rc.commonOptions, oss.DeleteObjectsQuiet(true))
-		delRes, err := bucket.DeleteObjectVersions(objectVersions, listOptions...)
-		getFailedObject := false
-		if err == nil {
-			deletedNum += (len(objectVersions) - len(delRes.DeletedObjectsDetail))
-			if len(delRes.DeletedObjectsDetail) == 0 {
-				return deletedNum, nil
-			}
-			getFailedObject = true
-		} else {
-			// when 4XX,5XX error,delRes.DeletedObjects is empty
-			if len(delRes.DeletedObjectsDetail) > 0 {
-				deletedNum += (len(objectVersions) - len(delRes.DeletedObjectsDetail))
-				getFailedObject = true
+	if rc.rmOption.dryRun {
+		for _, object := range objectVersions {
+			if object.VersionId == "" {
+				printDryRun("remove "+CloudURLToString(bucket.BucketName, object.Key), "")
+				continue
 			}
+			printDryRun(fmt.Sprintf("remove %s, versionId: %s", CloudURLToString(bucket.BucketName, object.Key), object.VersionId), "")
 		}
+		return num, nil
+	}
 
-		if err != nil {
-			serviceError, noNeedRetry := err.(oss.ServiceError)
-			if int64(i) >= retryTimes || (noNeedRetry && serviceError.StatusCode < 500) {
-				return deletedNum, fmt.Errorf("%s,delete versioning objects: %#v failed", err.Error(), objectVersions)
-			}
+	deletedNum := 0
+	listOptions := append(rc.commonOptions, oss.DeleteObjectsQuiet(true))
+	err := rc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		delRes, err := bucket.DeleteObjectVersions(objectVersions, withResponseHeader(listOptions, respHeader)...)
+		// when 4XX,5XX error,delRes.DeletedObjectsDetail is empty
+		if len(delRes.DeletedObjectsDetail) > 0 || err == nil {
+			deletedNum += (len(objectVersions) - len(delRes.DeletedObjectsDetail))
 		}
-
-		if getFailedObject {
+		if len(delRes.DeletedObjectsDetail) > 0 {
 			objectVersions = make([]oss.DeleteObject, 0)
 			for _, object := range delRes.DeletedObjectsDetail {
 				objectVersions = append(objectVersions, oss.DeleteObject{
@@ -1197,6 +1377,15 @@ func (rc *RemoveCommand) ossBatchDeleteObjectsRetryVersion(bucket *oss.Bucket, o
 					VersionId: object.VersionId,
 				})
 			}
+			if err == nil {
+				// the versions failed to delete are deleted again by the policy, as many times as the request
+				return fmt.Errorf("%d versioning objects are not deleted", len(objectVersions))
+			}
 		}
+		return err
+	})
+	if err != nil {
+		return deletedNum, fmt.Errorf("%s,delete versioning objects: %#v failed", err.Error(), objectVersions)
 	}
+	return deletedNum, nil
 }
@@ -136,6 +136,44 @@ func (s *OssutilCommandSuite) TestRemoveObjects(c *C) {
 	s.removeBucket(bucketName, true, c)
 }
 
+func (s *OssutilCommandSuite) TestRemoveObjectsDryRun(c *C) {
+	bucketName := bucketNamePrefix + randLowStr(10)
+	s.putBucket(bucketName, c)
+
+	// put object
+	num := 2
+	for i := 0; i < num; i++ {
+		object := fmt.Sprintf("remove%d", i)
+		s.putObject(bucketName, object, uploadFileName, c)
+	}
+
+	command := "rm"
+	args := []string{CloudURLToString(bucketName, "")}
+	str := ""
+	ok := true
+	options := OptionMapType{
+		"endpoint":        &str,
+		"accessKeyID":     &str,
+		"accessKeySecret": &str,
+		"stsToken":        &str,
+		"configFile":      &configFile,
+		"recursive":       &ok,
+		"bucket":          &ok,
+		"dryRun":          &ok,
+	}
+
+	// remove nothing without prompt
+	_, err := cm.RunCommand(command, args, options)
+	c.Assert(err, IsNil)
+	c.Assert(removeCommand.monitor.objectNum, Equals, int64(num))
+
+	objects := s.listObjects(bucketName, "", "ls - ", c)
+	c.Assert(len(objects), Equals, num)
+	s.getStat(bucketName, "remove0", c)
+
+	s.removeBucket(bucketName, true, c)
+}
+
 func (s *OssutilCommandSuite) TestRemoveObjectBucketOption(c *C) {
 	bucketName := bucketNamePrefix + randLowStr(10)
 	s.putBucket(bucketName, c)
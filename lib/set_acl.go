@@ -2,6 +2,7 @@ package lib
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 
@@ -61,7 +62,7 @@ var specChineseSetACL = SpecText{
 	paramText: "cloud_url [acl] [options]",
 
 	syntaxText: ` 
-    ossutil set-acl oss://bucket[/prefix] [acl] [-r] [-b] [-f] [-c file] [--version-id versionId]
+    ossutil set-acl oss://bucket[/prefix] [acl] [-r] [-b] [-f] [-c file] [--version-id versionId] [--manifest file]
 `,
 
 	detailHelpText: ` 
@@ -110,7 +111,10 @@ ACL：
     如果--force选项被指定，则不会进行询问提示。如果用户在命令行中缺失acl信息，会进入交互模式，询问
     用户的acl信息。
         如果指定了--include/--exclude选项，ossutil会查找所有匹配pattern的objects，批量设置。
-        --include和--exclude选项说明，请参考cp命令帮助。
+        --include、--exclude、--include-regex、--exclude-regex和--exclude-from选项说明，请参考cp命令帮助。
+        如果指定了--manifest选项，ossutil从清单中读取prefix下的objects批量设置，而不是列举bucket，
+    此时可以不指定--recursive选项，清单中指定了版本号时设置该版本的acl。--manifest选项说明，请参考
+    cp命令帮助。
 `,
 
 	sampleText: ` 
@@ -125,6 +129,8 @@ ACL：
     (4)ossutil set-acl oss://bucket1/%e4%b8%ad%e6%96%87 default --encoding-type url
 
     (5)ossutil set-acl oss://bucket1/obj1 private --version-id versionId
+
+    (6)ossutil set-acl oss://bucket1 private --manifest objects.csv -f
 `,
 }
 
@@ -135,7 +141,7 @@ var specEnglishSetACL = SpecText{
 	paramText: "cloud_url [acl] [options]",
 
 	syntaxText: ` 
-    ossutil set-acl oss://bucket[/prefix] [acl] [-r] [-b] [-f] [-c file] [--version-id versionId]
+    ossutil set-acl oss://bucket[/prefix] [acl] [-r] [-b] [-f] [-c file] [--version-id versionId] [--manifest file]
 `,
 
 	detailHelpText: ` 
@@ -193,7 +199,11 @@ Usage：
     ossutil will enter interactive mode and ask you for it. 
         If --include/--exclude option is specified, ossutil will search for pattern-matching 
     objects and set meta on those objects.
-        --include and --exclude option, please refer cp command help.
+        --include, --exclude, --include-regex, --exclude-regex and --exclude-from option, please refer cp command help.
+        If --manifest option is specified, ossutil reads the objects under the prefix from 
+    the manifest instead of listing the bucket, --recursive option can be omitted then, and 
+    the acl is set on the version if the manifest specifies it. --manifest option, please 
+    refer cp command help.
 `,
 
 	sampleText: ` 
@@ -208,6 +218,8 @@ Usage：
     (4)ossutil set-acl oss://bucket1/%e4%b8%ad%e6%96%87 default --encoding-type url
 
     (5)ossutil set-acl oss://bucket1/obj1 private --version-id versionId
+
+    (6)ossutil set-acl oss://bucket1 private --manifest objects.csv -f
 `,
 }
 
@@ -217,6 +229,7 @@ type SetACLCommand struct {
 	command  Command
 	saOption batchOptionType
 	filters  []filterOptionType
+	manifest *manifestSource
 }
 
 var setACLCommand = SetACLCommand{
@@ -233,9 +246,15 @@ var setACLCommand = SetACLCommand{
 			OptionBucket,
 			OptionForce,
 			OptionEncodingType,
+			OptionManifest,
+			OptionManifestFormat,
 			OptionConfigFile,
+			OptionProfile,
 			OptionInclude,
 			OptionExclude,
+			OptionIncludeRegex,
+			OptionExcludeRegex,
+			OptionExcludeFrom,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -244,6 +263,9 @@ var setACLCommand = SetACLCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionRoutines,
 			OptionOutputDir,
 			OptionLogLevel,
@@ -257,6 +279,10 @@ var setACLCommand = SetACLCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
@@ -291,12 +317,16 @@ func (sc *SetACLCommand) RunCommand() error {
 	routines, _ := GetInt(OptionRoutines, sc.command.options)
 	encodingType, _ := GetString(OptionEncodingType, sc.command.options)
 	versionId, _ := GetString(OptionVersionId, sc.command.options)
+	// the objects of the manifest are set in batch as the recursive ones
+	if manifest, _ := GetString(OptionManifest, sc.command.options); manifest != "" {
+		recursive = true
+	}
 
-	var res bool
-	res, sc.filters = getFilter(os.Args)
-	if !res {
-		return fmt.Errorf("--include or --exclude does not support format containing dir info")
+	filters, err := getFilter(os.Args)
+	if err != nil {
+		return err
 	}
+	sc.filters = filters
 
 	if !recursive && len(sc.filters) > 0 {
 		return fmt.Errorf("--include or --exclude only work with --recursive")
@@ -320,6 +350,13 @@ func (sc *SetACLCommand) RunCommand() error {
 		return err
 	}
 
+	if sc.manifest, err = sc.command.openManifest(cloudURL); err != nil {
+		return err
+	}
+	if sc.manifest != nil && toBucket {
+		return fmt.Errorf("--manifest only work on objects, it can't be used with --bucket")
+	}
+
 	if toBucket {
 		return sc.setBucketACL(&bucket.Client, cloudURL, recursive)
 	}
@@ -392,16 +429,13 @@ func (cmd *Command) checkACL(acl string, aclType setACLType) (oss.ACLType, error
 }
 
 func (sc *SetACLCommand) ossSetBucketACLRetry(client *oss.Client, bucket string, acl oss.ACLType) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, sc.command.options)
-	for i := 1; ; i++ {
-		err := client.SetBucketACL(bucket, acl)
-		if err == nil {
-			return err
-		}
-		if int64(i) >= retryTimes {
-			return BucketError{err, bucket}
-		}
+	err := sc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		return client.SetBucketACL(bucket, acl, oss.GetResponseHeader(respHeader))
+	})
+	if err != nil {
+		return BucketError{err, bucket}
 	}
+	return nil
 }
 
 func (sc *SetACLCommand) setObjectACL(bucket *oss.Bucket, cloudURL CloudURL, versionId string) error {
@@ -418,20 +452,17 @@ func (sc *SetACLCommand) setObjectACL(bucket *oss.Bucket, cloudURL CloudURL, ver
 }
 
 func (sc *SetACLCommand) ossSetObjectACLRetry(bucket *oss.Bucket, object string, acl oss.ACLType, versionId string) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, sc.command.options)
-	for i := 1; ; i++ {
-		var options []oss.Option
-		if len(versionId) > 0 {
-			options = append(options, oss.VersionId(versionId))
-		}
-		err := bucket.SetObjectACL(object, acl, options...)
-		if err == nil {
-			return err
-		}
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucket.BucketName, object}
-		}
+	var options []oss.Option
+	if len(versionId) > 0 {
+		options = append(options, oss.VersionId(versionId))
 	}
+	err := sc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		return bucket.SetObjectACL(object, acl, withResponseHeader(options, respHeader)...)
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, object}
+	}
+	return nil
 }
 
 func (sc *SetACLCommand) batchSetObjectACL(bucket *oss.Bucket, cloudURL CloudURL, force bool, routines int64) error {
@@ -458,6 +489,9 @@ func (sc *SetACLCommand) batchSetObjectACL(bucket *oss.Bucket, cloudURL CloudURL
 	}
 	defer sc.saOption.reporter.Clear()
 
+	if sc.manifest != nil {
+		return sc.setManifestObjectACLs(bucket, acl, routines)
+	}
 	return sc.setObjectACLs(bucket, cloudURL, acl, force, routines)
 }
 
@@ -476,9 +510,37 @@ func (sc *SetACLCommand) setObjectACLs(bucket *oss.Bucket, cloudURL CloudURL, ac
 	return sc.waitRoutinueComplete(chError, chListError, routines)
 }
 
+// setManifestObjectACLs sets acl on the objects read from the manifest, on the versions it specifies
+func (sc *SetACLCommand) setManifestObjectACLs(bucket *oss.Bucket, acl oss.ACLType, routines int64) error {
+	chEntries := make(chan ManifestEntry, ChannelBuf)
+	chError := make(chan error, routines+1)
+	chListError := make(chan error, 1)
+	go sc.command.manifestProducer(sc.manifest, sc.filters, chEntries, chListError, &sc.monitor)
+	for i := 0; int64(i) < routines; i++ {
+		go sc.setManifestObjectACLConsumer(bucket, acl, chEntries, chError)
+	}
+
+	return sc.waitRoutinueComplete(chError, chListError, routines)
+}
+
+func (sc *SetACLCommand) setManifestObjectACLConsumer(bucket *oss.Bucket, acl oss.ACLType, chEntries <-chan ManifestEntry, chError chan<- error) {
+	for entry := range chEntries {
+		err := sc.setObjectACLWithReport(bucket, entry.Key, entry.VersionId, acl)
+		if err != nil {
+			chError <- err
+			if !sc.saOption.ctnu {
+				return
+			}
+			continue
+		}
+	}
+
+	chError <- nil
+}
+
 func (sc *SetACLCommand) setObjectACLConsumer(bucket *oss.Bucket, acl oss.ACLType, chObjects <-chan string, chError chan<- error) {
 	for object := range chObjects {
-		err := sc.setObjectACLWithReport(bucket, object, acl)
+		err := sc.setObjectACLWithReport(bucket, object, "", acl)
 		if err != nil {
 			chError <- err
 			if !sc.saOption.ctnu {
@@ -491,8 +553,8 @@ func (sc *SetACLCommand) setObjectACLConsumer(bucket *oss.Bucket, acl oss.ACLTyp
 	chError <- nil
 }
 
-func (sc *SetACLCommand) setObjectACLWithReport(bucket *oss.Bucket, object string, acl oss.ACLType) error {
-	err := sc.ossSetObjectACLRetry(bucket, object, acl, "")
+func (sc *SetACLCommand) setObjectACLWithReport(bucket *oss.Bucket, object, versionId string, acl oss.ACLType) error {
+	err := sc.ossSetObjectACLRetry(bucket, object, acl, versionId)
 	sc.command.updateMonitor(err, &sc.monitor)
 	msg := fmt.Sprintf("set acl on %s", CloudURLToString(bucket.BucketName, object))
 	sc.command.report(msg, err, &sc.saOption)
@@ -682,8 +682,8 @@ func (s *OssutilCommandSuite) TestSetObjectAclWithNonSequenceIncludeEx(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(showElapse, Equals, true)
 
-	res, filters := getFilter(cmdline)
-	c.Assert(res, Equals, true)
+	filters, err := getFilter(cmdline)
+	c.Assert(err, IsNil)
 	inFiles := filterStrsWithInclude(objs, filters[0].pattern)
 	exFiles := filterStrsWithExclude(objs, filters[0].pattern)
 
@@ -928,8 +928,8 @@ func (s *OssutilCommandSuite) TestSetObjectAclWithNonSequenceExcludeEx(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(showElapse, Equals, true)
 
-	res, filters := getFilter(cmdline)
-	c.Assert(res, Equals, true)
+	filters, err := getFilter(cmdline)
+	c.Assert(err, IsNil)
 	inFiles := filterStrsWithInclude(objs, filters[0].pattern)
 	exFiles := filterStrsWithExclude(objs, filters[0].pattern)
 
@@ -1197,7 +1197,7 @@ func (s *OssutilCommandSuite) TestSetObjectAclWithInvalidIncExc(c *C) {
 	cmdline = []string{"ossutil", "set-acl", bucketStr, acl, "-f", "--include", "/*.txt", "--exclude", "*2*"}
 	showElapse, err = s.rawSetAclWithFilter(args, false, true, cmdline)
 	c.Assert(showElapse, Equals, false)
-	c.Assert(err.Error() == "--include or --exclude does not support format containing dir info", Equals, true)
+	c.Assert(err.Error() == "--include or --exclude only work with --recursive", Equals, true)
 
 	os.RemoveAll(dir)
 	s.removeBucket(bucketName, true, c)
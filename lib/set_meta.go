@@ -89,7 +89,7 @@ var specChineseSetMeta = SpecText{
 	paramText: "cloud_url [meta] [options]",
 
 	syntaxText: ` 
-    ossutil set-meta oss://bucket[/prefix] [header:value#header:value...] [--update] [--delete] [-r] [-f] [-c file] [--version-id versionId] [--object-file file] [--snapshot-path dir] [--disable-ignore-error]
+    ossutil set-meta oss://bucket[/prefix] [header:value#header:value...] [--update] [--delete] [-r] [-f] [-c file] [--version-id versionId] [--object-file file] [--snapshot-path dir] [--disable-ignore-error] [--dryrun] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types]
 `,
 
 	detailHelpText: ` 
@@ -113,6 +113,10 @@ var specChineseSetMeta = SpecText{
     该命令不支持bucket的meta设置，需要设置bucket的meta信息，请使用bucket相关操作。
     查看bucket或者object的meta信息，请使用stat命令。
 
+    批量设置时，可以使用--min-size、--max-size、--older-than、--newer-than、--storage-class、
+    --has-tag和--content-type选项按大小、最后修改时间、存储方式、标签和Content-Type筛选objects，
+    选项的格式请参考ls命令的帮助。
+
 Headers:
 
     可选的header列表如下：
@@ -138,7 +142,7 @@ Headers:
     文件，并继续操作其他object，成功操作的object信息将不会被记录到report文件中（更多信息
     见cp命令的帮助）。
         如果指定了--include/--exclude选项，ossutil会查找所有匹配pattern的objects，批量设置。
-        --include和--exclude选项说明，请参考cp命令帮助。
+        --include、--exclude、--include-regex、--exclude-regex和--exclude-from选项说明，请参考cp命令帮助。
         如果--force选项被指定，则不会进行询问提示。
         --update选项和--delete选项的用法参考上文。
 
@@ -151,6 +155,9 @@ Headers:
         快照，则忽略本次操作。（仅支持在-r、--object-file基础上）
         如果--force选项被指定，则不会进行询问提示。
         --update选项和--delete选项的用法参考上文。
+
+    如果指定了--dryrun选项，ossutil只列举并输出将要设置meta的objects，不会实际修改任何object，
+    也不会进行询问提示和更新快照信息。
 `,
 
 	sampleText: ` 
@@ -193,7 +200,7 @@ var specEnglishSetMeta = SpecText{
 	paramText: "cloud_url [meta] [options]",
 
 	syntaxText: ` 
-    ossutil set-meta oss://bucket[/prefix] [header:value#header:value...] [--update] [--delete] [-r] [-f] [-c file] [--version-id versionId] [--object-file file] [--snapshot-path dir] [--disable-ignore-error]
+    ossutil set-meta oss://bucket[/prefix] [header:value#header:value...] [--update] [--delete] [-r] [-f] [-c file] [--version-id versionId] [--object-file file] [--snapshot-path dir] [--disable-ignore-error] [--dryrun] [--min-size size] [--max-size size] [--older-than time] [--newer-than time] [--storage-class classes] [--has-tag tags] [--content-type types]
 `,
 
 	detailHelpText: ` 
@@ -224,6 +231,10 @@ var specEnglishSetMeta = SpecText{
     The meta data of bucket can not be setted by the command, please use other commands. 
     User can use stat command to check the meta information of bucket or objects.
 
+    When setting in batch, --min-size, --max-size, --older-than, --newer-than, --storage-class, 
+    --has-tag and --content-type options can filter the objects by size, last modified time, 
+    storage class, tags and content type, see the help of ls command for the format of them.
+
 Headers:
 
     ossutil supports following headers:
@@ -250,7 +261,7 @@ Usage:
     more information see help of cp command). 
         If --include/--exclude option is specified, ossutil will search for pattern-matching objects and 
     set meta on those objects. 
-	    --include and --exclude option, please refer cp command help.
+	    --include, --exclude, --include-regex, --exclude-regex and --exclude-from option, please refer cp command help.
         If --force option is specified, ossutil will not show prompt question.
         The usage of --update option and --delete option is showed in detailHelpText.
 
@@ -272,6 +283,9 @@ Usage:
 		and if the snapshot exists, then cancel this operate.
         If --force option is specified, ossutil will not show prompt question.
         The usage of --update option and --delete option is showed in detailHelpText.
+
+    If --dryrun option is specified, ossutil only lists and prints the objects to set meta on, 
+    without modifying any object, showing prompt question or updating the snapshot.
 `,
 
 	sampleText: ` 
@@ -335,7 +349,18 @@ var setMetaCommand = SetMetaCommand{
 			OptionEncodingType,
 			OptionInclude,
 			OptionExclude,
+			OptionIncludeRegex,
+			OptionExcludeRegex,
+			OptionExcludeFrom,
+			OptionMinSize,
+			OptionMaxSize,
+			OptionOlderThan,
+			OptionNewerThan,
+			OptionStorageClass,
+			OptionHasTag,
+			OptionContentType,
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -344,6 +369,9 @@ var setMetaCommand = SetMetaCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionRoutines,
 			OptionLanguage,
 			OptionOutputDir,
@@ -358,6 +386,10 @@ var setMetaCommand = SetMetaCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionObjectFile,
@@ -367,6 +399,7 @@ var setMetaCommand = SetMetaCommand{
 			OptionRegion,
 			OptionCloudBoxID,
 			OptionForcePathStyle,
+			OptionDryRun,
 		},
 	},
 }
@@ -387,7 +420,12 @@ func (sc *SetMetaCommand) Init(args []string, options OptionMapType) error {
 
 // RunCommand simulate inheritance, and polymorphism
 func (sc *SetMetaCommand) RunCommand() error {
-	sc.monitor.init("Setted meta on")
+	sc.smOption.dryRun = sc.command.isDryRun()
+	if sc.smOption.dryRun {
+		sc.monitor.init("Would set meta on")
+	} else {
+		sc.monitor.init("Setted meta on")
+	}
 	isUpdate, _ := GetBool(OptionUpdate, sc.command.options)
 	isDelete, _ := GetBool(OptionDelete, sc.command.options)
 	recursive, _ := GetBool(OptionRecursion, sc.command.options)
@@ -414,7 +452,7 @@ func (sc *SetMetaCommand) RunCommand() error {
 	if err != nil {
 		return err
 	}
-	if err := sc.checkOptions(cloudURL, isUpdate, isDelete, force, recursive, language, versionId, objFileXml); err != nil {
+	if err := sc.checkOptions(cloudURL, isUpdate, isDelete, force || sc.smOption.dryRun, recursive, language, versionId, objFileXml); err != nil {
 		return err
 	}
 	bucket, err := sc.command.ossBucket(cloudURL.bucket)
@@ -469,16 +507,24 @@ func (sc *SetMetaCommand) checkOptions(cloudURL CloudURL, isUpdate, isDelete, fo
 		}
 	}
 
-	var res bool
-	res, sc.filters = getFilter(os.Args)
-	if !res {
-		return fmt.Errorf("--include or --exclude does not support format containing dir info")
+	filters, err := getFilter(os.Args)
+	if err != nil {
+		return err
 	}
+	sc.filters = filters
 
 	if !recursive && len(sc.filters) > 0 {
 		return fmt.Errorf("--include or --exclude only work with --recursive")
 	}
 
+	attrFilter, err := sc.command.attributeFilter()
+	if err != nil {
+		return err
+	}
+	if attrFilter != nil && (!recursive || objFileXml != "") {
+		return fmt.Errorf("--min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag and --content-type only work with --recursive, and can't be used with --object-file")
+	}
+
 	if (recursive && len(versionId) > 0) || (objFileXml != "" && len(versionId) > 0) {
 		return fmt.Errorf("--version-id only work on single object")
 	}
@@ -610,6 +656,9 @@ func (sc *SetMetaCommand) setObjectMeta(bucket *oss.Bucket, object string, heade
 		allheaders, isSkip = sc.mergeHeader(props, headers, isUpdate, isDelete)
 		if isSkip {
 			atomic.AddUint64(&sc.skipCount, uint64(1))
+			if sc.smOption.dryRun {
+				printDryRun("skip set meta on "+CloudURLToString(bucket.BucketName, object), "meta is not changed")
+			}
 			return nil
 		}
 	}
@@ -678,18 +727,21 @@ func (sc *SetMetaCommand) mergeHeader(props http.Header, headers map[string]stri
 }
 
 func (sc *SetMetaCommand) ossSetObjectMetaRetry(bucket *oss.Bucket, object string, options ...oss.Option) error {
-	retryTimes, _ := GetInt(OptionRetryTimes, sc.command.options)
+	if sc.smOption.dryRun {
+		printDryRun("set meta on "+CloudURLToString(bucket.BucketName, object), "")
+		return nil
+	}
+
 	cpOptions := append(options, oss.MetadataDirective(oss.MetaReplace))
 
-	for i := 1; ; i++ {
-		_, err := bucket.CopyObject(object, object, cpOptions...)
-		if err == nil {
-			return nil
-		}
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucket.BucketName, object}
-		}
+	err := sc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		_, err := bucket.CopyObject(object, object, withResponseHeader(cpOptions, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return ObjectError{err, bucket.BucketName, object}
 	}
+	return nil
 }
 
 func (sc *SetMetaCommand) batchSetObjectMeta(bucket *oss.Bucket, cloudURL CloudURL, headers map[string]string, isUpdate, isDelete, force bool, routines int64) error {
@@ -896,7 +948,7 @@ func (sc *SetMetaCommand) skipSetMeta(spath string) bool {
 }
 
 func (sc *SetMetaCommand) updateSnapshot(err error, spath string, srct int64) error {
-	if sc.smOption.snapshotPath != "" && err == nil {
+	if sc.smOption.snapshotPath != "" && err == nil && !sc.smOption.dryRun {
 		srctstr := fmt.Sprintf("%d", srct)
 		err := sc.smOption.snapshotldb.Put([]byte(spath), []byte(srctstr), nil)
 		if err != nil {
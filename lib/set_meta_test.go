@@ -1099,8 +1099,8 @@ func (s *OssutilCommandSuite) TestSetObjectMetaWithNonSequenceIncludeEx(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(showElapse, Equals, true)
 
-	res, filters := getFilter(cmdline)
-	c.Assert(res, Equals, true)
+	filters, err := getFilter(cmdline)
+	c.Assert(err, IsNil)
 	inFiles := filterStrsWithInclude(objs, filters[0].pattern)
 	exFiles := filterStrsWithExclude(objs, filters[0].pattern)
 
@@ -1380,8 +1380,8 @@ func (s *OssutilCommandSuite) TestSetObjectMetaWithNonSequenceExcludeEx(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(showElapse, Equals, true)
 
-	res, filters := getFilter(cmdline)
-	c.Assert(res, Equals, true)
+	filters, err := getFilter(cmdline)
+	c.Assert(err, IsNil)
 	inFiles := filterStrsWithInclude(objs, filters[0].pattern)
 	exFiles := filterStrsWithExclude(objs, filters[0].pattern)
 
@@ -1681,7 +1681,7 @@ func (s *OssutilCommandSuite) TestSetObjectMetaWithInvalidIncExc(c *C) {
 	cmdline = []string{"ossutil", "cp", dir, bucketStr, "-f", "--include", "*.txt", "--exclude", "/*2*"}
 	showElapse, err = s.rawCPWithFilter(args, false, true, false, DefaultBigFileThreshold, CheckpointDir, cmdline, "", "")
 	c.Assert(showElapse, Equals, false)
-	c.Assert(err.Error() == "--include or --exclude does not support format containing dir info", Equals, true)
+	c.Assert(err.Error() == "--include or --exclude only work with --recursive", Equals, true)
 
 	// cleanup
 	os.RemoveAll(dir)
@@ -1,20 +1,27 @@
 package lib
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
 )
 
 var specChineseSignurl = SpecText{
 
-	synopsisText: "生成object下载链接",
+	synopsisText: "生成object下载或上传链接",
 
 	paramText: "cloud_url [meta] [options]",
 
 	syntaxText: ` 
-    ossutil sign cloud_url [--timeout t] [--version-id versionId] [--trafic-limit limitSpeed] [--disable-encode-slash] [--payer requester] [--query-param key:value]
+    ossutil sign cloud_url [--timeout t] [--version-id versionId] [--trafic-limit limitSpeed] [--disable-encode-slash] [--payer requester] [--query-param key:value] [--method GET|PUT|HEAD] [--meta meta]
+    ossutil sign cloud_url -r [--include/--exclude pattern] [--output-format json|jsonl|csv] [--method GET|PUT|HEAD] [--timeout t]
+    ossutil sign cloud_url --upload-id uploadId --part-number n[-m] [--timeout t]
+    ossutil sign cloud_url --post-policy [--content-length-range min-max] [--meta meta] [--timeout t]
 `,
 
 	detailHelpText: ` 
@@ -22,9 +29,43 @@ var specChineseSignurl = SpecText{
     必须为形如：oss://bucket/object的cloud_url，bucket和object不可缺少。通过--timeout选项指
     定url的过期时间，默认为60s。通过--version-id选项指定版本号。
 
+    如果指定了--manifest选项，ossutil从清单中读取objects，每个object输出一行签名url，此时
+    cloud_url为oss://bucket[/prefix]，只处理prefix下的objects，版本号由清单指定。
+
+    如果指定了--recursive选项，ossutil列举cloud_url前缀下的所有objects，为每个object生成签名url，
+    此时cloud_url为oss://bucket[/prefix]。可以通过--include、--exclude、--min-size等选项过滤
+    objects，用法同ls命令，其中--include、--exclude选项也可以用于过滤--manifest中的objects。
+
+    默认每行输出一个签名url。如果指定了--output-format选项，ossutil以json、jsonl或csv格式为每个
+    签名url输出一条记录，包括bucket、key、version_id、method、url以及expiration，其中expiration
+    为url的过期时间(UTC)，便于生成分享列表。
+
+    通过--method选项指定签名url的请求方法，取值为GET、PUT、HEAD，默认为GET。指定PUT时生成上传链接，
+    可以通过--meta选项指定Content-Type、x-oss-meta-*等header，这些header会被签名，使用该url上传时
+    必须携带相同的header。--meta选项的格式同cp命令。
+
+    如果指定了--upload-id和--part-number选项，ossutil生成上传指定分片的签名url（方法为PUT），
+    --part-number可以为分片号或者范围如1-100，每个分片输出一行签名url。uploadID需要已经存在。
+
+    如果指定了--post-policy选项，ossutil生成浏览器表单上传(PostObject)的policy和签名，以json格式输出
+    表单提交的url和字段，此时cloud_url为oss://bucket[/prefix]，上传的object名必须以prefix开头，
+    字段key为prefix${filename}，可以自行修改为prefix开头的其它值。policy的过期时间由--timeout指定，
+    --content-length-range指定允许上传的文件大小范围，--meta指定的header要求表单字段取值完全相同。
+    签名版本和sign-version一致，v4签名时使用OSS4-HMAC-SHA256，否则使用v1签名。
+
 用法：
 
     ossutil sign oss://bucket/object [--timeout t] [--version-id versionId] [--trafic-limit limitSpeed] [--disable-encode-slash] [--payer requester] [--query-param key:value]
+
+    ossutil sign oss://bucket[/prefix] --manifest file [--manifest-format format] [--timeout t]
+
+    ossutil sign oss://bucket[/prefix] -r [--include/--exclude pattern] [--output-format json|jsonl|csv] [--timeout t]
+
+    ossutil sign oss://bucket/object --method PUT [--meta meta] [--timeout t]
+
+    ossutil sign oss://bucket/object --upload-id uploadId --part-number n[-m] [--timeout t]
+
+    ossutil sign oss://bucket[/prefix] --post-policy [--content-length-range min-max] [--meta meta] [--timeout t]
 `,
 
 	sampleText: ` 
@@ -51,17 +92,35 @@ var specChineseSignurl = SpecText{
 
     ossutil sign oss://bucket1/object1.jpg  --query-param x-oss-process:image/resize,m_fixed,w_100,h_100/rotate,90
         生成处理过的图片 oss://bucket1/dir/object1.jpg的签名url 
+
+    ossutil sign oss://bucket1 --manifest keys.txt --timeout 3600
+        为keys.txt中的每个object生成签名url，超时时间3600s
+
+    ossutil sign oss://bucket1/release/v1.0/ -r --exclude "*.tmp" --output-format csv --timeout 604800
+        为oss://bucket1/release/v1.0/下除*.tmp外的每个object生成签名url，超时时间7天，以csv格式输出key、url和过期时间
+
+    ossutil sign oss://bucket1/upload/a.jpg --method PUT --meta Content-Type:image/jpeg#x-oss-meta-owner:partner1 --timeout 3600
+        生成上传oss://bucket1/upload/a.jpg的签名url，上传时必须携带指定的Content-Type和x-oss-meta-owner
+
+    ossutil sign oss://bucket1/big.zip --upload-id 0004B9895DBBB6EC98E36 --part-number 1-10
+        生成上传分片1到10的签名url
+
+    ossutil sign oss://bucket1/partner1/ --post-policy --content-length-range 0-10M --timeout 3600
+        生成表单上传到oss://bucket1/partner1/下，文件不超过10M的policy和签名
 `,
 }
 
 var specEnglishSignurl = SpecText{
 
-	synopsisText: "Generate download link for object",
+	synopsisText: "Generate download or upload link for object",
 
 	paramText: "cloud_url [options]",
 
 	syntaxText: ` 
-    ossutil sign cloud_url [--timeout t] [--version-id versionId] [--trafic-limit limitSpeed] [--disable-encode-slash] [--payer requester] [--query-param key:value]
+    ossutil sign cloud_url [--timeout t] [--version-id versionId] [--trafic-limit limitSpeed] [--disable-encode-slash] [--payer requester] [--query-param key:value] [--method GET|PUT|HEAD] [--meta meta]
+    ossutil sign cloud_url -r [--include/--exclude pattern] [--output-format json|jsonl|csv] [--method GET|PUT|HEAD] [--timeout t]
+    ossutil sign cloud_url --upload-id uploadId --part-number n[-m] [--timeout t]
+    ossutil sign cloud_url --post-policy [--content-length-range min-max] [--meta meta] [--timeout t]
 `,
 
 	detailHelpText: ` 
@@ -74,10 +133,44 @@ var specEnglishSignurl = SpecText{
     use --disable-encode-slash to specify not encoding of '/' in url path section
     use --payer to specify request payment
     use --query-param to specify the query parameters, can be passed multiple times.
+    Use --manifest to sign the objects read from the manifest, one signed url per line,
+    cloud_url is oss://bucket[/prefix] then and only the objects under the prefix are signed,
+    the version of each object is specified by the manifest.
+    Use --recursive to sign each object listed under the prefix of cloud_url, cloud_url is
+    oss://bucket[/prefix] then, the objects can be filtered by --include, --exclude, --min-size
+    and so on as the ls command does, --include and --exclude can filter the objects read from
+    --manifest too.
+    One signed url is printed per line by default. Use --output-format to print a record of each
+    signed url in json, jsonl or csv format, with the bucket, key, version_id, method, url and
+    expiration, which is the time the url expires in UTC, so that it can be published as a share list.
+    Use --method to specify the method of the signed url, the value is GET, PUT or HEAD, the
+    default is GET. With PUT the url is an upload link, --meta specifies the headers like
+    Content-Type and x-oss-meta-* in the same format as the cp command, they are signed, so the
+    upload with the url must carry the same headers.
+    Use --upload-id and --part-number to sign the urls of uploading the parts of an existing
+    multipart upload by PUT, --part-number is a part number or a range like 1-100, one signed url
+    per part.
+    Use --post-policy to generate the policy and signature of the browser form upload(PostObject),
+    the url and the fields of the form are printed as json, cloud_url is oss://bucket[/prefix] then
+    and the key to upload must start with the prefix, the field key is prefix${filename}, which can
+    be changed to other values starting with the prefix. The policy expires after --timeout,
+    --content-length-range limits the size of the file, the form fields must be exactly the values
+    of the headers specified by --meta. The policy is signed by OSS4-HMAC-SHA256 if the sign
+    version is v4, otherwise by the signature v1.
 
 Usage:
 
     ossutil sign oss://bucket/object [--timeout t] [--version-id versionId] [--trafic-limit limitSpeed] [--disable-encode-slash] [--payer requester] [--query-param key:value]
+
+    ossutil sign oss://bucket[/prefix] --manifest file [--manifest-format format] [--timeout t]
+
+    ossutil sign oss://bucket[/prefix] -r [--include/--exclude pattern] [--output-format json|jsonl|csv] [--timeout t]
+
+    ossutil sign oss://bucket/object --method PUT [--meta meta] [--timeout t]
+
+    ossutil sign oss://bucket/object --upload-id uploadId --part-number n[-m] [--timeout t]
+
+    ossutil sign oss://bucket[/prefix] --post-policy [--content-length-range min-max] [--meta meta] [--timeout t]
 `,
 
 	sampleText: ` 
@@ -104,6 +197,21 @@ Usage:
 
     ossutil sign oss://bucket1/object1.jpg  --query-param x-oss-process:image/resize,m_fixed,w_100,h_100/rotate,90
 		Generate the signature of processed picture oss://bucket1/dir/object1.jpg
+
+    ossutil sign oss://bucket1 --manifest keys.txt --timeout 3600
+        Generate the signature of each object in keys.txt with expire time 3600s
+
+    ossutil sign oss://bucket1/release/v1.0/ -r --exclude "*.tmp" --output-format csv --timeout 604800
+        Generate the signature of each object except *.tmp under oss://bucket1/release/v1.0/ with expire time 7 days, print the key, url and expiration in csv format
+
+    ossutil sign oss://bucket1/upload/a.jpg --method PUT --meta Content-Type:image/jpeg#x-oss-meta-owner:partner1 --timeout 3600
+        Generate the upload link of oss://bucket1/upload/a.jpg, the upload must carry the Content-Type and x-oss-meta-owner
+
+    ossutil sign oss://bucket1/big.zip --upload-id 0004B9895DBBB6EC98E36 --part-number 1-10
+        Generate the signed urls of uploading the part 1 to 10
+
+    ossutil sign oss://bucket1/partner1/ --post-policy --content-length-range 0-10M --timeout 3600
+        Generate the policy and signature of the form upload to oss://bucket1/partner1/, the file size is at most 10M
 `,
 }
 
@@ -111,8 +219,11 @@ Usage:
 type SignurlCommand struct {
 	command Command
 	signUrl string
+	output  *OutputWriter
 }
 
+var signURLColumns = []string{"bucket", "key", "version_id", "method", "url", "expiration"}
+
 var signURLCommand = SignurlCommand{
 	command: Command{
 		name:        "sign",
@@ -125,7 +236,30 @@ var signURLCommand = SignurlCommand{
 		validOptionNames: []string{
 			OptionTimeout,
 			OptionEncodingType,
+			OptionManifest,
+			OptionManifestFormat,
+			OptionMethod,
+			OptionMeta,
+			OptionUploadID,
+			OptionPartNumber,
+			OptionPostPolicy,
+			OptionContentLengthRange,
+			OptionRecursion,
+			OptionInclude,
+			OptionExclude,
+			OptionIncludeRegex,
+			OptionExcludeRegex,
+			OptionExcludeFrom,
+			OptionMinSize,
+			OptionMaxSize,
+			OptionOlderThan,
+			OptionNewerThan,
+			OptionStorageClass,
+			OptionHasTag,
+			OptionContentType,
+			OptionOutputFormat,
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -143,6 +277,10 @@ var signURLCommand = SignurlCommand{
 			OptionRamRoleArn,
 			OptionRoleSessionName,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionUserAgent,
 			OptionQueryParam,
 			OptionSignVersion,
@@ -170,13 +308,47 @@ func (sc *SignurlCommand) Init(args []string, options OptionMapType) error {
 // RunCommand simulate inheritance, and polymorphism
 func (sc *SignurlCommand) RunCommand() error {
 	encodingType, _ := GetString(OptionEncodingType, sc.command.options)
-	cloudURL, err := ObjectURLFromString(sc.command.args[0], encodingType)
+	manifest, _ := GetString(OptionManifest, sc.command.options)
+	postPolicy, _ := GetBool(OptionPostPolicy, sc.command.options)
+	recursive, _ := GetBool(OptionRecursion, sc.command.options)
+	var cloudURL CloudURL
+	var err error
+	if manifest != "" || postPolicy || recursive {
+		// the url is the bucket or the prefix of the objects to sign, or of the keys allowed to post
+		cloudURL, err = CloudURLFromString(sc.command.args[0], encodingType)
+		if err == nil && cloudURL.bucket == "" {
+			err = fmt.Errorf("invalid cloud url: %s, miss bucket", sc.command.args[0])
+		}
+	} else {
+		cloudURL, err = ObjectURLFromString(sc.command.args[0], encodingType)
+	}
 	if err != nil {
 		return err
 	}
 
 	timeout, _ := GetInt(OptionTimeout, sc.command.options)
 	versionId, _ := GetString(OptionVersionId, sc.command.options)
+	if manifest != "" && len(versionId) > 0 {
+		return fmt.Errorf("--manifest can't be used with --version-id, please specify the version of each object in the manifest")
+	}
+	if recursive && (manifest != "" || len(versionId) > 0) {
+		return fmt.Errorf("--recursive can't be used with --manifest or --version-id")
+	}
+
+	filters, err := getFilter(os.Args)
+	if err != nil {
+		return err
+	}
+	if len(filters) > 0 && !recursive && manifest == "" {
+		return fmt.Errorf("--include or --exclude only work with --recursive or --manifest")
+	}
+	attrFilter, err := sc.command.attributeFilter()
+	if err != nil {
+		return err
+	}
+	if attrFilter != nil && !recursive {
+		return fmt.Errorf("--min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag and --content-type only work with --recursive")
+	}
 	trafficLimit, getErr := GetInt(OptionTrafficLimit, sc.command.options)
 	if getErr == nil && trafficLimit < 0 {
 		return fmt.Errorf("Option value of --trafic-limit must be greater than 0")
@@ -188,12 +360,30 @@ func (sc *SignurlCommand) RunCommand() error {
 	}
 	query, _ := GetStrings(OptionQueryParam, sc.command.options)
 
+	method, err := sc.checkUploadOptions(postPolicy, recursive, manifest, versionId)
+	if err != nil {
+		return err
+	}
+	meta, _ := GetString(OptionMeta, sc.command.options)
+	headers, err := sc.command.parseHeaders(meta, false)
+	if err != nil {
+		return err
+	}
+
 	bucket, err := sc.command.ossBucket(cloudURL.bucket)
 	if err != nil {
 		return err
 	}
 
+	if postPolicy {
+		return sc.signPostPolicy(bucket, cloudURL.object, timeout, headers)
+	}
+
+	sc.output = NewOutputWriter(getOutputFormat(sc.command.options), signURLColumns)
+	defer sc.output.Close()
+
 	var options []oss.Option
+	var listOptions []oss.Option
 	if len(versionId) > 0 {
 		options = append(options, oss.VersionId(versionId))
 	}
@@ -204,6 +394,7 @@ func (sc *SignurlCommand) RunCommand() error {
 
 	if payer != "" {
 		options = append(options, oss.RequestPayerParam(oss.PayerType(payer)))
+		listOptions = append(listOptions, oss.RequestPayer(oss.PayerType(payer)))
 	}
 
 	if len(query) > 0 {
@@ -213,18 +404,238 @@ func (sc *SignurlCommand) RunCommand() error {
 		}
 	}
 
-	str, err := sc.ossSign(bucket, cloudURL.object, timeout, options...)
+	// the headers are signed, the request with the signed url must carry the same headers
+	if len(headers) > 0 {
+		headerOptions, err := sc.command.getOSSOptions(headerOptionMap, headers)
+		if err != nil {
+			return err
+		}
+		options = append(options, headerOptions...)
+	}
+
+	if uploadID, _ := GetString(OptionUploadID, sc.command.options); uploadID != "" {
+		return sc.signUploadParts(bucket, cloudURL.object, uploadID, timeout, options)
+	}
+
+	ms, err := sc.command.openManifest(cloudURL)
+	if err != nil {
+		return err
+	}
+	if ms != nil {
+		return sc.signManifestObjects(bucket, ms, filters, method, timeout, options)
+	}
+
+	if recursive {
+		return sc.signPrefixObjects(bucket, cloudURL, filters, attrFilter, method, timeout, options, listOptions)
+	}
+
+	return sc.signObject(bucket, cloudURL.object, versionId, method, timeout, options...)
+}
+
+// checkUploadOptions checks the options of signing the upload and returns the method of the signed url
+func (sc *SignurlCommand) checkUploadOptions(postPolicy, recursive bool, manifest, versionId string) (oss.HTTPMethod, error) {
+	method, _ := GetString(OptionMethod, sc.command.options)
+	uploadID, _ := GetString(OptionUploadID, sc.command.options)
+	partNumber, _ := GetString(OptionPartNumber, sc.command.options)
+	lengthRange, _ := GetString(OptionContentLengthRange, sc.command.options)
+	meta, _ := GetString(OptionMeta, sc.command.options)
+
+	if postPolicy {
+		if method != "" || uploadID != "" || partNumber != "" || manifest != "" || recursive || versionId != "" {
+			return "", fmt.Errorf("--post-policy can't be used with --method, --upload-id, --part-number, --manifest, --recursive or --version-id")
+		}
+		if getOutputFormat(sc.command.options) != "" {
+			return "", fmt.Errorf("--post-policy prints the url and the fields of the form as json, it can't be used with --output-format")
+		}
+		return oss.HTTPPost, nil
+	}
+	if lengthRange != "" {
+		return "", fmt.Errorf("--content-length-range only works with --post-policy")
+	}
+
+	if uploadID != "" || partNumber != "" {
+		if uploadID == "" || partNumber == "" {
+			return "", fmt.Errorf("--upload-id and --part-number must be specified together")
+		}
+		if manifest != "" || recursive || versionId != "" {
+			return "", fmt.Errorf("--upload-id can't be used with --manifest, --recursive or --version-id")
+		}
+		if method == "" {
+			method = string(oss.HTTPPut)
+		}
+		if !strings.EqualFold(method, string(oss.HTTPPut)) {
+			return "", fmt.Errorf("the part can only be uploaded by PUT, the method %s is not supported with --upload-id", method)
+		}
+	}
+
+	if method == "" {
+		method = DefaultMethod
+	}
+	if FindPosCaseInsen(method, signMethodList) == -1 {
+		return "", fmt.Errorf("invalid option value of method, the value: %s is not anyone of %s", method, strings.Join(signMethodList, "/"))
+	}
+	method = strings.ToUpper(method)
+	if meta != "" && method != string(oss.HTTPPut) {
+		return "", fmt.Errorf("--meta only works with the method PUT or --post-policy")
+	}
+	return oss.HTTPMethod(method), nil
+}
+
+var signMethodList = []string{string(oss.HTTPGet), string(oss.HTTPPut), string(oss.HTTPHead)}
+
+// signManifestObjects prints the signed url of each object read from the manifest, on the version it specifies
+func (sc *SignurlCommand) signManifestObjects(bucket *oss.Bucket, ms *manifestSource, filters []filterOptionType, method oss.HTTPMethod, timeout int64, options []oss.Option) error {
+	return ms.forEach(func(entry ManifestEntry) error {
+		if !doesSingleObjectMatchPatterns(filterRelativeKey(ms.prefix, entry.Key), filters) {
+			return nil
+		}
+		entryOptions := options
+		if entry.VersionId != "" {
+			entryOptions = append(append([]oss.Option{}, options...), oss.VersionId(entry.VersionId))
+		}
+		return sc.signObject(bucket, entry.Key, entry.VersionId, method, timeout, entryOptions...)
+	})
+}
+
+// signPrefixObjects prints the signed url of each object under the prefix matching the filters, in the order of listing
+func (sc *SignurlCommand) signPrefixObjects(bucket *oss.Bucket, cloudURL CloudURL, filters []filterOptionType, attrFilter *attributeFilter,
+	method oss.HTTPMethod, timeout int64, options, listOptions []oss.Option) error {
+	pre := oss.Prefix(cloudURL.object)
+	marker := oss.Marker("")
+	for {
+		lor, err := sc.command.ossListObjectsRetry(bucket, append(listOptions, marker, pre)...)
+		if err != nil {
+			return err
+		}
+		for _, object := range lor.Objects {
+			if !doesSingleObjectMatchPatterns(filterRelativeKey(cloudURL.object, object.Key), filters) {
+				continue
+			}
+			match, err := attrFilter.matchObject(bucket, object, listOptions...)
+			if err != nil {
+				return err
+			}
+			if !match {
+				continue
+			}
+			if err := sc.signObject(bucket, object.Key, "", method, timeout, options...); err != nil {
+				return err
+			}
+		}
+
+		marker = oss.Marker(lor.NextMarker)
+		if !lor.IsTruncated {
+			return nil
+		}
+	}
+}
+
+// signObject signs the url of the object and prints it, or a record with the key and the expiration if --output-format is specified
+func (sc *SignurlCommand) signObject(bucket *oss.Bucket, object, versionId string, method oss.HTTPMethod, timeout int64, options ...oss.Option) error {
+	// the same as the expiration the sdk signs with
+	expiration := time.Unix(time.Now().Unix()+timeout, 0)
+	str, err := sc.ossSign(bucket, object, method, timeout, options...)
 	if err != nil {
 		return err
 	}
 	sc.signUrl = str
 
-	fmt.Println(str)
+	if sc.output == nil {
+		fmt.Println(str)
+		return nil
+	}
+	return sc.output.Write(OutputRecord{
+		"bucket":     bucket.BucketName,
+		"key":        object,
+		"version_id": versionId,
+		"method":     string(method),
+		"url":        str,
+		"expiration": expiration,
+	})
+}
+
+// signUploadParts prints the signed url of uploading each part of the multipart upload, in the order of part number
+func (sc *SignurlCommand) signUploadParts(bucket *oss.Bucket, object, uploadID string, timeout int64, options []oss.Option) error {
+	partNumber, _ := GetString(OptionPartNumber, sc.command.options)
+	first, last, err := parsePartNumberRange(partNumber)
+	if err != nil {
+		return err
+	}
+
+	for part := first; part <= last; part++ {
+		partOptions := append(append([]oss.Option{}, options...),
+			oss.AddParam("partNumber", strconv.Itoa(part)), oss.AddParam("uploadId", uploadID))
+		if err := sc.signObject(bucket, object, "", oss.HTTPPut, timeout, partOptions...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parsePartNumberRange parses the part number like 3 or the range like 1-100
+func parsePartNumberRange(str string) (int, int, error) {
+	pair := strings.SplitN(str, "-", 2)
+	first, err := strconv.Atoi(strings.TrimSpace(pair[0]))
+	last := first
+	if err == nil && len(pair) == 2 {
+		last, err = strconv.Atoi(strings.TrimSpace(pair[1]))
+	}
+	if err != nil || first < 1 || last < first || last > MaxPartNum {
+		return 0, 0, fmt.Errorf("invalid option value of --part-number: %s, it should be a number or a range like 1-100, value range is: 1-%d", str, MaxPartNum)
+	}
+	return first, last, nil
+}
+
+// signPostPolicy prints the url and the form fields of the browser form upload as json, the keys must start with prefix
+func (sc *SignurlCommand) signPostPolicy(bucket *oss.Bucket, prefix string, timeout int64, headers map[string]string) error {
+	opt := postPolicyOption{prefix: prefix, minSize: -1, maxSize: -1, headers: headers, expires: timeout}
+	if lengthRange, _ := GetString(OptionContentLengthRange, sc.command.options); lengthRange != "" {
+		var err error
+		if opt.minSize, opt.maxSize, err = parseContentLengthRange(lengthRange); err != nil {
+			return fmt.Errorf("invalid option value of --content-length-range, %s", err.Error())
+		}
+	}
+
+	config := bucket.Client.Config
+	var cred oss.Credentials
+	if providerE, ok := config.CredentialsProvider.(oss.CredentialsProviderE); ok {
+		var err error
+		if cred, err = providerE.GetCredentialsE(); err != nil {
+			return err
+		}
+	} else {
+		cred = config.GetCredentials()
+	}
+	opt.accessKeyID = cred.GetAccessKeyID()
+	opt.accessKeySecret = cred.GetAccessKeySecret()
+	opt.securityToken = cred.GetSecurityToken()
+	opt.authVersion = config.AuthVersion
+	opt.region = config.GetSignRegion()
+	opt.product = config.GetSignProduct()
+
+	// the url of the bucket is cut from a signed url, which is built in the same way as the requests,
+	// for the cname, path style and so on
+	str, err := bucket.SignURL("object", oss.HTTPPost, timeout)
+	if err != nil {
+		return err
+	}
+	url := strings.TrimSuffix(strings.SplitN(str, "?", 2)[0], "object")
+
+	form, err := newPostPolicyForm(bucket.BucketName, url, opt, time.Now())
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(form, "", "    ")
+	if err != nil {
+		return err
+	}
+	sc.signUrl = string(data)
+	fmt.Println(sc.signUrl)
 	return nil
 }
 
-func (sc *SignurlCommand) ossSign(bucket *oss.Bucket, object string, timeout int64, options ...oss.Option) (string, error) {
-	str, err := bucket.SignURL(object, oss.HTTPMethod(DefaultMethod), timeout, options...)
+func (sc *SignurlCommand) ossSign(bucket *oss.Bucket, object string, method oss.HTTPMethod, timeout int64, options ...oss.Option) (string, error) {
+	str, err := bucket.SignURL(object, method, timeout, options...)
 	if err != nil {
 		return str, ObjectError{err, bucket.BucketName, object}
 	}
@@ -1,7 +1,16 @@
 package lib
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/url"
 	"os"
 	"strconv"
@@ -1080,3 +1089,232 @@ func (s *OssutilCommandSuite) TestSignUrlWithSignV4(c *C) {
 	c.Assert(str != "", Equals, true)
 	os.Remove(downFileName)
 }
+
+func (s *OssutilCommandSuite) signUploadOptions(extra map[string]string) OptionMapType {
+	endpoint := "oss-cn-hangzhou.aliyuncs.com"
+	ak := "test-access-key-id"
+	sk := "test-access-key-secret"
+	timeout := "3600"
+	options := OptionMapType{
+		OptionEndpoint:        &endpoint,
+		OptionAccessKeyID:     &ak,
+		OptionAccessKeySecret: &sk,
+		OptionTimeout:         &timeout,
+	}
+	for name, value := range extra {
+		v := value
+		options[name] = &v
+	}
+	return options
+}
+
+func (s *OssutilCommandSuite) TestSignUrlPutWithMeta(c *C) {
+	options := s.signUploadOptions(map[string]string{
+		OptionMethod: "put",
+		OptionMeta:   "Content-Type:image/jpeg#x-oss-meta-owner:partner1",
+	})
+	_, err := cm.RunCommand("sign", []string{"oss://bucket1/upload/a.jpg"}, options)
+	c.Assert(err, IsNil)
+
+	// the signature covers the method and the headers
+	u, err := url.Parse(signURLCommand.signUrl)
+	c.Assert(err, IsNil)
+	c.Assert(u.Path, Equals, "/upload/a.jpg")
+	query := u.Query()
+	stringToSign := "PUT\n\nimage/jpeg\n" + query.Get("Expires") + "\nx-oss-meta-owner:partner1\n/bucket1/upload/a.jpg"
+	mac := hmac.New(sha1.New, []byte("test-access-key-secret"))
+	mac.Write([]byte(stringToSign))
+	c.Assert(query.Get("Signature"), Equals, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	// meta only works with PUT
+	options = s.signUploadOptions(map[string]string{OptionMeta: "Content-Type:image/jpeg"})
+	_, err = cm.RunCommand("sign", []string{"oss://bucket1/upload/a.jpg"}, options)
+	c.Assert(err, NotNil)
+
+	options = s.signUploadOptions(map[string]string{OptionMethod: "POST"})
+	_, err = cm.RunCommand("sign", []string{"oss://bucket1/upload/a.jpg"}, options)
+	c.Assert(err, NotNil)
+
+	options = s.signUploadOptions(map[string]string{OptionMethod: "PUT", OptionMeta: "Content-Length:10"})
+	_, err = cm.RunCommand("sign", []string{"oss://bucket1/upload/a.jpg"}, options)
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestSignUrlUploadPart(c *C) {
+	options := s.signUploadOptions(map[string]string{OptionUploadID: "0004B9895DBBB6EC98E3", OptionPartNumber: "1-3"})
+	_, err := cm.RunCommand("sign", []string{"oss://bucket1/big.zip"}, options)
+	c.Assert(err, IsNil)
+
+	u, err := url.Parse(signURLCommand.signUrl)
+	c.Assert(err, IsNil)
+	query := u.Query()
+	c.Assert(query.Get("partNumber"), Equals, "3")
+	c.Assert(query.Get("uploadId"), Equals, "0004B9895DBBB6EC98E3")
+	stringToSign := "PUT\n\n\n" + query.Get("Expires") + "\n/bucket1/big.zip?partNumber=3&uploadId=0004B9895DBBB6EC98E3"
+	mac := hmac.New(sha1.New, []byte("test-access-key-secret"))
+	mac.Write([]byte(stringToSign))
+	c.Assert(query.Get("Signature"), Equals, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	for _, extra := range []map[string]string{
+		{OptionUploadID: "id"},
+		{OptionPartNumber: "1"},
+		{OptionUploadID: "id", OptionPartNumber: "0"},
+		{OptionUploadID: "id", OptionPartNumber: "3-2"},
+		{OptionUploadID: "id", OptionPartNumber: "1-10001"},
+		{OptionUploadID: "id", OptionPartNumber: "1", OptionMethod: "GET"},
+		{OptionUploadID: "id", OptionPartNumber: "1", OptionVersionId: "v1"},
+	} {
+		_, err = cm.RunCommand("sign", []string{"oss://bucket1/big.zip"}, s.signUploadOptions(extra))
+		c.Assert(err, NotNil)
+	}
+
+	first, last, err := parsePartNumberRange("5")
+	c.Assert(err, IsNil)
+	c.Assert(first, Equals, 5)
+	c.Assert(last, Equals, 5)
+}
+
+func (s *OssutilCommandSuite) TestSignPostPolicy(c *C) {
+	options := s.signUploadOptions(map[string]string{
+		OptionPostPolicy:         "true",
+		OptionContentLengthRange: "1K-10M",
+		OptionMeta:               "Content-Type:image/jpeg",
+	})
+	postPolicy := true
+	options[OptionPostPolicy] = &postPolicy
+	_, err := cm.RunCommand("sign", []string{"oss://bucket1/partner1/"}, options)
+	c.Assert(err, IsNil)
+
+	var form postPolicyForm
+	c.Assert(json.Unmarshal([]byte(signURLCommand.signUrl), &form), IsNil)
+	c.Assert(form.URL, Equals, "http://bucket1.oss-cn-hangzhou.aliyuncs.com/")
+	c.Assert(form.Fields["key"], Equals, "partner1/${filename}")
+	c.Assert(form.Fields["OSSAccessKeyId"], Equals, "test-access-key-id")
+	c.Assert(form.Fields["Content-Type"], Equals, "image/jpeg")
+	mac := hmac.New(sha1.New, []byte("test-access-key-secret"))
+	mac.Write([]byte(form.Fields["policy"]))
+	c.Assert(form.Fields["Signature"], Equals, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	document, err := base64.StdEncoding.DecodeString(form.Fields["policy"])
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(document), `{"bucket":"bucket1"}`), Equals, true)
+	c.Assert(strings.Contains(string(document), `["starts-with","$key","partner1/"]`), Equals, true)
+	c.Assert(strings.Contains(string(document), `["content-length-range",1024,10485760]`), Equals, true)
+	c.Assert(strings.Contains(string(document), `["eq","$Content-Type","image/jpeg"]`), Equals, true)
+	c.Assert(strings.Contains(string(document), `"expiration":"`+form.Expiration+`"`), Equals, true)
+
+	// the signature v4
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	opt := postPolicyOption{authVersion: "v4", region: "cn-hangzhou", product: "oss", accessKeyID: "ak", accessKeySecret: "sk",
+		securityToken: "token", prefix: "dir/", minSize: -1, maxSize: 100, expires: 60}
+	v4, err := newPostPolicyForm("bucket1", "https://bucket1.oss-cn-hangzhou.aliyuncs.com/", opt, now)
+	c.Assert(err, IsNil)
+	c.Assert(v4.Expiration, Equals, "2024-01-02T03:05:05.000Z")
+	c.Assert(v4.Fields["x-oss-credential"], Equals, "ak/20240102/cn-hangzhou/oss/aliyun_v4_request")
+	c.Assert(v4.Fields["x-oss-date"], Equals, "20240102T030405Z")
+	c.Assert(v4.Fields["x-oss-signature-version"], Equals, "OSS4-HMAC-SHA256")
+	c.Assert(v4.Fields["x-oss-security-token"], Equals, "token")
+	key := []byte("aliyun_v4sk")
+	for _, scope := range []string{"20240102", "cn-hangzhou", "oss", "aliyun_v4_request", v4.Fields["policy"]} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(scope))
+		key = mac.Sum(nil)
+	}
+	c.Assert(v4.Fields["x-oss-signature"], Equals, hex.EncodeToString(key))
+	document, err = base64.StdEncoding.DecodeString(v4.Fields["policy"])
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(document), `["content-length-range",0,100]`), Equals, true)
+	c.Assert(strings.Contains(string(document), `{"x-oss-date":"20240102T030405Z"}`), Equals, true)
+
+	opt.region = ""
+	_, err = newPostPolicyForm("bucket1", "", opt, now)
+	c.Assert(err, NotNil)
+
+	for _, str := range []string{"10", "-", "x-1M", "10M-1M"} {
+		_, _, err = parseContentLengthRange(str)
+		c.Assert(err, NotNil)
+	}
+	minSize, maxSize, err := parseContentLengthRange("1M-")
+	c.Assert(err, IsNil)
+	c.Assert(minSize, Equals, int64(1048576))
+	c.Assert(maxSize, Equals, int64(-1))
+
+	// the options of the signed url can't be used with the post policy
+	options[OptionMethod] = &opt.prefix
+	_, err = cm.RunCommand("sign", []string{"oss://bucket1/partner1/"}, options)
+	c.Assert(err, NotNil)
+
+	options = s.signUploadOptions(map[string]string{OptionContentLengthRange: "1K-10M"})
+	_, err = cm.RunCommand("sign", []string{"oss://bucket1/partner1/a"}, options)
+	c.Assert(err, NotNil)
+}
+
+func (s *OssutilCommandSuite) TestSignUrlOutputFormat(c *C) {
+	manifestName := "ossutil-sign-manifest-" + randLowStr(6) + ".txt"
+	c.Assert(ioutil.WriteFile(manifestName, []byte("release/a.zip\nrelease/a.tmp\nrelease/doc/b.txt\nother/c.zip\n"), 0600), IsNil)
+	defer os.Remove(manifestName)
+	resultName := "ossutil-sign-result-" + randLowStr(6)
+	defer os.Remove(resultName)
+
+	cmdline := []string{"ossutil", "sign", "oss://bucket1/release/", "--manifest", manifestName, "--exclude", "*.tmp", "--output-format", "csv"}
+	os.Args = cmdline
+	defer func() { os.Args = []string{} }()
+	resultFile, err := os.OpenFile(resultName, os.O_RDWR|os.O_TRUNC|os.O_CREATE, 0664)
+	c.Assert(err, IsNil)
+	oldStdout := os.Stdout
+	os.Stdout = resultFile
+	options := s.signUploadOptions(map[string]string{OptionManifest: manifestName, OptionOutputFormat: "csv"})
+	before := time.Now().Unix()
+	_, err = cm.RunCommand("sign", []string{"oss://bucket1/release/"}, options)
+	os.Stdout = oldStdout
+	resultFile.Close()
+	c.Assert(err, IsNil)
+
+	data, err := ioutil.ReadFile(resultName)
+	c.Assert(err, IsNil)
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	c.Assert(err, IsNil)
+	c.Assert(len(records), Equals, 3)
+	c.Assert(records[0], DeepEquals, signURLColumns)
+	c.Assert(records[1][1], Equals, "release/a.zip")
+	c.Assert(records[2][1], Equals, "release/doc/b.txt")
+	c.Assert(records[2][3], Equals, "GET")
+	c.Assert(records[2][4], Equals, signURLCommand.signUrl)
+
+	// the expiration is the same as the url
+	u, err := url.Parse(records[2][4])
+	c.Assert(err, IsNil)
+	expires, err := strconv.ParseInt(u.Query().Get("Expires"), 10, 64)
+	c.Assert(err, IsNil)
+	c.Assert(expires >= before+3600, Equals, true)
+	c.Assert(records[2][5], Equals, time.Unix(expires, 0).UTC().Format(time.RFC3339))
+
+	// filters only work with --recursive or --manifest
+	os.Args = []string{"ossutil", "sign", "oss://bucket1/release/a.zip", "--include", "*.zip"}
+	_, err = cm.RunCommand("sign", []string{"oss://bucket1/release/a.zip"}, s.signUploadOptions(nil))
+	c.Assert(err, NotNil)
+	os.Args = []string{}
+
+	recursive := true
+	for _, extra := range []map[string]string{
+		{OptionManifest: manifestName},
+		{OptionVersionId: "v1"},
+		{OptionUploadID: "id", OptionPartNumber: "1"},
+	} {
+		options := s.signUploadOptions(extra)
+		options[OptionRecursion] = &recursive
+		_, err = cm.RunCommand("sign", []string{"oss://bucket1/release/"}, options)
+		c.Assert(err, NotNil)
+	}
+
+	// the attribute filters only work with --recursive
+	_, err = cm.RunCommand("sign", []string{"oss://bucket1/release/a.zip"}, s.signUploadOptions(map[string]string{OptionMinSize: "1M"}))
+	c.Assert(err, NotNil)
+
+	// the post policy is always json
+	postPolicy := true
+	options = s.signUploadOptions(map[string]string{OptionOutputFormat: "json"})
+	options[OptionPostPolicy] = &postPolicy
+	_, err = cm.RunCommand("sign", []string{"oss://bucket1/release/"}, options)
+	c.Assert(err, NotNil)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,7 +18,7 @@ var specChineseStat = SpecText{
 	paramText: "cloud_url [options]",
 
 	syntaxText: ` 
-    ossutil stat oss://bucket[/object] [--encoding-type url] [--version-id versionId] [--payer requester] [-c file] 
+    ossutil stat oss://bucket[/object] [--encoding-type url] [--version-id versionId] [--payer requester] [--output-format json|jsonl|csv] [-c file] 
 `,
 
 	detailHelpText: ` 
@@ -35,6 +36,9 @@ var specChineseStat = SpecText{
     2) ossutil stat oss://bucket/object [--encoding-type url] [--version-id versionId]
         ossutil显示指定object的元信息，包括文件大小，最新更新时间，etag，文件类型，acl，文
     件的自定义meta等信息。
+
+    如果指定了--output-format选项，ossutil以json、jsonl或csv格式输出一条记录，记录的type字段
+    为bucket或者object，object的自定义meta在user_meta字段中。
 `,
 
 	sampleText: ` 
@@ -53,7 +57,7 @@ var specEnglishStat = SpecText{
 	paramText: "cloud_url [options]",
 
 	syntaxText: ` 
-    ossutil stat oss://bucket[/object] [--encoding-type url]  [--version-id versionId] [--payer requester] [-c file] 
+    ossutil stat oss://bucket[/object] [--encoding-type url]  [--version-id versionId] [--payer requester] [--output-format json|jsonl|csv] [-c file] 
 `,
 
 	detailHelpText: ` 
@@ -71,6 +75,10 @@ Usage：
     2) ossutil stat oss://bucket/object [--encoding-type url] [--version-id versionId]
         ossutil display object meta info, include file size, last modify time, etag, content-type, 
     user meta etc.
+
+    If --output-format option is specified, ossutil outputs one record in json, jsonl or csv format, 
+    the type field of the record is bucket or object, and the user meta of object is in the user_meta 
+    field.
 `,
 
 	sampleText: ` 
@@ -87,8 +95,15 @@ type StatCommand struct {
 	command       Command
 	versionId     string
 	commonOptions []oss.Option
+	output        *OutputWriter
 }
 
+var statColumns = []string{"type", "bucket", "key", "size", "etag", "storage_class", "last_modified", "version_id",
+	"content_type", "content_md5", "crc64", "object_type", "acl", "owner", "user_meta",
+	"location", "creation_date", "extranet_endpoint", "intranet_endpoint", "redundancy_type",
+	"sse_algorithm", "kms_master_key_id", "kms_data_encryption", "transfer_acceleration",
+	"cross_region_replication", "access_monitor"}
+
 var statCommand = StatCommand{
 	command: Command{
 		name:        "stat",
@@ -101,6 +116,7 @@ var statCommand = StatCommand{
 		validOptionNames: []string{
 			OptionEncodingType,
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -109,6 +125,9 @@ var statCommand = StatCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionLogLevel,
 			OptionVersionId,
 			OptionRequestPayer,
@@ -121,12 +140,17 @@ var statCommand = StatCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionUserAgent,
 			OptionSignVersion,
 			OptionRegion,
 			OptionCloudBoxID,
 			OptionForcePathStyle,
+			OptionOutputFormat,
 		},
 	},
 }
@@ -171,6 +195,9 @@ func (sc *StatCommand) RunCommand() error {
 		return err
 	}
 
+	sc.output = NewOutputWriter(getOutputFormat(sc.command.options), statColumns)
+	defer sc.output.Close()
+
 	if cloudURL.object == "" {
 		return sc.bucketStat(bucket, cloudURL)
 	}
@@ -184,6 +211,27 @@ func (sc *StatCommand) bucketStat(bucket *oss.Bucket, cloudURL CloudURL) error {
 		return err
 	}
 
+	if sc.output != nil {
+		return sc.output.Write(OutputRecord{
+			"type":                     recordBucket,
+			"bucket":                   gbar.BucketInfo.Name,
+			"location":                 gbar.BucketInfo.Location,
+			"creation_date":            gbar.BucketInfo.CreationDate,
+			"extranet_endpoint":        gbar.BucketInfo.ExtranetEndpoint,
+			"intranet_endpoint":        gbar.BucketInfo.IntranetEndpoint,
+			"acl":                      gbar.BucketInfo.ACL,
+			"owner":                    gbar.BucketInfo.Owner.ID,
+			"storage_class":            gbar.BucketInfo.StorageClass,
+			"redundancy_type":          gbar.BucketInfo.RedundancyType,
+			"sse_algorithm":            gbar.BucketInfo.SseRule.SSEAlgorithm,
+			"kms_master_key_id":        gbar.BucketInfo.SseRule.KMSMasterKeyID,
+			"kms_data_encryption":      gbar.BucketInfo.SseRule.KMSDataEncryption,
+			"transfer_acceleration":    gbar.BucketInfo.TransferAcceleration,
+			"cross_region_replication": gbar.BucketInfo.CrossRegionReplication,
+			"access_monitor":           gbar.BucketInfo.AccessMonitor,
+		})
+	}
+
 	fmt.Printf("%-22s: %s\n", StatName, gbar.BucketInfo.Name)
 	fmt.Printf("%-22s: %s\n", StatLocation, gbar.BucketInfo.Location)
 	fmt.Printf("%-22s: %s\n", StatCreationDate, utcToLocalTime(gbar.BucketInfo.CreationDate))
@@ -214,16 +262,16 @@ func (sc *StatCommand) bucketStat(bucket *oss.Bucket, cloudURL CloudURL) error {
 }
 
 func (sc *StatCommand) ossGetBucketStatRetry(bucket *oss.Bucket) (oss.GetBucketInfoResult, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, sc.command.options)
-	for i := 1; ; i++ {
-		gbar, err := bucket.Client.GetBucketInfo(bucket.BucketName, sc.commonOptions...)
-		if err == nil {
-			return gbar, err
-		}
-		if int64(i) >= retryTimes {
-			return gbar, BucketError{err, bucket.BucketName}
-		}
+	var gbar oss.GetBucketInfoResult
+	err := sc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		gbar, err = bucket.Client.GetBucketInfo(bucket.BucketName, withResponseHeader(sc.commonOptions, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return gbar, BucketError{err, bucket.BucketName}
 	}
+	return gbar, nil
 }
 
 func (sc *StatCommand) objectStat(bucket *oss.Bucket, cloudURL CloudURL) error {
@@ -245,6 +293,10 @@ func (sc *StatCommand) objectStat(bucket *oss.Bucket, cloudURL CloudURL) error {
 		return err
 	}
 
+	if sc.output != nil {
+		return sc.output.Write(objectStatRecord(bucket.BucketName, cloudURL.object, props, goar))
+	}
+
 	sortNames := []string{}
 	attrMap := map[string]string{}
 	maxNameLen := 0
@@ -283,21 +335,53 @@ func (sc *StatCommand) objectStat(bucket *oss.Bucket, cloudURL CloudURL) error {
 	return nil
 }
 
+// objectStatRecord makes the machine readable record of object meta
+func objectStatRecord(bucket, object string, props http.Header, goar oss.GetObjectACLResult) OutputRecord {
+	size, _ := strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64)
+	record := OutputRecord{
+		"type":          recordObject,
+		"bucket":        bucket,
+		"key":           object,
+		"size":          size,
+		"etag":          strings.Trim(props.Get(oss.HTTPHeaderEtag), "\""),
+		"storage_class": props.Get(oss.HTTPHeaderOssStorageClass),
+		"version_id":    oss.GetVersionId(props),
+		"content_type":  props.Get(oss.HTTPHeaderContentType),
+		"content_md5":   props.Get(oss.HTTPHeaderContentMD5),
+		"crc64":         props.Get(oss.HTTPHeaderOssCRC64),
+		"object_type":   props.Get(StatObjectType),
+		"acl":           goar.ACL,
+		"owner":         goar.Owner.ID,
+	}
+	if lm, err := time.Parse(http.TimeFormat, props.Get(oss.HTTPHeaderLastModified)); err == nil {
+		record["last_modified"] = lm
+	}
+
+	userMeta := map[string]string{}
+	for name := range props {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(oss.HTTPHeaderOssMetaPrefix)) {
+			userMeta[strings.ToLower(name[len(oss.HTTPHeaderOssMetaPrefix):])] = props.Get(name)
+		}
+	}
+	record["user_meta"] = userMeta
+	return record
+}
+
 func (sc *StatCommand) ossGetObjectACLRetry(bucket *oss.Bucket, object string) (oss.GetObjectACLResult, error) {
-	retryTimes, _ := GetInt(OptionRetryTimes, sc.command.options)
 	aclOptions := []oss.Option{}
 	if len(sc.versionId) > 0 {
 		aclOptions = append(aclOptions, oss.VersionId(sc.versionId))
 	}
 	aclOptions = append(aclOptions, sc.commonOptions...)
 
-	for i := 1; ; i++ {
-		goar, err := bucket.GetObjectACL(object, aclOptions...)
-		if err == nil {
-			return goar, err
-		}
-		if int64(i) >= retryTimes {
-			return goar, ObjectError{err, bucket.BucketName, object}
-		}
+	var goar oss.GetObjectACLResult
+	err := sc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		goar, err = bucket.GetObjectACL(object, withResponseHeader(aclOptions, respHeader)...)
+		return err
+	})
+	if err != nil {
+		return goar, ObjectError{err, bucket.BucketName, object}
 	}
+	return goar, nil
 }
@@ -0,0 +1,405 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+// storageURLFromString parses the url of cp, "-" means stdin as the source or stdout as the destination
+func (cc *CopyCommand) storageURLFromString(url string) (StorageURLer, error) {
+	if url == StdioURLString {
+		return StdioURL{}, nil
+	}
+	return StorageURLFromString(url, cc.cpOption.encodingType)
+}
+
+// checkStdioArgs checks the urls and options when the source is stdin or the destination is stdout
+func (cc *CopyCommand) checkStdioArgs(srcURLList []StorageURLer, destURL StorageURLer) error {
+	for _, url := range srcURLList {
+		if IsStdioURL(url) && len(srcURLList) > 1 {
+			return fmt.Errorf("invalid url: %s, stdin can only be the only source", url.ToString())
+		}
+	}
+	if IsStdioURL(destURL) && len(srcURLList) > 1 {
+		return fmt.Errorf("only one source can be copied to stdout")
+	}
+
+	var objectURL StorageURLer
+	if IsStdioURL(srcURLList[0]) {
+		if !destURL.IsCloudURL() {
+			return fmt.Errorf("invalid url: %s, the destination must be an oss url when the source is stdin", destURL.ToString())
+		}
+		objectURL = destURL
+	} else if IsStdioURL(destURL) {
+		if !srcURLList[0].IsCloudURL() {
+			return fmt.Errorf("invalid url: %s, the source must be an oss url when the destination is stdout", srcURLList[0].ToString())
+		}
+		objectURL = srcURLList[0]
+	} else {
+		return nil
+	}
+
+	if object := objectURL.(CloudURL).object; object == "" || strings.HasSuffix(object, "/") {
+		return fmt.Errorf("invalid url: %s, the object name must be specified with stdin or stdout", objectURL.ToString())
+	}
+
+	resumeJob, _ := GetString(OptionResumeJob, cc.command.options)
+	unsupported := []struct {
+		used bool
+		name string
+	}{
+		{cc.cpOption.recursive, "-r"},
+		{cc.isIncremental(), "-u or --compare"},
+		{cc.cpOption.snapshotPath != "", "--snapshot-path"},
+		{cc.cpOption.partitionInfo != "", "--partition-download"},
+		{cc.cpOption.dryRun, "--dryrun"},
+		{resumeJob != "", "--resume-job"},
+		{cc.cpOption.encryption != nil, "client side encryption"},
+		{cc.cpOption.compress != "" || cc.cpOption.decompress, "--compress or --decompress"},
+		{cc.cpOption.move != nil, "mv"},
+	}
+	for _, option := range unsupported {
+		if option.used {
+			return fmt.Errorf("%s can't be used with stdin or stdout", option.name)
+		}
+	}
+	return nil
+}
+
+// uploadFromStdin uploads the stream of stdin to the object
+func (cc *CopyCommand) uploadFromStdin(destURL CloudURL) error {
+	if err := cc.startBandwidthScheduler(); err != nil {
+		return err
+	}
+	defer cc.stopBandwidthScheduler()
+
+	bucket, err := cc.command.ossBucket(destURL.bucket)
+	if err != nil {
+		return err
+	}
+
+	// stdin is the data, so it can't be used to confirm overwriting
+	if !cc.cpOption.force {
+		if _, err := cc.command.ossGetObjectMetaRetry(bucket, destURL.object, cc.cpOption.payerOptions...); err == nil {
+			return fmt.Errorf("%s already exists, use --force(-f) to overwrite it", CloudURLToString(destURL.bucket, destURL.object))
+		}
+	}
+
+	size, err := cc.uploadStream(bucket, destURL.object, os.Stdin)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Succeed: upload %s bytes from stdin to %s.\n", getSizeString(size), CloudURLToString(destURL.bucket, destURL.object))
+	return nil
+}
+
+// stdinPartSize returns the part size to read stdin, which is not less than the min part size of oss,
+// otherwise all the parts but the last one are rejected when the upload is completed
+func (cc *CopyCommand) stdinPartSize() int64 {
+	partSize, err := GetInt(OptionPartSize, cc.command.options)
+	if err != nil {
+		return DefaultStdinPartSize
+	}
+	if partSize < oss.MinPartSize {
+		LogInfo("part size %d is less than %d, use %d to upload stdin\n", partSize, oss.MinPartSize, oss.MinPartSize)
+		return oss.MinPartSize
+	}
+	return partSize
+}
+
+type streamPart struct {
+	number int
+	data   []byte
+}
+
+// uploadStream uploads the stream whose length is unknown. The stream is read part by part and the parts
+// are uploaded in parallel, at most parallel+1 parts are kept in memory. The stream less than a part is
+// uploaded by PutObject.
+func (cc *CopyCommand) uploadStream(bucket *oss.Bucket, objectName string, reader io.Reader) (int64, error) {
+	partSize := cc.stdinPartSize()
+	routines, err := GetInt(OptionParallel, cc.command.options)
+	if err != nil {
+		routines = DefaultStdinParallel
+	}
+
+	crc := crc64.New(crc64.MakeTable(crc64.ECMA))
+	reader = io.TeeReader(reader, crc)
+	buf := make([]byte, partSize)
+	n, err := io.ReadFull(reader, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return int64(n), cc.ossPutObjectRetry(bucket, objectName, string(buf[:n]))
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	size, respHeader, err := cc.uploadParts(bucket, objectName, reader, buf, routines, cc.cpOption.options)
+	if err != nil {
+		return size, err
+	}
+
+	// the crc64 of each part is checked by sdk, the whole stream is checked here
+	if serverCRC := respHeader.Get(oss.HTTPHeaderOssCRC64); bucket.GetConfig().IsEnableCRC && serverCRC != "" {
+		if clientCRC := strconv.FormatUint(crc.Sum64(), 10); clientCRC != serverCRC {
+			return size, ObjectError{fmt.Errorf("crc64 of the stream is %s, not the same as %s of the object", clientCRC, serverCRC), bucket.BucketName, objectName}
+		}
+	}
+	return size, nil
+}
+
+// uploadParts uploads the content by multipart upload, first is the first part which is read already and
+// the others are read from reader in the same size. The parts are uploaded in parallel by routines, at most
+// routines+1 parts are kept in memory. The response header of CompleteMultipartUpload is returned.
+func (cc *CopyCommand) uploadParts(bucket *oss.Bucket, objectName string, reader io.Reader, first []byte, routines int64, options []oss.Option) (int64, http.Header, error) {
+	var imur oss.InitiateMultipartUploadResult
+	err := cc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		imur, err = bucket.InitiateMultipartUpload(objectName, withResponseHeader(options, respHeader)...)
+		if err != nil {
+			LogError("try count:%d,initiate multipart upload of %s error,%s\n", attempt, objectName, err.Error())
+		}
+		return err
+	})
+	if err != nil {
+		return 0, nil, ObjectError{err, bucket.BucketName, objectName}
+	}
+
+	// the buffers are allocated when needed and reused after the part is uploaded
+	partSize := len(first)
+	bufs := make(chan []byte, routines+1)
+	for i := int64(0); i < routines; i++ {
+		bufs <- nil
+	}
+	chParts := make(chan streamPart)
+	partOptions := oss.ChoiceTransferPartOption(options)
+	var lock sync.Mutex
+	var parts []oss.UploadPart
+	var uploadErr error
+	var wg sync.WaitGroup
+	for i := int64(0); i < routines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range chParts {
+				lock.Lock()
+				failed := uploadErr != nil
+				lock.Unlock()
+				if !failed {
+					uploaded, err := cc.ossUploadPartRetry(bucket, imur, part.data, part.number, partOptions...)
+					lock.Lock()
+					if err != nil {
+						uploadErr = err
+					} else {
+						parts = append(parts, uploaded)
+					}
+					lock.Unlock()
+				}
+				bufs <- part.data[:cap(part.data)]
+			}
+		}()
+	}
+
+	buf := first
+	n := len(first)
+	size := int64(n)
+	last := false
+	for number := 1; ; number++ {
+		chParts <- streamPart{number, buf[:n]}
+		lock.Lock()
+		failed := uploadErr != nil
+		lock.Unlock()
+		if last || failed {
+			break
+		}
+		if number >= MaxPartNum {
+			err = fmt.Errorf("the content is larger than %d parts of %d bytes, please specify a larger --part-size", MaxPartNum, partSize)
+			break
+		}
+
+		if buf = <-bufs; buf == nil {
+			buf = make([]byte, partSize)
+		}
+		if n, err = io.ReadFull(reader, buf); err == io.EOF {
+			err = nil
+			break
+		} else if err == io.ErrUnexpectedEOF {
+			err = nil
+			last = true
+		} else if err != nil {
+			break
+		}
+		size += int64(n)
+	}
+	close(chParts)
+	wg.Wait()
+
+	if err == nil {
+		err = uploadErr
+	}
+	if err != nil {
+		bucket.AbortMultipartUpload(imur, oss.ChoiceAbortPartOption(options)...)
+		return size, nil, ObjectError{err, bucket.BucketName, objectName}
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	var completeHeader http.Header
+	err = cc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		var err error
+		_, err = bucket.CompleteMultipartUpload(imur, parts, withResponseHeader(oss.ChoiceCompletePartOption(options), respHeader)...)
+		if err != nil {
+			LogError("try count:%d,complete multipart upload of %s error,%s\n", attempt, objectName, err.Error())
+		}
+		completeHeader = *respHeader
+		return err
+	})
+	if err != nil {
+		return size, nil, ObjectError{err, bucket.BucketName, objectName}
+	}
+	return size, completeHeader, nil
+}
+
+// ossUploadPartRetry uploads the part by the client with the current bandwidth limit, each attempt is observed by
+// the auto tuner
+func (cc *CopyCommand) ossUploadPartRetry(bucket *oss.Bucket, imur oss.InitiateMultipartUploadResult, data []byte, partNumber int, options ...oss.Option) (oss.UploadPart, error) {
+	var part oss.UploadPart
+	err := cc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		startT := time.Now()
+		var err error
+		part, err = cc.command.bandwidthBucket(bucket).UploadPart(imur, bytes.NewReader(data), int64(len(data)), partNumber,
+			withResponseHeader(options, respHeader)...)
+		cc.cpOption.tuner.observe(err, time.Since(startT))
+		if err != nil {
+			LogError("try count:%d,upload part %d of %s error,%s\n", attempt, partNumber, imur.Key, err.Error())
+		}
+		return err
+	})
+	return part, err
+}
+
+// downloadToStdout writes the object to stdout
+func (cc *CopyCommand) downloadToStdout(srcURL CloudURL) error {
+	if err := cc.startBandwidthScheduler(); err != nil {
+		return err
+	}
+	defer cc.stopBandwidthScheduler()
+
+	bucket, err := cc.command.ossBucket(srcURL.bucket)
+	if err != nil {
+		return err
+	}
+	_, err = cc.downloadStream(bucket, srcURL.object, os.Stdout)
+	return err
+}
+
+type streamData struct {
+	data []byte
+	err  error
+}
+
+// downloadStream downloads the object or its --range by ranged GETs in parallel, and writes them to the
+// writer in order, at most parallel+1 parts are kept in memory.
+func (cc *CopyCommand) downloadStream(bucket *oss.Bucket, object string, writer io.Writer) (int64, error) {
+	statOptions := cc.cpOption.payerOptions
+	if cc.cpOption.versionId != "" {
+		statOptions = append(statOptions, oss.VersionId(cc.cpOption.versionId))
+	}
+	props, err := cc.command.ossGetObjectStatRetry(bucket, object, statOptions...)
+	if err != nil {
+		return 0, err
+	}
+	size, err := strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	start, end := int64(0), size
+	if cc.cpOption.vrange != "" {
+		ur, err := oss.ParseRange("bytes=" + cc.cpOption.vrange)
+		if err != nil {
+			return 0, err
+		}
+		start, end = oss.AdjustRange(ur, size)
+	}
+	if end <= start {
+		return 0, nil
+	}
+
+	partSize, routines := cc.preparePartOption(end - start)
+	ordered := make(chan chan streamData, routines)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(ordered)
+		for from := start; from < end; from += partSize {
+			to := from + partSize
+			if to > end {
+				to = end
+			}
+			ch := make(chan streamData, 1)
+			select {
+			case ordered <- ch:
+			case <-done:
+				return
+			}
+			go func(from, to int64) {
+				data, err := cc.ossGetRangeRetry(bucket, object, from, to)
+				ch <- streamData{data, err}
+			}(from, to)
+		}
+	}()
+
+	crc := crc64.New(crc64.MakeTable(crc64.ECMA))
+	var written int64
+	for ch := range ordered {
+		part := <-ch
+		if part.err != nil {
+			return written, ObjectError{part.err, bucket.BucketName, object}
+		}
+		if _, err := writer.Write(part.data); err != nil {
+			return written, err
+		}
+		crc.Write(part.data)
+		written += int64(len(part.data))
+	}
+
+	// the ranged GETs are not checked by sdk, the whole object is checked here
+	if serverCRC := props.Get(oss.HTTPHeaderOssCRC64); bucket.GetConfig().IsEnableCRC && serverCRC != "" && start == 0 && end == size {
+		if clientCRC := strconv.FormatUint(crc.Sum64(), 10); clientCRC != serverCRC {
+			return written, ObjectError{fmt.Errorf("crc64 of the downloaded content is %s, not the same as %s of the object", clientCRC, serverCRC), bucket.BucketName, object}
+		}
+	}
+	return written, nil
+}
+
+func (cc *CopyCommand) ossGetRangeRetry(bucket *oss.Bucket, object string, from, to int64) ([]byte, error) {
+	options := append(append([]oss.Option{}, cc.cpOption.options...), oss.Range(from, to-1))
+	var data []byte
+	err := cc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		body, err := bucket.GetObject(object, withResponseHeader(options, respHeader)...)
+		if err == nil {
+			data = make([]byte, to-from)
+			_, err = io.ReadFull(body, data)
+			body.Close()
+			if err == nil {
+				return nil
+			}
+		}
+		LogError("try count:%d,get range %d-%d of %s error,%s\n", attempt, from, to-1, object, err.Error())
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,81 @@
+package lib
+
+import (
+	"strconv"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	. "gopkg.in/check.v1"
+)
+
+func (s *OssutilCommandSuite) TestStdioURL(c *C) {
+	cc := CopyCommand{}
+	stdio, err := cc.storageURLFromString("-")
+	c.Assert(err, IsNil)
+	c.Assert(IsStdioURL(stdio), Equals, true)
+	c.Assert(stdio.IsCloudURL(), Equals, false)
+	c.Assert(stdio.IsFileURL(), Equals, false)
+	c.Assert(stdio.ToString(), Equals, StdioURLString)
+
+	file, err := cc.storageURLFromString("./-")
+	c.Assert(err, IsNil)
+	c.Assert(IsStdioURL(file), Equals, false)
+	c.Assert(file.IsFileURL(), Equals, true)
+
+	// "-" is only parsed by cp
+	file, err = StorageURLFromString("-", "")
+	c.Assert(err, IsNil)
+	c.Assert(file.IsFileURL(), Equals, true)
+
+	cloud, err := cc.storageURLFromString("oss://bucket/object")
+	c.Assert(err, IsNil)
+	c.Assert(cc.getCommandType([]StorageURLer{stdio}, cloud), Equals, operationTypePut)
+	c.Assert(cc.getCommandType([]StorageURLer{cloud}, stdio), Equals, operationTypeGet)
+}
+
+func (s *OssutilCommandSuite) TestCheckStdioArgs(c *C) {
+	cc := CopyCommand{}
+	cc.command.options = OptionMapType{}
+	stdio := StdioURL{}
+	object, _ := cc.storageURLFromString("oss://bucket/object")
+	dir, _ := cc.storageURLFromString("oss://bucket/dir/")
+	file, _ := cc.storageURLFromString("file")
+
+	c.Assert(cc.checkStdioArgs([]StorageURLer{stdio}, object), IsNil)
+	c.Assert(cc.checkStdioArgs([]StorageURLer{object}, stdio), IsNil)
+	c.Assert(cc.checkStdioArgs([]StorageURLer{file}, object), IsNil)
+
+	c.Assert(cc.checkStdioArgs([]StorageURLer{stdio}, stdio), NotNil)
+	c.Assert(cc.checkStdioArgs([]StorageURLer{stdio}, file), NotNil)
+	c.Assert(cc.checkStdioArgs([]StorageURLer{file}, stdio), NotNil)
+	c.Assert(cc.checkStdioArgs([]StorageURLer{stdio}, dir), NotNil)
+	c.Assert(cc.checkStdioArgs([]StorageURLer{dir}, stdio), NotNil)
+	c.Assert(cc.checkStdioArgs([]StorageURLer{file, stdio}, object), NotNil)
+	c.Assert(cc.checkStdioArgs([]StorageURLer{stdio, file}, object), NotNil)
+	c.Assert(cc.checkStdioArgs([]StorageURLer{object, object}, stdio), NotNil)
+
+	cc.cpOption.recursive = true
+	c.Assert(cc.checkStdioArgs([]StorageURLer{object}, stdio), NotNil)
+	cc.cpOption.recursive = false
+	cc.cpOption.update = true
+	c.Assert(cc.checkStdioArgs([]StorageURLer{stdio}, object), NotNil)
+	cc.cpOption.update = false
+	cc.cpOption.decompress = true
+	c.Assert(cc.checkStdioArgs([]StorageURLer{object}, stdio), NotNil)
+	cc.cpOption.decompress = false
+	cc.cpOption.move = &moveRecorder{}
+	c.Assert(cc.checkStdioArgs([]StorageURLer{stdio}, object), NotNil)
+}
+
+func (s *OssutilCommandSuite) TestStdinPartSize(c *C) {
+	cc := CopyCommand{}
+	cc.command.options = OptionMapType{}
+	c.Assert(cc.stdinPartSize(), Equals, DefaultStdinPartSize)
+
+	// the part less than the min part size of oss can't be completed
+	partSize := "1024"
+	cc.command.options[OptionPartSize] = &partSize
+	c.Assert(cc.stdinPartSize(), Equals, int64(oss.MinPartSize))
+
+	partSize = strconv.Itoa(10 * 1024 * 1024)
+	c.Assert(cc.stdinPartSize(), Equals, int64(10*1024*1024))
+}
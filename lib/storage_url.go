@@ -163,6 +163,33 @@ func (fu FileURL) ToString() string {
 	return fu.urlStr
 }
 
+// StdioURLString is the url of stdin when it's the source of cp, or stdout when it's the destination
+const StdioURLString = "-"
+
+// StdioURL describes stdin or stdout
+type StdioURL struct{}
+
+// IsCloudURL simulate inheritance, and polymorphism
+func (su StdioURL) IsCloudURL() bool {
+	return false
+}
+
+// IsFileURL simulate inheritance, and polymorphism
+func (su StdioURL) IsFileURL() bool {
+	return false
+}
+
+// ToString simulate inheritance, and polymorphism
+func (su StdioURL) ToString() string {
+	return StdioURLString
+}
+
+// IsStdioURL returns true if the url is stdin or stdout
+func IsStdioURL(url StorageURLer) bool {
+	_, ok := url.(StdioURL)
+	return ok
+}
+
 // StorageURLFromString analysis input url type and build a storage url from the url
 func StorageURLFromString(urlStr, encodingType string) (StorageURLer, error) {
 	if strings.HasPrefix(strings.ToLower(urlStr), SchemePrefix) {
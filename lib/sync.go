@@ -6,10 +6,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	leveldb "github.com/syndtr/goleveldb/leveldb"
 )
 
 /*
@@ -18,7 +18,11 @@ import (
  * Please guarantee the alignment if you add new filed
  */
 
-var MaxSyncNumbers int = 1000000
+// the reason of deleting or moving dest keys, shown in dry run mode
+const reasonNotInSrc = "not exist in source"
+
+// syncKeyHandler is called for every key listed from the src or dest of sync
+type syncKeyHandler func(key, prefix string) error
 
 type syncOptionType struct {
 	bDelete      bool
@@ -32,8 +36,10 @@ type syncOptionType struct {
 	disableAllSymlink bool
 	cpDir             string
 	removeCount       int
+	dryRun            bool
 
 	filters      []filterOptionType
+	attrFilter   *attributeFilter
 	payerOptions []oss.Option
 }
 
@@ -44,9 +50,9 @@ var specChineseSync = SpecText{
 	paramText: "src dest [options]",
 
 	syntaxText: ` 
-    ossutil sync local_dir cloud_url [-f] [-u] [--delete] [--backup-dir] [--enable-symlink-dir] [--disable-all-symlink] [--disable-ignore-error] [--only-current-dir] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--snapshot-path=sdir] [--payer requester]
-    ossutil sync cloud_url local_dir [-f] [-u] [--delete] [--backup-dir] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--range=x-y] [--payer requester]
-    ossutil sync cloud_url cloud_url [-f] [-u] [--delete] [--backup-dir] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
+    ossutil sync local_dir cloud_url [-f] [-u] [--delete] [--backup-dir] [--dryrun] [--enable-symlink-dir] [--disable-all-symlink] [--disable-ignore-error] [--only-current-dir] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--snapshot-path=sdir] [--payer requester]
+    ossutil sync cloud_url local_dir [-f] [-u] [--delete] [--backup-dir] [--dryrun] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--range=x-y] [--payer requester]
+    ossutil sync cloud_url cloud_url [-f] [-u] [--delete] [--backup-dir] [--dryrun] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
 `,
 
 	detailHelpText: ` 
@@ -69,6 +75,14 @@ var specChineseSync = SpecText{
 --backup-dir
     该选项表示用于备份目的端文件的目录, 不能是目的端目录的子目录,如果输入了--delete, 该选项必须输入
 
+--dryrun
+    只输出将要执行的上传、下载、拷贝、跳过以及删除或者移走操作及原因, 不实际修改源端和目的端的任何数据,
+    也不会提示是否删除
+
+--min-size、--max-size、--older-than、--newer-than、--storage-class、--has-tag、--content-type
+    只同步满足条件的文件或者object，源端按这些条件筛选；指定--delete时，只删除或者移走目的端
+    满足条件的多余object或者文件，源端存在的object或者文件不会被删除或者移走
+
   
     其他选项说明、用法和cp命令相同
 `,
@@ -201,9 +215,9 @@ var specEnglishSync = SpecText{
 	paramText: "src dest [options]",
 
 	syntaxText: ` 
-    ossutil sync local_dir cloud_url [-f] [-u] [--delete] [--backup-dir] [--enable-symlink-dir] [--disable-all-symlink] [--disable-ignore-error] [--only-current-dir] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--snapshot-path=sdir] [--payer requester]
-    ossutil sync cloud_url local_dir [-f] [-u] [--delete] [--backup-dir] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--range=x-y] [--payer requester]
-    ossutil sync cloud_url cloud_url [-f] [-u] [--delete] [--backup-dir] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
+    ossutil sync local_dir cloud_url [-f] [-u] [--delete] [--backup-dir] [--dryrun] [--enable-symlink-dir] [--disable-all-symlink] [--disable-ignore-error] [--only-current-dir] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--snapshot-path=sdir] [--payer requester]
+    ossutil sync cloud_url local_dir [-f] [-u] [--delete] [--backup-dir] [--dryrun] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--range=x-y] [--payer requester]
+    ossutil sync cloud_url cloud_url [-f] [-u] [--delete] [--backup-dir] [--dryrun] [--only-current-dir] [--disable-ignore-error] [--output-dir=odir] [--bigfile-threshold=size] [--checkpoint-dir=cdir] [--payer requester]
 `,
 
 	detailHelpText: ` 
@@ -232,6 +246,15 @@ var specEnglishSync = SpecText{
     It cannot be a subdirectory of the destination directory. 
     If you enter --delete, this option must be entered
 
+--dryrun
+    Only print the upload, download, copy, skip, delete or remove actions to be done with the reasons,
+    without modifying any data on src or destination, and without prompting for deletion
+
+--min-size, --max-size, --older-than, --newer-than, --storage-class, --has-tag, --content-type
+    Only sync the files or objects matching them, the src is filtered by them; with --delete, only 
+    the redundant objects or files on the destination matching them are deleted or removed, those 
+    existing on the src are never deleted or removed
+
     Other options descriptions and usage are the same as the cp command
 `,
 
@@ -386,9 +409,20 @@ var syncCommand = SyncCommand{
 			OptionEncodingType,
 			OptionInclude,
 			OptionExclude,
+			OptionIncludeRegex,
+			OptionExcludeRegex,
+			OptionExcludeFrom,
+			OptionMinSize,
+			OptionMaxSize,
+			OptionOlderThan,
+			OptionNewerThan,
+			OptionStorageClass,
+			OptionHasTag,
+			OptionContentType,
 			OptionMeta,
 			OptionACL,
 			OptionConfigFile,
+			OptionProfile,
 			OptionEndpoint,
 			OptionAccessKeyID,
 			OptionAccessKeySecret,
@@ -397,6 +431,9 @@ var syncCommand = SyncCommand{
 			OptionProxyUser,
 			OptionProxyPwd,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionRoutines,
 			OptionParallel,
 			OptionSnapshotPath,
@@ -422,13 +459,31 @@ var syncCommand = SyncCommand{
 			OptionReadTimeout,
 			OptionConnectTimeout,
 			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
 			OptionSkipVerifyCert,
 			OptionMaxDownSpeed,
+			OptionBwLimit,
+			OptionBwLimitFile,
+			OptionResumeJob,
+			OptionJobsDir,
+			OptionEncryptionPubKey,
+			OptionEncryptionPriKey,
+			OptionEncryptionAesKey,
+			OptionEncryptionMatDesc,
+			OptionCompress,
+			OptionCompressInclude,
+			OptionDecompress,
+			OptionAutoTune,
 			OptionUserAgent,
 			OptionSignVersion,
 			OptionRegion,
 			OptionCloudBoxID,
 			OptionForcePathStyle,
+			OptionCompare,
+			OptionDryRun,
 
 			// The following options are only supported by sc command, not supported by cp command
 			OptionDelete,
@@ -479,6 +534,7 @@ func (sc *SyncCommand) RunCommand() error {
 	sc.syncOption.disableDirObject, _ = GetBool(OptionDisableDirObject, sc.command.options)
 	sc.syncOption.disableAllSymlink, _ = GetBool(OptionDisableAllSymlink, sc.command.options)
 	sc.syncOption.force, _ = GetBool(OptionForce, sc.command.options)
+	sc.syncOption.dryRun = sc.command.isDryRun()
 
 	// check point dir
 	sc.syncOption.cpDir, _ = GetString(OptionCheckpointDir, sc.command.options)
@@ -493,16 +549,20 @@ func (sc *SyncCommand) RunCommand() error {
 	}
 
 	// filters
-	var res bool
-	res, sc.syncOption.filters = getFilter(os.Args)
-	if !res {
-		return fmt.Errorf("--include or --exclude does not support format containing dir info")
+	filters, err := getFilter(os.Args)
+	if err != nil {
+		return err
 	}
+	sc.syncOption.filters = filters
 
 	for k, v := range sc.syncOption.filters {
 		LogInfo("filter %d,name:%s,pattern:%s\n", k, v.name, v.pattern)
 	}
 
+	if sc.syncOption.attrFilter, err = sc.command.attributeFilter(); err != nil {
+		return err
+	}
+
 	srcURL, err := StorageURLFromString(sc.command.args[0], sc.syncOption.encodingType)
 	if err != nil {
 		return err
@@ -538,6 +598,10 @@ func (sc *SyncCommand) RunCommand() error {
 
 	// check backup dir
 	if destURL.IsFileURL() {
+		if _, err := os.Stat(destURL.ToString()); err != nil && sc.syncOption.dryRun {
+			// dest dir is not created in dry run mode, there is nothing to remove
+			return copyCommand.RunCommand()
+		}
 		err = sc.CheckDestBackupDir(destURL)
 		if err != nil {
 			return err
@@ -545,45 +609,59 @@ func (sc *SyncCommand) RunCommand() error {
 	}
 	opType := sc.getCommandType(srcURL, destURL)
 
-	// get file list or object key list
-	srcKeys := make(map[string]string)
-	destKeys := make(map[string]string)
-	if srcURL.IsFileURL() {
-		err = sc.GetLocalFileKeys(srcURL, srcKeys)
-	} else {
-		err = sc.GetOssKeys(srcURL, srcKeys)
-	}
-
+	// dest keys are spilled to a temporary leveldb instead of memory,
+	// so the number of keys to sync is not limited
+	keyDB, keyDBPath, err := sc.openKeyDB()
 	if err != nil {
 		return err
 	}
+	defer sc.closeKeyDB(keyDB, keyDBPath)
 
+	// put all dest keys
+	putKey := func(key, prefix string) error {
+		return keyDB.Put([]byte(key), []byte(prefix), nil)
+	}
+	// only the dest keys matching the attributes can be deleted, the src keys are not filtered by
+	// the attributes, so that the dest keys whose src exists are never deleted
 	if destURL.IsFileURL() {
-		err = sc.GetLocalFileKeys(destURL, destKeys)
+		err = sc.GetLocalFileKeys(destURL, sc.syncOption.attrFilter, putKey)
 	} else {
-		err = sc.GetOssKeys(destURL, destKeys)
+		err = sc.GetOssKeys(destURL, sc.syncOption.attrFilter, putKey)
 	}
 
 	if err != nil {
 		return err
 	}
 
-	// Get keys to be deleted
+	// remove the dest keys which exist in src, the remaining are keys to be deleted
 	bSame := (string(os.PathSeparator) == "/")
-	for k, _ := range srcKeys {
-		if bSame || opType == operationTypeCopy {
-			delete(destKeys, k)
-		} else if opType == operationTypePut {
-			delete(destKeys, strings.Replace(k, "\\", "/", -1))
-		} else {
-			delete(destKeys, strings.Replace(k, "/", "\\", -1))
+	deleteKey := func(key, prefix string) error {
+		if !bSame && opType == operationTypePut {
+			key = strings.Replace(key, "\\", "/", -1)
+		} else if !bSame && opType == operationTypeGet {
+			key = strings.Replace(key, "/", "\\", -1)
 		}
+		return keyDB.Delete([]byte(key), nil)
+	}
+	if srcURL.IsFileURL() {
+		err = sc.GetLocalFileKeys(srcURL, nil, deleteKey)
+	} else {
+		err = sc.GetOssKeys(srcURL, nil, deleteKey)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	deleteCount, err := sc.countKeys(keyDB)
+	if err != nil {
+		return err
 	}
 
 	if destURL.IsFileURL() {
-		fmt.Printf("\nfile(directory) will be removed count:%d\n", len(destKeys))
+		fmt.Printf("\nfile(directory) will be removed count:%d\n", deleteCount)
 	} else {
-		fmt.Printf("\nobject will be deleted count:%d\n", len(destKeys))
+		fmt.Printf("\nobject will be deleted count:%d\n", deleteCount)
 	}
 
 	err = copyCommand.RunCommand()
@@ -593,13 +671,41 @@ func (sc *SyncCommand) RunCommand() error {
 
 	// move dest files or rm dest objects which not exist in src
 	if opType == operationTypeCopy || opType == operationTypePut {
-		err = sc.DeleteExtraObjects(destKeys, destURL)
+		err = sc.DeleteExtraObjects(keyDB, destURL)
 	} else {
-		err = sc.RemoveExtraFiles(destKeys, destURL)
+		err = sc.RemoveExtraFiles(keyDB, destURL)
 	}
 	return err
 }
 
+func (sc *SyncCommand) openKeyDB() (*leveldb.DB, string, error) {
+	if err := os.MkdirAll(sc.syncOption.cpDir, 0755); err != nil {
+		return nil, "", err
+	}
+
+	dbPath := filepath.Join(sc.syncOption.cpDir, SyncKeyDBPrefix+randStr(8))
+	db, err := leveldb.OpenFile(dbPath, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("open sync key db %s error: %s", dbPath, err.Error())
+	}
+	return db, dbPath, nil
+}
+
+func (sc *SyncCommand) closeKeyDB(db *leveldb.DB, dbPath string) {
+	db.Close()
+	os.RemoveAll(dbPath)
+}
+
+func (sc *SyncCommand) countKeys(db *leveldb.DB) (int64, error) {
+	var count int64
+	iter := db.NewIterator(nil, nil)
+	for iter.Next() {
+		count++
+	}
+	iter.Release()
+	return count, iter.Error()
+}
+
 func (sc *SyncCommand) adjustCloudUrl(sUrl StorageURLer) StorageURLer {
 	if sUrl.IsFileURL() {
 		return sUrl
@@ -612,7 +718,7 @@ func (sc *SyncCommand) adjustCloudUrl(sUrl StorageURLer) StorageURLer {
 	return cloudUrl
 }
 
-func (sc *SyncCommand) DeleteExtraObjects(keys map[string]string, sUrl StorageURLer) error {
+func (sc *SyncCommand) DeleteExtraObjects(keyDB *leveldb.DB, sUrl StorageURLer) error {
 	bucketName := sUrl.(CloudURL).bucket
 	bucket, err := sc.command.ossBucket(bucketName)
 	if err != nil {
@@ -622,7 +728,18 @@ func (sc *SyncCommand) DeleteExtraObjects(keys map[string]string, sUrl StorageUR
 	deleteCount := 0
 	rmOptions := append(sc.syncOption.payerOptions, oss.DeleteObjectsQuiet(true))
 	objects := []string{}
-	for k, v := range keys {
+	iter := keyDB.NewIterator(nil, nil)
+	defer iter.Release()
+	if sc.syncOption.dryRun {
+		fmt.Printf("\n")
+		for iter.Next() {
+			printDryRun("delete "+CloudURLToString(bucketName, string(iter.Value())+string(iter.Key())), reasonNotInSrc)
+			deleteCount++
+		}
+		fmt.Printf("would delete object count:%d\n", deleteCount)
+		return iter.Error()
+	}
+	for iter.Next() {
 		if len(objects) >= MaxBatchCount {
 			if sc.confirm(objects) {
 				err := sc.BatchRmObjects(bucket, objects, rmOptions)
@@ -635,7 +752,11 @@ func (sc *SyncCommand) DeleteExtraObjects(keys map[string]string, sUrl StorageUR
 			fmt.Printf("\rdelete object count:%d", deleteCount)
 		}
 		// prefix + relativeKey
-		objects = append(objects, v+k)
+		objects = append(objects, string(iter.Value())+string(iter.Key()))
+	}
+
+	if err := iter.Error(); err != nil {
+		return err
 	}
 
 	if len(objects) > 0 && sc.confirm(objects) {
@@ -649,21 +770,26 @@ func (sc *SyncCommand) DeleteExtraObjects(keys map[string]string, sUrl StorageUR
 	return nil
 }
 
-func (sc *SyncCommand) RemoveExtraFiles(keys map[string]string, sUrl StorageURLer) error {
-	var sortList []string
-	for k, _ := range keys {
-		sortList = append(sortList, k)
-	}
-	// remove files first,then remove dir
-	sort.Sort(sort.Reverse(sort.StringSlice(sortList)))
-
+func (sc *SyncCommand) RemoveExtraFiles(keyDB *leveldb.DB, sUrl StorageURLer) error {
 	absDirName, err := sc.GetAbsPath(sUrl.ToString())
 	if err != nil {
 		return err
 	}
 
+	// remove files first,then remove dir
+	// leveldb keeps keys sorted, so walk them in reverse order
+	iter := keyDB.NewIterator(nil, nil)
+	defer iter.Release()
+
 	nowFatherDirName := ""
-	for _, k := range sortList {
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		k := string(iter.Key())
+		if sc.syncOption.dryRun {
+			// the files are not moved in dry run mode, so don't check whether the dir is empty
+			printDryRun(fmt.Sprintf("move %s to %s", absDirName+k, sc.syncOption.backupDir+k), reasonNotInSrc)
+			sc.syncOption.removeCount++
+			continue
+		}
 		if strings.HasSuffix(k, string(os.PathSeparator)) {
 			// is dir
 			dirName := k[0 : len(k)-1]
@@ -704,7 +830,10 @@ func (sc *SyncCommand) RemoveExtraFiles(keys map[string]string, sUrl StorageURLe
 			}
 		}
 	}
-	return nil
+	if sc.syncOption.dryRun {
+		fmt.Printf("would remove file(directory) count:%d\n", sc.syncOption.removeCount)
+	}
+	return iter.Error()
 }
 
 func (sc *SyncCommand) BatchRmObjects(bucket *oss.Bucket, objects []string, options []oss.Option) error {
@@ -739,7 +868,7 @@ func (sc *SyncCommand) getCommandType(srcURL StorageURLer, destURL StorageURLer)
 	return operationTypePut
 }
 
-func (sc *SyncCommand) GetLocalFileKeys(sUrl StorageURLer, keys map[string]string) error {
+func (sc *SyncCommand) GetLocalFileKeys(sUrl StorageURLer, attrFilter *attributeFilter, handler syncKeyHandler) error {
 	strPath := sUrl.ToString()
 	if !strings.HasSuffix(strPath, string(os.PathSeparator)) {
 		// for symlink dir
@@ -748,42 +877,42 @@ func (sc *SyncCommand) GetLocalFileKeys(sUrl StorageURLer, keys map[string]strin
 
 	chFiles := make(chan fileInfoType, ChannelBuf)
 	chFinish := make(chan error, 2)
-	go sc.ReadLocalFileKeys(chFiles, chFinish, keys)
-	go sc.GetFileList(strPath, chFiles, chFinish)
-	select {
-	case err := <-chFinish:
-		if err != nil {
-			return err
+	go sc.ReadLocalFileKeys(chFiles, chFinish, handler)
+	go sc.GetFileList(strPath, attrFilter, chFiles, chFinish)
+	// wait for both the lister and the reader, a lost listing error
+	// would make existing keys look like extra keys
+	var rerr error
+	for i := 0; i < 2; i++ {
+		if err := <-chFinish; err != nil && rerr == nil {
+			rerr = err
 		}
 	}
-	return nil
+	return rerr
 }
 
-func (sc *SyncCommand) GetFileList(strPath string, chFiles chan<- fileInfoType, chFinish chan<- error) {
+func (sc *SyncCommand) GetFileList(strPath string, attrFilter *attributeFilter, chFiles chan<- fileInfoType, chFinish chan<- error) {
 	err := getFileListCommon(strPath, chFiles, sc.syncOption.onlyCurrentDir,
-		sc.syncOption.disableAllSymlink, sc.syncOption.enableSymlinkDir, sc.syncOption.filters)
-	if err != nil {
-		chFinish <- err
-	}
+		sc.syncOption.disableAllSymlink, sc.syncOption.enableSymlinkDir, sc.syncOption.filters, attrFilter)
+	chFinish <- err
 }
 
-func (sc *SyncCommand) ReadLocalFileKeys(chFiles <-chan fileInfoType, chFinish chan<- error, keys map[string]string) {
+func (sc *SyncCommand) ReadLocalFileKeys(chFiles <-chan fileInfoType, chFinish chan<- error, handler syncKeyHandler) {
+	var err error
 	totalCount := 0
 	fmt.Printf("\n")
 	for fileInfo := range chFiles {
+		if err != nil {
+			// drain the channel to let the producer exit
+			continue
+		}
 		if copyCommand.filterFile(fileInfo, sc.syncOption.cpDir) { // exclude checkpoint files
 			totalCount++
 			fmt.Printf("\rtotal file(directory) count:%d", totalCount)
-			keys[fileInfo.filePath] = ""
-			if len(keys) > MaxSyncNumbers {
-				fmt.Printf("\n")
-				chFinish <- fmt.Errorf("over max sync numbers %d", MaxSyncNumbers)
-				break
-			}
+			err = handler(fileInfo.filePath, "")
 		}
 	}
 	fmt.Printf("\rtotal file(directory) count:%d", totalCount)
-	chFinish <- nil
+	chFinish <- err
 }
 
 func (sc *SyncCommand) GetAbsPath(strPath string) (string, error) {
@@ -855,6 +984,9 @@ func (sc *SyncCommand) CheckDestBackupDir(sUrl StorageURLer) error {
 	// create bacup dir
 	f, err = os.Stat(sc.syncOption.backupDir)
 	if err != nil {
+		if sc.syncOption.dryRun {
+			return nil
+		}
 		if err := os.MkdirAll(sc.syncOption.backupDir, 0755); err != nil {
 			return err
 		}
@@ -864,7 +996,7 @@ func (sc *SyncCommand) CheckDestBackupDir(sUrl StorageURLer) error {
 	return nil
 }
 
-func (sc *SyncCommand) GetOssKeys(sUrl StorageURLer, keys map[string]string) error {
+func (sc *SyncCommand) GetOssKeys(sUrl StorageURLer, attrFilter *attributeFilter, handler syncKeyHandler) error {
 	bucketName := sUrl.(CloudURL).bucket
 	bucket, err := sc.command.ossBucket(bucketName)
 	if err != nil {
@@ -873,41 +1005,41 @@ func (sc *SyncCommand) GetOssKeys(sUrl StorageURLer, keys map[string]string) err
 
 	chFiles := make(chan objectInfoType, ChannelBuf)
 	chFinish := make(chan error, 2)
-	go sc.ReadOssKeys(keys, sUrl, chFiles, chFinish)
-	go sc.GetOssKeyList(bucket, sUrl, chFiles, chFinish)
-	select {
-	case err := <-chFinish:
-		if err != nil {
-			return err
+	go sc.ReadOssKeys(handler, sUrl, chFiles, chFinish)
+	go sc.GetOssKeyList(bucket, sUrl, attrFilter, chFiles, chFinish)
+	// wait for both the lister and the reader, a lost listing error
+	// would make existing keys look like extra keys
+	var rerr error
+	for i := 0; i < 2; i++ {
+		if err := <-chFinish; err != nil && rerr == nil {
+			rerr = err
 		}
 	}
-	return nil
+	return rerr
 }
 
-func (sc *SyncCommand) GetOssKeyList(bucket *oss.Bucket, sURL StorageURLer, chObjects chan<- objectInfoType, chFinish chan<- error) {
+func (sc *SyncCommand) GetOssKeyList(bucket *oss.Bucket, sURL StorageURLer, attrFilter *attributeFilter, chObjects chan<- objectInfoType, chFinish chan<- error) {
 	cloudURL := sURL.(CloudURL)
 	err := getObjectListCommon(bucket, cloudURL, chObjects, sc.syncOption.onlyCurrentDir,
-		sc.syncOption.filters, sc.syncOption.payerOptions)
-	if err != nil {
-		chFinish <- err
-	}
+		sc.syncOption.filters, attrFilter, sc.syncOption.payerOptions)
+	chFinish <- err
 }
 
-func (sc *SyncCommand) ReadOssKeys(keys map[string]string, sURL StorageURLer, chObjects <-chan objectInfoType, chFinish chan<- error) {
+func (sc *SyncCommand) ReadOssKeys(handler syncKeyHandler, sURL StorageURLer, chObjects <-chan objectInfoType, chFinish chan<- error) {
+	var err error
 	totalCount := 0
 	fmt.Printf("\n")
 	for objectInfo := range chObjects {
+		if err != nil {
+			// drain the channel to let the producer exit
+			continue
+		}
 		totalCount++
 		fmt.Printf("\r%s,total oss object count:%d", sURL.ToString(), totalCount)
-		keys[objectInfo.relativeKey] = objectInfo.prefix
-		if len(keys) > MaxSyncNumbers {
-			fmt.Printf("\n")
-			chFinish <- fmt.Errorf("over max sync numbers %d", MaxSyncNumbers)
-			break
-		}
+		err = handler(objectInfo.relativeKey, objectInfo.prefix)
 	}
 	fmt.Printf("\r%s,total oss object count:%d", sURL.ToString(), totalCount)
-	chFinish <- nil
+	chFinish <- err
 }
 
 func (sc *SyncCommand) confirm(keys []string) bool {
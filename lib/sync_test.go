@@ -2,6 +2,7 @@ package lib
 
 import (
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -1221,7 +1222,7 @@ func (s *OssutilCommandSuite) TestSyncUploadWithDeleteWithoutConfirm(c *C) {
 	s.removeBucket(bucketName, true, c)
 }
 
-func (s *OssutilCommandSuite) TestSyncUploadExceedMaxCount(c *C) {
+func (s *OssutilCommandSuite) TestSyncUploadWithoutMaxCount(c *C) {
 	bucketName := bucketNamePrefix + randLowStr(10)
 	s.putBucket(bucketName, c)
 
@@ -1231,9 +1232,6 @@ func (s *OssutilCommandSuite) TestSyncUploadExceedMaxCount(c *C) {
 	filePrefix1 := "prefix1"
 	s.prepareTestFiles(dirName1, subDirName1, filePrefix1, "", 3, c)
 
-	maxCount := MaxSyncNumbers
-	MaxSyncNumbers = 2
-
 	// upload dir1 with prefix
 	ossPrefix := "prefix"
 	syncArgs := []string{dirName1, CloudURLToString(bucketName, ossPrefix)}
@@ -1252,14 +1250,17 @@ func (s *OssutilCommandSuite) TestSyncUploadExceedMaxCount(c *C) {
 	}
 
 	_, err := cm.RunCommand("sync", syncArgs, options)
-	c.Assert(err, NotNil)
-	MaxSyncNumbers = maxCount
+	c.Assert(err, IsNil)
+
+	// the temporary key db is removed after sync
+	keyDBs, _ := filepath.Glob(filepath.Join(cpDir, SyncKeyDBPrefix+"*"))
+	c.Assert(len(keyDBs), Equals, 0)
 
 	os.RemoveAll(dirName1)
 	s.removeBucket(bucketName, true, c)
 }
 
-func (s *OssutilCommandSuite) TestSyncDownloadExceedMaxCount(c *C) {
+func (s *OssutilCommandSuite) TestSyncDownloadWithoutMaxCount(c *C) {
 	bucketName := bucketNamePrefix + randLowStr(10)
 	s.putBucket(bucketName, c)
 
@@ -1269,8 +1270,6 @@ func (s *OssutilCommandSuite) TestSyncDownloadExceedMaxCount(c *C) {
 	filePrefix1 := "prefix1"
 	s.prepareTestFiles(dirName1, subDirName1, filePrefix1, "", 3, c)
 
-	maxCount := MaxSyncNumbers
-
 	// upload dir1 with prefix
 	ossPrefix := "prefix"
 	syncArgs := []string{dirName1, CloudURLToString(bucketName, ossPrefix)}
@@ -1292,12 +1291,10 @@ func (s *OssutilCommandSuite) TestSyncDownloadExceedMaxCount(c *C) {
 	c.Assert(err, IsNil)
 
 	os.RemoveAll(dirName1)
-	MaxSyncNumbers = 2
 	syncArgs = []string{CloudURLToString(bucketName, ossPrefix), dirName1}
 	_, err = cm.RunCommand("sync", syncArgs, options)
-	c.Assert(err, NotNil)
+	c.Assert(err, IsNil)
 
-	MaxSyncNumbers = maxCount
 	os.RemoveAll(dirName1)
 	s.removeBucket(bucketName, true, c)
 }
@@ -1381,8 +1378,8 @@ func (s *OssutilCommandSuite) TestSyncFilterError(c *C) {
 	// sync dir to oss
 	syncArgs := []string{dirName, CloudURLToString(bucketName, "")}
 
-	//Error: --include or --exclude does not support format containing dir info
-	strFilter := "a" + string(os.PathSeparator) + "b" + string(os.PathSeparator) + "*.txt"
+	//Error: the character class of --include is not closed
+	strFilter := "a/b/[*.txt"
 
 	cmdline := []string{"ossutil", "sync", dirName, CloudURLToString(bucketName, ""), "-f", "--include", strFilter}
 	str := ""
@@ -2235,3 +2232,47 @@ func (s *OssutilCommandSuite) TestSyncUploadSubSymlinkDir(c *C) {
 	os.RemoveAll(dirName)
 	s.removeBucket(bucketName, true, c)
 }
+
+func (s *OssutilCommandSuite) TestSyncUploadDeleteDryRun(c *C) {
+	bucketName := bucketNamePrefix + randLowStr(10)
+	s.putBucket(bucketName, c)
+
+	// dir1
+	dirName1 := "testdir1-" + randLowStr(3)
+	subDirName1 := "subdir1-" + randLowStr(4)
+	filePrefix1 := "prefix1"
+	s.prepareTestFiles(dirName1, subDirName1, filePrefix1, "", 3, c)
+
+	// the extra object which doesn't exist in dir1
+	ossPrefix := "prefix"
+	extraObject := ossPrefix + "/extra-" + randLowStr(5)
+	s.putObject(bucketName, extraObject, uploadFileName, c)
+
+	syncArgs := []string{dirName1, CloudURLToString(bucketName, ossPrefix)}
+	str := ""
+	cpDir := CheckpointDir
+	bDelete := true
+	dryRun := true
+	routines := strconv.Itoa(Routines)
+	options := OptionMapType{
+		"endpoint":        &str,
+		"accessKeyID":     &str,
+		"accessKeySecret": &str,
+		"configFile":      &configFile,
+		"checkpointDir":   &cpDir,
+		"routines":        &routines,
+		"delete":          &bDelete,
+		"dryRun":          &dryRun,
+	}
+
+	// nothing is uploaded or deleted without prompt
+	_, err := cm.RunCommand("sync", syncArgs, options)
+	c.Assert(err, IsNil)
+
+	objects := s.listObjects(bucketName, ossPrefix, "ls - ", c)
+	c.Assert(len(objects), Equals, 1)
+	c.Assert(objects[0], Equals, extraObject)
+
+	os.RemoveAll(dirName1)
+	s.removeBucket(bucketName, true, c)
+}
@@ -0,0 +1,551 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+)
+
+var specChineseTail = SpecText{
+	synopsisText: "持续输出追加类型object新追加的内容",
+
+	paramText: "cloud_url [options]",
+
+	syntaxText: ` 
+    ossutil tail oss://bucket/object [--lines N] [--bytes] [--interval ms] [--max-interval ms] [--payer requester]
+    ossutil tail oss://bucket[/prefix] -r [--lines N] [--bytes] [--interval ms] [--max-interval ms] [--payer requester]
+`,
+	detailHelpText: ` 
+    tail命令类似tail -f，用于跟踪appendfromfile等方式写入的追加类型object（如日志），先输出object
+    末尾的--lines行（默认为10，指定--bytes时为字节），然后持续轮询object的长度，输出新追加的内容，
+    按Ctrl+C退出。该命令总是跟踪object，不需要-f选项，只输出一次末尾内容请使用cat --tail。
+
+    轮询的间隔由--interval指定，单位为毫秒，默认为1000，没有新内容时间隔逐次翻倍，最大为--max-interval
+    （默认为30000），有新内容时恢复为--interval。
+
+    ossutil记录每个object已输出的位置，并用object的crc64校验已输出的内容，object被删除、截断或者被
+    覆盖为其他内容时，在标准错误输出提示，并从头开始输出新的object。
+
+用法:
+
+    1) ossutil tail oss://bucket/object [--lines N] [--bytes]
+       跟踪单个追加类型object，object需要已经存在
+
+    2) ossutil tail oss://bucket[/prefix] -r [--lines N] [--bytes]
+       同时跟踪prefix下的所有追加类型object，每行输出以"key: "开头，未结束的行在结束后输出，
+       之后新创建的追加类型object从头开始输出，被删除或者被覆盖为其他类型的object不再跟踪
+`,
+	sampleText: ` 
+    1) 跟踪日志，先输出最后10行
+       ossutil tail oss://bucket/app.log
+
+    2) 跟踪日志，不输出已有内容，每5秒轮询一次
+       ossutil tail oss://bucket/app.log --lines 0 --interval 5000
+
+    3) 同时跟踪logs/下的所有日志，先输出每个日志的最后100个字节
+       ossutil tail oss://bucket/logs/ -r --lines 100 --bytes
+`,
+}
+
+var specEnglishTail = SpecText{
+	synopsisText: "Keep outputting the appended content of the appendable objects",
+
+	paramText: "cloud_url [options]",
+
+	syntaxText: ` 
+    ossutil tail oss://bucket/object [--lines N] [--bytes] [--interval ms] [--max-interval ms] [--payer requester]
+    ossutil tail oss://bucket[/prefix] -r [--lines N] [--bytes] [--interval ms] [--max-interval ms] [--payer requester]
+`,
+	detailHelpText: ` 
+    The tail command works like tail -f, it follows the appendable objects such as the logs written
+    by appendfromfile. The last --lines lines (10 by default, or bytes if --bytes is specified) of the
+    object are output first, then the length of the object is polled and the appended content is
+    output, press Ctrl+C to exit. The command always follows the objects, -f is not needed, use
+    cat --tail to output the tail only once.
+
+    The polling interval is specified by --interval in milliseconds, 1000 by default, it's doubled
+    each time there is no new content, up to --max-interval (30000 by default), and gets back to
+    --interval when there is new content.
+
+    ossutil records the position output of each object, and validates the content output by the
+    crc64 of the object. If the object is deleted, truncated or overwritten by other content, a
+    notice is printed to standard error, and the new object is output from the beginning.
+
+Usage:
+
+    1) ossutil tail oss://bucket/object [--lines N] [--bytes]
+       Follow the single appendable object, which must exist
+
+    2) ossutil tail oss://bucket[/prefix] -r [--lines N] [--bytes]
+       Follow all the appendable objects under the prefix at once, each line output starts with
+       "key: ", the line not ended is output after it ends. The appendable objects created later
+       are output from the beginning, the objects deleted or overwritten by other types are not
+       followed any more.
+`,
+	sampleText: ` 
+    1) follow the log, output the last 10 lines first
+       ossutil tail oss://bucket/app.log
+
+    2) follow the log without outputting the existing content, poll every 5 seconds
+       ossutil tail oss://bucket/app.log --lines 0 --interval 5000
+
+    3) follow all the logs under logs/ at once, output the last 100 bytes of each log first
+       ossutil tail oss://bucket/logs/ -r --lines 100 --bytes
+`,
+}
+
+// TailCommand follows the appendable objects
+type TailCommand struct {
+	command Command
+}
+
+var tailCommand = TailCommand{
+	command: Command{
+		name:        "tail",
+		nameAlias:   []string{"tail"},
+		minArgc:     1,
+		maxArgc:     1,
+		specChinese: specChineseTail,
+		specEnglish: specEnglishTail,
+		group:       GroupTypeNormalCommand,
+		validOptionNames: []string{
+			OptionConfigFile,
+			OptionProfile,
+			OptionEndpoint,
+			OptionAccessKeyID,
+			OptionAccessKeySecret,
+			OptionSTSToken,
+			OptionProxyHost,
+			OptionProxyUser,
+			OptionProxyPwd,
+			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
+			OptionEncodingType,
+			OptionLogLevel,
+			OptionRecursion,
+			OptionLines,
+			OptionBytes,
+			OptionInterval,
+			OptionMaxInterval,
+			OptionRequestPayer,
+			OptionPassword,
+			OptionMode,
+			OptionECSRoleName,
+			OptionTokenTimeout,
+			OptionRamRoleArn,
+			OptionRoleSessionName,
+			OptionReadTimeout,
+			OptionConnectTimeout,
+			OptionSTSRegion,
+			OptionCredentialProcess,
+			OptionOIDCProviderArn,
+			OptionOIDCTokenFile,
+			OptionCredentialsCache,
+			OptionSkipVerifyCert,
+			OptionUserAgent,
+			OptionSignVersion,
+			OptionRegion,
+			OptionCloudBoxID,
+			OptionForcePathStyle,
+		},
+	},
+}
+
+// function for FormatHelper interface
+func (tc *TailCommand) formatHelpForWhole() string {
+	return tc.command.formatHelpForWhole()
+}
+
+func (tc *TailCommand) formatIndependHelp() string {
+	return tc.command.formatIndependHelp()
+}
+
+// Init simulate inheritance, and polymorphism
+func (tc *TailCommand) Init(args []string, options OptionMapType) error {
+	return tc.command.Init(args, options, tc)
+}
+
+// RunCommand simulate inheritance, and polymorphism
+func (tc *TailCommand) RunCommand() error {
+	encodingType, _ := GetString(OptionEncodingType, tc.command.options)
+	recursive, _ := GetBool(OptionRecursion, tc.command.options)
+	cloudURL, err := CloudURLFromString(tc.command.args[0], encodingType)
+	if err != nil {
+		return err
+	}
+	if cloudURL.bucket == "" {
+		return fmt.Errorf("invalid cloud url: %s, miss bucket", tc.command.args[0])
+	}
+	if !recursive && cloudURL.object == "" {
+		return fmt.Errorf("object key is empty, please specify -r to follow the objects under the bucket")
+	}
+
+	lines, _ := GetInt(OptionLines, tc.command.options)
+	bytesMode, _ := GetBool(OptionBytes, tc.command.options)
+
+	var options []oss.Option
+	payer, _ := GetString(OptionRequestPayer, tc.command.options)
+	if payer != "" {
+		if payer != strings.ToLower(string(oss.Requester)) {
+			return fmt.Errorf("invalid request payer: %s, please check", payer)
+		}
+		options = append(options, oss.RequestPayer(oss.PayerType(payer)))
+	}
+
+	bucket, err := tc.command.ossBucket(cloudURL.bucket)
+	if err != nil {
+		return err
+	}
+	follower, err := newAppendFollower(&tc.command, bucket, options)
+	if err != nil {
+		return err
+	}
+
+	if !recursive {
+		props, err := tc.command.ossGetObjectStatRetry(bucket, cloudURL.object, options...)
+		if err != nil {
+			return err
+		}
+		if props.Get(StatObjectType) != "Appendable" {
+			return fmt.Errorf("%s is not appendable, the type is %s", CloudURLToString(bucket.BucketName, cloudURL.object), props.Get(StatObjectType))
+		}
+		size, err := strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64)
+		if err != nil {
+			return err
+		}
+		obj, err := follower.startObject(cloudURL.object, size, lines, bytesMode)
+		if err != nil {
+			return err
+		}
+		return follower.followObject(obj)
+	}
+
+	follower.keyPrefix = true
+	err = follower.listAppendable(cloudURL.object, func(object oss.ObjectProperties) error {
+		obj, err := follower.startObject(object.Key, object.Size, lines, bytesMode)
+		if err == nil {
+			follower.objects[object.Key] = obj
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return follower.followPrefix(cloudURL.object)
+}
+
+// the content read by a range download at most when following the object,
+// the rest is read at the next time without waiting
+const followReadSize int64 = 8 * 1024 * 1024
+
+// followedObject is the state of the appendable object followed
+type followedObject struct {
+	key      string
+	position int64  // the content before the position has been output
+	crc      uint64 // the crc64 of the content before the position
+	crcKnown bool
+	envelope *ClientEnvelope
+	pending  []byte // the last line not ended yet, which is output with the key prefix after it ends
+}
+
+// appendFollower polls the appendable objects and outputs the content appended after the position of each object
+type appendFollower struct {
+	command     *Command
+	bucket      *oss.Bucket
+	options     []oss.Option
+	interval    time.Duration
+	maxInterval time.Duration
+	keyPrefix   bool // output "key: " before each line, when following several objects
+	out         io.Writer
+	objects     map[string]*followedObject
+}
+
+func newAppendFollower(cmd *Command, bucket *oss.Bucket, options []oss.Option) (*appendFollower, error) {
+	f := &appendFollower{
+		command:     cmd,
+		bucket:      bucket,
+		options:     options,
+		interval:    time.Duration(FollowInterval) * time.Millisecond,
+		maxInterval: time.Duration(FollowMaxInterval) * time.Millisecond,
+		out:         os.Stdout,
+		objects:     map[string]*followedObject{},
+	}
+	if interval, err := GetInt(OptionInterval, cmd.options); err == nil {
+		f.interval = time.Duration(interval) * time.Millisecond
+	}
+	if maxInterval, err := GetInt(OptionMaxInterval, cmd.options); err == nil {
+		f.maxInterval = time.Duration(maxInterval) * time.Millisecond
+	}
+	if f.maxInterval < f.interval {
+		return nil, fmt.Errorf("--max-interval %d is less than --interval %d", f.maxInterval/time.Millisecond, f.interval/time.Millisecond)
+	}
+	return f, nil
+}
+
+// startObject outputs the last count lines or bytes of the object, and returns it to follow
+func (f *appendFollower) startObject(key string, size, count int64, bytesMode bool) (*followedObject, error) {
+	start := max(size-count, 0)
+	if !bytesMode {
+		cr := &catRangeReader{bucket: f.bucket, object: key, options: f.options}
+		var err error
+		if start, err = tailLinesStart(cr.readRange, size, count); err != nil {
+			return nil, err
+		}
+	}
+
+	obj := &followedObject{key: key, position: start, crcKnown: start == 0}
+	for obj.position < size {
+		if _, err := f.poll(obj, size); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// followObject follows the single object until the process is interrupted, waits for the object deleted to be created again
+func (f *appendFollower) followObject(obj *followedObject) error {
+	delay := f.interval
+	for {
+		time.Sleep(delay)
+		props, err := f.command.ossGetObjectStatRetry(f.bucket, obj.key, f.options...)
+		if err != nil && !isNoSuchKeyError(err) {
+			return err
+		}
+
+		progressed := false
+		if err != nil {
+			if obj.position > 0 || len(obj.pending) > 0 {
+				f.reset(obj, "is deleted")
+			}
+		} else if props.Get(StatObjectType) != "Appendable" {
+			return fmt.Errorf("%s is overwritten by the %s object, can't follow it any more",
+				CloudURLToString(f.bucket.BucketName, obj.key), props.Get(StatObjectType))
+		} else {
+			size, err := strconv.ParseInt(props.Get(oss.HTTPHeaderContentLength), 10, 64)
+			if err != nil {
+				return err
+			}
+			if progressed, err = f.poll(obj, size); err != nil {
+				return err
+			}
+		}
+		delay = f.nextDelay(delay, progressed)
+	}
+}
+
+// followPrefix follows all the appendable objects under the prefix until the process is interrupted,
+// the objects created later are output from the beginning
+func (f *appendFollower) followPrefix(prefix string) error {
+	delay := f.interval
+	for {
+		time.Sleep(delay)
+		progressed := false
+		listed := map[string]bool{}
+		err := f.listAppendable(prefix, func(object oss.ObjectProperties) error {
+			listed[object.Key] = true
+			obj, ok := f.objects[object.Key]
+			if !ok {
+				obj = &followedObject{key: object.Key, crcKnown: true}
+				f.objects[object.Key] = obj
+			}
+			polled, err := f.poll(obj, object.Size)
+			progressed = progressed || polled
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for key, obj := range f.objects {
+			if !listed[key] {
+				f.flushPending(obj)
+				fmt.Fprintf(os.Stderr, "%s is deleted or not appendable any more, stop following it\n", CloudURLToString(f.bucket.BucketName, key))
+				delete(f.objects, key)
+			}
+		}
+		delay = f.nextDelay(delay, progressed)
+	}
+}
+
+// nextDelay doubles the delay up to the max interval if there is no new content
+func (f *appendFollower) nextDelay(delay time.Duration, progressed bool) time.Duration {
+	if progressed {
+		return f.interval
+	}
+	if delay *= 2; delay > f.maxInterval {
+		delay = f.maxInterval
+	}
+	return delay
+}
+
+// listAppendable calls fn for each appendable object under the prefix
+func (f *appendFollower) listAppendable(prefix string, fn func(object oss.ObjectProperties) error) error {
+	pre := oss.Prefix(prefix)
+	marker := oss.Marker("")
+	for {
+		lor, err := f.command.ossListObjectsRetry(f.bucket, append(f.options, marker, pre)...)
+		if err != nil {
+			return err
+		}
+		for _, object := range lor.Objects {
+			if object.Type != "Appendable" {
+				continue
+			}
+			if err := fn(object); err != nil {
+				return err
+			}
+		}
+		marker = oss.Marker(lor.NextMarker)
+		if !lor.IsTruncated {
+			return nil
+		}
+	}
+}
+
+// poll outputs the content of the object from the position to the size, and returns whether there is new content.
+// The content output is validated by the crc64 of the object, the object is output from the beginning if
+// it is truncated or overwritten
+func (f *appendFollower) poll(obj *followedObject, size int64) (bool, error) {
+	if size < obj.position {
+		f.reset(obj, "is truncated or overwritten")
+	}
+	if size == obj.position {
+		return false, nil
+	}
+
+	end := size
+	if end-obj.position > followReadSize {
+		end = obj.position + followReadSize
+	}
+	options := append(append([]oss.Option{}, f.options...), oss.Range(obj.position, end-1))
+	var data []byte
+	var header http.Header
+	err := f.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		result, err := f.bucket.DoGetObject(&oss.GetObjectRequest{ObjectKey: obj.key}, withResponseHeader(options, respHeader))
+		if err != nil {
+			return err
+		}
+		defer result.Response.Body.Close()
+		header = result.Response.Headers
+		data, err = ioutil.ReadAll(result.Response.Body)
+		return err
+	})
+	if isNoSuchKeyError(err) || isInvalidRangeError(err) {
+		// overwritten by a shorter object after the size is got
+		f.reset(obj, "is truncated or overwritten")
+		return true, nil
+	}
+	if err != nil {
+		return false, ObjectError{err, f.bucket.BucketName, obj.key}
+	}
+	if int64(len(data)) != end-obj.position {
+		return false, fmt.Errorf("read %d bytes of %s, expect %d", len(data), CloudURLToString(f.bucket.BucketName, obj.key), end-obj.position)
+	}
+
+	crc := oss.CRC64Combine(obj.crc, crc64.Checksum(data, crc64.MakeTable(crc64.ECMA)), uint64(len(data)))
+	total := contentRangeTotal(header.Get("Content-Range"))
+	serverCRC, errCRC := strconv.ParseUint(header.Get(oss.HTTPHeaderOssCRC64), 10, 64)
+	if total == end && errCRC == nil {
+		// the crc64 of the whole object validates the content output before
+		if obj.crcKnown && crc != serverCRC {
+			f.reset(obj, "is overwritten")
+			return true, nil
+		}
+		crc = serverCRC
+		obj.crcKnown = true
+	}
+
+	if obj.envelope != nil {
+		reader, err := obj.envelope.Reader(bytes.NewReader(data), obj.position)
+		if err != nil {
+			return false, err
+		}
+		if data, err = ioutil.ReadAll(reader); err != nil {
+			return false, err
+		}
+	}
+	if err := f.write(obj, data); err != nil {
+		return false, err
+	}
+	obj.position = end
+	obj.crc = crc
+	return true, nil
+}
+
+// reset outputs the object from the beginning again
+func (f *appendFollower) reset(obj *followedObject, reason string) {
+	f.flushPending(obj)
+	fmt.Fprintf(os.Stderr, "%s %s, output from the beginning\n", CloudURLToString(f.bucket.BucketName, obj.key), reason)
+	obj.position = 0
+	obj.crc = 0
+	obj.crcKnown = true
+}
+
+// write outputs the content, with "key: " before each line if the key prefix is required
+func (f *appendFollower) write(obj *followedObject, data []byte) error {
+	if !f.keyPrefix {
+		_, err := f.out.Write(data)
+		return err
+	}
+
+	data = append(obj.pending, data...)
+	var buffer bytes.Buffer
+	for {
+		pos := bytes.IndexByte(data, '\n')
+		if pos < 0 {
+			break
+		}
+		buffer.WriteString(obj.key)
+		buffer.WriteString(": ")
+		buffer.Write(data[:pos+1])
+		data = data[pos+1:]
+	}
+	obj.pending = append([]byte{}, data...)
+	_, err := f.out.Write(buffer.Bytes())
+	return err
+}
+
+// flushPending outputs the last line not ended yet, when the object won't be appended any more
+func (f *appendFollower) flushPending(obj *followedObject) {
+	if len(obj.pending) > 0 {
+		fmt.Fprintf(f.out, "%s: %s\n", obj.key, obj.pending)
+		obj.pending = nil
+	}
+}
+
+// contentRangeTotal returns the total size in the Content-Range like "bytes 0-9/100", or -1 if it's unknown
+func contentRangeTotal(contentRange string) int64 {
+	pos := strings.LastIndex(contentRange, "/")
+	if pos < 0 {
+		return -1
+	}
+	total, err := strconv.ParseInt(strings.TrimSpace(contentRange[pos+1:]), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return total
+}
+
+func isInvalidRangeError(err error) bool {
+	if objectErr, ok := err.(ObjectError); ok {
+		err = objectErr.err
+	}
+	switch e := err.(type) {
+	case oss.ServiceError:
+		return e.StatusCode == http.StatusRequestedRangeNotSatisfiable
+	case oss.UnexpectedStatusCodeError:
+		return e.Got() == http.StatusRequestedRangeNotSatisfiable
+	}
+	return false
+}
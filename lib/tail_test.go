@@ -0,0 +1,222 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"hash/crc64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	oss "github.com/aliyun/aliyun-oss-go-sdk/oss"
+	. "gopkg.in/check.v1"
+)
+
+// appendObjectServer serves the appendable objects of the bucket by path style, for HEAD, ranged GET and list
+type appendObjectServer struct {
+	mutex   sync.Mutex
+	objects map[string]string
+}
+
+func (as *appendObjectServer) set(key, content string) {
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+	as.objects[key] = content
+}
+
+func (as *appendObjectServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+
+	path := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if len(path) < 2 || path[1] == "" {
+		var buffer bytes.Buffer
+		buffer.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated>`)
+		for key, content := range as.objects {
+			if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+				fmt.Fprintf(&buffer, "<Contents><Key>%s</Key><Size>%d</Size><Type>Appendable</Type></Contents>", key, len(content))
+			}
+		}
+		buffer.WriteString(`<Contents><Key>normal.txt</Key><Size>1</Size><Type>Normal</Type></Contents></ListBucketResult>`)
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write(buffer.Bytes())
+		return
+	}
+
+	content, ok := as.objects[path[1]]
+	if r.Method == http.MethodPost {
+		as.append(w, r, path[1], content)
+		return
+	}
+	if r.Method == http.MethodDelete {
+		delete(as.objects, path[1])
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set(StatObjectType, "Appendable")
+	w.Header().Set(oss.HTTPHeaderOssNextAppendPosition, strconv.Itoa(len(content)))
+	w.Header().Set(oss.HTTPHeaderOssCRC64, strconv.FormatUint(crc64.Checksum([]byte(content), crc64.MakeTable(crc64.ECMA)), 10))
+	if r.Method == http.MethodHead {
+		w.Header().Set(oss.HTTPHeaderContentLength, strconv.Itoa(len(content)))
+		return
+	}
+
+	var start, end int
+	if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil || start >= len(content) {
+		w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if end >= len(content) {
+		end = len(content) - 1
+	}
+	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+	w.WriteHeader(http.StatusPartialContent)
+	w.Write([]byte(content[start : end+1]))
+}
+
+// append appends the body at the position, PositionNotEqualToLength is returned if it isn't the length
+func (as *appendObjectServer) append(w http.ResponseWriter, r *http.Request, key, content string) {
+	crc := func(content string) string {
+		return strconv.FormatUint(crc64.Checksum([]byte(content), crc64.MakeTable(crc64.ECMA)), 10)
+	}
+	if r.URL.Query().Get("position") != strconv.Itoa(len(content)) {
+		w.Header().Set(oss.HTTPHeaderOssNextAppendPosition, strconv.Itoa(len(content)))
+		w.Header().Set(oss.HTTPHeaderOssCRC64, crc(content))
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>PositionNotEqualToLength</Code><Message>Position is not equal to file length</Message></Error>`))
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	content += string(body)
+	as.objects[key] = content
+	w.Header().Set(oss.HTTPHeaderOssNextAppendPosition, strconv.Itoa(len(content)))
+	w.Header().Set(oss.HTTPHeaderOssCRC64, crc(content))
+}
+
+func (s *OssutilCommandSuite) newTestAppendFollower(c *C, as *appendObjectServer) (*appendFollower, *bytes.Buffer, func()) {
+	svr := httptest.NewServer(as)
+	client, err := oss.New(svr.URL, "ak", "sk")
+	c.Assert(err, IsNil)
+	bucket, err := client.Bucket("bucket")
+	c.Assert(err, IsNil)
+
+	follower, err := newAppendFollower(&Command{options: OptionMapType{}}, bucket, nil)
+	c.Assert(err, IsNil)
+	var out bytes.Buffer
+	follower.out = &out
+	return follower, &out, svr.Close
+}
+
+func (s *OssutilCommandSuite) TestTailFollowObject(c *C) {
+	as := &appendObjectServer{objects: map[string]string{"app.log": "a\nb\nc\n"}}
+	follower, out, closeServer := s.newTestAppendFollower(c, as)
+	defer closeServer()
+
+	obj, err := follower.startObject("app.log", 6, 2, false)
+	c.Assert(err, IsNil)
+	c.Assert(out.String(), Equals, "b\nc\n")
+	c.Assert(obj.position, Equals, int64(6))
+	c.Assert(obj.crcKnown, Equals, true)
+
+	// appended
+	as.set("app.log", "a\nb\nc\nd\n")
+	progressed, err := follower.poll(obj, 8)
+	c.Assert(err, IsNil)
+	c.Assert(progressed, Equals, true)
+	c.Assert(out.String(), Equals, "b\nc\nd\n")
+	progressed, err = follower.poll(obj, 8)
+	c.Assert(err, IsNil)
+	c.Assert(progressed, Equals, false)
+
+	// overwritten by the longer object, the content output before is different
+	out.Reset()
+	as.set("app.log", "x\ny\nz\nw\nv\n")
+	progressed, err = follower.poll(obj, 10)
+	c.Assert(err, IsNil)
+	c.Assert(progressed, Equals, true)
+	c.Assert(out.Len(), Equals, 0)
+	c.Assert(obj.position, Equals, int64(0))
+	_, err = follower.poll(obj, 10)
+	c.Assert(err, IsNil)
+	c.Assert(out.String(), Equals, "x\ny\nz\nw\nv\n")
+
+	// truncated
+	out.Reset()
+	as.set("app.log", "q\n")
+	_, err = follower.poll(obj, 2)
+	c.Assert(err, IsNil)
+	c.Assert(out.String(), Equals, "q\n")
+
+	// overwritten after the size is got
+	out.Reset()
+	_, err = follower.poll(obj, 4)
+	c.Assert(err, IsNil)
+	c.Assert(obj.position, Equals, int64(0))
+	_, err = follower.poll(obj, 2)
+	c.Assert(err, IsNil)
+	c.Assert(out.String(), Equals, "q\n")
+
+	// the unknown content before the start is validated by the crc64 of the whole object later
+	out.Reset()
+	as.set("app.log", "0123456789")
+	obj, err = follower.startObject("app.log", 10, 3, true)
+	c.Assert(err, IsNil)
+	c.Assert(out.String(), Equals, "789")
+	c.Assert(obj.crcKnown, Equals, true)
+}
+
+func (s *OssutilCommandSuite) TestTailFollowPrefix(c *C) {
+	as := &appendObjectServer{objects: map[string]string{"logs/a.log": "a1\na2", "logs/b.log": "b1\n", "other.log": "o\n"}}
+	follower, out, closeServer := s.newTestAppendFollower(c, as)
+	defer closeServer()
+	follower.keyPrefix = true
+
+	var keys []string
+	err := follower.listAppendable("logs/", func(object oss.ObjectProperties) error {
+		obj, err := follower.startObject(object.Key, object.Size, 10, false)
+		follower.objects[object.Key] = obj
+		keys = append(keys, object.Key)
+		return err
+	})
+	c.Assert(err, IsNil)
+	c.Assert(len(keys), Equals, 2)
+	c.Assert(strings.Contains(out.String(), "logs/a.log: a1\n"), Equals, true)
+	c.Assert(strings.Contains(out.String(), "logs/b.log: b1\n"), Equals, true)
+	c.Assert(strings.Contains(out.String(), "a2"), Equals, false)
+
+	// the line is output after it ends
+	out.Reset()
+	obj := follower.objects["logs/a.log"]
+	as.set("logs/a.log", "a1\na2 end\na3")
+	_, err = follower.poll(obj, 12)
+	c.Assert(err, IsNil)
+	c.Assert(out.String(), Equals, "logs/a.log: a2 end\n")
+
+	out.Reset()
+	follower.flushPending(obj)
+	c.Assert(out.String(), Equals, "logs/a.log: a3\n")
+
+	follower.interval = 100 * time.Millisecond
+	follower.maxInterval = 250 * time.Millisecond
+	c.Assert(follower.nextDelay(100*time.Millisecond, false), Equals, 200*time.Millisecond)
+	c.Assert(follower.nextDelay(200*time.Millisecond, false), Equals, 250*time.Millisecond)
+	c.Assert(follower.nextDelay(250*time.Millisecond, true), Equals, 100*time.Millisecond)
+
+	interval := "2000"
+	maxInterval := "1000"
+	_, err = newAppendFollower(&Command{options: OptionMapType{OptionInterval: &interval, OptionMaxInterval: &maxInterval}}, follower.bucket, nil)
+	c.Assert(err, NotNil)
+
+	c.Assert(contentRangeTotal("bytes 0-9/100"), Equals, int64(100))
+	c.Assert(contentRangeTotal("bytes 0-9/*"), Equals, int64(-1))
+	c.Assert(contentRangeTotal(""), Equals, int64(-1))
+}
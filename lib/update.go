@@ -78,6 +78,9 @@ var updateCommand = UpdateCommand{
 		validOptionNames: []string{
 			OptionForce,
 			OptionRetryTimes,
+			OptionRetryBaseDelay,
+			OptionRetryMaxDelay,
+			OptionRetryMaxElapsed,
 			OptionLanguage,
 			OptionProxyHost,
 			OptionProxyUser,
@@ -194,16 +197,13 @@ func (uc *UpdateCommand) getLastestVersion() (string, error) {
 
 func (uc *UpdateCommand) anonymousGetToFileRetry(bucketName, objectName, filePath string) error {
 	host := fmt.Sprintf("http://%s.%s/%s", bucketName, vUpdateEndpoint, objectName)
-	retryTimes, _ := GetInt(OptionRetryTimes, uc.command.options)
-	for i := 1; ; i++ {
-		err := uc.ossAnonymousGetToFile(host, filePath)
-		if err == nil {
-			return err
-		}
-		if int64(i) >= retryTimes {
-			return ObjectError{err, bucketName, objectName}
-		}
+	err := uc.command.retryPolicy().Do(func(attempt int, respHeader *http.Header) error {
+		return uc.ossAnonymousGetToFile(host, filePath)
+	})
+	if err != nil {
+		return ObjectError{err, bucketName, objectName}
 	}
+	return nil
 }
 
 func (uc *UpdateCommand) ossAnonymousGetToFile(host, filePath string) error {
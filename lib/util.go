@@ -190,42 +190,40 @@ func filterObjectsFromChanWithPattern(srcCh <-chan string, pattern string, dstCh
 }
 
 // Following for strings
-func getFilter(cmdline []string) (bool, []filterOptionType) {
+// getFilter gets the --include, --exclude, --include-regex, --exclude-regex and --exclude-from
+// in the order of the command line, the later filters override the former ones
+func getFilter(cmdline []string) ([]filterOptionType, error) {
 	filters := make([]filterOptionType, 0)
 	for i, item := range cmdline {
-		var strTag = ""
-		if strings.Index(item, IncludePrompt) == 0 {
-			strTag = IncludePrompt
-		} else if strings.Index(item, ExcludePrompt) == 0 {
-			strTag = ExcludePrompt
-		}
-
-		if strTag != "" {
-			var filter filterOptionType
-			var strArg string
-
-			filter.name = strTag
-			if item == strTag {
-				strArg = cmdline[i+1]
-			} else if item[len(strTag)] == '=' {
-				strArg = item[len(strTag)+1:]
+		var filter filterOptionType
+		for _, prompt := range filterPrompts {
+			if item == prompt {
+				if i+1 < len(cmdline) {
+					filter = filterOptionType{prompt, cmdline[i+1]}
+				}
+				break
 			}
-
-			if strArg == "" {
-				continue
+			if strings.HasPrefix(item, prompt+"=") {
+				filter = filterOptionType{prompt, item[len(prompt)+1:]}
+				break
 			}
+		}
 
+		if filter.pattern == "" {
+			continue
+		}
+
+		if filter.name == IncludePrompt || filter.name == ExcludePrompt {
 			// To support standard glob
-			filter.pattern = strings.Replace(strArg, "[!", "[^", -1)
-			dir, _ := filepath.Split(filter.pattern)
-			if dir != "" {
-				return false, filters
-			}
-			filters = append(filters, filter)
+			filter.pattern = strings.Replace(filter.pattern, "[!", "[^", -1)
 		}
+		if _, err := getFilterMatcher(filter); err != nil {
+			return filters, err
+		}
+		filters = append(filters, filter)
 	}
 
-	return true, filters
+	return filters, nil
 }
 
 func containsInStrsSlice(vs []string, t string) bool {
@@ -283,18 +281,16 @@ func matchFiltersForStr(str string, filters []filterOptionType) bool {
 		return true
 	}
 
-	var res bool
-	if filters[0].name == IncludePrompt {
-		res = filterSingleStr(str, filters[0].pattern, true)
-	} else {
-		res = filterSingleStr(str, filters[0].pattern, false)
+	res := matchFilterPattern(str, filters[0])
+	if !isIncludeFilter(filters[0].name) {
+		res = !res
 	}
 
 	for _, filter := range filters[1:] {
-		if filter.name == IncludePrompt {
-			res = res || filterSingleStr(str, filter.pattern, true)
+		if isIncludeFilter(filter.name) {
+			res = res || matchFilterPattern(str, filter)
 		} else {
-			res = res && filterSingleStr(str, filter.pattern, false)
+			res = res && !matchFilterPattern(str, filter)
 		}
 	}
 
@@ -533,7 +529,7 @@ func currentHomeDir() string {
 	return homeDir
 }
 
-func getCurrentDirFileListCommon(dpath string, chFiles chan<- fileInfoType, filters []filterOptionType) error {
+func getCurrentDirFileListCommon(dpath string, chFiles chan<- fileInfoType, filters []filterOptionType, attrFilter *attributeFilter) error {
 	if !strings.HasSuffix(dpath, string(os.PathSeparator)) {
 		dpath += string(os.PathSeparator)
 	}
@@ -551,7 +547,7 @@ func getCurrentDirFileListCommon(dpath string, chFiles chan<- fileInfoType, filt
 				continue
 			}
 
-			if doesSingleFileMatchPatterns(fileInfo.Name(), filters) {
+			if doesSingleFileMatchPatterns(fileInfo.Name(), filters) && attrFilter.matchFilePath(dpath+fileInfo.Name(), fileInfo) {
 				chFiles <- fileInfoType{fileInfo.Name(), dpath}
 			}
 		}
@@ -560,10 +556,10 @@ func getCurrentDirFileListCommon(dpath string, chFiles chan<- fileInfoType, filt
 }
 
 func getFileListCommon(dpath string, chFiles chan<- fileInfoType, onlyCurrentDir bool, disableAllSymlink bool,
-	enableSymlinkDir bool, filters []filterOptionType) error {
+	enableSymlinkDir bool, filters []filterOptionType, attrFilter *attributeFilter) error {
 	defer close(chFiles)
 	if onlyCurrentDir {
-		return getCurrentDirFileListCommon(dpath, chFiles, filters)
+		return getCurrentDirFileListCommon(dpath, chFiles, filters, attrFilter)
 	}
 
 	name := dpath
@@ -615,7 +611,7 @@ func getFileListCommon(dpath string, chFiles chan<- fileInfoType, onlyCurrentDir
 			}
 		}
 
-		if doesSingleFileMatchPatterns(fileName, filters) {
+		if doesSingleFileMatchPatterns(fileName, filters) && attrFilter.matchFilePath(fpath, f) {
 			chFiles <- fileInfoType{fileName, name}
 		}
 		return nil
@@ -639,7 +635,7 @@ func getFileListCommon(dpath string, chFiles chan<- fileInfoType, onlyCurrentDir
 }
 
 func getObjectListCommon(bucket *oss.Bucket, cloudURL CloudURL, chObjects chan<- objectInfoType,
-	onlyCurrentDir bool, filters []filterOptionType, payerOptions []oss.Option) error {
+	onlyCurrentDir bool, filters []filterOptionType, attrFilter *attributeFilter, payerOptions []oss.Option) error {
 	defer close(chObjects)
 	pre := oss.Prefix(cloudURL.object)
 	marker := oss.Marker("")
@@ -668,7 +664,7 @@ func getObjectListCommon(bucket *oss.Bucket, cloudURL CloudURL, chObjects chan<-
 				relativeKey = object.Key[index+1:]
 			}
 
-			if doesSingleObjectMatchPatterns(object.Key, filters) {
+			if doesSingleObjectMatchPatterns(relativeKey, filters) {
 				if strings.ToLower(object.Type) == "symlink" {
 					props, err := bucket.GetObjectDetailedMeta(object.Key, payerOptions...)
 					if err != nil {
@@ -683,7 +679,14 @@ func getObjectListCommon(bucket *oss.Bucket, cloudURL CloudURL, chObjects chan<-
 					}
 					object.Size = size
 				}
-				chObjects <- objectInfoType{prefix, relativeKey, int64(object.Size), object.LastModified}
+				match, err := attrFilter.matchObject(bucket, object, payerOptions...)
+				if err != nil {
+					return err
+				}
+				if !match {
+					continue
+				}
+				chObjects <- objectInfoType{prefix: prefix, relativeKey: relativeKey, size: int64(object.Size), lastModified: object.LastModified}
 			}
 		}
 
@@ -112,6 +112,42 @@ func (c *Client) AssumeRole(tokenTimeout uint, stsEndPoint string) (*Response, e
 	return c.handleResponse(body, status)
 }
 
+// AssumeRoleWithOIDC assume role with the OIDC token, the request needs no signature
+func (c *Client) AssumeRoleWithOIDC(oidcProviderArn, oidcToken string, tokenTimeout uint, stsEndPoint string) (*Response, error) {
+	host := StsHost
+	if stsEndPoint != "" {
+		host = stsEndPoint
+	}
+
+	form := url.Values{}
+	form.Set("Format", RespBodyFormat)
+	form.Set("Version", StsAPIVersion)
+	form.Set("Action", "AssumeRoleWithOIDC")
+	form.Set("Timestamp", time.Now().UTC().Format(TimeFormat))
+	form.Set("RoleArn", c.RoleArn)
+	form.Set("OIDCProviderArn", oidcProviderArn)
+	form.Set("OIDCToken", oidcToken)
+	form.Set("RoleSessionName", c.SessionName)
+	form.Set("DurationSeconds", strconv.FormatUint((uint64)(tokenTimeout), 10))
+
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{},
+	}
+	client := &http.Client{Transport: tr}
+
+	resp, err := client.PostForm(host, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return c.handleResponse(body, resp.StatusCode)
+}
+
 // Private function
 func (c *Client) generateSignedURL(expiredTime uint) (string, error) {
 	randId := strings.ToUpper(randStr(24))